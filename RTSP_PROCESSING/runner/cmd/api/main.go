@@ -4,6 +4,9 @@ import (
 	"context"
 	"log"
 	"net"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"google.golang.org/grpc"
@@ -12,6 +15,7 @@ import (
 	"runner/internal/env"
 	"runner/internal/grpc_api/v1/global_handler"
 	"runner/internal/infrastructure/inference_service"
+	"runner/internal/infrastructure/registry_client"
 	"runner/internal/infrastructure/s3"
 	"runner/internal/infrastructure/worker_manager"
 	pb "runner/proto/server/runner/v1"
@@ -31,6 +35,52 @@ func loggingInterceptor(ctx context.Context, req interface{}, info *grpc.UnarySe
 	return resp, err
 }
 
+// runHeartbeat периодически сообщает scheduler'у адрес, ёмкость и запущенные воркеры runner'а
+// и останавливает воркеры, отозванные scheduler'ом.
+// Первый heartbeat отправляется сразу, чтобы узел стал доступен для размещения без ожидания интервала.
+func runHeartbeat(ctx context.Context, client *registry_client.Client, cfg env.RegistryEnv, workerManager *worker_manager.WorkerManager) {
+	ticker := time.NewTicker(cfg.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		leases := workerManager.Leases()
+		workers := make([]registry_client.WorkerLease, 0, len(leases))
+		for _, lease := range leases {
+			workers = append(workers, registry_client.WorkerLease{
+				CameraID:     int32(lease.CameraID),
+				FencingToken: lease.FencingToken,
+			})
+		}
+
+		revoked, err := client.Heartbeat(ctx, registry_client.NodeInfo{
+			NodeID:      cfg.NodeID,
+			Address:     cfg.AdvertiseAddress,
+			Capacity:    cfg.Capacity,
+			WorkerCount: int32(len(leases)),
+			Workers:     workers,
+		})
+		if err != nil {
+			log.Printf("heartbeat failed: node_id=%s error=%v", cfg.NodeID, err)
+		}
+
+		// Камеры, переразмещенные scheduler'ом на другие runner'ы, пока этот runner не присылал
+		// heartbeat'ы, останавливаются здесь, чтобы не обрабатывать их дважды
+		for _, cameraID := range revoked {
+			if err := workerManager.RemoveWorker(int(cameraID)); err != nil {
+				log.Printf("failed to revoke worker: camera_id=%d error=%v", cameraID, err)
+				continue
+			}
+			log.Printf("worker revoked: camera_id=%d", cameraID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 func main() {
 	cfg := env.LoadEnv()
 
@@ -56,7 +106,16 @@ func main() {
 	workerManager := worker_manager.NewWorkerManager()
 	defer workerManager.Close()
 
-	lis, err := net.Listen("tcp", ":50052")
+	registryClient, err := registry_client.New(registry_client.Config{
+		Address: cfg.Registry.Address,
+		Timeout: cfg.Registry.Timeout,
+	})
+	if err != nil {
+		log.Fatalf("failed to create registry client: %v", err)
+	}
+	defer registryClient.Close()
+
+	lis, err := net.Listen("tcp", cfg.GRPC.ListenAddress)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
@@ -68,7 +127,25 @@ func main() {
 
 	reflection.Register(s)
 
-	log.Printf("server listening at %v", lis.Addr())
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go runHeartbeat(ctx, registryClient, cfg.Registry, workerManager)
+
+	go func() {
+		<-ctx.Done()
+
+		// Сначала снимаем узел с учёта, чтобы scheduler перестал размещать на нём новые камеры
+		deregisterCtx, cancel := context.WithTimeout(context.Background(), cfg.Registry.Timeout)
+		defer cancel()
+		if err := registryClient.Deregister(deregisterCtx, cfg.Registry.NodeID); err != nil {
+			log.Printf("failed to deregister node: node_id=%s error=%v", cfg.Registry.NodeID, err)
+		}
+
+		s.GracefulStop()
+	}()
+
+	log.Printf("server listening at %v node_id=%s", lis.Addr(), cfg.Registry.NodeID)
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("failed to serve: %v", err)
 	}
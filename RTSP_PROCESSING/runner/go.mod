@@ -14,6 +14,13 @@ require (
 	gocv.io/x/gocv v0.42.0
 	google.golang.org/grpc v1.77.0
 	google.golang.org/protobuf v1.36.10
+	shared v0.0.0
+)
+
+require (
+	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
+	github.com/xdg-go/scram v1.1.2 // indirect
+	github.com/xdg-go/stringprep v1.0.4 // indirect
 )
 
 require (
@@ -47,3 +54,5 @@ require (
 	golang.org/x/text v0.30.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20251022142026-3a174f9686a8 // indirect
 )
+
+replace shared => ../../shared
@@ -61,7 +61,7 @@ func NewApp() (App, error) {
 	})
 
 	// Инициализация Kafka consumer
-	kafkaConfig := kafka.DefaultConfig(cfg.Consumer.KafkaBrokers...)
+	kafkaConfig := NewKafkaConfig(cfg)
 	kafkaConfig.ConsumerGroup = cfg.Consumer.KafkaConsumerGroup
 
 	topics := []string{cfg.Consumer.KafkaInboxInferenceTopic}
@@ -88,3 +88,16 @@ func InitDB(ctx context.Context, cfg *config.Config) (*pgxpool.Pool, error) {
 
 	return dbPool, nil
 }
+
+// NewKafkaConfig создает настройки подключения к Kafka с аутентификацией и TLS из cfg
+func NewKafkaConfig(cfg *config.Config) *kafka.Config {
+	return kafka.DefaultConfig(cfg.Consumer.KafkaBrokers...).
+		WithSASL(cfg.Kafka.SASLMechanism, cfg.Kafka.Username, cfg.Kafka.Password).
+		WithTLS(kafka.TLSConfig{
+			Enabled:            cfg.Kafka.TLS.Enabled,
+			CAFile:             cfg.Kafka.TLS.CAFile,
+			CertFile:           cfg.Kafka.TLS.CertFile,
+			KeyFile:            cfg.Kafka.TLS.KeyFile,
+			InsecureSkipVerify: cfg.Kafka.TLS.InsecureSkipVerify,
+		})
+}
@@ -40,7 +40,22 @@ type DatabaseConfig struct {
 
 type KafkaConfig struct {
 	Brokers []string
+	// SASLMechanism - PLAIN, SCRAM-SHA-256 или SCRAM-SHA-512. Пустой - без аутентификации
+	SASLMechanism string
+	Username      string
+	Password      string
+	TLS           KafkaTLSConfig
 }
+
+type KafkaTLSConfig struct {
+	Enabled  bool
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	// InsecureSkipVerify отключает проверку сертификата брокера, только для разработки
+	InsecureSkipVerify bool
+}
+
 type PoolConfig struct {
 	MaxConns          int32
 	MinConns          int32
@@ -125,6 +140,28 @@ func Load() (*Config, error) {
 	if len(cfg.Kafka.Brokers) == 0 {
 		cfg.Kafka.Brokers = []string{"localhost:9092", "localhost:9093", "localhost:9094"}
 	}
+
+	cfg.Kafka.Username = getEnv("KAFKA_USERNAME", "")
+	cfg.Kafka.Password = getEnv("KAFKA_PASSWORD", "")
+	// Заданные учетные данные без механизма означают SASL PLAIN
+	defaultSASLMechanism := ""
+	if cfg.Kafka.Username != "" {
+		defaultSASLMechanism = "PLAIN"
+	}
+	cfg.Kafka.SASLMechanism = strings.ToUpper(getEnv("KAFKA_SASL_MECHANISM", defaultSASLMechanism))
+
+	cfg.Kafka.TLS.Enabled, err = getEnvAsBool("KAFKA_TLS_ENABLED", false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KAFKA_TLS_ENABLED: %w", err)
+	}
+	cfg.Kafka.TLS.CAFile = getEnv("KAFKA_TLS_CA_FILE", "")
+	cfg.Kafka.TLS.CertFile = getEnv("KAFKA_TLS_CERT_FILE", "")
+	cfg.Kafka.TLS.KeyFile = getEnv("KAFKA_TLS_KEY_FILE", "")
+	cfg.Kafka.TLS.InsecureSkipVerify, err = getEnvAsBool("KAFKA_TLS_INSECURE_SKIP_VERIFY", false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KAFKA_TLS_INSECURE_SKIP_VERIFY: %w", err)
+	}
+
 	return cfg, nil
 }
 
@@ -147,6 +184,18 @@ func getEnvAsInt(key string, defaultValue int) (int, error) {
 	return value, nil
 }
 
+func getEnvAsBool(key string, defaultValue bool) (bool, error) {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.ParseBool(strings.TrimSpace(valueStr))
+	if err != nil {
+		return false, err
+	}
+	return value, nil
+}
+
 func parseList(s string) []string {
 	if s == "" {
 		return []string{}
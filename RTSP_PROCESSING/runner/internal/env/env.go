@@ -3,6 +3,7 @@ package env
 import (
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -20,9 +21,24 @@ type InferenceEnv struct {
 	Timeout time.Duration
 }
 
+type GRPCEnv struct {
+	ListenAddress string
+}
+
+type RegistryEnv struct {
+	Address           string
+	NodeID            string
+	AdvertiseAddress  string
+	Capacity          int32
+	HeartbeatInterval time.Duration
+	Timeout           time.Duration
+}
+
 type Env struct {
 	S3        S3Env
 	Inference InferenceEnv
+	GRPC      GRPCEnv
+	Registry  RegistryEnv
 }
 
 func LoadEnv() *Env {
@@ -45,9 +61,29 @@ func LoadEnv() *Env {
 				return d
 			}(),
 		},
+		GRPC: GRPCEnv{
+			ListenAddress: GetEnv("RUNNER_GRPC_LISTEN_ADDRESS", ":50052"),
+		},
+		Registry: RegistryEnv{
+			Address:           GetEnv("SCHEDULER_REGISTRY_ADDRESS", "localhost:50053"),
+			NodeID:            GetEnv("RUNNER_NODE_ID", defaultNodeID()),
+			AdvertiseAddress:  GetEnv("RUNNER_ADVERTISE_ADDRESS", "localhost:50052"),
+			Capacity:          getEnvInt32("RUNNER_CAPACITY", 10),
+			HeartbeatInterval: getEnvDuration("RUNNER_HEARTBEAT_INTERVAL", 10*time.Second),
+			Timeout:           getEnvDuration("SCHEDULER_REGISTRY_TIMEOUT", 5*time.Second),
+		},
 	}
 }
 
+// defaultNodeID использует hostname, чтобы в контейнерах node_id совпадал с именем пода
+func defaultNodeID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return "runner"
+	}
+	return hostname
+}
+
 func findAndLoadEnv() {
 	dir, err := os.Getwd()
 	if err != nil {
@@ -69,6 +105,22 @@ func findAndLoadEnv() {
 	}
 }
 
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	d, err := time.ParseDuration(GetEnv(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return d
+}
+
+func getEnvInt32(key string, defaultValue int32) int32 {
+	v, err := strconv.ParseInt(GetEnv(key, ""), 10, 32)
+	if err != nil {
+		return defaultValue
+	}
+	return int32(v)
+}
+
 func GetEnv(key string, defaultValue string) string {
 	value := os.Getenv(key)
 	if value == "" {
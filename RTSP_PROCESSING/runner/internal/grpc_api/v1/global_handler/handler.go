@@ -39,6 +39,7 @@ func (h *RunnerServiceHandler) StartWorker(ctx context.Context, req *pb.StartWor
 
 	worker := obtain_frame_worker.ObtainFrameWorkerNew(req.Url, nil, h.inferenceClient, h.s3Client)
 	worker.CameraID = cameraID
+	worker.FencingToken = req.FencingToken
 
 	if err := h.workerManager.AddWorker(worker); err != nil {
 		return &pb.StartWorkerResponse{
@@ -33,8 +33,14 @@ func NewKafkaConsumer(cfg *Config, topics []string, logger *zap.Logger) (Consume
 		return nil, fmt.Errorf("consumer group cannot be empty")
 	}
 
+	dialer, err := cfg.dialer()
+	if err != nil {
+		return nil, fmt.Errorf("invalid kafka security config: %w", err)
+	}
+
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:        cfg.Brokers,
+		Dialer:         dialer,
 		GroupID:        cfg.ConsumerGroup,
 		GroupTopics:    topics,
 		MinBytes:       10e3, // 10KB
@@ -36,7 +36,7 @@ func TestKafkaConsumerReadMessage(t *testing.T) {
 	// Создаем топик перед отправкой сообщения
 	topicName := "test-consumer-topic"
 	t.Log("ensuring topic exists...")
-	if err := EnsureTopic(ctx, brokers, topicName, 3, 3); err != nil {
+	if err := EnsureTopic(ctx, producerCfg, topicName, 3, 3); err != nil {
 		t.Fatalf("failed to ensure topic exists: %v", err)
 	}
 
@@ -128,7 +128,7 @@ func TestKafkaConsumerReadMessages(t *testing.T) {
 	// Создаем топик перед отправкой сообщений
 	topicName := "test-consumer-batch-topic"
 	t.Log("ensuring topic exists...")
-	if err := EnsureTopic(ctx, brokers, topicName, 3, 3); err != nil {
+	if err := EnsureTopic(ctx, producerCfg, topicName, 3, 3); err != nil {
 		t.Fatalf("failed to ensure topic exists: %v", err)
 	}
 
@@ -25,8 +25,14 @@ func NewKafkaProducer(cfg *Config, logger *zap.Logger) (Producer, error) {
 		return nil, fmt.Errorf("invalid kafka config: %w", err)
 	}
 
+	transport, err := cfg.transport()
+	if err != nil {
+		return nil, fmt.Errorf("invalid kafka security config: %w", err)
+	}
+
 	writer := &kafka.Writer{
 		Addr:                   kafka.TCP(cfg.Brokers...),
+		Transport:              transport,
 		Balancer:               &kafka.LeastBytes{},
 		ReadTimeout:            time.Duration(cfg.ReadTimeout) * time.Second,
 		WriteTimeout:           time.Duration(cfg.WriteTimeout) * time.Second,
@@ -153,6 +159,11 @@ func (p *KafkaProducer) Close() error {
 		return fmt.Errorf("close kafka writer: %w", err)
 	}
 
+	// Writer закрывает соединения только своего транспорта по умолчанию
+	if transport, ok := p.writer.Transport.(*kafka.Transport); ok {
+		transport.CloseIdleConnections()
+	}
+
 	if p.logger != nil {
 		p.logger.Info("kafka producer closed")
 	}
@@ -34,7 +34,7 @@ func TestKafkaProducerSendJSONMessage(t *testing.T) {
 
 	// Создаем топик перед отправкой сообщения
 	t.Log("ensuring topic exists...")
-	if err := EnsureTopic(ctx, cfg.Brokers, "test-topic", 3, 3); err != nil {
+	if err := EnsureTopic(ctx, cfg, "test-topic", 3, 3); err != nil {
 		t.Fatalf("failed to ensure topic exists: %v", err)
 	}
 
@@ -87,7 +87,7 @@ func TestKafkaProducerSendMessagesWithoutKey(t *testing.T) {
 	// Создаем топик перед отправкой сообщений
 	topicName := "test-topic-without-key-1"
 	t.Log("ensuring topic exists...")
-	if err := EnsureTopic(ctx, cfg.Brokers, topicName, 3, 3); err != nil {
+	if err := EnsureTopic(ctx, cfg, topicName, 3, 3); err != nil {
 		t.Fatalf("failed to ensure topic exists: %v", err)
 	}
 
@@ -8,12 +8,13 @@ import (
 )
 
 // CreateTopic создает топик с указанными параметрами
-func CreateTopic(ctx context.Context, brokers []string, topic string, partitions int, replicationFactor int) error {
-	if len(brokers) == 0 {
-		return fmt.Errorf("brokers list cannot be empty")
+func CreateTopic(ctx context.Context, cfg *Config, topic string, partitions int, replicationFactor int) error {
+	dialer, err := adminDialer(cfg)
+	if err != nil {
+		return err
 	}
 
-	conn, err := kafka.Dial("tcp", brokers[0])
+	conn, err := dialer.DialContext(ctx, "tcp", cfg.Brokers[0])
 	if err != nil {
 		return fmt.Errorf("failed to dial kafka: %w", err)
 	}
@@ -24,7 +25,7 @@ func CreateTopic(ctx context.Context, brokers []string, topic string, partitions
 		return fmt.Errorf("failed to get controller: %w", err)
 	}
 
-	controllerConn, err := kafka.Dial("tcp", fmt.Sprintf("%s:%d", controller.Host, controller.Port))
+	controllerConn, err := dialer.DialContext(ctx, "tcp", fmt.Sprintf("%s:%d", controller.Host, controller.Port))
 	if err != nil {
 		return fmt.Errorf("failed to dial controller: %w", err)
 	}
@@ -48,13 +49,14 @@ func CreateTopic(ctx context.Context, brokers []string, topic string, partitions
 
 // EnsureTopic проверяет существование топика и создает его при необходимости
 // Использует параметры по умолчанию: 1 партиция, replication factor 1
-func EnsureTopic(ctx context.Context, brokers []string, topic string, partitions, replicationFactor int) error {
-	if len(brokers) == 0 {
-		return fmt.Errorf("brokers list cannot be empty")
+func EnsureTopic(ctx context.Context, cfg *Config, topic string, partitions, replicationFactor int) error {
+	dialer, err := adminDialer(cfg)
+	if err != nil {
+		return err
 	}
 
 	// Пробуем получить метаданные топика
-	conn, err := kafka.Dial("tcp", brokers[0])
+	conn, err := dialer.DialContext(ctx, "tcp", cfg.Brokers[0])
 	if err != nil {
 		return fmt.Errorf("failed to dial kafka: %w", err)
 	}
@@ -68,16 +70,17 @@ func EnsureTopic(ctx context.Context, brokers []string, topic string, partitions
 	}
 
 	// Топик не существует, создаем его
-	return CreateTopic(ctx, brokers, topic, partitions, replicationFactor)
+	return CreateTopic(ctx, cfg, topic, partitions, replicationFactor)
 }
 
 // TopicExists проверяет существование топика
-func TopicExists(ctx context.Context, brokers []string, topic string) (bool, error) {
-	if len(brokers) == 0 {
-		return false, fmt.Errorf("brokers list cannot be empty")
+func TopicExists(ctx context.Context, cfg *Config, topic string) (bool, error) {
+	dialer, err := adminDialer(cfg)
+	if err != nil {
+		return false, err
 	}
 
-	conn, err := kafka.Dial("tcp", brokers[0])
+	conn, err := dialer.DialContext(ctx, "tcp", cfg.Brokers[0])
 	if err != nil {
 		return false, fmt.Errorf("failed to dial kafka: %w", err)
 	}
@@ -90,3 +93,16 @@ func TopicExists(ctx context.Context, brokers []string, topic string) (bool, err
 
 	return len(partitions) > 0, nil
 }
+
+// adminDialer создает dialer для прямых подключений к брокерам с настройками SASL и TLS из cfg
+func adminDialer(cfg *Config) (*kafka.Dialer, error) {
+	if cfg == nil || len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("brokers list cannot be empty")
+	}
+
+	dialer, err := cfg.dialer()
+	if err != nil {
+		return nil, fmt.Errorf("invalid kafka security config: %w", err)
+	}
+	return dialer, nil
+}
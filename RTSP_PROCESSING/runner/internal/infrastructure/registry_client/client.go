@@ -0,0 +1,111 @@
+package registry_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	registrypb "runner/proto/client/registry/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type Client struct {
+	client  registrypb.RunnerRegistryServiceClient
+	conn    *grpc.ClientConn
+	timeout time.Duration
+}
+
+type Config struct {
+	Address string
+	Timeout time.Duration
+}
+
+// NodeInfo описывает runner так, как его видит scheduler при выборе узла для камеры
+type NodeInfo struct {
+	NodeID      string
+	Address     string
+	Capacity    int32
+	WorkerCount int32
+	Workers     []WorkerLease
+}
+
+type WorkerLease struct {
+	CameraID     int32
+	FencingToken int64
+}
+
+func New(cfg Config) (*Client, error) {
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("address cannot be empty")
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	conn, err := grpc.NewClient(cfg.Address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to registry service: %w", err)
+	}
+
+	return &Client{
+		client:  registrypb.NewRunnerRegistryServiceClient(conn),
+		conn:    conn,
+		timeout: cfg.Timeout,
+	}, nil
+}
+
+// Heartbeat сообщает scheduler'у состояние runner'а и возвращает камеры, воркеры которых
+// нужно остановить, потому что камеры переразмещены на другой runner или их сценарий остановлен
+func (c *Client) Heartbeat(ctx context.Context, node NodeInfo) ([]int32, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	workers := make([]*registrypb.WorkerLease, 0, len(node.Workers))
+	for _, lease := range node.Workers {
+		workers = append(workers, &registrypb.WorkerLease{
+			CameraId:     lease.CameraID,
+			FencingToken: lease.FencingToken,
+		})
+	}
+
+	resp, err := c.client.Heartbeat(ctx, &registrypb.HeartbeatRequest{
+		NodeId:      node.NodeID,
+		Address:     node.Address,
+		Capacity:    node.Capacity,
+		WorkerCount: node.WorkerCount,
+		Workers:     workers,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("heartbeat: %w", err)
+	}
+	if !resp.GetSuccess() {
+		return nil, errors.New(resp.GetError())
+	}
+	return resp.GetRevokedCameraIds(), nil
+}
+
+func (c *Client) Deregister(ctx context.Context, nodeID string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.Deregister(ctx, &registrypb.DeregisterRequest{
+		NodeId: nodeID,
+	})
+	if err != nil {
+		return fmt.Errorf("deregister: %w", err)
+	}
+	if !resp.GetSuccess() {
+		return errors.New(resp.GetError())
+	}
+	return nil
+}
+
+func (c *Client) Close() error {
+	if c.conn != nil {
+		return c.conn.Close()
+	}
+	return nil
+}
@@ -17,16 +17,37 @@ func NewWorkerManager() *WorkerManager {
 	}
 }
 
+type Lease struct {
+	CameraID     int
+	FencingToken int64
+}
+
+// AddWorker запускает воркер камеры. Если воркер камеры уже запущен, решение принимается по
+// fencing token'у: тот же токен означает повторный запрос (воркер уже работает), больший -
+// камеру переразместили на этот runner заново и старый воркер заменяется, меньший - запрос
+// устарел и отклоняется.
 func (wm *WorkerManager) AddWorker(worker *obtain_frame_worker.ObtainFrameWorker) error {
 	wm.mu.Lock()
 	defer wm.mu.Unlock()
-	_, ok := wm.workers[worker.CameraID]
+	existing, ok := wm.workers[worker.CameraID]
+	if ok {
+		switch {
+		case existing.FencingToken == worker.FencingToken:
+			return nil
+		case existing.FencingToken > worker.FencingToken:
+			return fmt.Errorf("worker already exists with newer fencing token %d", existing.FencingToken)
+		}
+	}
+
+	if err := worker.Init(); err != nil {
+		return fmt.Errorf("init worker: %w", err)
+	}
+
 	if ok {
-		return fmt.Errorf("worker already exists")
+		existing.Close()
 	}
 
 	wm.workers[worker.CameraID] = worker
-	worker.Init()
 	go worker.Run()
 
 	return nil
@@ -57,6 +78,21 @@ func (wm *WorkerManager) RemoveWorker(cameraID int) error {
 	return nil
 }
 
+// Leases возвращает камеры запущенных воркеров с их fencing token'ами для heartbeat'а
+func (wm *WorkerManager) Leases() []Lease {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	leases := make([]Lease, 0, len(wm.workers))
+	for cameraID, worker := range wm.workers {
+		leases = append(leases, Lease{
+			CameraID:     cameraID,
+			FencingToken: worker.FencingToken,
+		})
+	}
+	return leases
+}
+
 func (wm *WorkerManager) Close() {
 	wm.mu.Lock()
 	defer wm.mu.Unlock()
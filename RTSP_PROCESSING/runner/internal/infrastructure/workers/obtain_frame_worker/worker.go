@@ -16,6 +16,7 @@ import (
 
 type ObtainFrameWorker struct {
 	CameraID        int
+	FencingToken    int64 // токен размещения камеры, с которым scheduler запустил воркер
 	skipFrames      *int
 	url             string
 	videoCap        *gocv.VideoCapture
@@ -36,10 +37,17 @@ func ObtainFrameWorkerNew(url string, skipFrames *int, inferenceClient Inference
 	}
 }
 
-func (w *ObtainFrameWorker) Init() {
+// Init открывает RTSP поток. Ошибка открытия возвращается вызывающему, чтобы запуск
+// сценария можно было скомпенсировать, а не ронять весь runner.
+func (w *ObtainFrameWorker) Init() error {
 	cap, err := gocv.OpenVideoCapture(w.url)
 	if err != nil {
-		log.Fatalf("failed to open stream: %v", err)
+		return fmt.Errorf("failed to open stream: %w", err)
+	}
+
+	if !cap.IsOpened() {
+		cap.Close()
+		return fmt.Errorf("failed to open stream: %s is not available", w.url)
 	}
 
 	w.videoCap = cap
@@ -51,6 +59,7 @@ func (w *ObtainFrameWorker) Init() {
 		w.skipFrames = &skipFrames
 	}
 
+	return nil
 }
 
 func (w *ObtainFrameWorker) Run() error {
@@ -78,6 +87,9 @@ func (w *ObtainFrameWorker) Run() error {
 }
 
 func (w *ObtainFrameWorker) Close() error {
+	if w.videoCap == nil {
+		return nil
+	}
 	if err := w.videoCap.Close(); err != nil {
 		return err
 	}
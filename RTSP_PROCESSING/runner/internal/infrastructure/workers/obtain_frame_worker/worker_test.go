@@ -35,7 +35,9 @@ func TestRTSPStreamGrabFrameWorker(t *testing.T) {
 	fmt.Println("Before Creating worker")
 	obtainFrameWorker := ObtainFrameWorkerNew("rtsp://localhost:8554/mystream", nil, svc, nil)
 	fmt.Println("Before Initializing worker")
-	obtainFrameWorker.Init() // Initialize the worker before running
+	if err := obtainFrameWorker.Init(); err != nil { // Initialize the worker before running
+		t.Fatalf("failed to init worker: %v", err)
+	}
 	fmt.Println("Initialized worker")
 	go func() {
 		err := obtainFrameWorker.Run()
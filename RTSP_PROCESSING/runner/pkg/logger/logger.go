@@ -3,12 +3,12 @@ package logger
 import (
 	"context"
 
+	sharedlogger "shared/logger"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-type ctxKey struct{}
-
 // InitLogger инициализирует zap логгер с JSON форматом для Loki
 func InitLogger() (*zap.Logger, error) {
 	config := zap.NewProductionConfig()
@@ -42,18 +42,11 @@ func InitLogger() (*zap.Logger, error) {
 
 // WithContext возвращает контекст, в который вложен логгер
 func WithContext(ctx context.Context, lg *zap.Logger) context.Context {
-	return context.WithValue(ctx, ctxKey{}, lg)
+	return sharedlogger.WithContext(ctx, lg)
 }
 
 // FromContext извлекает логгер из контекста.
 // Если его нет — возвращает "noop" логгер.
 func FromContext(ctx context.Context) *zap.Logger {
-	v := ctx.Value(ctxKey{})
-	if v == nil {
-		return zap.NewNop()
-	}
-	if lg, ok := v.(*zap.Logger); ok {
-		return lg
-	}
-	return zap.NewNop()
+	return sharedlogger.FromContext(ctx)
 }
@@ -22,9 +22,13 @@ const (
 )
 
 type StartWorkerRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	CameraId      string                 `protobuf:"bytes,1,opt,name=camera_id,json=cameraId,proto3" json:"camera_id,omitempty"`
-	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	CameraId string                 `protobuf:"bytes,1,opt,name=camera_id,json=cameraId,proto3" json:"camera_id,omitempty"`
+	Url      string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	// Fencing token размещения камеры. Монотонно растет при каждом переразмещении камеры
+	// scheduler'ом; runner отдает его обратно в heartbeat'е, чтобы устаревшие воркеры
+	// можно было отозвать
+	FencingToken  int64 `protobuf:"varint,3,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -73,6 +77,13 @@ func (x *StartWorkerRequest) GetUrl() string {
 	return ""
 }
 
+func (x *StartWorkerRequest) GetFencingToken() int64 {
+	if x != nil {
+		return x.FencingToken
+	}
+	return 0
+}
+
 type StartWorkerResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -225,10 +236,11 @@ var File_runner_v1_runner_proto protoreflect.FileDescriptor
 
 const file_runner_v1_runner_proto_rawDesc = "" +
 	"\n" +
-	"\x16runner/v1/runner.proto\x12\trunner.v1\"C\n" +
+	"\x16runner/v1/runner.proto\x12\trunner.v1\"h\n" +
 	"\x12StartWorkerRequest\x12\x1b\n" +
 	"\tcamera_id\x18\x01 \x01(\tR\bcameraId\x12\x10\n" +
-	"\x03url\x18\x02 \x01(\tR\x03url\"E\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12#\n" +
+	"\rfencing_token\x18\x03 \x01(\x03R\ffencingToken\"E\n" +
 	"\x13StartWorkerResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
 	"\x05error\x18\x02 \x01(\tR\x05error\"2\n" +
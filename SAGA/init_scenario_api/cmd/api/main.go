@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,10 +12,16 @@ import (
 	"init_scenario_api/config"
 	_ "init_scenario_api/docs"
 	loggerMiddleware "init_scenario_api/internal/api/middleware"
+	"init_scenario_api/internal/api/v1/get_scenario"
 	"init_scenario_api/internal/api/v1/health"
 	"init_scenario_api/internal/api/v1/init_scenario"
+	"init_scenario_api/internal/api/v1/outbox_admin"
+	"init_scenario_api/internal/api/v1/stop_scenario"
 	"init_scenario_api/internal/application"
+	getScenarioUseCase "init_scenario_api/internal/usecase/get_scenario"
 	initScenarioUseCase "init_scenario_api/internal/usecase/init_scenario"
+	outboxAdminUseCase "init_scenario_api/internal/usecase/outbox_admin"
+	stopScenarioUseCase "init_scenario_api/internal/usecase/stop_scenario"
 	"init_scenario_api/pkg/common"
 
 	"github.com/go-chi/chi/v5"
@@ -45,9 +52,19 @@ func run() int {
 
 	app.Logger.Info("application initialized successfully")
 
-	initScenarioUC := initScenarioUseCase.NewUseCase(app.PostgresRepo)
+	stopScenarioUC := stopScenarioUseCase.NewUseCase(app.PostgresRepo)
+	stopScenarioHandler := stop_scenario.NewHandler(stopScenarioUC)
+
+	// В режиме replace=true init останавливает занимающий камеру сценарий через stop use case
+	initScenarioUC := initScenarioUseCase.NewUseCase(app.PostgresRepo, stopScenarioUC)
 	initScenarioHandler := init_scenario.NewHandler(initScenarioUC)
 
+	getScenarioUC := getScenarioUseCase.NewUseCase(app.PostgresRepo)
+	getScenarioHandler := get_scenario.NewHandler(getScenarioUC)
+
+	outboxAdminUC := outboxAdminUseCase.NewUseCase(app.PostgresRepo)
+	outboxAdminHandler := outbox_admin.NewHandler(outboxAdminUC)
+
 	r := chi.NewRouter()
 
 	r.Use(loggerMiddleware.New(app.Logger).Handle)
@@ -56,8 +73,19 @@ func run() int {
 		r.Get("/health", health.HealthCheckHandler)
 
 		r.Post("/scenario/init", initScenarioHandler.InitScenario)
+		r.Post("/scenario/{uuid}/stop", stopScenarioHandler.StopScenario)
+		r.Get("/scenario/{uuid}", getScenarioHandler.GetScenario)
+		r.Get("/scenarios", getScenarioHandler.ListScenarios)
 	})
 
+	// Административные ручки меняют состояние outbox, поэтому слушают отдельный адрес и не
+	// попадают в публичный API
+	adminRouter := chi.NewRouter()
+	adminRouter.Use(loggerMiddleware.New(app.Logger).Handle)
+	adminRouter.Use(loggerMiddleware.AdminAuth(cfg.API.AdminToken))
+	adminRouter.Get("/admin/outbox/failed", outboxAdminHandler.ListFailed)
+	adminRouter.Post("/admin/outbox/requeue", outboxAdminHandler.Requeue)
+
 	addr := fmt.Sprintf(":%d", cfg.API.Port)
 
 	srv := &http.Server{
@@ -68,12 +96,20 @@ func run() int {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	adminSrv := &http.Server{
+		Addr:         cfg.API.AdminAddr,
+		Handler:      adminRouter,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
 	app.Closer.Add(func() error {
 		app.Logger.Info("shutting down HTTP server...")
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		if err := srv.Shutdown(ctx); err != nil {
+		if err := errors.Join(srv.Shutdown(ctx), adminSrv.Shutdown(ctx)); err != nil {
 			app.Logger.Error("HTTP server shutdown error", zap.Error(err))
 			return err
 		}
@@ -88,6 +124,13 @@ func run() int {
 		}
 	}()
 
+	go func() {
+		app.Logger.Info("admin server starting", zap.String("addr", cfg.API.AdminAddr))
+		if err := adminSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			app.Logger.Error("admin ListenAndServe error", zap.Error(err))
+		}
+	}()
+
 	app.Closer.Wait()
 
 	app.Logger.Info("application stopped")
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"init_scenario_api/config"
+	"init_scenario_api/internal/application"
+	"init_scenario_api/internal/infastructure/kafka"
+	modelerror "init_scenario_api/internal/models/error"
+	kafkaModels "init_scenario_api/internal/models/kafka"
+	"init_scenario_api/internal/usecase/scenario_compensation"
+	"init_scenario_api/internal/usecase/scenario_result_processor"
+	"init_scenario_api/pkg/common"
+	"init_scenario_api/pkg/logger"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	app, err := application.NewApp()
+	if err != nil {
+		log.Fatalf("failed to initialize application: %v", err)
+		return common.FailExitCode
+	}
+
+	app.Logger.Info("consumer service starting")
+
+	ctx, cancel := context.WithCancel(logger.WithContext(context.Background(), app.Logger))
+	defer cancel()
+
+	app.Closer.Add(func() error {
+		app.Logger.Info("cancelling consumer context...")
+		cancel()
+		return nil
+	})
+
+	kafkaConfig := application.NewKafkaConfig(app.Config)
+	kafkaConfig.ConsumerGroup = app.Config.Consumer.KafkaConsumerGroup
+
+	consumer, err := kafka.NewKafkaConsumer(
+		kafkaConfig,
+		[]string{kafkaModels.ScenarioResultTopic},
+		app.Logger,
+	)
+	if err != nil {
+		app.Logger.Error("failed to create kafka consumer", zap.Error(err))
+		return common.FailExitCode
+	}
+	app.Closer.Add(func() error {
+		app.Logger.Info("closing kafka consumer")
+		return consumer.Close()
+	})
+
+	compensationUsecase := scenario_compensation.NewUseCase(app.PostgresRepo)
+	scenarioResultUsecase := scenario_result_processor.NewUseCase(app.PostgresRepo, compensationUsecase)
+
+	go runConsumer(ctx, app.Logger, consumer, app.KafkaProducer, app.Config.Consumer, scenarioResultUsecase)
+
+	app.Closer.Wait()
+
+	app.Logger.Info("consumer service stopped")
+	return common.SuccessExitCode
+}
+
+// runConsumer обрабатывает результаты запуска и остановки сценариев. Ошибки чтения и обработки
+// повторяются с экспоненциальной задержкой без ограничения числа попыток: сообщение подтверждается
+// только после того, как результат применен. Сообщение, которое не удалось разобрать или которое не
+// прошло проверку, сначала отправляется в cfg.DeadLetterTopic и подтверждается, чтобы не задерживать
+// подтверждение следующих сообщений партиции
+func runConsumer(
+	ctx context.Context,
+	lg *zap.Logger,
+	consumer kafka.Consumer,
+	producer kafka.Producer,
+	cfg config.ConsumerConfig,
+	scenarioResultUsecase *scenario_result_processor.UseCase,
+) {
+	lg.Info("consumer worker started")
+
+	readFailures := 0
+	for {
+		if ctx.Err() != nil {
+			lg.Info("consumer worker stopping...")
+			return
+		}
+
+		msg, err := consumer.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				continue
+			}
+			readFailures++
+			delay := retryDelay(cfg, readFailures)
+			lg.Error("failed to read message", zap.Duration("retry_in", delay), zap.Error(err))
+			wait(ctx, delay)
+			continue
+		}
+		readFailures = 0
+
+		msgLog := lg.With(
+			zap.String("outbox_uuid", string(msg.Headers[kafkaModels.OutboxUUIDHeader])),
+			zap.String("event_type", string(msg.Headers[kafkaModels.EventTypeHeader])),
+		)
+
+		if err := handleMessage(ctx, msgLog, msg, cfg, scenarioResultUsecase); err != nil {
+			if ctx.Err() != nil {
+				// Остановка: сообщение не подтверждается и будет прочитано заново
+				return
+			}
+			if err := deadLetter(ctx, msgLog, producer, cfg, msg, err); err != nil {
+				return
+			}
+		}
+
+		if err := consumer.CommitMessages(ctx, msg); err != nil {
+			msgLog.Error("failed to commit message", zap.Error(err))
+			continue
+		}
+		msgLog.Info("message processed and committed successfully")
+	}
+}
+
+// handleMessage разбирает и обрабатывает сообщение. Возвращает ошибку разбора или проверки
+// сообщения (ErrInvalidMessage) либо ошибку ctx. Остальные ошибки обработки повторяются
+// с экспоненциальной задержкой, пока результат не будет применен
+func handleMessage(
+	ctx context.Context,
+	msgLog *zap.Logger,
+	msg *kafka.Message,
+	cfg config.ConsumerConfig,
+	scenarioResultUsecase *scenario_result_processor.UseCase,
+) error {
+	outboxUUID, err := uuid.Parse(string(msg.Headers[kafkaModels.OutboxUUIDHeader]))
+	if err != nil {
+		return fmt.Errorf("failed to parse outbox_uuid header: %w", err)
+	}
+
+	event, err := kafkaModels.Schemas.DecodeMessage(msg.Headers, msg.Value, kafkaModels.OutboxUUIDHeader, kafkaModels.EventTypeHeader)
+	if err != nil {
+		return fmt.Errorf("failed to decode scenario result: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := scenarioResultUsecase.ProcessScenarioResult(ctx, outboxUUID, event)
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, modelerror.ErrInvalidMessage) {
+			return fmt.Errorf("failed to process scenario result: %w", err)
+		}
+
+		delay := retryDelay(cfg, attempt)
+		msgLog.Error("failed to process scenario result, retrying",
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", delay),
+			zap.Error(err),
+		)
+
+		if !wait(ctx, delay) {
+			return ctx.Err()
+		}
+	}
+}
+
+// deadLetter публикует сообщение в cfg.DeadLetterTopic с исходным payload и заголовками ошибки.
+// Публикация повторяется до успеха: подтвердить сообщение без копии значит потерять его.
+// Ошибка возвращается, только если ctx отменен
+func deadLetter(ctx context.Context, msgLog *zap.Logger, producer kafka.Producer, cfg config.ConsumerConfig, msg *kafka.Message, cause error) error {
+	if cfg.DeadLetterTopic == "" {
+		msgLog.Error("dropping message that cannot be processed", zap.Error(cause))
+		return nil
+	}
+
+	headers := make(map[string][]byte, len(msg.Headers)+3)
+	for name, value := range msg.Headers {
+		headers[name] = value
+	}
+	headers[kafkaModels.DeadLetterErrorHeader] = []byte(cause.Error())
+	headers[kafkaModels.DeadLetterOriginalTopicHeader] = []byte(msg.Topic)
+	headers[kafkaModels.DeadLetterFailedAtHeader] = []byte(time.Now().UTC().Format(time.RFC3339Nano))
+
+	msgLog.Error("message sent to dead letter topic",
+		zap.String("dead_letter_topic", cfg.DeadLetterTopic),
+		zap.Error(cause),
+	)
+
+	deadMsg := kafka.NewMessage(cfg.DeadLetterTopic, msg.Key, msg.Value, headers)
+	for attempt := 1; ; attempt++ {
+		err := producer.SendMessage(ctx, deadMsg)
+		if err == nil {
+			return nil
+		}
+
+		delay := retryDelay(cfg, attempt)
+		msgLog.Error("failed to publish message to dead letter topic, retrying",
+			zap.Duration("retry_in", delay),
+			zap.Error(err),
+		)
+
+		if !wait(ctx, delay) {
+			return ctx.Err()
+		}
+	}
+}
+
+// retryDelay возвращает экспоненциальную задержку перед повтором после attempt неудачных попыток
+func retryDelay(cfg config.ConsumerConfig, attempt int) time.Duration {
+	return min(cfg.RetryBaseDelay<<min(attempt-1, 30), cfg.RetryMaxDelay)
+}
+
+// wait ждет d и возвращает false, если ctx отменили раньше
+func wait(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
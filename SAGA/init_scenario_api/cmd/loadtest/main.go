@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 const (
@@ -49,6 +51,7 @@ func main() {
 			for cameraID := range jobs {
 				start := time.Now()
 				res := result{cameraID: cameraID}
+				idempotencyKey := uuid.NewString()
 
 				body, err := json.Marshal(payload{
 					CameraID: cameraID,
@@ -69,6 +72,8 @@ func main() {
 
 				req.Header.Set("Content-Type", "application/json")
 				req.Header.Set("Accept", "application/json")
+				// Ключ на каждую камеру: повтор запроса по таймауту не создаст второй сценарий
+				req.Header.Set("Idempotency-Key", idempotencyKey)
 
 				resp, err := client.Do(req)
 				if err != nil {
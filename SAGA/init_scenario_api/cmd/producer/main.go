@@ -6,15 +6,32 @@ import (
 	"os"
 	"time"
 
+	"init_scenario_api/config"
 	"init_scenario_api/internal/application"
 	"init_scenario_api/internal/infastructure/kafka"
+	"init_scenario_api/internal/infastructure/replication"
+	"init_scenario_api/internal/models/entity"
 	kafkaModels "init_scenario_api/internal/models/kafka"
+	"init_scenario_api/internal/usecase/outbox_cdc_relay"
 	"init_scenario_api/internal/usecase/outbox_scenario_processor"
 	"init_scenario_api/pkg/common"
+	"init_scenario_api/pkg/database"
+	"init_scenario_api/pkg/logger"
+	genericOutbox "shared/outbox"
 
 	"go.uber.org/zap"
 )
 
+const (
+	// outboxBatchSize - максимальное количество outbox записей в одном батче публикации
+	outboxBatchSize = 1000
+	// outboxLease - на сколько захваченные события общего outbox скрываются от других экземпляров producer
+	outboxLease = time.Minute
+	// cdcStatusInterval - период отправки подтвержденной позиции слота, меньше wal_sender_timeout
+	cdcStatusInterval = 10 * time.Second
+	cdcReconnectDelay = 5 * time.Second
+)
+
 func main() {
 	os.Exit(run())
 }
@@ -22,7 +39,7 @@ func main() {
 func run() int {
 	app, err := application.NewApp()
 	if err != nil {
-		log.Fatalf("failed to initialize application: %w", err)
+		log.Fatalf("failed to initialize application: %v", err)
 		return common.FailExitCode
 	}
 
@@ -36,11 +53,79 @@ func run() int {
 		cancel()
 		return nil
 	})
-	outboxScenarioUsecase := outbox_scenario_processor.NewUseCase(app.PostgresRepo, app.KafkaProducer)
+	outboxScenarioUsecase := outbox_scenario_processor.NewUseCase(app.PostgresRepo, app.KafkaProducer, outbox_scenario_processor.RetryPolicy{
+		MaxAttempts: app.Config.Producer.OutboxMaxAttempts,
+		BaseDelay:   app.Config.Producer.OutboxRetryBaseDelay,
+		MaxDelay:    app.Config.Producer.OutboxRetryMaxDelay,
+	})
+
+	prepapeKafka(ctx, app.Logger, application.NewKafkaConfig(app.Config))
+
+	// Общий outbox публикуется выборкой во всех режимах: notify и cdc настроены только на outbox_scenario
+	genericRelay := genericOutbox.NewRelay(app.PostgresRepo.Outbox(), app.PostgresRepo, kafka.NewOutboxSender(app.KafkaProducer), genericOutbox.Config{
+		BatchSize: outboxBatchSize,
+		Lease:     outboxLease,
+		Retry: genericOutbox.RetryPolicy{
+			MaxAttempts: app.Config.Producer.OutboxMaxAttempts,
+			BaseDelay:   app.Config.Producer.OutboxRetryBaseDelay,
+			MaxDelay:    app.Config.Producer.OutboxRetryMaxDelay,
+		},
+	})
+	go runGenericRelay(logger.WithContext(ctx, app.Logger), app.Logger, genericRelay, app.Config.Producer.OutboxPollInterval)
+
+	if app.Config.Producer.OutboxRelayMode == config.OutboxRelayModeCDC {
+		relay := outbox_cdc_relay.NewUseCase(outboxScenarioUsecase, outbox_cdc_relay.Config{
+			Topic:          kafkaModels.OutboxScenarioTopic,
+			BatchSize:      outboxBatchSize,
+			FlushInterval:  app.Config.Producer.OutboxCDCFlushInterval,
+			RetryBaseDelay: app.Config.Producer.OutboxRetryBaseDelay,
+			RetryMaxDelay:  app.Config.Producer.OutboxRetryMaxDelay,
+		})
+		replicationCfg := replication.Config{
+			DSN:            database.DSN(app.Config.Database),
+			Slot:           app.Config.Producer.OutboxReplicationSlot,
+			Publication:    app.Config.Producer.OutboxPublication,
+			StatusInterval: cdcStatusInterval,
+		}
+
+		app.Logger.Info("outbox relay configured",
+			zap.String("mode", app.Config.Producer.OutboxRelayMode),
+			zap.String("slot", replicationCfg.Slot),
+			zap.Duration("safety_poll_interval", app.Config.Producer.OutboxSafetyPollInterval),
+		)
+
+		go runCDCRelay(logger.WithContext(ctx, app.Logger), app.Logger, relay, replicationCfg)
+
+		// Слот отдает только вставки: записи, возвращенные в pending админкой (в том числе failed
+		// из-за несоответствия схеме), публикует редкая страховочная выборка
+		go runProducer(ctx, app.Logger, outboxScenarioUsecase, nil, app.Config.Producer.OutboxSafetyPollInterval)
+
+		app.Closer.Wait()
+
+		app.Logger.Info("producer service stopped")
+		return common.SuccessExitCode
+	}
+
+	// wake с буфером 1: пачка NOTIFY во время обработки батча схлопывается в одну выборку
+	wake := make(chan struct{}, 1)
+	interval := app.Config.Producer.OutboxPollInterval
+
+	if app.Config.Producer.OutboxRelayMode == config.OutboxRelayModeNotify {
+		interval = app.Config.Producer.OutboxSafetyPollInterval
+		listener := database.NewListener(app.PostgresRepo.GetDBPool(), entity.OutboxScenarioNotifyChannel)
+		go func() {
+			if err := listener.Run(logger.WithContext(ctx, app.Logger), wake); err != nil {
+				app.Logger.Error("outbox listener stopped", zap.Error(err))
+			}
+		}()
+	}
 
-	prepapeKafka(ctx, app.Logger, app.Config.Kafka.Brokers)
+	app.Logger.Info("outbox relay configured",
+		zap.String("mode", app.Config.Producer.OutboxRelayMode),
+		zap.Duration("poll_interval", interval),
+	)
 
-	go runProducer(ctx, app.Logger, outboxScenarioUsecase)
+	go runProducer(ctx, app.Logger, outboxScenarioUsecase, wake, interval)
 
 	app.Closer.Wait()
 
@@ -48,8 +133,11 @@ func run() int {
 	return common.SuccessExitCode
 }
 
-func runProducer(ctx context.Context, lg *zap.Logger, outboxScenarioUsecase *outbox_scenario_processor.UseCase) {
-	ticker := time.NewTicker(3 * time.Second)
+// runProducer публикует outbox по таймеру и по сигналам wake. В режиме poll сигналов нет,
+// в режимах notify и cdc таймер служит страховкой на случай потерянных уведомлений и записей,
+// которые вернулись в pending без новой вставки
+func runProducer(ctx context.Context, lg *zap.Logger, outboxScenarioUsecase *outbox_scenario_processor.UseCase, wake <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	lg.Info("producer worker started")
@@ -59,23 +147,99 @@ func runProducer(ctx context.Context, lg *zap.Logger, outboxScenarioUsecase *out
 		case <-ctx.Done():
 			lg.Info("producer worker stopping...")
 			return
+		case <-wake:
+			processOutbox(ctx, lg, outboxScenarioUsecase, "notify")
 		case <-ticker.C:
-			lg.Info("producer processing tick",
-				zap.Time("timestamp", time.Now()),
-				zap.String("status", "processing"),
-			)
-			outboxScenarioUsecase.ProcessScenarioOutboxMessages(ctx, kafkaModels.OutboxScenarioTopic, 1000)
-			lg.Info("producer processed tick",
-				zap.Time("timestamp", time.Now()),
-				zap.String("status", "processed"),
-			)
+			processOutbox(ctx, lg, outboxScenarioUsecase, "tick")
+		}
+	}
+}
+
+// runGenericRelay публикует события общего outbox по таймеру, пока батчи приходят полными
+func runGenericRelay(ctx context.Context, lg *zap.Logger, relay *genericOutbox.Relay, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			lg.Info("generic outbox relay stopping...")
+			return
+		case <-ticker.C:
+			for ctx.Err() == nil {
+				processed, err := relay.ProcessBatch(ctx)
+				if err != nil {
+					lg.Error("failed to process generic outbox batch", zap.Error(err))
+				}
+				if err != nil || processed < outboxBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// runCDCRelay держит стрим из слота репликации и переподключается после ошибок. Слот может
+// читать только одно соединение, поэтому второй экземпляр producer будет ждать в переподключениях,
+// пока первый не остановится
+func runCDCRelay(ctx context.Context, lg *zap.Logger, relay *outbox_cdc_relay.UseCase, cfg replication.Config) {
+	lg.Info("cdc relay started")
+
+	for {
+		err := runCDCStream(ctx, relay, cfg)
+		if ctx.Err() != nil {
+			lg.Info("cdc relay stopping...")
+			return
+		}
+		lg.Error("cdc relay stream failed, reconnecting", zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			lg.Info("cdc relay stopping...")
+			return
+		case <-time.After(cdcReconnectDelay):
+		}
+	}
+}
+
+func runCDCStream(ctx context.Context, relay *outbox_cdc_relay.UseCase, cfg replication.Config) error {
+	stream, err := replication.Connect(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	if err := stream.Start(ctx); err != nil {
+		return err
+	}
+
+	return relay.Run(ctx, stream)
+}
+
+// processOutbox повторяет выборку, пока батчи доставляются: отправка события открывает
+// следующее событие того же ключа, о котором отдельного уведомления не будет
+func processOutbox(ctx context.Context, lg *zap.Logger, outboxScenarioUsecase *outbox_scenario_processor.UseCase, trigger string) {
+	lg.Debug("producer processing outbox", zap.String("trigger", trigger))
+
+	for ctx.Err() == nil {
+		processed, err := outboxScenarioUsecase.ProcessScenarioOutboxMessages(ctx, kafkaModels.OutboxScenarioTopic, outboxBatchSize)
+		if err != nil {
+			lg.Error("failed to process outbox batch", zap.Error(err))
+			return
+		}
+		if processed == 0 {
+			return
 		}
+		lg.Info("producer processed outbox batch",
+			zap.String("trigger", trigger),
+			zap.Int("processed_count", processed),
+		)
 	}
 }
 
-func prepapeKafka(ctx context.Context, lg *zap.Logger, brokers []string) {
+func prepapeKafka(ctx context.Context, lg *zap.Logger, kafkaConfig *kafka.Config) {
 
-	err := kafka.EnsureTopic(ctx, brokers, kafkaModels.OutboxScenarioTopic, 3, 3)
+	err := kafka.EnsureTopic(ctx, kafkaConfig, kafkaModels.OutboxScenarioTopic, 3, 3)
 	if err != nil {
 		lg.Error("failed to ensure topic exists", zap.Error(err))
 		return
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"init_scenario_api/config"
+	"init_scenario_api/internal/application"
+	"init_scenario_api/internal/infastructure/archive"
+	"init_scenario_api/internal/infastructure/metrics"
+	"init_scenario_api/internal/usecase/retention"
+	"init_scenario_api/pkg/common"
+	"init_scenario_api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	app, err := application.NewApp()
+	if err != nil {
+		log.Fatalf("failed to initialize application: %v", err)
+		return common.FailExitCode
+	}
+
+	cfg := app.Config.Retention
+	app.Logger.Info("retention service starting",
+		zap.String("mode", cfg.Mode),
+		zap.Duration("interval", cfg.Interval),
+		zap.Duration("older_than", cfg.OlderThan),
+	)
+
+	ctx, cancel := context.WithCancel(logger.WithContext(context.Background(), app.Logger))
+	defer cancel()
+
+	app.Closer.Add(func() error {
+		app.Logger.Info("cancelling retention context...")
+		cancel()
+		return nil
+	})
+
+	var archiver retention.Archiver
+	if cfg.Mode == config.RetentionModeArchive {
+		archiver = archive.NewJSONLArchiver(cfg.ArchiveDir)
+	}
+
+	retentionUsecase := retention.NewUseCase(app.PostgresRepo, app.PostgresRepo.Outbox(), archiver, metrics.NewRetention(), retention.Config{
+		OlderThan:  cfg.OlderThan,
+		BatchSize:  cfg.BatchSize,
+		MaxBatches: cfg.MaxBatches,
+		BatchPause: cfg.BatchPause,
+	})
+
+	startMetricsServer(&app, cfg.MetricsPort)
+
+	go runRetention(ctx, app.Logger, cfg.Interval, retentionUsecase)
+
+	app.Closer.Wait()
+
+	app.Logger.Info("retention service stopped")
+	return common.SuccessExitCode
+}
+
+// startMetricsServer отдает счетчики expvar, в том числе retention_rows_purged, на /debug/vars
+func startMetricsServer(app *application.App, port int) {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	srv := &http.Server{
+		Addr:         fmt.Sprintf(":%d", port),
+		Handler:      mux,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+	}
+
+	app.Closer.Add(func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return srv.Shutdown(ctx)
+	})
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			app.Logger.Error("metrics server error", zap.Error(err))
+		}
+	}()
+}
+
+func runRetention(ctx context.Context, lg *zap.Logger, interval time.Duration, retentionUsecase *retention.UseCase) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lg.Info("retention worker started")
+
+	for {
+		purged, err := retentionUsecase.Purge(ctx)
+		if err != nil && ctx.Err() == nil {
+			lg.Error("retention run failed", zap.Int("rows_purged", purged), zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			lg.Info("retention worker stopping...")
+			return
+		case <-ticker.C:
+		}
+	}
+}
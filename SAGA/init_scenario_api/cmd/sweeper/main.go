@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"init_scenario_api/config"
+	"init_scenario_api/internal/application"
+	"init_scenario_api/internal/usecase/scenario_compensation"
+	"init_scenario_api/pkg/common"
+	"init_scenario_api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	app, err := application.NewApp()
+	if err != nil {
+		log.Fatalf("failed to initialize application: %v", err)
+		return common.FailExitCode
+	}
+
+	app.Logger.Info("sweeper service starting",
+		zap.Duration("startup_timeout", app.Config.Sweeper.StartupTimeout),
+		zap.Duration("interval", app.Config.Sweeper.Interval),
+	)
+
+	ctx, cancel := context.WithCancel(logger.WithContext(context.Background(), app.Logger))
+	defer cancel()
+
+	app.Closer.Add(func() error {
+		app.Logger.Info("cancelling sweeper context...")
+		cancel()
+		return nil
+	})
+
+	compensationUsecase := scenario_compensation.NewUseCase(app.PostgresRepo)
+
+	go runSweeper(ctx, app.Logger, app.Config.Sweeper, compensationUsecase)
+
+	app.Closer.Wait()
+
+	app.Logger.Info("sweeper service stopped")
+	return common.SuccessExitCode
+}
+
+func runSweeper(ctx context.Context, lg *zap.Logger, cfg config.SweeperConfig, compensationUsecase *scenario_compensation.UseCase) {
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	lg.Info("sweeper worker started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			lg.Info("sweeper worker stopping...")
+			return
+		case <-ticker.C:
+			// Выгребаем зависшие сценарии батчами, пока они не закончатся
+			for {
+				compensated, err := compensationUsecase.SweepStuckScenarios(ctx, cfg.StartupTimeout, cfg.BatchSize)
+				if err != nil || compensated < int(cfg.BatchSize) {
+					break
+				}
+			}
+		}
+	}
+}
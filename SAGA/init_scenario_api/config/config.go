@@ -9,16 +9,24 @@ import (
 )
 
 type Config struct {
-	API      APIConfig
-	Producer ProducerConfig
-	Database DatabaseConfig
-	Pool     PoolConfig
-	Kafka    KafkaConfig
+	API       APIConfig
+	Producer  ProducerConfig
+	Consumer  ConsumerConfig
+	Sweeper   SweeperConfig
+	Retention RetentionConfig
+	Database  DatabaseConfig
+	Pool      PoolConfig
+	Kafka     KafkaConfig
 }
 
 type APIConfig struct {
 	Port        int
 	CORSOrigins []string
+	// AdminAddr - адрес отдельного listener'а административных ручек. По умолчанию слушает
+	// только loopback, чтобы ручки не были доступны вместе с публичным API
+	AdminAddr string
+	// AdminToken - если задан, административные ручки требуют заголовок Authorization: Bearer <token>
+	AdminToken string
 }
 
 type ProducerConfig struct {
@@ -28,8 +36,67 @@ type ProducerConfig struct {
 	KafkaPassword     string
 	KafkaAnswersTopic string
 	KafkaResultsTopic string
+	// OutboxMaxAttempts - количество попыток публикации outbox записи, после которого она переводится в failed
+	OutboxMaxAttempts    int32
+	OutboxRetryBaseDelay time.Duration
+	OutboxRetryMaxDelay  time.Duration
+	// OutboxRelayMode - poll: выборка outbox по таймеру OutboxPollInterval;
+	// notify: выборка по NOTIFY из Postgres и страховочно раз в OutboxSafetyPollInterval;
+	// cdc: чтение вставок из слота логической репликации OutboxReplicationSlot и страховочно
+	// выборка раз в OutboxSafetyPollInterval
+	OutboxRelayMode          string
+	OutboxPollInterval       time.Duration
+	OutboxSafetyPollInterval time.Duration
+	OutboxReplicationSlot    string
+	OutboxPublication        string
+	// OutboxCDCFlushInterval - сколько cdc relay копит транзакции перед публикацией неполного батча
+	OutboxCDCFlushInterval time.Duration
 }
 
+const (
+	OutboxRelayModePoll   = "poll"
+	OutboxRelayModeNotify = "notify"
+	OutboxRelayModeCDC    = "cdc"
+)
+
+type ConsumerConfig struct {
+	KafkaConsumerGroup string
+	// RetryBaseDelay и RetryMaxDelay - экспоненциальная задержка повторов после ошибок базы и Kafka.
+	// Такие ошибки повторяются без ограничения числа попыток
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// DeadLetterTopic - топик для сообщений, которые не удалось разобрать или которые не прошли
+	// проверку. Если пустой, такие сообщения только логируются и подтверждаются
+	DeadLetterTopic string
+}
+
+type SweeperConfig struct {
+	StartupTimeout time.Duration
+	Interval       time.Duration
+	BatchSize      int32
+}
+
+// RetentionConfig задает очистку sent записей outbox и обработанных записей inbox
+type RetentionConfig struct {
+	// Mode - delete: строки удаляются; archive: перед удалением пишутся в gzip JSONL в ArchiveDir
+	Mode       string
+	ArchiveDir string
+	Interval   time.Duration
+	// OlderThan - возраст строки, после которого она удаляется. Для inbox должен быть больше
+	// окна повторной доставки из Kafka, иначе дубль события будет обработан заново
+	OlderThan time.Duration
+	BatchSize int32
+	// MaxBatches - ограничение количества батчей на таблицу за один проход
+	MaxBatches  int
+	BatchPause  time.Duration
+	MetricsPort int
+}
+
+const (
+	RetentionModeDelete  = "delete"
+	RetentionModeArchive = "archive"
+)
+
 type DatabaseConfig struct {
 	Host     string
 	Port     int
@@ -40,7 +107,22 @@ type DatabaseConfig struct {
 
 type KafkaConfig struct {
 	Brokers []string
+	// SASLMechanism - PLAIN, SCRAM-SHA-256 или SCRAM-SHA-512. Пустой - без аутентификации
+	SASLMechanism string
+	Username      string
+	Password      string
+	TLS           KafkaTLSConfig
+}
+
+type KafkaTLSConfig struct {
+	Enabled  bool
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	// InsecureSkipVerify отключает проверку сертификата брокера, только для разработки
+	InsecureSkipVerify bool
 }
+
 type PoolConfig struct {
 	MaxConns          int32
 	MinConns          int32
@@ -62,6 +144,9 @@ func Load() (*Config, error) {
 	corsOrigins := getEnv("CORS_ORIGINS", "*")
 	cfg.API.CORSOrigins = parseList(corsOrigins)
 
+	cfg.API.AdminAddr = getEnv("ADMIN_ADDR", "127.0.0.1:3004")
+	cfg.API.AdminToken = getEnv("ADMIN_TOKEN", "")
+
 	producerPort, err := getEnvAsInt("PRODUCER_PORT", 3001)
 	if err != nil {
 		return nil, fmt.Errorf("invalid PRODUCER_PORT: %w", err)
@@ -76,6 +161,116 @@ func Load() (*Config, error) {
 	cfg.Producer.KafkaAnswersTopic = getEnv("KAFKA_ANSWERS_TOPIC", "answers")
 	cfg.Producer.KafkaResultsTopic = getEnv("KAFKA_RESULTS_TOPIC", "results")
 
+	outboxMaxAttempts, err := getEnvAsInt("OUTBOX_MAX_ATTEMPTS", 10)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOX_MAX_ATTEMPTS: %w", err)
+	}
+	cfg.Producer.OutboxMaxAttempts = int32(outboxMaxAttempts)
+
+	cfg.Producer.OutboxRetryBaseDelay, err = getEnvAsDuration("OUTBOX_RETRY_BASE_DELAY", 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOX_RETRY_BASE_DELAY: %w", err)
+	}
+
+	cfg.Producer.OutboxRetryMaxDelay, err = getEnvAsDuration("OUTBOX_RETRY_MAX_DELAY", 5*time.Minute)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOX_RETRY_MAX_DELAY: %w", err)
+	}
+
+	cfg.Producer.OutboxRelayMode = getEnv("OUTBOX_RELAY_MODE", OutboxRelayModePoll)
+	switch cfg.Producer.OutboxRelayMode {
+	case OutboxRelayModePoll, OutboxRelayModeNotify, OutboxRelayModeCDC:
+	default:
+		return nil, fmt.Errorf("invalid OUTBOX_RELAY_MODE: %q, expected %s, %s or %s",
+			cfg.Producer.OutboxRelayMode, OutboxRelayModePoll, OutboxRelayModeNotify, OutboxRelayModeCDC)
+	}
+
+	cfg.Producer.OutboxPollInterval, err = getEnvAsDuration("OUTBOX_POLL_INTERVAL", 3*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOX_POLL_INTERVAL: %w", err)
+	}
+
+	cfg.Producer.OutboxSafetyPollInterval, err = getEnvAsDuration("OUTBOX_SAFETY_POLL_INTERVAL", 30*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOX_SAFETY_POLL_INTERVAL: %w", err)
+	}
+
+	cfg.Producer.OutboxReplicationSlot = getEnv("OUTBOX_REPLICATION_SLOT", "outbox_scenario_slot")
+	cfg.Producer.OutboxPublication = getEnv("OUTBOX_PUBLICATION", "outbox_scenario_pub")
+
+	cfg.Producer.OutboxCDCFlushInterval, err = getEnvAsDuration("OUTBOX_CDC_FLUSH_INTERVAL", 100*time.Millisecond)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOX_CDC_FLUSH_INTERVAL: %w", err)
+	}
+
+	cfg.Consumer.KafkaConsumerGroup = getEnv("KAFKA_CONSUMER_GROUP", "init_scenario_api_scenario_result_consumer_group")
+
+	cfg.Consumer.RetryBaseDelay, err = getEnvAsDuration("CONSUMER_RETRY_BASE_DELAY", 500*time.Millisecond)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONSUMER_RETRY_BASE_DELAY: %w", err)
+	}
+
+	cfg.Consumer.RetryMaxDelay, err = getEnvAsDuration("CONSUMER_RETRY_MAX_DELAY", 30*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONSUMER_RETRY_MAX_DELAY: %w", err)
+	}
+
+	cfg.Consumer.DeadLetterTopic = getEnv("CONSUMER_DEAD_LETTER_TOPIC", "outbox_runner_scheduler.dlq")
+
+	cfg.Sweeper.StartupTimeout, err = getEnvAsDuration("SCENARIO_STARTUP_TIMEOUT", 5*time.Minute)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCENARIO_STARTUP_TIMEOUT: %w", err)
+	}
+
+	cfg.Sweeper.Interval, err = getEnvAsDuration("SCENARIO_SWEEP_INTERVAL", 30*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCENARIO_SWEEP_INTERVAL: %w", err)
+	}
+
+	sweepBatchSize, err := getEnvAsInt("SCENARIO_SWEEP_BATCH_SIZE", 100)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCENARIO_SWEEP_BATCH_SIZE: %w", err)
+	}
+	cfg.Sweeper.BatchSize = int32(sweepBatchSize)
+
+	cfg.Retention.Mode = getEnv("RETENTION_MODE", RetentionModeDelete)
+	if cfg.Retention.Mode != RetentionModeDelete && cfg.Retention.Mode != RetentionModeArchive {
+		return nil, fmt.Errorf("invalid RETENTION_MODE: %q, expected %s or %s",
+			cfg.Retention.Mode, RetentionModeDelete, RetentionModeArchive)
+	}
+	cfg.Retention.ArchiveDir = getEnv("RETENTION_ARCHIVE_DIR", "./archive")
+
+	cfg.Retention.Interval, err = getEnvAsDuration("RETENTION_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_INTERVAL: %w", err)
+	}
+
+	cfg.Retention.OlderThan, err = getEnvAsDuration("RETENTION_OLDER_THAN", 7*24*time.Hour)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_OLDER_THAN: %w", err)
+	}
+
+	retentionBatchSize, err := getEnvAsInt("RETENTION_BATCH_SIZE", 1000)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_BATCH_SIZE: %w", err)
+	}
+	cfg.Retention.BatchSize = int32(retentionBatchSize)
+
+	cfg.Retention.MaxBatches, err = getEnvAsInt("RETENTION_MAX_BATCHES", 100)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_MAX_BATCHES: %w", err)
+	}
+
+	cfg.Retention.BatchPause, err = getEnvAsDuration("RETENTION_BATCH_PAUSE", 100*time.Millisecond)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_BATCH_PAUSE: %w", err)
+	}
+
+	cfg.Retention.MetricsPort, err = getEnvAsInt("RETENTION_METRICS_PORT", 3003)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_METRICS_PORT: %w", err)
+	}
+
 	cfg.Database.Host = getEnv("DB_HOST", "localhost")
 
 	dbPort, err := getEnvAsInt("DB_PORT", 5432)
@@ -125,6 +320,28 @@ func Load() (*Config, error) {
 	if len(cfg.Kafka.Brokers) == 0 {
 		cfg.Kafka.Brokers = []string{"localhost:9092", "localhost:9093", "localhost:9094"}
 	}
+
+	cfg.Kafka.Username = getEnv("KAFKA_USERNAME", "")
+	cfg.Kafka.Password = getEnv("KAFKA_PASSWORD", "")
+	// Заданные учетные данные без механизма означают SASL PLAIN
+	defaultSASLMechanism := ""
+	if cfg.Kafka.Username != "" {
+		defaultSASLMechanism = "PLAIN"
+	}
+	cfg.Kafka.SASLMechanism = strings.ToUpper(getEnv("KAFKA_SASL_MECHANISM", defaultSASLMechanism))
+
+	cfg.Kafka.TLS.Enabled, err = getEnvAsBool("KAFKA_TLS_ENABLED", false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KAFKA_TLS_ENABLED: %w", err)
+	}
+	cfg.Kafka.TLS.CAFile = getEnv("KAFKA_TLS_CA_FILE", "")
+	cfg.Kafka.TLS.CertFile = getEnv("KAFKA_TLS_CERT_FILE", "")
+	cfg.Kafka.TLS.KeyFile = getEnv("KAFKA_TLS_KEY_FILE", "")
+	cfg.Kafka.TLS.InsecureSkipVerify, err = getEnvAsBool("KAFKA_TLS_INSECURE_SKIP_VERIFY", false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KAFKA_TLS_INSECURE_SKIP_VERIFY: %w", err)
+	}
+
 	return cfg, nil
 }
 
@@ -147,6 +364,18 @@ func getEnvAsInt(key string, defaultValue int) (int, error) {
 	return value, nil
 }
 
+func getEnvAsBool(key string, defaultValue bool) (bool, error) {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.ParseBool(strings.TrimSpace(valueStr))
+	if err != nil {
+		return false, err
+	}
+	return value, nil
+}
+
 func parseList(s string) []string {
 	if s == "" {
 		return []string{}
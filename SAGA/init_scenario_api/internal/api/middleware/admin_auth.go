@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"init_scenario_api/internal/api/response"
+	"init_scenario_api/pkg/logger"
+)
+
+// AdminAuth пропускает только запросы с заголовком Authorization: Bearer <token>. Пустой token
+// отключает проверку: тогда доступ ограничивает только адрес admin listener'а
+func AdminAuth(token string) func(http.Handler) http.Handler {
+	expected := []byte("Bearer " + token)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+				log := logger.FromContext(r.Context())
+				response.Error(w, log, http.StatusUnauthorized, "Unauthorized", "valid admin token required")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
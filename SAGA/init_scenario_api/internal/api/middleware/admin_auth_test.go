@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminAuth(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	cases := []struct {
+		name   string
+		token  string
+		header string
+		want   int
+	}{
+		{name: "valid token", token: "secret", header: "Bearer secret", want: http.StatusOK},
+		{name: "wrong token", token: "secret", header: "Bearer other", want: http.StatusUnauthorized},
+		{name: "missing header", token: "secret", want: http.StatusUnauthorized},
+		{name: "check disabled", want: http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/admin/outbox/failed", nil)
+			if tc.header != "" {
+				r.Header.Set("Authorization", tc.header)
+			}
+			w := httptest.NewRecorder()
+
+			AdminAuth(tc.token)(ok).ServeHTTP(w, r)
+
+			if w.Code != tc.want {
+				t.Fatalf("expected status %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"init_scenario_api/internal/api/response"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// DecodeQuery заполняет поля dst с тегом query из параметров запроса, отклоняя неизвестные,
+// повторяющиеся и не приводимые к типу поля параметры, и проверяет dst по тегам validate.
+// Поддерживаются string, bool, целые числа и указатели на них. Возвращает *DecodeError или
+// *ValidationError, которые WriteError превращает в ответ клиенту.
+func DecodeQuery(r *http.Request, dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decode query: dst must be a pointer to struct, got %T", dst)
+	}
+	v = v.Elem()
+
+	fields := make(map[string]reflect.Value)
+	for i := 0; i < v.NumField(); i++ {
+		if name := v.Type().Field(i).Tag.Get("query"); name != "" && name != "-" {
+			fields[name] = v.Field(i)
+		}
+	}
+
+	for name, values := range r.URL.Query() {
+		field, ok := fields[name]
+		if !ok {
+			return &DecodeError{
+				Status:  http.StatusBadRequest,
+				Message: "request contains unknown query parameter",
+				Fields:  []response.FieldError{{Field: name, Rule: "unknown", Message: "is not allowed"}},
+			}
+		}
+		if len(values) > 1 {
+			return &DecodeError{
+				Status:  http.StatusBadRequest,
+				Message: "request contains repeated query parameter",
+				Fields:  []response.FieldError{{Field: name, Rule: "single", Message: "must be passed once"}},
+			}
+		}
+		if values[0] == "" {
+			continue
+		}
+		if err := setQueryField(field, values[0]); err != nil {
+			return &DecodeError{
+				Status:  http.StatusBadRequest,
+				Message: "request contains a query parameter of wrong type",
+				Fields:  []response.FieldError{{Field: name, Rule: "type", Message: err.Error()}},
+			}
+		}
+	}
+
+	return Validate(dst)
+}
+
+func setQueryField(field reflect.Value, raw string) error {
+	if field.Kind() == reflect.Pointer {
+		value := reflect.New(field.Type().Elem())
+		if err := setQueryField(value.Elem(), raw); err != nil {
+			return err
+		}
+		field.Set(value)
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("must be bool")
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be %s", field.Type())
+		}
+		field.SetInt(n)
+	default:
+		panic(fmt.Sprintf("decode query: unsupported field type %s", field.Type()))
+	}
+	return nil
+}
+
+// PathUUID разбирает path параметр name как UUID. Ошибка возвращается как *DecodeError
+func PathUUID(r *http.Request, name string) (uuid.UUID, error) {
+	id, err := uuid.Parse(chi.URLParam(r, name))
+	if err != nil {
+		return uuid.Nil, &DecodeError{
+			Status:  http.StatusBadRequest,
+			Message: "request path contains invalid parameter",
+			Fields:  []response.FieldError{{Field: name, Rule: "uuid", Message: "must be a valid UUID"}},
+		}
+	}
+	return id, nil
+}
@@ -0,0 +1,126 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"init_scenario_api/internal/models/dto"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func decodeQuery(t *testing.T, query string, dst any) error {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+	return DecodeQuery(r, dst)
+}
+
+func TestDecodeQueryValidRequest(t *testing.T) {
+	var req dto.ListScenariosRequest
+	if err := decodeQuery(t, "camera_id=7&status=active&cursor=abc&limit=10", &req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.CameraID == nil || *req.CameraID != 7 || req.Status == nil || *req.Status != "active" ||
+		req.Cursor != "abc" || req.Limit != 10 {
+		t.Fatalf("unexpected decoded request: %+v", req)
+	}
+
+	req = dto.ListScenariosRequest{}
+	if err := decodeQuery(t, "camera_id=&limit=", &req); err != nil {
+		t.Fatalf("unexpected error for empty parameters: %v", err)
+	}
+	if req.CameraID != nil || req.Limit != 0 {
+		t.Fatalf("expected empty parameters to be skipped, got %+v", req)
+	}
+}
+
+func TestDecodeQueryFieldErrors(t *testing.T) {
+	var req dto.ListScenariosRequest
+	err := decodeQuery(t, "camera_id=-1&status=unknown&limit=-5", &req)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	rules := make(map[string]string)
+	for _, f := range validationErr.Fields {
+		rules[f.Field] = f.Rule
+	}
+	if rules["camera_id"] != "gt" || rules["status"] != "scenario_status" || rules["limit"] != "gte" {
+		t.Fatalf("unexpected field errors: %+v", validationErr.Fields)
+	}
+}
+
+func TestDecodeQueryRejectsMalformedParameters(t *testing.T) {
+	cases := map[string]struct {
+		query string
+		field string
+		rule  string
+	}{
+		"unknown parameter":  {query: "page=2", field: "page", rule: "unknown"},
+		"repeated parameter": {query: "limit=1&limit=2", field: "limit", rule: "single"},
+		"wrong type":         {query: "camera_id=abc", field: "camera_id", rule: "type"},
+		"overflow":           {query: "limit=3000000000", field: "limit", rule: "type"},
+	}
+
+	for name, tc := range cases {
+		var req dto.ListScenariosRequest
+		err := decodeQuery(t, tc.query, &req)
+
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Fatalf("%s: expected DecodeError, got %v", name, err)
+		}
+		if decodeErr.Status != http.StatusBadRequest {
+			t.Fatalf("%s: expected status 400, got %d", name, decodeErr.Status)
+		}
+		if len(decodeErr.Fields) != 1 || decodeErr.Fields[0].Field != tc.field || decodeErr.Fields[0].Rule != tc.rule {
+			t.Fatalf("%s: expected %s error for %s, got %+v", name, tc.rule, tc.field, decodeErr.Fields)
+		}
+	}
+}
+
+func TestDecodeQueryBool(t *testing.T) {
+	req := dto.InitScenarioRequest{CameraID: 1, URL: "rtsp://localhost/stream"}
+	if err := decodeQuery(t, "replace=true", &req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !req.Replace {
+		t.Fatalf("expected replace to be decoded")
+	}
+
+	var decodeErr *DecodeError
+	if err := decodeQuery(t, "replace=yes", &req); !errors.As(err, &decodeErr) {
+		t.Fatalf("expected DecodeError for invalid bool, got %v", err)
+	}
+}
+
+func TestPathUUID(t *testing.T) {
+	pathRequest := func(value string) *http.Request {
+		routeCtx := chi.NewRouteContext()
+		routeCtx.URLParams.Add("uuid", value)
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+	}
+
+	id, err := PathUUID(pathRequest("0b9e4f3c-8a8f-4c55-9f4e-2f6f1d1e7a10"), "uuid")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id.String() != "0b9e4f3c-8a8f-4c55-9f4e-2f6f1d1e7a10" {
+		t.Fatalf("unexpected uuid %s", id)
+	}
+
+	_, err = PathUUID(pathRequest("not-a-uuid"), "uuid")
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected DecodeError, got %v", err)
+	}
+	if len(decodeErr.Fields) != 1 || decodeErr.Fields[0].Field != "uuid" || decodeErr.Fields[0].Rule != "uuid" {
+		t.Fatalf("unexpected field errors: %+v", decodeErr.Fields)
+	}
+}
@@ -0,0 +1,135 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"init_scenario_api/internal/api/response"
+
+	"go.uber.org/zap"
+)
+
+// MaxBodyBytes - максимальный размер тела запроса
+const MaxBodyBytes = 1 << 20
+
+// ValidationError содержит нарушения правил валидации по полям запроса
+type ValidationError struct {
+	Fields []response.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, fmt.Sprintf("%s %s", f.Field, f.Message))
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// DecodeError - тело, параметры или path запроса не удалось разобрать
+type DecodeError struct {
+	Status  int
+	Message string
+	Fields  []response.FieldError
+}
+
+func (e *DecodeError) Error() string {
+	return e.Message
+}
+
+// DecodeJSON читает тело запроса не больше MaxBodyBytes в dst, отклоняя неизвестные поля и
+// данные после JSON объекта, и проверяет dst по тегам validate. Возвращает *DecodeError или
+// *ValidationError, которые WriteError превращает в ответ клиенту.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+
+	if err := decoder.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return &DecodeError{
+			Status:  http.StatusBadRequest,
+			Message: "request body must contain a single JSON object",
+		}
+	}
+
+	return Validate(dst)
+}
+
+func decodeError(err error) error {
+	var (
+		maxBytesErr      *http.MaxBytesError
+		syntaxErr        *json.SyntaxError
+		unmarshalTypeErr *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return &DecodeError{
+			Status:  http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("request body must not exceed %d bytes", maxBytesErr.Limit),
+		}
+	case errors.Is(err, io.EOF):
+		return &DecodeError{
+			Status:  http.StatusBadRequest,
+			Message: "request body is empty",
+		}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return &DecodeError{
+			Status:  http.StatusBadRequest,
+			Message: "request body is not valid JSON",
+		}
+	case errors.As(err, &unmarshalTypeErr):
+		return &DecodeError{
+			Status:  http.StatusBadRequest,
+			Message: "request body contains a field of wrong type",
+			Fields: []response.FieldError{{
+				Field:   unmarshalTypeErr.Field,
+				Rule:    "type",
+				Message: fmt.Sprintf("must be %s", unmarshalTypeErr.Type),
+			}},
+		}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json не экспортирует тип ошибки для DisallowUnknownFields
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return &DecodeError{
+			Status:  http.StatusBadRequest,
+			Message: "request body contains unknown field",
+			Fields: []response.FieldError{{
+				Field:   field,
+				Rule:    "unknown",
+				Message: "is not allowed",
+			}},
+		}
+	default:
+		return &DecodeError{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		}
+	}
+}
+
+// WriteError пишет ответ для ошибки DecodeJSON, DecodeQuery, PathUUID или Validate. Возвращает
+// false, если err не относится к разбору или валидации запроса и должен быть обработан вызывающим
+func WriteError(w http.ResponseWriter, log *zap.Logger, err error) bool {
+	var (
+		decodeErr     *DecodeError
+		validationErr *ValidationError
+	)
+
+	switch {
+	case errors.As(err, &decodeErr):
+		response.ValidationError(w, log, decodeErr.Status, "Invalid request", decodeErr.Message, decodeErr.Fields)
+		return true
+	case errors.As(err, &validationErr):
+		response.ValidationError(w, log, http.StatusBadRequest, "Validation failed", "request has invalid fields", validationErr.Fields)
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,116 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"init_scenario_api/internal/models/dto"
+)
+
+func decode(t *testing.T, body string, dst any) error {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	return DecodeJSON(httptest.NewRecorder(), r, dst)
+}
+
+func TestDecodeJSONValidRequest(t *testing.T) {
+	var req dto.InitScenarioRequest
+	if err := decode(t, `{"camera_id": 1, "url": "rtsp://localhost:8554/stream"}`, &req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.CameraID != 1 || req.URL != "rtsp://localhost:8554/stream" {
+		t.Fatalf("unexpected decoded request: %+v", req)
+	}
+}
+
+func TestDecodeJSONFieldErrors(t *testing.T) {
+	var req dto.InitScenarioRequest
+	err := decode(t, `{"camera_id": 0, "url": "ftp://localhost/stream"}`, &req)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	rules := make(map[string]string)
+	for _, f := range validationErr.Fields {
+		rules[f.Field] = f.Rule
+	}
+	if rules["camera_id"] != "required" {
+		t.Fatalf("expected camera_id to fail on required, got %q", rules["camera_id"])
+	}
+	if rules["url"] != "stream_url" {
+		t.Fatalf("expected url to fail on stream_url, got %q", rules["url"])
+	}
+}
+
+func TestDecodeJSONStreamURL(t *testing.T) {
+	cases := map[string]bool{
+		"rtsp://localhost:8554/stream":  true,
+		"rtsps://camera.local/live":     true,
+		"http://camera.local/mjpeg":     true,
+		"HTTPS://camera.local/hls.m3u8": true,
+		"rtsp:///stream":                false,
+		"localhost:8554/stream":         false,
+		"file:///dev/video0":            false,
+		"not a url":                     false,
+	}
+
+	for url, valid := range cases {
+		var req dto.InitScenarioRequest
+		err := decode(t, `{"camera_id": 1, "url": "`+url+`"}`, &req)
+		if valid && err != nil {
+			t.Fatalf("expected %q to be valid, got %v", url, err)
+		}
+		if !valid && err == nil {
+			t.Fatalf("expected %q to be rejected", url)
+		}
+	}
+}
+
+func TestDecodeJSONOneOf(t *testing.T) {
+	var req dto.UpdateOutboxScenarioStateRequest
+	err := decode(t, `{"state": "unknown"}`, &req)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	if len(validationErr.Fields) != 1 || validationErr.Fields[0].Field != "state" || validationErr.Fields[0].Rule != "oneof" {
+		t.Fatalf("unexpected field errors: %+v", validationErr.Fields)
+	}
+}
+
+func TestDecodeJSONRejectsMalformedBody(t *testing.T) {
+	cases := map[string]struct {
+		body   string
+		status int
+		field  string
+	}{
+		"unknown field": {body: `{"camera_id": 1, "url": "rtsp://h/s", "extra": true}`, status: http.StatusBadRequest, field: "extra"},
+		"wrong type":    {body: `{"camera_id": "1", "url": "rtsp://h/s"}`, status: http.StatusBadRequest, field: "camera_id"},
+		"trailing data": {body: `{"camera_id": 1, "url": "rtsp://h/s"} {}`, status: http.StatusBadRequest},
+		"empty body":    {body: ``, status: http.StatusBadRequest},
+		"invalid json":  {body: `{"camera_id": 1,`, status: http.StatusBadRequest},
+		"too large":     {body: `{"url": "` + strings.Repeat("a", MaxBodyBytes) + `"}`, status: http.StatusRequestEntityTooLarge},
+	}
+
+	for name, tc := range cases {
+		var req dto.InitScenarioRequest
+		err := decode(t, tc.body, &req)
+
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Fatalf("%s: expected DecodeError, got %v", name, err)
+		}
+		if decodeErr.Status != tc.status {
+			t.Fatalf("%s: expected status %d, got %d", name, tc.status, decodeErr.Status)
+		}
+		if tc.field != "" && (len(decodeErr.Fields) != 1 || decodeErr.Fields[0].Field != tc.field) {
+			t.Fatalf("%s: expected field error for %s, got %+v", name, tc.field, decodeErr.Fields)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"init_scenario_api/internal/api/response"
+	"init_scenario_api/internal/models/entity"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// streamURLSchemes содержит схемы URL, по которым runner умеет читать поток камеры
+var streamURLSchemes = map[string]struct{}{
+	"rtsp":  {},
+	"rtsps": {},
+	"http":  {},
+	"https": {},
+}
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// В ошибках поля называются так же, как в JSON или параметрах запроса
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		if name := field.Tag.Get("query"); name != "" && name != "-" {
+			return name
+		}
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	if err := v.RegisterValidation("stream_url", isStreamURL); err != nil {
+		panic(fmt.Sprintf("register stream_url validation: %v", err))
+	}
+	if err := v.RegisterValidation("scenario_status", isScenarioStatus); err != nil {
+		panic(fmt.Sprintf("register scenario_status validation: %v", err))
+	}
+
+	return v
+}
+
+// isStreamURL проверяет, что значение - абсолютный rtsp://, rtsps://, http:// или https:// URL с хостом
+func isStreamURL(fl validator.FieldLevel) bool {
+	u, err := url.Parse(fl.Field().String())
+	if err != nil {
+		return false
+	}
+	if _, ok := streamURLSchemes[strings.ToLower(u.Scheme)]; !ok {
+		return false
+	}
+	return u.Host != "" && u.Hostname() != ""
+}
+
+// isScenarioStatus проверяет, что значение - один из статусов сценария
+func isScenarioStatus(fl validator.FieldLevel) bool {
+	return entity.IsValidScenarioStatus(fl.Field().String())
+}
+
+// Validate проверяет структуру по тегам validate. Нарушения возвращаются как *ValidationError
+func Validate(v any) error {
+	err := validate.Struct(v)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	fields := make([]response.FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields = append(fields, response.FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Message: fieldMessage(fe),
+		})
+	}
+
+	return &ValidationError{Fields: fields}
+}
+
+// fieldPath возвращает путь к полю без имени корневой структуры (camera_id, а не InitScenarioRequest.camera_id)
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", fe.Param())
+	case "lt":
+		return fmt.Sprintf("must be less than %s", fe.Param())
+	case "lte":
+		return fmt.Sprintf("must be less than or equal to %s", fe.Param())
+	case "min":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "uuid", "uuid4", "uuid7":
+		return "must be a valid UUID"
+	case "stream_url":
+		return "must be a valid rtsp://, rtsps://, http:// or https:// URL"
+	case "scenario_status":
+		return fmt.Sprintf("must be one of: %s", strings.Join(entity.ScenarioStatuses, ", "))
+	default:
+		return fmt.Sprintf("failed on %s validation", fe.Tag())
+	}
+}
@@ -22,7 +22,24 @@ func Error(w http.ResponseWriter, log *zap.Logger, code int, error, message stri
 	})
 }
 
+// ValidationError пишет ответ с ошибками валидации отдельных полей запроса
+func ValidationError(w http.ResponseWriter, log *zap.Logger, code int, error, message string, fields []FieldError) {
+	JSON(w, log, code, ErrorResponse{
+		Error:   error,
+		Message: message,
+		Fields:  fields,
+	})
+}
+
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message,omitempty"`
+	Error   string       `json:"error"`
+	Message string       `json:"message,omitempty"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError описывает нарушение правила валидации в одном поле запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
@@ -0,0 +1,14 @@
+package get_scenario
+
+import (
+	"context"
+	"init_scenario_api/internal/models/dto"
+
+	"github.com/google/uuid"
+)
+
+// GetScenarioUseCase определяет интерфейс use case для чтения сценариев
+type GetScenarioUseCase interface {
+	GetScenario(ctx context.Context, scenarioUUID uuid.UUID) (*dto.ScenarioResponse, error)
+	ListScenarios(ctx context.Context, input dto.ListScenariosRequest) (*dto.ListScenariosResponse, error)
+}
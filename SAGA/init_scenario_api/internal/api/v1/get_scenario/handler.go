@@ -0,0 +1,98 @@
+package get_scenario
+
+import (
+	"errors"
+	"init_scenario_api/internal/api/request"
+	"init_scenario_api/internal/api/response"
+	"init_scenario_api/internal/models/dto"
+	modelerror "init_scenario_api/internal/models/error"
+	"init_scenario_api/pkg/logger"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	useCase GetScenarioUseCase
+}
+
+func NewHandler(useCase GetScenarioUseCase) *Handler {
+	return &Handler{
+		useCase: useCase,
+	}
+}
+
+// GetScenario godoc
+// @Summary      Получить сценарий
+// @Description  Возвращает сценарий по UUID
+// @Tags         scenario
+// @Produce      json
+// @Param        uuid path string true "UUID сценария"
+// @Success      200 {object} dto.ScenarioResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      404 {object} response.ErrorResponse
+// @Failure      500 {object} response.ErrorResponse
+// @Router       /scenario/{uuid} [get]
+func (h *Handler) GetScenario(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	scenarioUUID, err := request.PathUUID(r, "uuid")
+	if err != nil {
+		request.WriteError(w, log, err)
+		return
+	}
+
+	output, err := h.useCase.GetScenario(ctx, scenarioUUID)
+	if err != nil {
+		if errors.Is(err, modelerror.ErrNotFound) {
+			response.Error(w, log, http.StatusNotFound, "Scenario not found", err.Error())
+			return
+		}
+		log.Error("failed to get scenario", zap.Error(err))
+		response.Error(w, log, http.StatusInternalServerError, "Failed to get scenario", err.Error())
+		return
+	}
+
+	response.JSON(w, log, http.StatusOK, output)
+}
+
+// ListScenarios godoc
+// @Summary      Список сценариев
+// @Description  Возвращает сценарии от новых к старым с фильтрами по камере и статусу. Для следующей страницы передайте next_cursor в cursor
+// @Tags         scenario
+// @Produce      json
+// @Param        camera_id query int false "ID камеры"
+// @Param        status query string false "Статус сценария"
+// @Param        cursor query string false "Курсор следующей страницы"
+// @Param        limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Success      200 {object} dto.ListScenariosResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      500 {object} response.ErrorResponse
+// @Router       /scenarios [get]
+func (h *Handler) ListScenarios(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	var req dto.ListScenariosRequest
+	if err := request.DecodeQuery(r, &req); err != nil {
+		if !request.WriteError(w, log, err) {
+			log.Error("failed to decode request", zap.Error(err))
+			response.Error(w, log, http.StatusInternalServerError, "Failed to decode request", err.Error())
+		}
+		return
+	}
+
+	output, err := h.useCase.ListScenarios(ctx, req)
+	if err != nil {
+		if errors.Is(err, modelerror.ErrInvalidCursor) {
+			response.Error(w, log, http.StatusBadRequest, "Invalid cursor", err.Error())
+			return
+		}
+		log.Error("failed to list scenarios", zap.Error(err))
+		response.Error(w, log, http.StatusInternalServerError, "Failed to list scenarios", err.Error())
+		return
+	}
+
+	response.JSON(w, log, http.StatusOK, output)
+}
@@ -7,5 +7,5 @@ import (
 
 // InitScenarioUseCase определяет интерфейс use case для создания покупки
 type InitScenarioUseCase interface {
-	InitScenario(ctx context.Context, input dto.InitScenarioRequest) (*dto.InitScenarioResponse, error)
+	InitScenario(ctx context.Context, input dto.InitScenarioRequest, idempotencyKey string) (*dto.InitScenarioResponse, error)
 }
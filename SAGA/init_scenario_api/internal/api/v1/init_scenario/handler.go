@@ -1,14 +1,24 @@
 package init_scenario
 
 import (
+	"errors"
+	"init_scenario_api/internal/api/request"
 	"init_scenario_api/internal/api/response"
 	"init_scenario_api/internal/models/dto"
+	modelerror "init_scenario_api/internal/models/error"
 	"init_scenario_api/pkg/logger"
 	"net/http"
 
 	"go.uber.org/zap"
 )
 
+const (
+	// IdempotencyKeyHeader - заголовок с ключом идемпотентности запроса
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	maxIdempotencyKeyLength = 255
+)
+
 type Handler struct {
 	useCase InitScenarioUseCase
 }
@@ -21,39 +31,65 @@ func NewHandler(useCase InitScenarioUseCase) *Handler {
 
 // InitScenario godoc
 // @Summary      Инициализировать сценарий
-// @Description  Создает новый сценарий и отправляет событие в Kafka
+// @Description  Создает новый сценарий и отправляет событие в Kafka. Повторный запрос с тем же
+// @Description  заголовком Idempotency-Key и телом возвращает ответ первого запроса.
+// @Description  На камере допускается один запускаемый или активный сценарий; replace=true
+// @Description  останавливает его перед созданием нового
 // @Tags         scenario
 // @Accept       json
 // @Produce      json
+// @Param        Idempotency-Key header string false "Ключ идемпотентности запроса"
+// @Param        replace query bool false "Остановить активный сценарий камеры"
 // @Param        request body dto.InitScenarioRequest true "Данные для создания сценария"
 // @Success      201 {object} dto.InitScenarioResponse
 // @Failure      400 {object} response.ErrorResponse
+// @Failure      409 {object} dto.InitScenarioConflictResponse
+// @Failure      413 {object} response.ErrorResponse
+// @Failure      422 {object} response.ErrorResponse
 // @Failure      500 {object} response.ErrorResponse
 // @Router       /scenario/init [post]
 func (h *Handler) InitScenario(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	log := logger.FromContext(ctx)
 
-	var req dto.InitScenarioRequest
-
-	if err := req.Decode(r.Body); err != nil {
-		log.Error("failed to decode request", zap.Error(err))
-		response.Error(w, log, http.StatusBadRequest, "Invalid request body", err.Error())
+	idempotencyKey := r.Header.Get(IdempotencyKeyHeader)
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		response.Error(w, log, http.StatusBadRequest, "Invalid Idempotency-Key", "Idempotency-Key must not exceed 255 characters")
 		return
 	}
 
-	if req.CameraID <= 0 {
-		response.Error(w, log, http.StatusBadRequest, "Invalid camera_id", "camera_id must be positive")
+	var req dto.InitScenarioRequest
+	if err := request.DecodeJSON(w, r, &req); err != nil {
+		if !request.WriteError(w, log, err) {
+			log.Error("failed to decode request", zap.Error(err))
+			response.Error(w, log, http.StatusInternalServerError, "Failed to decode request", err.Error())
+		}
 		return
 	}
 
-	if req.URL == "" {
-		response.Error(w, log, http.StatusBadRequest, "Invalid url", "url is required")
+	if err := request.DecodeQuery(r, &req); err != nil {
+		if !request.WriteError(w, log, err) {
+			log.Error("failed to decode request", zap.Error(err))
+			response.Error(w, log, http.StatusInternalServerError, "Failed to decode request", err.Error())
+		}
 		return
 	}
 
-	output, err := h.useCase.InitScenario(ctx, req)
+	output, err := h.useCase.InitScenario(ctx, req, idempotencyKey)
 	if err != nil {
+		var conflict *modelerror.ScenarioConflictError
+		if errors.As(err, &conflict) {
+			response.JSON(w, log, http.StatusConflict, dto.InitScenarioConflictResponse{
+				Error:        "Camera already has an active scenario",
+				Message:      "use replace=true to stop it and start a new one",
+				ScenarioUUID: conflict.ScenarioUUID,
+			})
+			return
+		}
+		if errors.Is(err, modelerror.ErrIdempotencyKeyReused) {
+			response.Error(w, log, http.StatusUnprocessableEntity, "Idempotency-Key reused", err.Error())
+			return
+		}
 		log.Error("failed to init scenario", zap.Error(err))
 		response.Error(w, log, http.StatusInternalServerError, "Failed to init scenario", err.Error())
 		return
@@ -0,0 +1,14 @@
+package outbox_admin
+
+import (
+	"context"
+	"init_scenario_api/internal/models/dto"
+
+	"github.com/google/uuid"
+)
+
+// OutboxAdminUseCase определяет интерфейс use case для разбора failed outbox записей
+type OutboxAdminUseCase interface {
+	ListFailed(ctx context.Context, input dto.ListFailedOutboxRequest) (*dto.ListFailedOutboxResponse, error)
+	Requeue(ctx context.Context, outboxUUIDs []uuid.UUID) (*dto.RequeueOutboxResponse, error)
+}
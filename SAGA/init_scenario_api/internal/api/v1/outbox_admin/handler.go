@@ -0,0 +1,83 @@
+package outbox_admin
+
+import (
+	"init_scenario_api/internal/api/request"
+	"init_scenario_api/internal/api/response"
+	"init_scenario_api/internal/models/dto"
+	"init_scenario_api/pkg/logger"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	useCase OutboxAdminUseCase
+}
+
+func NewHandler(useCase OutboxAdminUseCase) *Handler {
+	return &Handler{
+		useCase: useCase,
+	}
+}
+
+// ListFailed обрабатывает GET /admin/outbox/failed на admin listener'е: возвращает outbox записи,
+// исчерпавшие попытки публикации в Kafka, с последней ошибкой. Параметры limit (по умолчанию 50,
+// максимум 500) и offset. Ручка не входит в публичный API и swagger
+func (h *Handler) ListFailed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	var req dto.ListFailedOutboxRequest
+	if err := request.DecodeQuery(r, &req); err != nil {
+		if !request.WriteError(w, log, err) {
+			log.Error("failed to decode request", zap.Error(err))
+			response.Error(w, log, http.StatusInternalServerError, "Failed to decode request", err.Error())
+		}
+		return
+	}
+
+	output, err := h.useCase.ListFailed(ctx, req)
+	if err != nil {
+		log.Error("failed to list failed outbox scenarios", zap.Error(err))
+		response.Error(w, log, http.StatusInternalServerError, "Failed to list failed outbox records", err.Error())
+		return
+	}
+
+	response.JSON(w, log, http.StatusOK, output)
+}
+
+// Requeue обрабатывает POST /admin/outbox/requeue на admin listener'е: возвращает failed записи
+// в pending со сброшенным счетчиком попыток. Записи не в статусе failed пропускаются
+func (h *Handler) Requeue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	var req dto.RequeueOutboxRequest
+	if err := request.DecodeJSON(w, r, &req); err != nil {
+		if !request.WriteError(w, log, err) {
+			log.Error("failed to decode request", zap.Error(err))
+			response.Error(w, log, http.StatusInternalServerError, "Failed to decode request", err.Error())
+		}
+		return
+	}
+
+	outboxUUIDs := make([]uuid.UUID, len(req.OutboxUUIDs))
+	for i, v := range req.OutboxUUIDs {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			response.Error(w, log, http.StatusBadRequest, "Invalid outbox uuid", err.Error())
+			return
+		}
+		outboxUUIDs[i] = id
+	}
+
+	output, err := h.useCase.Requeue(ctx, outboxUUIDs)
+	if err != nil {
+		log.Error("failed to requeue outbox scenarios", zap.Error(err))
+		response.Error(w, log, http.StatusInternalServerError, "Failed to requeue outbox records", err.Error())
+		return
+	}
+
+	response.JSON(w, log, http.StatusOK, output)
+}
@@ -0,0 +1,13 @@
+package stop_scenario
+
+import (
+	"context"
+	"init_scenario_api/internal/models/dto"
+
+	"github.com/google/uuid"
+)
+
+// StopScenarioUseCase определяет интерфейс use case для остановки сценария
+type StopScenarioUseCase interface {
+	StopScenario(ctx context.Context, scenarioUUID uuid.UUID) (*dto.StopScenarioResponse, error)
+}
@@ -0,0 +1,61 @@
+package stop_scenario
+
+import (
+	"errors"
+	"init_scenario_api/internal/api/request"
+	"init_scenario_api/internal/api/response"
+	modelerror "init_scenario_api/internal/models/error"
+	"init_scenario_api/pkg/logger"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	useCase StopScenarioUseCase
+}
+
+func NewHandler(useCase StopScenarioUseCase) *Handler {
+	return &Handler{
+		useCase: useCase,
+	}
+}
+
+// StopScenario godoc
+// @Summary      Остановить сценарий
+// @Description  Переводит сценарий в статус init_shutdown и отправляет событие остановки в Kafka. После подтверждения остановки от runner_scheduler сценарий переходит в inactive
+// @Tags         scenario
+// @Produce      json
+// @Param        uuid path string true "UUID сценария"
+// @Success      202 {object} dto.StopScenarioResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      404 {object} response.ErrorResponse
+// @Failure      409 {object} response.ErrorResponse
+// @Failure      500 {object} response.ErrorResponse
+// @Router       /scenario/{uuid}/stop [post]
+func (h *Handler) StopScenario(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	scenarioUUID, err := request.PathUUID(r, "uuid")
+	if err != nil {
+		request.WriteError(w, log, err)
+		return
+	}
+
+	output, err := h.useCase.StopScenario(ctx, scenarioUUID)
+	if err != nil {
+		switch {
+		case errors.Is(err, modelerror.ErrNotFound):
+			response.Error(w, log, http.StatusNotFound, "Scenario not found", err.Error())
+		case errors.Is(err, modelerror.ErrScenarioNotStoppable):
+			response.Error(w, log, http.StatusConflict, "Scenario cannot be stopped", err.Error())
+		default:
+			log.Error("failed to stop scenario", zap.Error(err))
+			response.Error(w, log, http.StatusInternalServerError, "Failed to stop scenario", err.Error())
+		}
+		return
+	}
+
+	response.JSON(w, log, http.StatusAccepted, output)
+}
@@ -60,7 +60,7 @@ func NewApp() (App, error) {
 		return nil
 	})
 
-	kafkaConfig := kafka.DefaultConfig(cfg.Producer.KafkaBrokers...)
+	kafkaConfig := NewKafkaConfig(cfg)
 	kafkaProducer, err := kafka.NewKafkaProducer(kafkaConfig, log)
 	if err != nil {
 		log.Error("failed to initialize kafka producer", zap.Error(err))
@@ -83,3 +83,16 @@ func InitDB(ctx context.Context, cfg *config.Config) (*pgxpool.Pool, error) {
 
 	return dbPool, nil
 }
+
+// NewKafkaConfig создает настройки подключения к Kafka с аутентификацией и TLS из cfg
+func NewKafkaConfig(cfg *config.Config) *kafka.Config {
+	return kafka.DefaultConfig(cfg.Producer.KafkaBrokers...).
+		WithSASL(cfg.Kafka.SASLMechanism, cfg.Kafka.Username, cfg.Kafka.Password).
+		WithTLS(kafka.TLSConfig{
+			Enabled:            cfg.Kafka.TLS.Enabled,
+			CAFile:             cfg.Kafka.TLS.CAFile,
+			CertFile:           cfg.Kafka.TLS.CertFile,
+			KeyFile:            cfg.Kafka.TLS.KeyFile,
+			InsecureSkipVerify: cfg.Kafka.TLS.InsecureSkipVerify,
+		})
+}
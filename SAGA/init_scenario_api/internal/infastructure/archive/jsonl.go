@@ -0,0 +1,78 @@
+package archive
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// JSONLArchiver пишет строки таблиц в сжатые gzip JSONL файлы
+// <dir>/<table>/<table>-<время>-<суффикс>.jsonl.gz, по одному файлу на батч.
+// Файл сначала пишется во временный и синхронизируется на диск, поэтому после успешного
+// Archive он не может оказаться обрезанным.
+type JSONLArchiver struct {
+	dir string
+}
+
+func NewJSONLArchiver(dir string) *JSONLArchiver {
+	return &JSONLArchiver{dir: dir}
+}
+
+func (a *JSONLArchiver) Archive(ctx context.Context, table string, rows []any) error {
+	tableDir := filepath.Join(a.dir, table)
+	if err := os.MkdirAll(tableDir, 0o755); err != nil {
+		return fmt.Errorf("create archive dir: %w", err)
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("generate archive file suffix: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s-%s.jsonl.gz", table, time.Now().UTC().Format("20060102T150405"), hex.EncodeToString(suffix))
+	path := filepath.Join(tableDir, name)
+
+	tmp, err := os.CreateTemp(tableDir, name+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create archive file: %w", err)
+	}
+	defer func() {
+		// После успешного переименования временного файла уже нет
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if err := writeRows(tmp, rows); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("sync archive file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close archive file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename archive file: %w", err)
+	}
+	return nil
+}
+
+func writeRows(f *os.File, rows []any) error {
+	gz := gzip.NewWriter(f)
+	enc := json.NewEncoder(gz)
+	for _, row := range rows {
+		if err := enc.Encode(row); err != nil {
+			return fmt.Errorf("encode archive row: %w", err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("compress archive file: %w", err)
+	}
+	return nil
+}
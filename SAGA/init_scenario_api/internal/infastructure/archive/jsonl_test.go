@@ -0,0 +1,60 @@
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type row struct {
+	ID    int    `json:"id"`
+	State string `json:"state"`
+}
+
+func TestJSONLArchiverWritesCompressedRows(t *testing.T) {
+	dir := t.TempDir()
+	archiver := NewJSONLArchiver(dir)
+
+	rows := []any{row{ID: 1, State: "sent"}, row{ID: 2, State: "sent"}}
+	if err := archiver.Archive(context.Background(), "outbox_scenario", rows); err != nil {
+		t.Fatalf("Archive returned error: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "outbox_scenario", "*"))
+	if err != nil {
+		t.Fatalf("glob archive files: %v", err)
+	}
+	if len(files) != 1 || filepath.Ext(files[0]) != ".gz" {
+		t.Fatalf("expected one .gz archive file without temp files, got %v", files)
+	}
+
+	f, err := os.Open(files[0])
+	if err != nil {
+		t.Fatalf("open archive file: %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("open gzip reader: %v", err)
+	}
+
+	var got []row
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var r row
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("decode archive line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, r)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("read archive file: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != 1 || got[1].ID != 2 {
+		t.Fatalf("unexpected archived rows: %+v", got)
+	}
+}
@@ -0,0 +1,22 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestKeyedBalancerKeepsKeyOnOnePartition(t *testing.T) {
+	balancer := &keyedBalancer{keyed: &kafka.Hash{}, unkeyed: &kafka.LeastBytes{}}
+	partitions := []int{0, 1, 2, 3, 4, 5}
+
+	for _, key := range []string{"1", "42", "1001"} {
+		first := balancer.Balance(kafka.Message{Key: []byte(key), Value: []byte("a")}, partitions...)
+		for i := 0; i < 10; i++ {
+			got := balancer.Balance(kafka.Message{Key: []byte(key), Value: []byte("payload")}, partitions...)
+			if got != first {
+				t.Fatalf("key %q routed to partitions %d and %d", key, first, got)
+			}
+		}
+	}
+}
@@ -4,6 +4,7 @@ import "fmt"
 
 type Config struct {
 	Brokers                []string
+	ConsumerGroup          string
 	ReadTimeout            int
 	WriteTimeout           int
 	RequiredAcks           int
@@ -11,6 +12,10 @@ type Config struct {
 	MaxAttempts            int
 	Async                  bool
 	AllowAutoTopicCreation bool
+	// SASL - аутентификация на брокерах. Пустой Mechanism - без аутентификации
+	SASL SASLConfig
+	// TLS - шифрование соединения с брокерами
+	TLS TLSConfig
 }
 
 func DefaultConfig(brokers ...string) *Config {
@@ -60,6 +65,14 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("max attempts must be greater than 0")
 	}
 
+	if err := c.SASL.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.TLS.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -82,3 +95,13 @@ func (c *Config) WithAsync(async bool) *Config {
 	c.Async = async
 	return c
 }
+
+func (c *Config) WithSASL(mechanism, username, password string) *Config {
+	c.SASL = SASLConfig{Mechanism: mechanism, Username: username, Password: password}
+	return c
+}
+
+func (c *Config) WithTLS(tls TLSConfig) *Config {
+	c.TLS = tls
+	return c
+}
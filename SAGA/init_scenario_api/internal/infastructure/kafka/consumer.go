@@ -1 +1,222 @@
 package kafka
+
+import (
+	"context"
+	"fmt"
+	"shared/offsets"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+type Consumer interface {
+	ReadMessage(ctx context.Context) (*Message, error)
+	CommitMessages(ctx context.Context, msgs ...*Message) error
+	Close() error
+}
+
+type KafkaConsumer struct {
+	reader  *kafka.Reader
+	logger  *zap.Logger
+	offsets *offsets.Tracker
+	// commitMu упорядочивает подтверждения: смещения считаются по состоянию трекера и применяются
+	// к нему только после коммита в брокере, параллельный CommitMessages между этими шагами
+	// посчитал бы смещения по устаревшему состоянию
+	commitMu sync.Mutex
+
+	// client запрашивает подтвержденные в группе смещения после ребалансировки
+	client *kafka.Client
+	// rebalanced - была ребалансировка, после которой смещения трекера еще не сверены с группой
+	rebalanced atomic.Bool
+}
+
+// NewKafkaConsumer создает consumer, читающий topics в группе cfg.ConsumerGroup
+func NewKafkaConsumer(cfg *Config, topics []string, logger *zap.Logger) (Consumer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid kafka config: %w", err)
+	}
+
+	if len(topics) == 0 {
+		return nil, fmt.Errorf("topics list cannot be empty")
+	}
+
+	if cfg.ConsumerGroup == "" {
+		return nil, fmt.Errorf("consumer group cannot be empty")
+	}
+
+	dialer, err := cfg.dialer()
+	if err != nil {
+		return nil, fmt.Errorf("invalid kafka security config: %w", err)
+	}
+
+	transport, err := cfg.transport()
+	if err != nil {
+		return nil, fmt.Errorf("invalid kafka security config: %w", err)
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        cfg.Brokers,
+		Dialer:         dialer,
+		GroupID:        cfg.ConsumerGroup,
+		GroupTopics:    topics,
+		MinBytes:       10e3, // 10KB
+		MaxBytes:       10e6, // 10MB
+		MaxWait:        1 * time.Second,
+		ReadBackoffMin: 100 * time.Millisecond,
+		ReadBackoffMax: 1 * time.Second,
+		StartOffset:    kafka.LastOffset,
+		// runner_scheduler в exactly-once режиме пишет в транзакциях: сообщения отмененных
+		// транзакций не должны попадать в обработку
+		IsolationLevel: kafka.ReadCommitted,
+		CommitInterval: 0, // Отключаем автоматический commit
+		Logger:         kafka.LoggerFunc(logger.Sugar().Debugf),
+		ErrorLogger:    kafka.LoggerFunc(logger.Sugar().Errorf),
+	})
+
+	logger.Info("kafka consumer initialized",
+		zap.Strings("brokers", cfg.Brokers),
+		zap.String("consumer_group", cfg.ConsumerGroup),
+		zap.Strings("topics", topics),
+	)
+
+	return &KafkaConsumer{
+		reader:  reader,
+		logger:  logger,
+		offsets: offsets.NewTracker(),
+		client: &kafka.Client{
+			Addr:      kafka.TCP(cfg.Brokers...),
+			Timeout:   10 * time.Second,
+			Transport: transport,
+		},
+	}, nil
+}
+
+func (c *KafkaConsumer) ReadMessage(ctx context.Context) (*Message, error) {
+	kafkaMsg, err := c.reader.FetchMessage(ctx)
+	if err != nil {
+		c.logger.Error("failed to fetch message from kafka",
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch message: %w", err)
+	}
+
+	c.syncAfterRebalance(ctx)
+	c.offsets.Fetched(kafkaMsg.Topic, kafkaMsg.Partition, kafkaMsg.Offset)
+
+	msg := convertMessage(kafkaMsg)
+
+	logFields := []zap.Field{
+		zap.String("topic", msg.Topic),
+		zap.Int("partition", kafkaMsg.Partition),
+		zap.Int64("offset", kafkaMsg.Offset),
+	}
+	if msg.Key != nil {
+		logFields = append(logFields, zap.String("key", *msg.Key))
+	}
+	c.logger.Debug("message fetched successfully", logFields...)
+
+	return msg, nil
+}
+
+// CommitMessages подтверждает переданные сообщения. Сообщения могут обрабатываться не по
+// порядку: смещение партиции продвигается только до старшего сообщения, перед которым все
+// выданные сообщения этой партиции уже подтверждены
+func (c *KafkaConsumer) CommitMessages(ctx context.Context, msgs ...*Message) error {
+	if len(msgs) == 0 {
+		return fmt.Errorf("no message to commit")
+	}
+
+	processed := make([]offsets.Offset, len(msgs))
+	for i, msg := range msgs {
+		if msg == nil {
+			return fmt.Errorf("message cannot be nil")
+		}
+		processed[i] = offsets.Offset{Topic: msg.Topic, Partition: msg.Partition, Offset: msg.Offset}
+	}
+
+	c.commitMu.Lock()
+	defer c.commitMu.Unlock()
+
+	commits, err := c.offsets.Pending(processed...)
+	if err != nil {
+		return fmt.Errorf("failed to commit message: %w", err)
+	}
+
+	if len(commits) == 0 {
+		c.offsets.Processed(processed...)
+		return nil
+	}
+
+	kafkaMsgs := make([]kafka.Message, 0, len(commits))
+	for key, offset := range commits {
+		kafkaMsgs = append(kafkaMsgs, kafka.Message{Topic: key.Topic, Partition: key.Partition, Offset: offset})
+	}
+
+	if err := c.reader.CommitMessages(ctx, kafkaMsgs...); err != nil {
+		c.logger.Error("failed to commit messages",
+			zap.Error(err),
+			zap.Int("partitions", len(kafkaMsgs)),
+		)
+		return fmt.Errorf("failed to commit messages: %w", err)
+	}
+	c.offsets.Processed(processed...)
+
+	for _, kafkaMsg := range kafkaMsgs {
+		c.logger.Debug("offset committed successfully",
+			zap.String("topic", kafkaMsg.Topic),
+			zap.Int("partition", kafkaMsg.Partition),
+			zap.Int64("offset", kafkaMsg.Offset),
+		)
+	}
+
+	return nil
+}
+
+// convertMessage переводит сообщение kafka-go в Message
+func convertMessage(kafkaMsg kafka.Message) *Message {
+	var key *string
+	if len(kafkaMsg.Key) > 0 {
+		keyStr := string(kafkaMsg.Key)
+		key = &keyStr
+	}
+
+	headers := make(map[string][]byte)
+	for _, header := range kafkaMsg.Headers {
+		headers[header.Key] = header.Value
+	}
+
+	return &Message{
+		Topic:     kafkaMsg.Topic,
+		Key:       key,
+		Value:     kafkaMsg.Value,
+		Headers:   headers,
+		Partition: kafkaMsg.Partition,
+		Offset:    kafkaMsg.Offset,
+	}
+}
+
+func (c *KafkaConsumer) Close() error {
+	if c == nil || c.reader == nil {
+		return nil
+	}
+
+	if err := c.reader.Close(); err != nil {
+		if c.logger != nil {
+			c.logger.Error("failed to close kafka reader", zap.Error(err))
+		}
+		return fmt.Errorf("close kafka reader: %w", err)
+	}
+
+	if transport, ok := c.client.Transport.(*kafka.Transport); ok {
+		transport.CloseIdleConnections()
+	}
+
+	if c.logger != nil {
+		c.logger.Info("kafka consumer closed")
+	}
+
+	return nil
+}
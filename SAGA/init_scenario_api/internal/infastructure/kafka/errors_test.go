@@ -0,0 +1,33 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestNewBatchError(t *testing.T) {
+	writeErrors := kafka.WriteErrors{nil, errors.New("rejected"), nil}
+
+	batchErr := newBatchError(fmt.Errorf("write: %w", writeErrors), 3)
+	if batchErr == nil {
+		t.Fatalf("expected batch error for kafka.WriteErrors")
+	}
+	if batchErr.Count() != 1 {
+		t.Fatalf("expected 1 failed message, got %d", batchErr.Count())
+	}
+	if batchErr.Failed(0) || !batchErr.Failed(1) || batchErr.Failed(2) {
+		t.Fatalf("unexpected failed indexes: %v", batchErr.Errors)
+	}
+}
+
+func TestNewBatchErrorUnknownResult(t *testing.T) {
+	if newBatchError(errors.New("connection refused"), 3) != nil {
+		t.Fatalf("expected nil for error without per-message result")
+	}
+	if newBatchError(kafka.WriteErrors{nil, nil}, 3) != nil {
+		t.Fatalf("expected nil when result length does not match batch size")
+	}
+}
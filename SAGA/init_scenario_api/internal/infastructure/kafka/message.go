@@ -7,6 +7,10 @@ type Message struct {
 	Key     *string
 	Value   []byte
 	Headers map[string][]byte
+	// Partition и Offset заполняются consumer'ом и нужны для подтверждения сообщения,
+	// producer их игнорирует
+	Partition int
+	Offset    int64
 }
 
 func (m *Message) Validate() error {
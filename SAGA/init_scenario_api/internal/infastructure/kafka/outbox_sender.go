@@ -0,0 +1,26 @@
+package kafka
+
+import (
+	"context"
+
+	"shared/outbox"
+)
+
+// OutboxSender отправляет сообщения outbox relay через Producer. Ошибка частичной
+// доставки (*BatchError) реализует outbox.PartialError
+type OutboxSender struct {
+	producer Producer
+}
+
+func NewOutboxSender(producer Producer) *OutboxSender {
+	return &OutboxSender{producer: producer}
+}
+
+func (s *OutboxSender) Send(ctx context.Context, msgs []outbox.Message) error {
+	kafkaMsgs := make([]*Message, len(msgs))
+	for i, msg := range msgs {
+		key := msg.Key
+		kafkaMsgs[i] = NewMessage(msg.Topic, &key, msg.Value, msg.Headers)
+	}
+	return s.producer.SendMessages(ctx, kafkaMsgs)
+}
@@ -25,9 +25,15 @@ func NewKafkaProducer(cfg *Config, logger *zap.Logger) (Producer, error) {
 		return nil, fmt.Errorf("invalid kafka config: %w", err)
 	}
 
+	transport, err := cfg.transport()
+	if err != nil {
+		return nil, fmt.Errorf("invalid kafka security config: %w", err)
+	}
+
 	writer := &kafka.Writer{
 		Addr:                   kafka.TCP(cfg.Brokers...),
-		Balancer:               &kafka.LeastBytes{},
+		Transport:              transport,
+		Balancer:               &keyedBalancer{keyed: &kafka.Hash{}, unkeyed: &kafka.LeastBytes{}},
 		ReadTimeout:            time.Duration(cfg.ReadTimeout) * time.Second,
 		WriteTimeout:           time.Duration(cfg.WriteTimeout) * time.Second,
 		RequiredAcks:           kafka.RequiredAcks(cfg.RequiredAcks),
@@ -93,6 +99,8 @@ func (p KafkaProducer) SendMessage(ctx context.Context, msg *Message) error {
 	return nil
 }
 
+// SendMessages отправляет батч сообщений. Если брокер отклонил только часть сообщений,
+// возвращается ошибка, оборачивающая *BatchError с результатом по каждому сообщению
 func (p KafkaProducer) SendMessages(ctx context.Context, msgs []*Message) error {
 	if len(msgs) == 0 {
 		return nil
@@ -127,6 +135,14 @@ func (p KafkaProducer) SendMessages(ctx context.Context, msgs []*Message) error
 
 	err := p.writer.WriteMessages(ctx, kafkaMsgs...)
 	if err != nil {
+		if batchErr := newBatchError(err, len(msgs)); batchErr != nil {
+			p.logger.Error("failed to send part of messages batch to kafka",
+				zap.Int("messages_count", len(msgs)),
+				zap.Int("failed_count", batchErr.Count()),
+			)
+			return fmt.Errorf("failed to send messages batch: %w", batchErr)
+		}
+
 		p.logger.Error("failed to send messages batch to kafka",
 			zap.Int("messages_count", len(msgs)),
 			zap.Error(err),
@@ -153,6 +169,11 @@ func (p *KafkaProducer) Close() error {
 		return fmt.Errorf("close kafka writer: %w", err)
 	}
 
+	// Writer закрывает соединения только своего транспорта по умолчанию
+	if transport, ok := p.writer.Transport.(*kafka.Transport); ok {
+		transport.CloseIdleConnections()
+	}
+
 	if p.logger != nil {
 		p.logger.Info("kafka producer closed")
 	}
@@ -170,3 +191,17 @@ func convertHeaders(headers map[string][]byte) []kafka.Header {
 	}
 	return kafkaHeaders
 }
+
+// keyedBalancer распределяет сообщения с ключом по хэшу ключа, чтобы сообщения одного ключа
+// попадали в одну партицию и читались по порядку, а сообщения без ключа - по LeastBytes
+type keyedBalancer struct {
+	keyed   kafka.Balancer
+	unkeyed kafka.Balancer
+}
+
+func (b *keyedBalancer) Balance(msg kafka.Message, partitions ...int) int {
+	if msg.Key != nil {
+		return b.keyed.Balance(msg, partitions...)
+	}
+	return b.unkeyed.Balance(msg, partitions...)
+}
@@ -0,0 +1,29 @@
+package kafka
+
+import (
+	"shared/kafkasecurity"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Механизмы SASL
+const (
+	SASLPlain       = kafkasecurity.SASLPlain
+	SASLScramSHA256 = kafkasecurity.SASLScramSHA256
+	SASLScramSHA512 = kafkasecurity.SASLScramSHA512
+)
+
+type (
+	SASLConfig = kafkasecurity.SASLConfig
+	TLSConfig  = kafkasecurity.TLSConfig
+)
+
+// dialer создает dialer для reader'а и прямых подключений к брокерам
+func (c *Config) dialer() (*kafka.Dialer, error) {
+	return kafkasecurity.Dialer(c.SASL, c.TLS)
+}
+
+// transport создает транспорт для writer'а
+func (c *Config) transport() (*kafka.Transport, error) {
+	return kafkasecurity.Transport(c.SASL, c.TLS)
+}
@@ -0,0 +1,16 @@
+package metrics
+
+import "expvar"
+
+// retentionRowsPurged - количество удаленных очисткой строк по таблицам, отдается на /debug/vars
+var retentionRowsPurged = expvar.NewMap("retention_rows_purged")
+
+type Retention struct{}
+
+func NewRetention() *Retention {
+	return &Retention{}
+}
+
+func (Retention) AddPurged(table string, rows int) {
+	retentionRowsPurged.Add(table, int64(rows))
+}
@@ -0,0 +1,11 @@
+package replication
+
+import "fmt"
+
+// LSN - позиция в журнале WAL
+type LSN uint64
+
+// String возвращает LSN в формате Postgres, например 16/B374D848
+func (l LSN) String() string {
+	return fmt.Sprintf("%X/%X", uint32(l>>32), uint32(l))
+}
@@ -0,0 +1,177 @@
+package replication
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Сообщения протокола pgoutput версии 1, которые нужны relay. Остальные типы
+// (Origin, Type, Update, Delete, Truncate) пропускаются.
+const (
+	messageBegin    = 'B'
+	messageCommit   = 'C'
+	messageRelation = 'R'
+	messageInsert   = 'I'
+)
+
+const (
+	tupleNull      = 'n'
+	tupleUnchanged = 'u'
+	tupleText      = 't'
+)
+
+var errShortMessage = errors.New("pgoutput message is too short")
+
+type relation struct {
+	namespace string
+	name      string
+	columns   []string
+}
+
+type beginMessage struct {
+	finalLSN LSN
+}
+
+type commitMessage struct {
+	commitLSN LSN
+	endLSN    LSN
+}
+
+type insertMessage struct {
+	relationID uint32
+	values     []*string
+}
+
+// decoder читает поля pgoutput сообщения по порядку. Ошибка запоминается, и все
+// последующие чтения возвращают нулевые значения
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.data) < n {
+		d.err = errShortMessage
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *decoder) uint8() uint8 {
+	b := d.bytes(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (d *decoder) uint16() uint16 {
+	b := d.bytes(2)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b)
+}
+
+func (d *decoder) uint32() uint32 {
+	b := d.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func (d *decoder) uint64() uint64 {
+	b := d.bytes(8)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+func (d *decoder) string() string {
+	if d.err != nil {
+		return ""
+	}
+	for i, c := range d.data {
+		if c == 0 {
+			s := string(d.data[:i])
+			d.data = d.data[i+1:]
+			return s
+		}
+	}
+	d.err = errShortMessage
+	return ""
+}
+
+func decodeBegin(data []byte) (beginMessage, error) {
+	d := decoder{data: data}
+	msg := beginMessage{finalLSN: LSN(d.uint64())}
+	d.uint64() // commit timestamp
+	d.uint32() // xid
+	return msg, d.err
+}
+
+func decodeCommit(data []byte) (commitMessage, error) {
+	d := decoder{data: data}
+	d.uint8() // flags
+	msg := commitMessage{
+		commitLSN: LSN(d.uint64()),
+		endLSN:    LSN(d.uint64()),
+	}
+	d.uint64() // commit timestamp
+	return msg, d.err
+}
+
+func decodeRelation(data []byte) (uint32, relation, error) {
+	d := decoder{data: data}
+	id := d.uint32()
+	rel := relation{
+		namespace: d.string(),
+		name:      d.string(),
+	}
+	d.uint8() // replica identity
+
+	count := int(d.uint16())
+	rel.columns = make([]string, 0, count)
+	for i := 0; i < count && d.err == nil; i++ {
+		d.uint8() // flags
+		rel.columns = append(rel.columns, d.string())
+		d.uint32() // type oid
+		d.uint32() // type modifier
+	}
+	return id, rel, d.err
+}
+
+func decodeInsert(data []byte) (insertMessage, error) {
+	d := decoder{data: data}
+	msg := insertMessage{relationID: d.uint32()}
+
+	if kind := d.uint8(); d.err == nil && kind != 'N' {
+		return msg, fmt.Errorf("unexpected insert tuple kind %q", kind)
+	}
+
+	count := int(d.uint16())
+	msg.values = make([]*string, 0, count)
+	for i := 0; i < count && d.err == nil; i++ {
+		switch kind := d.uint8(); kind {
+		case tupleNull, tupleUnchanged:
+			msg.values = append(msg.values, nil)
+		case tupleText:
+			size := d.uint32()
+			value := string(d.bytes(int(size)))
+			msg.values = append(msg.values, &value)
+		default:
+			if d.err == nil {
+				return msg, fmt.Errorf("unsupported tuple column kind %q", kind)
+			}
+		}
+	}
+	return msg, d.err
+}
@@ -0,0 +1,113 @@
+package replication
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func encodeRelation(id uint32, name string, columns ...string) []byte {
+	data := []byte{messageRelation}
+	data = binary.BigEndian.AppendUint32(data, id)
+	data = append(data, "public\x00"...)
+	data = append(data, name+"\x00"...)
+	data = append(data, 'd')
+	data = binary.BigEndian.AppendUint16(data, uint16(len(columns)))
+	for _, column := range columns {
+		data = append(data, 0)
+		data = append(data, column+"\x00"...)
+		data = binary.BigEndian.AppendUint32(data, 25)
+		data = binary.BigEndian.AppendUint32(data, 0xFFFFFFFF)
+	}
+	return data
+}
+
+func encodeInsert(id uint32, values ...*string) []byte {
+	data := []byte{messageInsert}
+	data = binary.BigEndian.AppendUint32(data, id)
+	data = append(data, 'N')
+	data = binary.BigEndian.AppendUint16(data, uint16(len(values)))
+	for _, value := range values {
+		if value == nil {
+			data = append(data, tupleNull)
+			continue
+		}
+		data = append(data, tupleText)
+		data = binary.BigEndian.AppendUint32(data, uint32(len(*value)))
+		data = append(data, *value...)
+	}
+	return data
+}
+
+func encodeCommit(end LSN) []byte {
+	data := []byte{messageCommit, 0}
+	data = binary.BigEndian.AppendUint64(data, uint64(end)-8)
+	data = binary.BigEndian.AppendUint64(data, uint64(end))
+	data = binary.BigEndian.AppendUint64(data, 0)
+	return data
+}
+
+func encodeBegin() []byte {
+	data := []byte{messageBegin}
+	data = binary.BigEndian.AppendUint64(data, 0)
+	data = binary.BigEndian.AppendUint64(data, 0)
+	data = binary.BigEndian.AppendUint32(data, 1)
+	return data
+}
+
+func TestHandlePgoutputAssemblesTransaction(t *testing.T) {
+	s := &Stream{relations: make(map[uint32]relation)}
+	uuid := "0193a6f0-0000-7000-8000-000000000001"
+	key := "7"
+
+	messages := [][]byte{
+		encodeBegin(),
+		encodeRelation(16384, "outbox_scenario", "outbox_uuid", "last_error", "partition_key"),
+		encodeInsert(16384, &uuid, nil, &key),
+	}
+	for _, msg := range messages {
+		tx, err := s.handlePgoutput(msg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if tx != nil {
+			t.Fatalf("transaction returned before commit")
+		}
+	}
+
+	tx, err := s.handlePgoutput(encodeCommit(LSN(0x16B374D848)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tx == nil || len(tx.Inserts) != 1 {
+		t.Fatalf("expected transaction with one insert, got %+v", tx)
+	}
+	if tx.EndLSN.String() != "16/B374D848" {
+		t.Fatalf("unexpected end lsn %s", tx.EndLSN)
+	}
+
+	insert := tx.Inserts[0]
+	if insert.Table != "outbox_scenario" {
+		t.Fatalf("unexpected table %q", insert.Table)
+	}
+	if v := insert.Row["outbox_uuid"]; v == nil || *v != uuid {
+		t.Fatalf("unexpected outbox_uuid %v", v)
+	}
+	if v, ok := insert.Row["last_error"]; !ok || v != nil {
+		t.Fatalf("expected null last_error, got %v", v)
+	}
+	if v := insert.Row["partition_key"]; v == nil || *v != key {
+		t.Fatalf("unexpected partition_key %v", v)
+	}
+	if s.idle() {
+		t.Fatalf("stream must not be idle with unconfirmed transaction")
+	}
+}
+
+func TestDecodeInsertRejectsTruncatedMessage(t *testing.T) {
+	value := "payload"
+	data := encodeInsert(1, &value)
+
+	if _, err := decodeInsert(data[1 : len(data)-2]); err == nil {
+		t.Fatalf("expected error for truncated insert")
+	}
+}
@@ -0,0 +1,305 @@
+package replication
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+)
+
+const (
+	messageXLogData          = 'w'
+	messagePrimaryKeepalive  = 'k'
+	messageStandbyStatus     = 'r'
+	duplicateObjectErrorCode = "42710"
+)
+
+// postgresEpoch - начало отсчета времени в протоколе репликации
+var postgresEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+type Config struct {
+	// DSN подключения к базе. Параметр replication=database добавляется автоматически
+	DSN         string
+	Slot        string
+	Publication string
+	// StatusInterval - как часто сообщать серверу подтвержденную позицию, если он сам не запрашивает.
+	// Должен быть меньше wal_sender_timeout сервера
+	StatusInterval time.Duration
+}
+
+// Row - вставленная строка: значения в текстовом формате по именам колонок, nil для NULL
+type Row map[string]*string
+
+// Transaction - закоммиченная транзакция со вставками в таблицы публикации
+type Transaction struct {
+	// EndLSN - позиция после коммита. Подтверждение EndLSN означает, что транзакция
+	// обработана и не будет прислана повторно
+	EndLSN  LSN
+	Inserts []Insert
+}
+
+type Insert struct {
+	Table string
+	Row   Row
+}
+
+// Stream читает изменения из логического слота репликации через pgoutput.
+// Слот переживает перезапуск: после переподключения сервер присылает все транзакции
+// после последней подтвержденной позиции, поэтому доставка at-least-once.
+type Stream struct {
+	conn *pgconn.PgConn
+	cfg  Config
+
+	relations map[uint32]relation
+	current   *Transaction
+
+	// received - EndLSN последней отданной транзакции, confirmed - последняя подтвержденная
+	// позиция, которая отправляется серверу в статусах
+	received  LSN
+	confirmed LSN
+	// idleLSN - позиция сервера из keepalive. Пока нет неподтвержденных транзакций, слот
+	// можно сдвинуть до нее, чтобы сервер не копил WAL изменений других таблиц
+	idleLSN    LSN
+	nextStatus time.Time
+}
+
+func Connect(ctx context.Context, cfg Config) (*Stream, error) {
+	connCfg, err := pgconn.ParseConfig(cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("parse replication dsn: %w", err)
+	}
+	connCfg.RuntimeParams["replication"] = "database"
+
+	conn, err := pgconn.ConnectConfig(ctx, connCfg)
+	if err != nil {
+		return nil, fmt.Errorf("connect for replication: %w", err)
+	}
+
+	return &Stream{
+		conn:      conn,
+		cfg:       cfg,
+		relations: make(map[uint32]relation),
+	}, nil
+}
+
+// Start создает слот, если его еще нет, и запускает стриминг с последней подтвержденной
+// в слоте позиции
+func (s *Stream) Start(ctx context.Context) error {
+	createSQL := fmt.Sprintf("CREATE_REPLICATION_SLOT %s LOGICAL pgoutput NOEXPORT_SNAPSHOT", s.cfg.Slot)
+	if _, err := s.conn.Exec(ctx, createSQL).ReadAll(); err != nil {
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.Code != duplicateObjectErrorCode {
+			return fmt.Errorf("create replication slot %s: %w", s.cfg.Slot, err)
+		}
+	}
+
+	startSQL := fmt.Sprintf(
+		"START_REPLICATION SLOT %s LOGICAL 0/0 (proto_version '1', publication_names '%s')",
+		s.cfg.Slot, s.cfg.Publication,
+	)
+	s.conn.Frontend().Send(&pgproto3.Query{String: startSQL})
+	if err := s.conn.Frontend().Flush(); err != nil {
+		return fmt.Errorf("send start replication: %w", err)
+	}
+
+	for {
+		msg, err := s.conn.ReceiveMessage(ctx)
+		if err != nil {
+			return fmt.Errorf("start replication: %w", err)
+		}
+		switch msg := msg.(type) {
+		case *pgproto3.CopyBothResponse:
+			s.nextStatus = time.Now().Add(s.cfg.StatusInterval)
+			return nil
+		case *pgproto3.ErrorResponse:
+			return fmt.Errorf("start replication: %w", pgconn.ErrorResponseToPgError(msg))
+		case *pgproto3.NoticeResponse:
+		default:
+			return fmt.Errorf("start replication: unexpected message %T", msg)
+		}
+	}
+}
+
+// Receive блокируется до следующей закоммиченной транзакции со вставками или отмены ctx.
+// Keepalive и периодические статусы обрабатываются внутри. Ошибка по ctx оставляет
+// соединение рабочим, любая другая ошибка означает, что стрим нужно переоткрыть.
+func (s *Stream) Receive(ctx context.Context) (*Transaction, error) {
+	for {
+		if !time.Now().Before(s.nextStatus) {
+			if err := s.sendStatus(); err != nil {
+				return nil, err
+			}
+		}
+
+		receiveCtx, cancel := context.WithDeadline(ctx, s.nextStatus)
+		msg, err := s.conn.ReceiveMessage(receiveCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if pgconn.Timeout(err) {
+				continue
+			}
+			return nil, fmt.Errorf("receive replication message: %w", err)
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.CopyData:
+			tx, err := s.handleCopyData(msg.Data)
+			if err != nil {
+				return nil, err
+			}
+			if tx != nil {
+				return tx, nil
+			}
+		case *pgproto3.ErrorResponse:
+			return nil, fmt.Errorf("replication stream: %w", pgconn.ErrorResponseToPgError(msg))
+		case *pgproto3.CopyDone:
+			return nil, errors.New("replication stream closed by server")
+		}
+	}
+}
+
+// Confirm сообщает серверу, что все транзакции до lsn включительно обработаны, и слот
+// больше не должен их хранить
+func (s *Stream) Confirm(lsn LSN) error {
+	if lsn > s.confirmed {
+		s.confirmed = lsn
+	}
+	return s.sendStatus()
+}
+
+func (s *Stream) idle() bool {
+	return s.current == nil && s.received <= s.confirmed
+}
+
+func (s *Stream) Close(ctx context.Context) error {
+	return s.conn.Close(ctx)
+}
+
+func (s *Stream) handleCopyData(data []byte) (*Transaction, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	switch data[0] {
+	case messagePrimaryKeepalive:
+		d := decoder{data: data[1:]}
+		walEnd := LSN(d.uint64())
+		d.uint64() // server time
+		replyRequested := d.uint8() == 1
+		if d.err != nil {
+			return nil, fmt.Errorf("decode keepalive: %w", d.err)
+		}
+		if s.idle() && walEnd > s.idleLSN {
+			s.idleLSN = walEnd
+		}
+		if replyRequested {
+			return nil, s.sendStatus()
+		}
+		return nil, nil
+	case messageXLogData:
+		d := decoder{data: data[1:]}
+		d.uint64() // wal start
+		d.uint64() // server wal end
+		d.uint64() // server time
+		if d.err != nil {
+			return nil, fmt.Errorf("decode xlog data: %w", d.err)
+		}
+		return s.handlePgoutput(d.data)
+	default:
+		return nil, nil
+	}
+}
+
+func (s *Stream) handlePgoutput(data []byte) (*Transaction, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	switch data[0] {
+	case messageBegin:
+		if _, err := decodeBegin(data[1:]); err != nil {
+			return nil, fmt.Errorf("decode begin: %w", err)
+		}
+		s.current = &Transaction{}
+	case messageRelation:
+		id, rel, err := decodeRelation(data[1:])
+		if err != nil {
+			return nil, fmt.Errorf("decode relation: %w", err)
+		}
+		s.relations[id] = rel
+	case messageInsert:
+		msg, err := decodeInsert(data[1:])
+		if err != nil {
+			return nil, fmt.Errorf("decode insert: %w", err)
+		}
+		rel, ok := s.relations[msg.relationID]
+		if !ok {
+			return nil, fmt.Errorf("insert into unknown relation %d", msg.relationID)
+		}
+		if s.current == nil {
+			return nil, errors.New("insert outside of transaction")
+		}
+		row := make(Row, len(rel.columns))
+		for i, column := range rel.columns {
+			if i < len(msg.values) {
+				row[column] = msg.values[i]
+			}
+		}
+		s.current.Inserts = append(s.current.Inserts, Insert{Table: rel.name, Row: row})
+	case messageCommit:
+		msg, err := decodeCommit(data[1:])
+		if err != nil {
+			return nil, fmt.Errorf("decode commit: %w", err)
+		}
+		tx := s.current
+		s.current = nil
+		if tx == nil {
+			return nil, nil
+		}
+		tx.EndLSN = msg.endLSN
+		if len(tx.Inserts) == 0 {
+			// Транзакцию без вставок в публикацию можно сразу считать обработанной
+			if s.idle() && msg.endLSN > s.idleLSN {
+				s.idleLSN = msg.endLSN
+			}
+			return nil, nil
+		}
+		s.received = msg.endLSN
+		return tx, nil
+	}
+
+	return nil, nil
+}
+
+// sendStatus отправляет серверу подтвержденную позицию. Если неподтвержденных транзакций
+// нет, позиция сдвигается до последней известной позиции сервера
+func (s *Stream) sendStatus() error {
+	lsn := s.confirmed
+	if s.idle() && s.idleLSN > lsn {
+		lsn = s.idleLSN
+		s.confirmed = lsn
+	}
+
+	data := make([]byte, 0, 34)
+	data = append(data, messageStandbyStatus)
+	data = binary.BigEndian.AppendUint64(data, uint64(lsn)) // write
+	data = binary.BigEndian.AppendUint64(data, uint64(lsn)) // flush
+	data = binary.BigEndian.AppendUint64(data, uint64(lsn)) // apply
+	data = binary.BigEndian.AppendUint64(data, uint64(time.Since(postgresEpoch).Microseconds()))
+	data = append(data, 0) // reply requested
+
+	s.conn.Frontend().Send(&pgproto3.CopyData{Data: data})
+	if err := s.conn.Frontend().Flush(); err != nil {
+		return fmt.Errorf("send standby status: %w", err)
+	}
+
+	s.nextStatus = time.Now().Add(s.cfg.StatusInterval)
+	return nil
+}
@@ -0,0 +1,73 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: idempotency_queries.sql
+
+package idempotency
+
+import (
+	"context"
+)
+
+const createIdempotencyKey = `-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_key (
+    key,
+    request_hash
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (key) DO NOTHING
+RETURNING key, request_hash, response, created_at
+`
+
+type CreateIdempotencyKeyParams struct {
+	Key         string `json:"key"`
+	RequestHash string `json:"request_hash"`
+}
+
+// Занимает ключ. При конфликте строка не возвращается: конкурентный запрос с тем же ключом
+// дожидается фиксации транзакции первого запроса, после чего ключ читается GetIdempotencyKey
+func (q *Queries) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, createIdempotencyKey, arg.Key, arg.RequestHash)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
+		&i.RequestHash,
+		&i.Response,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT key, request_hash, response, created_at FROM idempotency_key
+WHERE key = $1
+`
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, key string) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, getIdempotencyKey, key)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
+		&i.RequestHash,
+		&i.Response,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const setIdempotencyKeyResponse = `-- name: SetIdempotencyKeyResponse :exec
+UPDATE idempotency_key
+SET response = $2
+WHERE key = $1
+`
+
+type SetIdempotencyKeyResponseParams struct {
+	Key      string `json:"key"`
+	Response []byte `json:"response"`
+}
+
+func (q *Queries) SetIdempotencyKeyResponse(ctx context.Context, arg SetIdempotencyKeyResponseParams) error {
+	_, err := q.db.Exec(ctx, setIdempotencyKeyResponse, arg.Key, arg.Response)
+	return err
+}
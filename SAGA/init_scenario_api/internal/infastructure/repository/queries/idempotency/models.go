@@ -0,0 +1,114 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package idempotency
+
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Idempotency keys of POST /scenario/init requests with the stored responses for replay
+type IdempotencyKey struct {
+	// Value of the Idempotency-Key request header
+	Key string `json:"key"`
+	// SHA-256 of the normalized request body the key was first used with
+	RequestHash string `json:"request_hash"`
+	// JSON response returned for the first request, replayed on repeats
+	Response []byte `json:"response"`
+	// Timestamp when the key was first used
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+// Inbox pattern table for deduplicating scenario start and stop results from runner_scheduler
+type InboxScenarioResult struct {
+	// UUID of the message in runner_scheduler outbox (idempotency key)
+	OutboxUuid pgtype.UUID `json:"outbox_uuid"`
+	// UUID of the associated scenario from scenario table
+	ScenarioUuid pgtype.UUID `json:"scenario_uuid"`
+	// Type of the event (scenario_started, scenario_start_failed, scenario_stopped)
+	EventType string `json:"event_type"`
+	// JSON data of the message payload
+	Payload []byte `json:"payload"`
+	// Timestamp when the message was received
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+// Generic outbox for reliable publishing of aggregate events to Kafka
+type Outbox struct {
+	// Unique identifier of the event, sent in the outbox_uuid header for deduplication
+	ID pgtype.UUID `json:"id"`
+	// Type of the aggregate the event belongs to (scenario, camera, ...)
+	AggregateType string `json:"aggregate_type"`
+	// Identifier of the aggregate; used as the Kafka message key, events of one aggregate are published in order
+	AggregateID string `json:"aggregate_id"`
+	// Type of the event
+	EventType string `json:"event_type"`
+	// Kafka topic the event is published to
+	Topic string `json:"topic"`
+	// Additional Kafka message headers
+	Headers []byte `json:"headers"`
+	// Message payload
+	Payload []byte `json:"payload"`
+	// State of the event (pending, sent, failed)
+	State string `json:"state"`
+	// Number of publish attempts
+	Attempts int32 `json:"attempts"`
+	// Error of the last failed publish attempt
+	LastError *string `json:"last_error"`
+	// Timestamp before which the event must not be claimed (lease of the relay or retry backoff)
+	NextAttemptAt pgtype.Timestamp `json:"next_attempt_at"`
+	// Insertion order of events, defines publish order within an aggregate
+	Seq int64 `json:"seq"`
+	// Timestamp when the event was created
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	// Timestamp when the event was last updated
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+// Outbox pattern table for reliable message publishing in SAGA
+type OutboxScenario struct {
+	OutboxUuid pgtype.UUID `json:"outbox_uuid"`
+	// UUID of the associated scenario from scenario table
+	ScenarioUuid pgtype.UUID `json:"scenario_uuid"`
+	// JSON data of the message payload
+	Payload []byte `json:"payload"`
+	// State of the message (pending, sent, failed)
+	State *string `json:"state"`
+	// Timestamp when the message was created
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	// Timestamp when the message was last updated
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+	// Timestamp until which the outbox message is locked from being processed (publish in progress or retry backoff)
+	LockedUntil pgtype.Timestamp `json:"locked_until"`
+	// Type of the event (init_scenario, stop_scenario, compensate_scenario)
+	EventType string `json:"event_type"`
+	// Number of attempts to publish the message to Kafka
+	Attempts int32 `json:"attempts"`
+	// Error of the last failed publish attempt
+	LastError *string `json:"last_error"`
+	// Kafka message key (camera_id of the scenario); events with the same key are published in seq order
+	PartitionKey string `json:"partition_key"`
+	// Monotonic insertion order of the message, defines publish order within a partition_key
+	Seq int64 `json:"seq"`
+}
+
+// Scenario table for storing scenario state and camera prediction
+type Scenario struct {
+	// Unique identifier for the scenario (UUID format)
+	Uuid pgtype.UUID `json:"uuid"`
+	// ID of the camera being used in the scenario
+	CameraID int32 `json:"camera_id"`
+	// URL to connect to camera
+	Url string `json:"url"`
+	// ID of the predicted person
+	PredictID *int32 `json:"predict_id"`
+	// Status of the scenario (init_startup, in_startup_processing, active, start_failed, init_shutdown, in_shutdown_processing, inactive)
+	Status *string `json:"status"`
+	// Timestamp when the scenario was created
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	// Timestamp when the scenario was last updated
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+	// Reason why the scenario failed to start (set together with start_failed status)
+	FailureReason *string `json:"failure_reason"`
+}
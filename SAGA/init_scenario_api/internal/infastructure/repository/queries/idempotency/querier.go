@@ -0,0 +1,19 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package idempotency
+
+import (
+	"context"
+)
+
+type Querier interface {
+	// Занимает ключ. При конфликте строка не возвращается: конкурентный запрос с тем же ключом
+	// дожидается фиксации транзакции первого запроса, после чего ключ читается GetIdempotencyKey
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	GetIdempotencyKey(ctx context.Context, key string) (IdempotencyKey, error)
+	SetIdempotencyKeyResponse(ctx context.Context, arg SetIdempotencyKeyResponseParams) error
+}
+
+var _ Querier = (*Queries)(nil)
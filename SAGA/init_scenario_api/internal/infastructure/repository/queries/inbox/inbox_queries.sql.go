@@ -0,0 +1,95 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: inbox_queries.sql
+
+package inbox
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createInboxScenarioResult = `-- name: CreateInboxScenarioResult :one
+INSERT INTO inbox_scenario_result (
+    outbox_uuid,
+    scenario_uuid,
+    event_type,
+    payload
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING outbox_uuid, scenario_uuid, event_type, payload, created_at
+`
+
+type CreateInboxScenarioResultParams struct {
+	OutboxUuid   pgtype.UUID `json:"outbox_uuid"`
+	ScenarioUuid pgtype.UUID `json:"scenario_uuid"`
+	EventType    string      `json:"event_type"`
+	Payload      []byte      `json:"payload"`
+}
+
+func (q *Queries) CreateInboxScenarioResult(ctx context.Context, arg CreateInboxScenarioResultParams) (InboxScenarioResult, error) {
+	row := q.db.QueryRow(ctx, createInboxScenarioResult,
+		arg.OutboxUuid,
+		arg.ScenarioUuid,
+		arg.EventType,
+		arg.Payload,
+	)
+	var i InboxScenarioResult
+	err := row.Scan(
+		&i.OutboxUuid,
+		&i.ScenarioUuid,
+		&i.EventType,
+		&i.Payload,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const purgeInboxScenarioResults = `-- name: PurgeInboxScenarioResults :many
+DELETE FROM inbox_scenario_result
+WHERE outbox_uuid IN (
+    SELECT outbox_uuid FROM inbox_scenario_result
+    WHERE created_at < NOW() - $1::integer * INTERVAL '1 second'
+    ORDER BY created_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING outbox_uuid, scenario_uuid, event_type, payload, created_at
+`
+
+type PurgeInboxScenarioResultsParams struct {
+	OlderThanSeconds int32 `json:"older_than_seconds"`
+	BatchSize        int32 `json:"batch_size"`
+}
+
+// Удаляет до batch_size записей inbox старше older_than_seconds и возвращает их для архива.
+// Записи inbox обрабатываются в момент вставки, поэтому все они считаются processed.
+// После удаления повторная доставка того же события будет обработана заново
+func (q *Queries) PurgeInboxScenarioResults(ctx context.Context, arg PurgeInboxScenarioResultsParams) ([]InboxScenarioResult, error) {
+	rows, err := q.db.Query(ctx, purgeInboxScenarioResults, arg.OlderThanSeconds, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InboxScenarioResult{}
+	for rows.Next() {
+		var i InboxScenarioResult
+		if err := rows.Scan(
+			&i.OutboxUuid,
+			&i.ScenarioUuid,
+			&i.EventType,
+			&i.Payload,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,19 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package inbox
+
+import (
+	"context"
+)
+
+type Querier interface {
+	CreateInboxScenarioResult(ctx context.Context, arg CreateInboxScenarioResultParams) (InboxScenarioResult, error)
+	// Удаляет до batch_size записей inbox старше older_than_seconds и возвращает их для архива.
+	// Записи inbox обрабатываются в момент вставки, поэтому все они считаются processed.
+	// После удаления повторная доставка того же события будет обработана заново
+	PurgeInboxScenarioResults(ctx context.Context, arg PurgeInboxScenarioResultsParams) ([]InboxScenarioResult, error)
+}
+
+var _ Querier = (*Queries)(nil)
@@ -15,20 +15,30 @@ const createOutboxScenario = `-- name: CreateOutboxScenario :one
 INSERT INTO outbox_scenario (
     outbox_uuid,
     scenario_uuid,
-    payload
+    payload,
+    event_type,
+    partition_key
 ) VALUES (
-    $1, $2, $3
-) RETURNING outbox_uuid, scenario_uuid, payload, state, created_at, updated_at, locked_until
+    $1, $2, $3, $4, $5
+) RETURNING outbox_uuid, scenario_uuid, payload, state, created_at, updated_at, locked_until, event_type, attempts, last_error, partition_key, seq
 `
 
 type CreateOutboxScenarioParams struct {
 	OutboxUuid   pgtype.UUID `json:"outbox_uuid"`
 	ScenarioUuid pgtype.UUID `json:"scenario_uuid"`
 	Payload      []byte      `json:"payload"`
+	EventType    string      `json:"event_type"`
+	PartitionKey string      `json:"partition_key"`
 }
 
 func (q *Queries) CreateOutboxScenario(ctx context.Context, arg CreateOutboxScenarioParams) (OutboxScenario, error) {
-	row := q.db.QueryRow(ctx, createOutboxScenario, arg.OutboxUuid, arg.ScenarioUuid, arg.Payload)
+	row := q.db.QueryRow(ctx, createOutboxScenario,
+		arg.OutboxUuid,
+		arg.ScenarioUuid,
+		arg.Payload,
+		arg.EventType,
+		arg.PartitionKey,
+	)
 	var i OutboxScenario
 	err := row.Scan(
 		&i.OutboxUuid,
@@ -38,19 +48,33 @@ func (q *Queries) CreateOutboxScenario(ctx context.Context, arg CreateOutboxScen
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LockedUntil,
+		&i.EventType,
+		&i.Attempts,
+		&i.LastError,
+		&i.PartitionKey,
+		&i.Seq,
 	)
 	return i, err
 }
 
 const getPendingOutboxScenarios = `-- name: GetPendingOutboxScenarios :many
-SELECT outbox_uuid, scenario_uuid, payload, state, created_at, updated_at, locked_until FROM outbox_scenario
-WHERE state = 'pending'
-  AND (locked_until IS NULL OR locked_until <= NOW())
-ORDER BY created_at ASC
+SELECT o.outbox_uuid, o.scenario_uuid, o.payload, o.state, o.created_at, o.updated_at, o.locked_until, o.event_type, o.attempts, o.last_error, o.partition_key, o.seq FROM outbox_scenario o
+WHERE o.state = 'pending'
+  AND (o.locked_until IS NULL OR o.locked_until <= NOW())
+  AND NOT EXISTS (
+      SELECT 1 FROM outbox_scenario prev
+      WHERE prev.partition_key = o.partition_key
+        AND prev.state <> 'sent'
+        AND prev.seq < o.seq
+  )
+ORDER BY o.seq ASC
 LIMIT $1
 FOR UPDATE SKIP LOCKED
 `
 
+// Берется только самое раннее неотправленное событие каждого partition_key: следующее
+// событие ключа не публикуется, пока предыдущее не отправлено (в том числе пока оно
+// в backoff, захвачено другим relay или в failed)
 func (q *Queries) GetPendingOutboxScenarios(ctx context.Context, limit int32) ([]OutboxScenario, error) {
 	rows, err := q.db.Query(ctx, getPendingOutboxScenarios, limit)
 	if err != nil {
@@ -68,6 +92,57 @@ func (q *Queries) GetPendingOutboxScenarios(ctx context.Context, limit int32) ([
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.LockedUntil,
+			&i.EventType,
+			&i.Attempts,
+			&i.LastError,
+			&i.PartitionKey,
+			&i.Seq,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFailedOutboxScenarios = `-- name: ListFailedOutboxScenarios :many
+SELECT outbox_uuid, scenario_uuid, payload, state, created_at, updated_at, locked_until, event_type, attempts, last_error, partition_key, seq FROM outbox_scenario
+WHERE state = 'failed'
+ORDER BY updated_at DESC, outbox_uuid DESC
+LIMIT $2
+OFFSET $1
+`
+
+type ListFailedOutboxScenariosParams struct {
+	PageOffset int32 `json:"page_offset"`
+	PageLimit  int32 `json:"page_limit"`
+}
+
+func (q *Queries) ListFailedOutboxScenarios(ctx context.Context, arg ListFailedOutboxScenariosParams) ([]OutboxScenario, error) {
+	rows, err := q.db.Query(ctx, listFailedOutboxScenarios, arg.PageOffset, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []OutboxScenario{}
+	for rows.Next() {
+		var i OutboxScenario
+		if err := rows.Scan(
+			&i.OutboxUuid,
+			&i.ScenarioUuid,
+			&i.Payload,
+			&i.State,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LockedUntil,
+			&i.EventType,
+			&i.Attempts,
+			&i.LastError,
+			&i.PartitionKey,
+			&i.Seq,
 		); err != nil {
 			return nil, err
 		}
@@ -99,10 +174,12 @@ func (q *Queries) LockOutboxScenario(ctx context.Context, arg LockOutboxScenario
 const lockOutboxScenariosBatch = `-- name: LockOutboxScenariosBatch :exec
 UPDATE outbox_scenario
 SET locked_until = NOW() + INTERVAL '1 minute',
+    attempts = attempts + 1,
     updated_at = NOW()
 WHERE outbox_uuid = ANY($1::uuid[])
 `
 
+// Захват записей на отправку считается попыткой публикации
 func (q *Queries) LockOutboxScenariosBatch(ctx context.Context, dollar_1 []pgtype.UUID) error {
 	_, err := q.db.Exec(ctx, lockOutboxScenariosBatch, dollar_1)
 	return err
@@ -121,6 +198,151 @@ func (q *Queries) MarkOutboxScenariosAsSentBatch(ctx context.Context, dollar_1 [
 	return err
 }
 
+const purgeSentOutboxScenarios = `-- name: PurgeSentOutboxScenarios :many
+DELETE FROM outbox_scenario
+WHERE outbox_uuid IN (
+    SELECT outbox_uuid FROM outbox_scenario
+    WHERE state = 'sent'
+      AND updated_at < NOW() - $1::integer * INTERVAL '1 second'
+    ORDER BY updated_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING outbox_uuid, scenario_uuid, payload, state, created_at, updated_at, locked_until, event_type, attempts, last_error, partition_key, seq
+`
+
+type PurgeSentOutboxScenariosParams struct {
+	OlderThanSeconds int32 `json:"older_than_seconds"`
+	BatchSize        int32 `json:"batch_size"`
+}
+
+// Удаляет до batch_size отправленных записей старше older_than_seconds и возвращает их
+// для архива. SKIP LOCKED не ждет строк, которые держат другие транзакции
+func (q *Queries) PurgeSentOutboxScenarios(ctx context.Context, arg PurgeSentOutboxScenariosParams) ([]OutboxScenario, error) {
+	rows, err := q.db.Query(ctx, purgeSentOutboxScenarios, arg.OlderThanSeconds, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []OutboxScenario{}
+	for rows.Next() {
+		var i OutboxScenario
+		if err := rows.Scan(
+			&i.OutboxUuid,
+			&i.ScenarioUuid,
+			&i.Payload,
+			&i.State,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LockedUntil,
+			&i.EventType,
+			&i.Attempts,
+			&i.LastError,
+			&i.PartitionKey,
+			&i.Seq,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const requeueFailedOutboxScenarios = `-- name: RequeueFailedOutboxScenarios :many
+UPDATE outbox_scenario
+SET state = 'pending',
+    attempts = 0,
+    locked_until = NULL,
+    updated_at = NOW()
+WHERE state = 'failed'
+  AND outbox_uuid = ANY($1::uuid[])
+RETURNING outbox_uuid
+`
+
+// Возвращает failed записи в очередь публикации с новым бюджетом попыток.
+// last_error сохраняется до следующей попытки
+func (q *Queries) RequeueFailedOutboxScenarios(ctx context.Context, outboxUuids []pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, requeueFailedOutboxScenarios, outboxUuids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var outbox_uuid pgtype.UUID
+		if err := rows.Scan(&outbox_uuid); err != nil {
+			return nil, err
+		}
+		items = append(items, outbox_uuid)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rescheduleOutboxScenariosBatch = `-- name: RescheduleOutboxScenariosBatch :many
+UPDATE outbox_scenario
+SET state = CASE WHEN attempts >= $1::integer THEN 'failed' ELSE 'pending' END,
+    locked_until = CASE
+        WHEN attempts >= $1::integer THEN NULL
+        ELSE NOW() + LEAST(
+            $2::bigint * POWER(2, LEAST(GREATEST(attempts - 1, 0), 30))::bigint,
+            $3::bigint
+        ) * INTERVAL '1 millisecond'
+    END,
+    last_error = $4,
+    updated_at = NOW()
+WHERE outbox_uuid = ANY($5::uuid[])
+RETURNING outbox_uuid, state, attempts
+`
+
+type RescheduleOutboxScenariosBatchParams struct {
+	MaxAttempts int32         `json:"max_attempts"`
+	BaseDelayMs int64         `json:"base_delay_ms"`
+	MaxDelayMs  int64         `json:"max_delay_ms"`
+	LastError   *string       `json:"last_error"`
+	OutboxUuids []pgtype.UUID `json:"outbox_uuids"`
+}
+
+type RescheduleOutboxScenariosBatchRow struct {
+	OutboxUuid pgtype.UUID `json:"outbox_uuid"`
+	State      *string     `json:"state"`
+	Attempts   int32       `json:"attempts"`
+}
+
+// Откладывает записи после неудачной публикации с экспоненциальной задержкой
+// base_delay_ms * 2^(attempts-1), ограниченной max_delay_ms. Записи, исчерпавшие
+// max_attempts попыток, переводятся в терминальное состояние failed
+func (q *Queries) RescheduleOutboxScenariosBatch(ctx context.Context, arg RescheduleOutboxScenariosBatchParams) ([]RescheduleOutboxScenariosBatchRow, error) {
+	rows, err := q.db.Query(ctx, rescheduleOutboxScenariosBatch,
+		arg.MaxAttempts,
+		arg.BaseDelayMs,
+		arg.MaxDelayMs,
+		arg.LastError,
+		arg.OutboxUuids,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RescheduleOutboxScenariosBatchRow{}
+	for rows.Next() {
+		var i RescheduleOutboxScenariosBatchRow
+		if err := rows.Scan(&i.OutboxUuid, &i.State, &i.Attempts); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateOutboxScenarioState = `-- name: UpdateOutboxScenarioState :exec
 UPDATE outbox_scenario
 SET state = $2,
@@ -12,10 +12,25 @@ import (
 
 type Querier interface {
 	CreateOutboxScenario(ctx context.Context, arg CreateOutboxScenarioParams) (OutboxScenario, error)
+	// Берется только самое раннее неотправленное событие каждого partition_key: следующее
+	// событие ключа не публикуется, пока предыдущее не отправлено (в том числе пока оно
+	// в backoff, захвачено другим relay или в failed)
 	GetPendingOutboxScenarios(ctx context.Context, limit int32) ([]OutboxScenario, error)
+	ListFailedOutboxScenarios(ctx context.Context, arg ListFailedOutboxScenariosParams) ([]OutboxScenario, error)
 	LockOutboxScenario(ctx context.Context, arg LockOutboxScenarioParams) error
+	// Захват записей на отправку считается попыткой публикации
 	LockOutboxScenariosBatch(ctx context.Context, dollar_1 []pgtype.UUID) error
 	MarkOutboxScenariosAsSentBatch(ctx context.Context, dollar_1 []pgtype.UUID) error
+	// Удаляет до batch_size отправленных записей старше older_than_seconds и возвращает их
+	// для архива. SKIP LOCKED не ждет строк, которые держат другие транзакции
+	PurgeSentOutboxScenarios(ctx context.Context, arg PurgeSentOutboxScenariosParams) ([]OutboxScenario, error)
+	// Возвращает failed записи в очередь публикации с новым бюджетом попыток.
+	// last_error сохраняется до следующей попытки
+	RequeueFailedOutboxScenarios(ctx context.Context, outboxUuids []pgtype.UUID) ([]pgtype.UUID, error)
+	// Откладывает записи после неудачной публикации с экспоненциальной задержкой
+	// base_delay_ms * 2^(attempts-1), ограниченной max_delay_ms. Записи, исчерпавшие
+	// max_attempts попыток, переводятся в терминальное состояние failed
+	RescheduleOutboxScenariosBatch(ctx context.Context, arg RescheduleOutboxScenariosBatchParams) ([]RescheduleOutboxScenariosBatchRow, error)
 	UpdateOutboxScenarioState(ctx context.Context, arg UpdateOutboxScenarioStateParams) error
 }
 
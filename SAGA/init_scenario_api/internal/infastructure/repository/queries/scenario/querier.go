@@ -6,10 +6,19 @@ package scenario
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
 	CreateScenario(ctx context.Context, arg CreateScenarioParams) (Scenario, error)
+	FailScenarioStartup(ctx context.Context, arg FailScenarioStartupParams) error
+	GetActiveScenarioByCameraIDForUpdate(ctx context.Context, arg GetActiveScenarioByCameraIDForUpdateParams) (Scenario, error)
+	GetScenarioByUUID(ctx context.Context, uuid pgtype.UUID) (Scenario, error)
+	GetScenarioByUUIDForUpdate(ctx context.Context, uuid pgtype.UUID) (Scenario, error)
+	GetStuckStartupScenarios(ctx context.Context, arg GetStuckStartupScenariosParams) ([]Scenario, error)
+	ListScenarios(ctx context.Context, arg ListScenariosParams) ([]Scenario, error)
+	TransitionScenarioStatusBatch(ctx context.Context, arg TransitionScenarioStatusBatchParams) error
 	UpdateScenarioPredictByUUID(ctx context.Context, arg UpdateScenarioPredictByUUIDParams) error
 	UpdateScenarioStatusBatch(ctx context.Context, arg UpdateScenarioStatusBatchParams) error
 	UpdateScenarioStatusByUUID(ctx context.Context, arg UpdateScenarioStatusByUUIDParams) error
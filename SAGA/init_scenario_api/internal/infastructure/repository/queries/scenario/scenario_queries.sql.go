@@ -18,7 +18,7 @@ INSERT INTO scenario (
     url
 ) VALUES (
     $1, $2, $3
-) RETURNING uuid, camera_id, url, predict_id, status, created_at, updated_at
+) RETURNING uuid, camera_id, url, predict_id, status, created_at, updated_at, failure_reason
 `
 
 type CreateScenarioParams struct {
@@ -38,10 +38,218 @@ func (q *Queries) CreateScenario(ctx context.Context, arg CreateScenarioParams)
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.FailureReason,
 	)
 	return i, err
 }
 
+const failScenarioStartup = `-- name: FailScenarioStartup :exec
+UPDATE scenario
+SET status = 'start_failed',
+    failure_reason = $1,
+    updated_at = NOW()
+WHERE uuid = $2
+`
+
+type FailScenarioStartupParams struct {
+	FailureReason *string     `json:"failure_reason"`
+	Uuid          pgtype.UUID `json:"uuid"`
+}
+
+func (q *Queries) FailScenarioStartup(ctx context.Context, arg FailScenarioStartupParams) error {
+	_, err := q.db.Exec(ctx, failScenarioStartup, arg.FailureReason, arg.Uuid)
+	return err
+}
+
+const getActiveScenarioByCameraIDForUpdate = `-- name: GetActiveScenarioByCameraIDForUpdate :one
+SELECT uuid, camera_id, url, predict_id, status, created_at, updated_at, failure_reason FROM scenario
+WHERE camera_id = $1
+  AND status = ANY($2::text[])
+FOR UPDATE
+`
+
+type GetActiveScenarioByCameraIDForUpdateParams struct {
+	CameraID int32    `json:"camera_id"`
+	Statuses []string `json:"statuses"`
+}
+
+func (q *Queries) GetActiveScenarioByCameraIDForUpdate(ctx context.Context, arg GetActiveScenarioByCameraIDForUpdateParams) (Scenario, error) {
+	row := q.db.QueryRow(ctx, getActiveScenarioByCameraIDForUpdate, arg.CameraID, arg.Statuses)
+	var i Scenario
+	err := row.Scan(
+		&i.Uuid,
+		&i.CameraID,
+		&i.Url,
+		&i.PredictID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FailureReason,
+	)
+	return i, err
+}
+
+const getScenarioByUUID = `-- name: GetScenarioByUUID :one
+SELECT uuid, camera_id, url, predict_id, status, created_at, updated_at, failure_reason FROM scenario
+WHERE uuid = $1
+`
+
+func (q *Queries) GetScenarioByUUID(ctx context.Context, uuid pgtype.UUID) (Scenario, error) {
+	row := q.db.QueryRow(ctx, getScenarioByUUID, uuid)
+	var i Scenario
+	err := row.Scan(
+		&i.Uuid,
+		&i.CameraID,
+		&i.Url,
+		&i.PredictID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FailureReason,
+	)
+	return i, err
+}
+
+const getScenarioByUUIDForUpdate = `-- name: GetScenarioByUUIDForUpdate :one
+SELECT uuid, camera_id, url, predict_id, status, created_at, updated_at, failure_reason FROM scenario
+WHERE uuid = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetScenarioByUUIDForUpdate(ctx context.Context, uuid pgtype.UUID) (Scenario, error) {
+	row := q.db.QueryRow(ctx, getScenarioByUUIDForUpdate, uuid)
+	var i Scenario
+	err := row.Scan(
+		&i.Uuid,
+		&i.CameraID,
+		&i.Url,
+		&i.PredictID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FailureReason,
+	)
+	return i, err
+}
+
+const getStuckStartupScenarios = `-- name: GetStuckStartupScenarios :many
+SELECT uuid, camera_id, url, predict_id, status, created_at, updated_at, failure_reason FROM scenario
+WHERE status = ANY($1::text[])
+  AND COALESCE(updated_at, created_at) < NOW() - $2::integer * INTERVAL '1 second'
+ORDER BY COALESCE(updated_at, created_at)
+LIMIT $3
+FOR UPDATE SKIP LOCKED
+`
+
+type GetStuckStartupScenariosParams struct {
+	Statuses       []string `json:"statuses"`
+	TimeoutSeconds int32    `json:"timeout_seconds"`
+	BatchLimit     int32    `json:"batch_limit"`
+}
+
+func (q *Queries) GetStuckStartupScenarios(ctx context.Context, arg GetStuckStartupScenariosParams) ([]Scenario, error) {
+	rows, err := q.db.Query(ctx, getStuckStartupScenarios, arg.Statuses, arg.TimeoutSeconds, arg.BatchLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Scenario{}
+	for rows.Next() {
+		var i Scenario
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.CameraID,
+			&i.Url,
+			&i.PredictID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.FailureReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listScenarios = `-- name: ListScenarios :many
+SELECT uuid, camera_id, url, predict_id, status, created_at, updated_at, failure_reason FROM scenario
+WHERE ($1::integer IS NULL OR camera_id = $1::integer)
+  AND ($2::text IS NULL OR status = $2::text)
+  AND (
+    $3::timestamp IS NULL
+    OR (created_at, uuid) < ($3::timestamp, $4::uuid)
+  )
+ORDER BY created_at DESC, uuid DESC
+LIMIT $5
+`
+
+type ListScenariosParams struct {
+	CameraID        *int32           `json:"camera_id"`
+	Status          *string          `json:"status"`
+	CursorCreatedAt pgtype.Timestamp `json:"cursor_created_at"`
+	CursorUuid      pgtype.UUID      `json:"cursor_uuid"`
+	PageLimit       int32            `json:"page_limit"`
+}
+
+func (q *Queries) ListScenarios(ctx context.Context, arg ListScenariosParams) ([]Scenario, error) {
+	rows, err := q.db.Query(ctx, listScenarios,
+		arg.CameraID,
+		arg.Status,
+		arg.CursorCreatedAt,
+		arg.CursorUuid,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Scenario{}
+	for rows.Next() {
+		var i Scenario
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.CameraID,
+			&i.Url,
+			&i.PredictID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.FailureReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const transitionScenarioStatusBatch = `-- name: TransitionScenarioStatusBatch :exec
+UPDATE scenario
+SET status = $1,
+    updated_at = NOW()
+WHERE uuid = ANY($2::uuid[])
+  AND status = $3
+`
+
+type TransitionScenarioStatusBatchParams struct {
+	ToStatus   *string       `json:"to_status"`
+	Uuids      []pgtype.UUID `json:"uuids"`
+	FromStatus *string       `json:"from_status"`
+}
+
+func (q *Queries) TransitionScenarioStatusBatch(ctx context.Context, arg TransitionScenarioStatusBatchParams) error {
+	_, err := q.db.Exec(ctx, transitionScenarioStatusBatch, arg.ToStatus, arg.Uuids, arg.FromStatus)
+	return err
+}
+
 const updateScenarioPredictByUUID = `-- name: UpdateScenarioPredictByUUID :exec
 UPDATE scenario
 SET predict_id = $2,
@@ -2,28 +2,48 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"init_scenario_api/internal/infastructure/repository/queries/idempotency"
+	"init_scenario_api/internal/infastructure/repository/queries/inbox"
 	"init_scenario_api/internal/infastructure/repository/queries/outbox"
 	"init_scenario_api/internal/infastructure/repository/queries/scenario"
+	modelerror "init_scenario_api/internal/models/error"
+	genericOutbox "shared/outbox"
+)
+
+const (
+	// pgErrCodeUniqueViolation - нарушение уникального ограничения (duplicate key)
+	pgErrCodeUniqueViolation = "23505"
+	// pgErrCodeForeignKeyViolation - ссылка на несуществующую запись
+	pgErrCodeForeignKeyViolation = "23503"
 )
 
 type Repository struct {
-	dbPool          *pgxpool.Pool
-	scenarioQueries *scenario.Queries
-	outboxQueries   *outbox.Queries
+	dbPool             *pgxpool.Pool
+	scenarioQueries    *scenario.Queries
+	outboxQueries      *outbox.Queries
+	inboxQueries       *inbox.Queries
+	idempotencyQueries *idempotency.Queries
+	outboxStore        *genericOutbox.Store
 }
 
 func NewRepository(dbPool *pgxpool.Pool) *Repository {
-	return &Repository{
-		dbPool:          dbPool,
-		scenarioQueries: scenario.New(dbPool),
-		outboxQueries:   outbox.New(dbPool),
+	r := &Repository{
+		dbPool:             dbPool,
+		scenarioQueries:    scenario.New(dbPool),
+		outboxQueries:      outbox.New(dbPool),
+		inboxQueries:       inbox.New(dbPool),
+		idempotencyQueries: idempotency.New(dbPool),
 	}
+	r.outboxStore = genericOutbox.NewStore(r.conn)
+	return r
 }
 
 type txKey struct{}
@@ -39,6 +59,20 @@ func extractTx(ctx context.Context) pgx.Tx {
 	return nil
 }
 
+// conn возвращает транзакцию из ctx, если она есть, иначе пул
+func (r *Repository) conn(ctx context.Context) genericOutbox.DBTX {
+	if tx := extractTx(ctx); tx != nil {
+		return tx
+	}
+	return r.dbPool
+}
+
+// Outbox возвращает общий outbox для событий агрегатов. События, записанные через
+// Outbox().Publish внутри WithinTransaction, коммитятся вместе с транзакцией
+func (r *Repository) Outbox() *genericOutbox.Store {
+	return r.outboxStore
+}
+
 func (r *Repository) getScenarioQueries(ctx context.Context) scenario.Querier {
 	tx := extractTx(ctx)
 	if tx != nil {
@@ -55,8 +89,71 @@ func (r *Repository) getOutboxQueries(ctx context.Context) outbox.Querier {
 	return r.outboxQueries
 }
 
+func (r *Repository) getInboxQueries(ctx context.Context) inbox.Querier {
+	tx := extractTx(ctx)
+	if tx != nil {
+		return r.inboxQueries.WithTx(tx)
+	}
+	return r.inboxQueries
+}
+
+func (r *Repository) getIdempotencyQueries(ctx context.Context) idempotency.Querier {
+	tx := extractTx(ctx)
+	if tx != nil {
+		return r.idempotencyQueries.WithTx(tx)
+	}
+	return r.idempotencyQueries
+}
+
+// CreateScenario создает сценарий. Если на камере уже есть активный сценарий
+// (scenario_camera_id_active_uidx), возвращает ErrDuplicateKey
 func (r *Repository) CreateScenario(ctx context.Context, arg scenario.CreateScenarioParams) (scenario.Scenario, error) {
-	return r.getScenarioQueries(ctx).CreateScenario(ctx, arg)
+	result, err := r.getScenarioQueries(ctx).CreateScenario(ctx, arg)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgErrCodeUniqueViolation {
+			return result, modelerror.ErrDuplicateKey
+		}
+		return result, err
+	}
+	return result, nil
+}
+
+func (r *Repository) GetActiveScenarioByCameraIDForUpdate(ctx context.Context, arg scenario.GetActiveScenarioByCameraIDForUpdateParams) (scenario.Scenario, error) {
+	result, err := r.getScenarioQueries(ctx).GetActiveScenarioByCameraIDForUpdate(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, modelerror.ErrNotFound
+		}
+		return result, err
+	}
+	return result, nil
+}
+
+func (r *Repository) GetScenarioByUUID(ctx context.Context, uuid pgtype.UUID) (scenario.Scenario, error) {
+	result, err := r.getScenarioQueries(ctx).GetScenarioByUUID(ctx, uuid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, modelerror.ErrNotFound
+		}
+		return result, err
+	}
+	return result, nil
+}
+
+func (r *Repository) ListScenarios(ctx context.Context, arg scenario.ListScenariosParams) ([]scenario.Scenario, error) {
+	return r.getScenarioQueries(ctx).ListScenarios(ctx, arg)
+}
+
+func (r *Repository) GetScenarioByUUIDForUpdate(ctx context.Context, uuid pgtype.UUID) (scenario.Scenario, error) {
+	result, err := r.getScenarioQueries(ctx).GetScenarioByUUIDForUpdate(ctx, uuid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, modelerror.ErrNotFound
+		}
+		return result, err
+	}
+	return result, nil
 }
 
 func (r *Repository) UpdateScenarioStatusByUUID(ctx context.Context, arg scenario.UpdateScenarioStatusByUUIDParams) error {
@@ -67,6 +164,18 @@ func (r *Repository) UpdateScenarioStatusBatch(ctx context.Context, arg scenario
 	return r.getScenarioQueries(ctx).UpdateScenarioStatusBatch(ctx, arg)
 }
 
+func (r *Repository) TransitionScenarioStatusBatch(ctx context.Context, arg scenario.TransitionScenarioStatusBatchParams) error {
+	return r.getScenarioQueries(ctx).TransitionScenarioStatusBatch(ctx, arg)
+}
+
+func (r *Repository) FailScenarioStartup(ctx context.Context, arg scenario.FailScenarioStartupParams) error {
+	return r.getScenarioQueries(ctx).FailScenarioStartup(ctx, arg)
+}
+
+func (r *Repository) GetStuckStartupScenarios(ctx context.Context, arg scenario.GetStuckStartupScenariosParams) ([]scenario.Scenario, error) {
+	return r.getScenarioQueries(ctx).GetStuckStartupScenarios(ctx, arg)
+}
+
 func (r *Repository) UpdateScenarioPredictByUUID(ctx context.Context, arg scenario.UpdateScenarioPredictByUUIDParams) error {
 	return r.getScenarioQueries(ctx).UpdateScenarioPredictByUUID(ctx, arg)
 }
@@ -95,6 +204,70 @@ func (r *Repository) MarkOutboxScenariosAsSentBatch(ctx context.Context, outboxU
 	return r.getOutboxQueries(ctx).MarkOutboxScenariosAsSentBatch(ctx, outboxUUIDs)
 }
 
+func (r *Repository) RescheduleOutboxScenariosBatch(ctx context.Context, arg outbox.RescheduleOutboxScenariosBatchParams) ([]outbox.RescheduleOutboxScenariosBatchRow, error) {
+	return r.getOutboxQueries(ctx).RescheduleOutboxScenariosBatch(ctx, arg)
+}
+
+func (r *Repository) ListFailedOutboxScenarios(ctx context.Context, arg outbox.ListFailedOutboxScenariosParams) ([]outbox.OutboxScenario, error) {
+	return r.getOutboxQueries(ctx).ListFailedOutboxScenarios(ctx, arg)
+}
+
+func (r *Repository) RequeueFailedOutboxScenarios(ctx context.Context, outboxUUIDs []pgtype.UUID) ([]pgtype.UUID, error) {
+	return r.getOutboxQueries(ctx).RequeueFailedOutboxScenarios(ctx, outboxUUIDs)
+}
+
+func (r *Repository) PurgeSentOutboxScenarios(ctx context.Context, arg outbox.PurgeSentOutboxScenariosParams) ([]outbox.OutboxScenario, error) {
+	return r.getOutboxQueries(ctx).PurgeSentOutboxScenarios(ctx, arg)
+}
+
+func (r *Repository) PurgeInboxScenarioResults(ctx context.Context, arg inbox.PurgeInboxScenarioResultsParams) ([]inbox.InboxScenarioResult, error) {
+	return r.getInboxQueries(ctx).PurgeInboxScenarioResults(ctx, arg)
+}
+
+// CreateInboxScenarioResult сохраняет результат в inbox. Для уже сохраненного сообщения возвращает
+// ErrDuplicateKey, для результата несуществующего сценария - ErrNotFound
+func (r *Repository) CreateInboxScenarioResult(ctx context.Context, arg inbox.CreateInboxScenarioResultParams) (inbox.InboxScenarioResult, error) {
+	result, err := r.getInboxQueries(ctx).CreateInboxScenarioResult(ctx, arg)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgErrCodeUniqueViolation {
+			return result, modelerror.ErrDuplicateKey
+		}
+		if errors.As(err, &pgErr) && pgErr.Code == pgErrCodeForeignKeyViolation {
+			return result, modelerror.ErrNotFound
+		}
+		return result, err
+	}
+	return result, nil
+}
+
+// CreateIdempotencyKey занимает ключ идемпотентности. Если ключ уже занят, возвращает ErrDuplicateKey
+func (r *Repository) CreateIdempotencyKey(ctx context.Context, arg idempotency.CreateIdempotencyKeyParams) (idempotency.IdempotencyKey, error) {
+	result, err := r.getIdempotencyQueries(ctx).CreateIdempotencyKey(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, modelerror.ErrDuplicateKey
+		}
+		return result, err
+	}
+	return result, nil
+}
+
+func (r *Repository) GetIdempotencyKey(ctx context.Context, key string) (idempotency.IdempotencyKey, error) {
+	result, err := r.getIdempotencyQueries(ctx).GetIdempotencyKey(ctx, key)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, modelerror.ErrNotFound
+		}
+		return result, err
+	}
+	return result, nil
+}
+
+func (r *Repository) SetIdempotencyKeyResponse(ctx context.Context, arg idempotency.SetIdempotencyKeyResponseParams) error {
+	return r.getIdempotencyQueries(ctx).SetIdempotencyKeyResponse(ctx, arg)
+}
+
 func (r *Repository) WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error {
 	tx := extractTx(ctx)
 	if tx != nil {
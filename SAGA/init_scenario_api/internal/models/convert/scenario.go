@@ -13,6 +13,7 @@ import (
 func ScenarioFromDB(dbScenario scenario.Scenario) *entity.Scenario {
 	result := &entity.Scenario{
 		CameraID: dbScenario.CameraID,
+		URL:      dbScenario.Url,
 	}
 
 	if dbScenario.Uuid.Valid {
@@ -35,6 +36,8 @@ func ScenarioFromDB(dbScenario scenario.Scenario) *entity.Scenario {
 		result.UpdatedAt = &dbScenario.UpdatedAt.Time
 	}
 
+	result.FailureReason = dbScenario.FailureReason
+
 	return result
 }
 
@@ -49,6 +52,8 @@ func OutboxScenarioFromDB(dbOutbox outbox.OutboxScenario) *entity.OutboxScenario
 		result.ScenarioUUID = uuid.UUID(dbOutbox.ScenarioUuid.Bytes)
 	}
 
+	result.EventType = dbOutbox.EventType
+
 	if len(dbOutbox.Payload) > 0 {
 		var payload map[string]interface{}
 		if err := json.Unmarshal(dbOutbox.Payload, &payload); err == nil {
@@ -68,6 +73,14 @@ func OutboxScenarioFromDB(dbOutbox outbox.OutboxScenario) *entity.OutboxScenario
 		result.UpdatedAt = &dbOutbox.UpdatedAt.Time
 	}
 
+	if dbOutbox.LockedUntil.Valid {
+		result.LockedUntil = &dbOutbox.LockedUntil.Time
+	}
+
+	result.Attempts = dbOutbox.Attempts
+	result.LastError = dbOutbox.LastError
+	result.PartitionKey = dbOutbox.PartitionKey
+
 	return result
 }
 
@@ -77,3 +90,28 @@ func ScenarioToDTO(scenario *entity.Scenario) *dto.InitScenarioResponse {
 		Status:       scenario.Status,
 	}
 }
+
+func ScenarioToDetailsDTO(scenario *entity.Scenario) dto.ScenarioResponse {
+	return dto.ScenarioResponse{
+		ScenarioUUID:  scenario.UUID.String(),
+		CameraID:      scenario.CameraID,
+		URL:           scenario.URL,
+		PredictID:     scenario.PredictID,
+		Status:        scenario.Status,
+		CreatedAt:     scenario.CreatedAt,
+		UpdatedAt:     scenario.UpdatedAt,
+		FailureReason: scenario.FailureReason,
+	}
+}
+
+func OutboxScenarioToDTO(outboxScenario *entity.OutboxScenario) dto.FailedOutboxScenarioResponse {
+	return dto.FailedOutboxScenarioResponse{
+		OutboxUUID:   outboxScenario.OutboxUUID.String(),
+		ScenarioUUID: outboxScenario.ScenarioUUID.String(),
+		EventType:    outboxScenario.EventType,
+		Attempts:     outboxScenario.Attempts,
+		LastError:    outboxScenario.LastError,
+		CreatedAt:    outboxScenario.CreatedAt,
+		UpdatedAt:    outboxScenario.UpdatedAt,
+	}
+}
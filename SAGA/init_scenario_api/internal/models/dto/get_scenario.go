@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+// ScenarioResponse представляет сценарий в ответах read API
+type ScenarioResponse struct {
+	ScenarioUUID  string     `json:"scenario_uuid"`
+	CameraID      int32      `json:"camera_id"`
+	URL           string     `json:"url"`
+	PredictID     int32      `json:"predict_id"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+	FailureReason *string    `json:"failure_reason,omitempty"`
+}
+
+// ListScenariosRequest представляет фильтры и параметры пагинации списка сценариев
+type ListScenariosRequest struct {
+	CameraID *int32  `query:"camera_id" validate:"omitempty,gt=0"`
+	Status   *string `query:"status" validate:"omitempty,scenario_status"`
+	Cursor   string  `query:"cursor"`
+	Limit    int32   `query:"limit" validate:"gte=0"`
+}
+
+// ListScenariosResponse представляет страницу списка сценариев
+type ListScenariosResponse struct {
+	Items      []ScenarioResponse `json:"items"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
@@ -1,19 +1,11 @@
 package dto
 
-import (
-	"encoding/json"
-	"io"
-)
-
 // InitScenarioRequest представляет запрос на создание нового сценария
 type InitScenarioRequest struct {
 	CameraID int32  `json:"camera_id" validate:"required,gt=0"`
-	URL      string `json:"url" validate:"required"`
-}
-
-// Decode декодирует JSON из reader в структуру
-func (r *InitScenarioRequest) Decode(reader io.Reader) error {
-	return json.NewDecoder(reader).Decode(r)
+	URL      string `json:"url" validate:"required,max=2048,stream_url"`
+	// Replace останавливает активный сценарий камеры перед созданием нового (query параметр replace=true)
+	Replace bool `json:"-" query:"replace"`
 }
 
 // InitScenarioResponse представляет ответ после создания сценария
@@ -22,6 +14,13 @@ type InitScenarioResponse struct {
 	Status       string `json:"status"`
 }
 
+// InitScenarioConflictResponse представляет ответ 409, когда на камере уже есть активный сценарий
+type InitScenarioConflictResponse struct {
+	Error        string `json:"error"`
+	Message      string `json:"message,omitempty"`
+	ScenarioUUID string `json:"scenario_uuid,omitempty"`
+}
+
 // UpdateScenarioStatusRequest представляет запрос на обновление статуса сценария
 type UpdateScenarioStatusRequest struct {
 	Status string `json:"status" validate:"required"`
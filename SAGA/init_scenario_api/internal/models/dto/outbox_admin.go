@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+// FailedOutboxScenarioResponse представляет outbox запись, исчерпавшую попытки публикации
+type FailedOutboxScenarioResponse struct {
+	OutboxUUID   string     `json:"outbox_uuid"`
+	ScenarioUUID string     `json:"scenario_uuid"`
+	EventType    string     `json:"event_type"`
+	Attempts     int32      `json:"attempts"`
+	LastError    *string    `json:"last_error,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}
+
+// ListFailedOutboxRequest представляет параметры пагинации списка failed outbox записей
+type ListFailedOutboxRequest struct {
+	Limit  int32 `query:"limit" validate:"gte=0"`
+	Offset int32 `query:"offset" validate:"gte=0"`
+}
+
+// ListFailedOutboxResponse представляет страницу failed outbox записей
+type ListFailedOutboxResponse struct {
+	Items []FailedOutboxScenarioResponse `json:"items"`
+}
+
+// RequeueOutboxRequest представляет запрос на повторную публикацию failed outbox записей
+type RequeueOutboxRequest struct {
+	OutboxUUIDs []string `json:"outbox_uuids" validate:"required,min=1,max=1000,dive,uuid"`
+}
+
+// RequeueOutboxResponse содержит записи, возвращенные в очередь. Записи не в статусе failed пропускаются
+type RequeueOutboxResponse struct {
+	OutboxUUIDs []string `json:"outbox_uuids"`
+}
@@ -0,0 +1,7 @@
+package dto
+
+// StopScenarioResponse представляет ответ после запроса на остановку сценария
+type StopScenarioResponse struct {
+	ScenarioUUID string `json:"scenario_uuid"`
+	Status       string `json:"status"`
+}
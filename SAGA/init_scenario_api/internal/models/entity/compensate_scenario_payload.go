@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CompensateScenarioPayload представляет payload компенсирующего события для сценария, запуск которого не удался
+type CompensateScenarioPayload struct {
+	ScenarioUUID pgtype.UUID `json:"scenario_uuid"`
+	CameraID     int32       `json:"camera_id"`
+	Reason       string      `json:"reason"`
+}
+
+// NewCompensateScenarioPayload создает новый payload компенсирующего события
+func NewCompensateScenarioPayload(scenarioUUID pgtype.UUID, cameraID int32, reason string) *CompensateScenarioPayload {
+	return &CompensateScenarioPayload{
+		ScenarioUUID: scenarioUUID,
+		CameraID:     cameraID,
+		Reason:       reason,
+	}
+}
@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,11 +11,22 @@ import (
 type OutboxScenario struct {
 	OutboxUUID   uuid.UUID              `json:"outbox_uuid" db:"outbox_uuid"`
 	ScenarioUUID uuid.UUID              `json:"scenario_uuid" db:"scenario_uuid"`
+	EventType    string                 `json:"event_type" db:"event_type"`
 	Payload      map[string]interface{} `json:"payload" db:"payload"`
 	State        string                 `json:"state" db:"state"`
 	CreatedAt    time.Time              `json:"created_at" db:"created_at"`
 	UpdatedAt    *time.Time             `json:"updated_at,omitempty" db:"updated_at"`
 	LockedUntil  *time.Time             `json:"locked_until,omitempty" db:"locked_until"`
+	Attempts     int32                  `json:"attempts" db:"attempts"`
+	LastError    *string                `json:"last_error,omitempty" db:"last_error"`
+	PartitionKey string                 `json:"partition_key" db:"partition_key"`
+}
+
+// OutboxPartitionKey возвращает ключ Kafka сообщения для событий сценария. Ключом служит камера:
+// все события одной камеры, в том числе stop старого и init нового сценария в режиме replace,
+// попадают в одну партицию и публикуются по порядку
+func OutboxPartitionKey(cameraID int32) string {
+	return strconv.FormatInt(int64(cameraID), 10)
 }
 
 // OutboxState представляет возможные состояния outbox сообщения
@@ -23,3 +35,15 @@ const (
 	OutboxStateSent    = "sent"
 	OutboxStateFailed  = "failed"
 )
+
+// OutboxScenarioNotifyChannel - канал Postgres, в который триггер на outbox_scenario шлет NOTIFY
+// при вставке записей
+const OutboxScenarioNotifyChannel = "outbox_scenario"
+
+// OutboxEventType представляет возможные типы событий outbox
+const (
+	OutboxEventInitScenario = "init_scenario"
+	OutboxEventStopScenario = "stop_scenario"
+	// OutboxEventCompensateScenario освобождает ресурсы runner'а для сценария, запуск которого не удался
+	OutboxEventCompensateScenario = "compensate_scenario"
+)
@@ -8,12 +8,14 @@ import (
 
 // Scenario представляет сущность сценария из таблицы scenario
 type Scenario struct {
-	UUID      uuid.UUID  `json:"uuid" db:"uuid"`
-	CameraID  int32      `json:"camera_id" db:"camera_id"`
-	PredictID int32      `json:"predict_id" db:"predict_id"`
-	Status    string     `json:"status" db:"status"`
-	CreatedAt time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+	UUID          uuid.UUID  `json:"uuid" db:"uuid"`
+	CameraID      int32      `json:"camera_id" db:"camera_id"`
+	URL           string     `json:"url" db:"url"`
+	PredictID     int32      `json:"predict_id" db:"predict_id"`
+	Status        string     `json:"status" db:"status"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+	FailureReason *string    `json:"failure_reason,omitempty" db:"failure_reason"`
 }
 
 // ScenarioStatus представляет возможные статусы сценария
@@ -21,7 +23,71 @@ const (
 	StatusInitStartup          = "init_startup"
 	StatusInStartupProcessing  = "in_startup_processing"
 	StatusActive               = "active"
+	StatusStartFailed          = "start_failed"
 	StatusInitShutdown         = "init_shutdown"
 	StatusInShutdownProcessing = "in_shutdown_processing"
 	StatusInactive             = "inactive"
 )
+
+// ScenarioStatuses содержит все допустимые статусы сценария
+var ScenarioStatuses = []string{
+	StatusInitStartup,
+	StatusInStartupProcessing,
+	StatusActive,
+	StatusStartFailed,
+	StatusInitShutdown,
+	StatusInShutdownProcessing,
+	StatusInactive,
+}
+
+// StartupStatuses содержит статусы сценария, в которых runner еще не подтвердил запуск
+var StartupStatuses = []string{
+	StatusInitStartup,
+	StatusInStartupProcessing,
+}
+
+// ShutdownStatuses содержит статусы сценария, в которых runner еще не подтвердил остановку
+var ShutdownStatuses = []string{
+	StatusInitShutdown,
+	StatusInShutdownProcessing,
+}
+
+// ActiveStatuses содержит статусы сценария, в которых он занимает камеру. На камеру допускается
+// не больше одного сценария в этих статусах (уникальный индекс scenario_camera_id_active_uidx).
+// Статусы остановки камеру не занимают: новый запуск не ждет подтверждения остановки, а воркер
+// останавливаемого сценария заменяется или отзывается по fencing token'у следующего размещения камеры
+var ActiveStatuses = []string{
+	StatusInitStartup,
+	StatusInStartupProcessing,
+	StatusActive,
+}
+
+// IsStartupStatus проверяет, что сценарий находится в процессе запуска
+func IsStartupStatus(status string) bool {
+	for _, s := range StartupStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsShutdownStatus проверяет, что сценарий находится в процессе остановки
+func IsShutdownStatus(status string) bool {
+	for _, s := range ShutdownStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidScenarioStatus проверяет, что статус является одним из допустимых
+func IsValidScenarioStatus(status string) bool {
+	for _, s := range ScenarioStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
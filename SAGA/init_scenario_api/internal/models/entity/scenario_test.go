@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"slices"
+	"testing"
+)
+
+// Статусы в ActiveStatuses совпадают с условием уникального индекса scenario_camera_id_active_uidx:
+// при изменении одного нужно менять и другое, иначе use case пропустит конфликт, который отклонит
+// база, или наоборот
+func TestActiveStatuses(t *testing.T) {
+	want := []string{StatusInitStartup, StatusInStartupProcessing, StatusActive}
+	if !slices.Equal(ActiveStatuses, want) {
+		t.Fatalf("ActiveStatuses are %v, the camera unique index covers %v", ActiveStatuses, want)
+	}
+
+	for _, status := range []string{StatusStartFailed, StatusInitShutdown, StatusInShutdownProcessing, StatusInactive} {
+		if slices.Contains(ActiveStatuses, status) {
+			t.Fatalf("status %s must not hold the camera", status)
+		}
+	}
+	for _, status := range ActiveStatuses {
+		if !IsValidScenarioStatus(status) {
+			t.Fatalf("unknown scenario status %s", status)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// StopScenarioPayload представляет payload для остановки сценария в outbox
+type StopScenarioPayload struct {
+	ScenarioUUID pgtype.UUID `json:"scenario_uuid"`
+	CameraID     int32       `json:"camera_id"`
+}
+
+// NewStopScenarioPayload создает новый payload для остановки сценария
+func NewStopScenarioPayload(scenarioUUID pgtype.UUID, cameraID int32) *StopScenarioPayload {
+	return &StopScenarioPayload{
+		ScenarioUUID: scenarioUUID,
+		CameraID:     cameraID,
+	}
+}
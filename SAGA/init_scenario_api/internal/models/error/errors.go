@@ -0,0 +1,44 @@
+package error
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound возвращается когда запись не найдена в БД
+	ErrNotFound = errors.New("record not found")
+
+	// ErrInvalidCursor возвращается когда курсор пагинации не удалось разобрать
+	ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+	// ErrDuplicateKey возвращается когда происходит нарушение уникального ключа (duplicate key)
+	// Это нормальная ситуация при повторной обработке сообщения (идемпотентность)
+	ErrDuplicateKey = errors.New("duplicate key: record already exists")
+
+	// ErrScenarioNotStoppable возвращается когда сценарий находится в статусе, из которого его нельзя остановить
+	ErrScenarioNotStoppable = errors.New("scenario cannot be stopped in current status")
+
+	// ErrIdempotencyKeyReused возвращается когда ключ идемпотентности повторно используется с другим телом запроса
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with different request body")
+
+	// ErrScenarioAlreadyExists возвращается когда на камере уже есть запускаемый или активный сценарий
+	ErrScenarioAlreadyExists = errors.New("camera already has an active scenario")
+
+	// ErrInvalidMessage возвращается когда сообщение из Kafka не проходит проверку. Повтор такого
+	// сообщения ничего не изменит, поэтому оно сразу отправляется в топик недоставленных сообщений
+	ErrInvalidMessage = errors.New("invalid message")
+)
+
+// ScenarioConflictError - ErrScenarioAlreadyExists с UUID сценария, который занимает камеру
+type ScenarioConflictError struct {
+	ScenarioUUID string
+}
+
+func (e *ScenarioConflictError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrScenarioAlreadyExists, e.ScenarioUUID)
+}
+
+func (e *ScenarioConflictError) Unwrap() error {
+	return ErrScenarioAlreadyExists
+}
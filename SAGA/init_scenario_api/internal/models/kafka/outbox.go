@@ -1,3 +1,26 @@
 package kafka
 
 var OutboxScenarioTopic = "outbox_scenario_api"
+
+// Заголовки сообщений outbox
+var (
+	OutboxUUIDHeader = "outbox_uuid"
+	EventTypeHeader  = "event_type"
+)
+
+// Заголовки сообщений в топике недоставленных сообщений
+var (
+	DeadLetterErrorHeader         = "dead_letter_error"
+	DeadLetterOriginalTopicHeader = "dead_letter_original_topic"
+	DeadLetterFailedAtHeader      = "dead_letter_failed_at"
+)
+
+// ScenarioResultTopic - топик с результатами запуска и остановки сценариев от runner_scheduler
+var ScenarioResultTopic = "outbox_runner_scheduler"
+
+// Типы событий с результатом запуска и остановки сценария
+var (
+	EventTypeScenarioStarted     = "scenario_started"
+	EventTypeScenarioStartFailed = "scenario_start_failed"
+	EventTypeScenarioStopped     = "scenario_stopped"
+)
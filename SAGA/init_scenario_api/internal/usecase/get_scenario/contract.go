@@ -0,0 +1,13 @@
+package get_scenario
+
+import (
+	"context"
+	"init_scenario_api/internal/infastructure/repository/queries/scenario"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Repository interface {
+	GetScenarioByUUID(ctx context.Context, uuid pgtype.UUID) (scenario.Scenario, error)
+	ListScenarios(ctx context.Context, arg scenario.ListScenariosParams) ([]scenario.Scenario, error)
+}
@@ -0,0 +1,44 @@
+package get_scenario
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// cursor указывает на последний сценарий предыдущей страницы.
+// Сортировка списка идет по (created_at, uuid) по убыванию, поэтому пара однозначно задает позицию.
+type cursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	UUID      uuid.UUID `json:"uuid"`
+}
+
+func encodeCursor(c cursor) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("marshal cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("decode cursor: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("unmarshal cursor: %w", err)
+	}
+
+	if c.CreatedAt.IsZero() || c.UUID == uuid.Nil {
+		return c, fmt.Errorf("cursor is incomplete")
+	}
+
+	return c, nil
+}
@@ -0,0 +1,41 @@
+package get_scenario
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	original := cursor{
+		CreatedAt: time.Date(2025, 11, 26, 12, 30, 45, 123456000, time.UTC),
+		UUID:      uuid.New(),
+	}
+
+	encoded, err := encodeCursor(original)
+	if err != nil {
+		t.Fatalf("failed to encode cursor: %v", err)
+	}
+
+	decoded, err := decodeCursor(encoded)
+	if err != nil {
+		t.Fatalf("failed to decode cursor: %v", err)
+	}
+
+	if !original.CreatedAt.Equal(decoded.CreatedAt) {
+		t.Fatalf("expected created_at %v, got %v", original.CreatedAt, decoded.CreatedAt)
+	}
+	if original.UUID != decoded.UUID {
+		t.Fatalf("expected uuid %s, got %s", original.UUID, decoded.UUID)
+	}
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	// не base64, пустой объект, null
+	for _, raw := range []string{"not-base64!", "e30", "bnVsbA"} {
+		if _, err := decodeCursor(raw); err == nil {
+			t.Fatalf("expected error for cursor %q", raw)
+		}
+	}
+}
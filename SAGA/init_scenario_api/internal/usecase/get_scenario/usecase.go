@@ -0,0 +1,105 @@
+package get_scenario
+
+import (
+	"context"
+	"fmt"
+	"init_scenario_api/internal/infastructure/repository/queries/scenario"
+	"init_scenario_api/internal/models/convert"
+	"init_scenario_api/internal/models/dto"
+	modelerror "init_scenario_api/internal/models/error"
+	"init_scenario_api/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+type UseCase struct {
+	repo Repository
+}
+
+func NewUseCase(repo Repository) *UseCase {
+	return &UseCase{
+		repo: repo,
+	}
+}
+
+// GetScenario возвращает сценарий по UUID
+func (uc *UseCase) GetScenario(ctx context.Context, scenarioUUID uuid.UUID) (*dto.ScenarioResponse, error) {
+	log := logger.FromContext(ctx)
+
+	scenarioDB, err := uc.repo.GetScenarioByUUID(ctx, pgtype.UUID{Bytes: scenarioUUID, Valid: true})
+	if err != nil {
+		log.Info("failed to get scenario", zap.String("scenario_uuid", scenarioUUID.String()), zap.Error(err))
+		return nil, fmt.Errorf("get scenario: %w", err)
+	}
+
+	result := convert.ScenarioToDetailsDTO(convert.ScenarioFromDB(scenarioDB))
+	return &result, nil
+}
+
+// ListScenarios возвращает страницу сценариев, отсортированных от новых к старым.
+// Пагинация keyset по (created_at, uuid): next_cursor указывает на последний элемент страницы.
+func (uc *UseCase) ListScenarios(ctx context.Context, input dto.ListScenariosRequest) (*dto.ListScenariosResponse, error) {
+	log := logger.FromContext(ctx)
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	if limit > MaxListLimit {
+		limit = MaxListLimit
+	}
+
+	params := scenario.ListScenariosParams{
+		CameraID:  input.CameraID,
+		Status:    input.Status,
+		PageLimit: limit + 1,
+	}
+
+	if input.Cursor != "" {
+		c, err := decodeCursor(input.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", modelerror.ErrInvalidCursor, err)
+		}
+		params.CursorCreatedAt = pgtype.Timestamp{Time: c.CreatedAt, Valid: true}
+		params.CursorUuid = pgtype.UUID{Bytes: c.UUID, Valid: true}
+	}
+
+	scenariosDB, err := uc.repo.ListScenarios(ctx, params)
+	if err != nil {
+		log.Error("failed to list scenarios", zap.Error(err))
+		return nil, fmt.Errorf("list scenarios: %w", err)
+	}
+
+	hasMore := len(scenariosDB) > int(limit)
+	if hasMore {
+		scenariosDB = scenariosDB[:limit]
+	}
+
+	result := &dto.ListScenariosResponse{
+		Items: make([]dto.ScenarioResponse, 0, len(scenariosDB)),
+	}
+	for _, scenarioDB := range scenariosDB {
+		result.Items = append(result.Items, convert.ScenarioToDetailsDTO(convert.ScenarioFromDB(scenarioDB)))
+	}
+
+	if hasMore {
+		last := scenariosDB[len(scenariosDB)-1]
+		nextCursor, err := encodeCursor(cursor{
+			CreatedAt: last.CreatedAt.Time,
+			UUID:      uuid.UUID(last.Uuid.Bytes),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("encode cursor: %w", err)
+		}
+		result.NextCursor = nextCursor
+	}
+
+	return result, nil
+}
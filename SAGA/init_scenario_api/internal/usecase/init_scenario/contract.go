@@ -2,12 +2,25 @@ package init_scenario
 
 import (
 	"context"
+	"init_scenario_api/internal/infastructure/repository/queries/idempotency"
 	"init_scenario_api/internal/infastructure/repository/queries/outbox"
 	"init_scenario_api/internal/infastructure/repository/queries/scenario"
+	"init_scenario_api/internal/models/dto"
+
+	"github.com/google/uuid"
 )
 
 type Repository interface {
 	CreateScenario(ctx context.Context, arg scenario.CreateScenarioParams) (scenario.Scenario, error)
+	GetActiveScenarioByCameraIDForUpdate(ctx context.Context, arg scenario.GetActiveScenarioByCameraIDForUpdateParams) (scenario.Scenario, error)
 	CreateOutboxScenario(ctx context.Context, arg outbox.CreateOutboxScenarioParams) (outbox.OutboxScenario, error)
+	CreateIdempotencyKey(ctx context.Context, arg idempotency.CreateIdempotencyKeyParams) (idempotency.IdempotencyKey, error)
+	GetIdempotencyKey(ctx context.Context, key string) (idempotency.IdempotencyKey, error)
+	SetIdempotencyKeyResponse(ctx context.Context, arg idempotency.SetIdempotencyKeyResponseParams) error
 	WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error
 }
+
+// ScenarioStopper останавливает сценарий, занимающий камеру, в режиме replace
+type ScenarioStopper interface {
+	StopScenario(ctx context.Context, scenarioUUID uuid.UUID) (*dto.StopScenarioResponse, error)
+}
@@ -2,13 +2,18 @@ package init_scenario
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"init_scenario_api/internal/infastructure/repository/queries/idempotency"
 	"init_scenario_api/internal/infastructure/repository/queries/outbox"
 	"init_scenario_api/internal/infastructure/repository/queries/scenario"
 	"init_scenario_api/internal/models/convert"
 	"init_scenario_api/internal/models/dto"
 	"init_scenario_api/internal/models/entity"
+	modelerror "init_scenario_api/internal/models/error"
 	"init_scenario_api/pkg/logger"
 
 	"github.com/google/uuid"
@@ -17,16 +22,25 @@ import (
 )
 
 type UseCase struct {
-	repo Repository
+	repo    Repository
+	stopper ScenarioStopper
 }
 
-func NewUseCase(repo Repository) *UseCase {
+func NewUseCase(repo Repository, stopper ScenarioStopper) *UseCase {
 	return &UseCase{
-		repo: repo,
+		repo:    repo,
+		stopper: stopper,
 	}
 }
 
-func (uc *UseCase) InitScenario(ctx context.Context, input dto.InitScenarioRequest) (*dto.InitScenarioResponse, error) {
+// InitScenario создает сценарий и outbox событие init_scenario. Если передан idempotencyKey,
+// ключ, хэш запроса и ответ сохраняются в той же транзакции: повторный запрос с тем же ключом
+// и телом получает сохраненный ответ без создания нового сценария, а с другим телом -
+// ErrIdempotencyKeyReused.
+//
+// На камере допускается один запускаемый или активный сценарий: если он уже есть, возвращается
+// ScenarioConflictError с его UUID, а в режиме input.Replace он останавливается в той же транзакции.
+func (uc *UseCase) InitScenario(ctx context.Context, input dto.InitScenarioRequest, idempotencyKey string) (*dto.InitScenarioResponse, error) {
 	log := logger.FromContext(ctx)
 
 	log.Info("starting purchase",
@@ -36,6 +50,24 @@ func (uc *UseCase) InitScenario(ctx context.Context, input dto.InitScenarioReque
 
 	var result *dto.InitScenarioResponse
 	err := uc.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+		if idempotencyKey != "" {
+			replayed, err := uc.claimIdempotencyKey(txCtx, idempotencyKey, input)
+			if err != nil {
+				return err
+			}
+			if replayed != nil {
+				log.Info("idempotent request replayed",
+					zap.String("idempotency_key", idempotencyKey),
+					zap.String("scenario_uuid", replayed.ScenarioUUID),
+				)
+				result = replayed
+				return nil
+			}
+		}
+
+		if err := uc.releaseCamera(txCtx, input); err != nil {
+			return err
+		}
 
 		createdScenarioDB, err := uc.repo.CreateScenario(txCtx, scenario.CreateScenarioParams{
 			Uuid:     uuidToUUIDV7(uuid.New()),
@@ -43,6 +75,10 @@ func (uc *UseCase) InitScenario(ctx context.Context, input dto.InitScenarioReque
 			Url:      input.URL,
 		})
 		if err != nil {
+			// Конкурентный запрос создал сценарий для камеры после проверки в releaseCamera
+			if errors.Is(err, modelerror.ErrDuplicateKey) {
+				return modelerror.ErrScenarioAlreadyExists
+			}
 			log.Error("failed to create scenario", zap.Error(err))
 			return fmt.Errorf("create scenario: %w", err)
 		}
@@ -60,6 +96,8 @@ func (uc *UseCase) InitScenario(ctx context.Context, input dto.InitScenarioReque
 			OutboxUuid:   uuidToUUIDV7(uuid.New()),
 			ScenarioUuid: createdScenarioDB.Uuid,
 			Payload:      payloadBytes,
+			EventType:    entity.OutboxEventInitScenario,
+			PartitionKey: entity.OutboxPartitionKey(createdScenarioDB.CameraID),
 		})
 
 		if err != nil {
@@ -74,10 +112,29 @@ func (uc *UseCase) InitScenario(ctx context.Context, input dto.InitScenarioReque
 
 		result = convert.ScenarioToDTO(scenarioEntity)
 
+		if idempotencyKey != "" {
+			responseBytes, err := json.Marshal(result)
+			if err != nil {
+				return fmt.Errorf("marshal idempotent response: %w", err)
+			}
+			if err := uc.repo.SetIdempotencyKeyResponse(txCtx, idempotency.SetIdempotencyKeyResponseParams{
+				Key:      idempotencyKey,
+				Response: responseBytes,
+			}); err != nil {
+				return fmt.Errorf("save idempotent response: %w", err)
+			}
+		}
+
 		return nil
 	})
 
 	if err != nil {
+		if errors.Is(err, modelerror.ErrIdempotencyKeyReused) {
+			return nil, err
+		}
+		if errors.Is(err, modelerror.ErrScenarioAlreadyExists) {
+			return nil, uc.scenarioConflict(ctx, input.CameraID)
+		}
 		log.Error("transaction failed", zap.Error(err))
 		return nil, fmt.Errorf("transaction failed: %w", err)
 	}
@@ -86,6 +143,109 @@ func (uc *UseCase) InitScenario(ctx context.Context, input dto.InitScenarioReque
 	return result, nil
 }
 
+// releaseCamera проверяет, что камера не занята другим сценарием. В режиме replace занимающий
+// камеру сценарий останавливается (init_shutdown и событие stop_scenario), иначе возвращается
+// ErrScenarioAlreadyExists
+func (uc *UseCase) releaseCamera(ctx context.Context, input dto.InitScenarioRequest) error {
+	log := logger.FromContext(ctx)
+
+	existing, err := uc.repo.GetActiveScenarioByCameraIDForUpdate(ctx, scenario.GetActiveScenarioByCameraIDForUpdateParams{
+		CameraID: input.CameraID,
+		Statuses: entity.ActiveStatuses,
+	})
+	if err != nil {
+		if errors.Is(err, modelerror.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("get active scenario: %w", err)
+	}
+
+	if !input.Replace {
+		return modelerror.ErrScenarioAlreadyExists
+	}
+
+	existingUUID := uuid.UUID(existing.Uuid.Bytes)
+	if _, err := uc.stopper.StopScenario(ctx, existingUUID); err != nil {
+		return fmt.Errorf("stop replaced scenario: %w", err)
+	}
+
+	log.Info("active scenario of the camera replaced", zap.String("replaced_scenario_uuid", existingUUID.String()))
+	return nil
+}
+
+// scenarioConflict возвращает ScenarioConflictError с UUID сценария, занимающего камеру. Читается
+// вне откаченной транзакции, чтобы увидеть сценарий, созданный конкурентным запросом
+func (uc *UseCase) scenarioConflict(ctx context.Context, cameraID int32) error {
+	existing, err := uc.repo.GetActiveScenarioByCameraIDForUpdate(ctx, scenario.GetActiveScenarioByCameraIDForUpdateParams{
+		CameraID: cameraID,
+		Statuses: entity.ActiveStatuses,
+	})
+	if err != nil {
+		if errors.Is(err, modelerror.ErrNotFound) {
+			return &modelerror.ScenarioConflictError{}
+		}
+		return fmt.Errorf("get active scenario: %w", err)
+	}
+
+	return &modelerror.ScenarioConflictError{ScenarioUUID: uuid.UUID(existing.Uuid.Bytes).String()}
+}
+
+// claimIdempotencyKey занимает ключ для текущего запроса. Если ключ уже использован с тем же
+// телом запроса, возвращает сохраненный ответ, с другим телом - ErrIdempotencyKeyReused.
+// Конкурентный запрос с тем же ключом ждет фиксации транзакции первого на вставке ключа.
+func (uc *UseCase) claimIdempotencyKey(ctx context.Context, key string, input dto.InitScenarioRequest) (*dto.InitScenarioResponse, error) {
+	hash, err := requestHash(input)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = uc.repo.CreateIdempotencyKey(ctx, idempotency.CreateIdempotencyKeyParams{
+		Key:         key,
+		RequestHash: hash,
+	})
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, modelerror.ErrDuplicateKey) {
+		return nil, fmt.Errorf("create idempotency key: %w", err)
+	}
+
+	stored, err := uc.repo.GetIdempotencyKey(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("get idempotency key: %w", err)
+	}
+
+	if stored.RequestHash != hash {
+		return nil, modelerror.ErrIdempotencyKeyReused
+	}
+
+	if len(stored.Response) == 0 {
+		return nil, fmt.Errorf("idempotency key %s has no stored response", key)
+	}
+
+	var replayed dto.InitScenarioResponse
+	if err := json.Unmarshal(stored.Response, &replayed); err != nil {
+		return nil, fmt.Errorf("unmarshal idempotent response: %w", err)
+	}
+
+	return &replayed, nil
+}
+
+// requestHash считает хэш нормализованного запроса (вместе с режимом replace), поэтому тела,
+// отличающиеся только форматированием или порядком полей, считаются одинаковыми
+func requestHash(input dto.InitScenarioRequest) (string, error) {
+	normalized, err := json.Marshal(struct {
+		dto.InitScenarioRequest
+		Replace bool `json:"replace"`
+	}{input, input.Replace})
+	if err != nil {
+		return "", fmt.Errorf("marshal request: %w", err)
+	}
+
+	sum := sha256.Sum256(normalized)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 func uuidToUUIDV7(u interface{ String() string }) pgtype.UUID {
 	var pgUUID pgtype.UUID
 	_ = pgUUID.Scan(u.String())
@@ -0,0 +1,222 @@
+package init_scenario
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"init_scenario_api/internal/infastructure/repository/queries/idempotency"
+	"init_scenario_api/internal/infastructure/repository/queries/outbox"
+	"init_scenario_api/internal/infastructure/repository/queries/scenario"
+	"init_scenario_api/internal/models/dto"
+	"init_scenario_api/internal/models/entity"
+	modelerror "init_scenario_api/internal/models/error"
+
+	"github.com/google/uuid"
+)
+
+// fakeRepository хранит ключи идемпотентности и последние сценарии камер в памяти. Транзакция
+// не откатывается, поэтому тесты не проверяют откат, только ветвления use case
+type fakeRepository struct {
+	keys      map[string]idempotency.IdempotencyKey
+	active    map[int32]scenario.Scenario
+	scenarios int
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{
+		keys:   make(map[string]idempotency.IdempotencyKey),
+		active: make(map[int32]scenario.Scenario),
+	}
+}
+
+func (r *fakeRepository) CreateScenario(ctx context.Context, arg scenario.CreateScenarioParams) (scenario.Scenario, error) {
+	// Как уникальный индекс scenario_camera_id_active_uidx: мешает только сценарий в ActiveStatuses
+	if existing, ok := r.active[arg.CameraID]; ok && slices.Contains(entity.ActiveStatuses, *existing.Status) {
+		return scenario.Scenario{}, modelerror.ErrDuplicateKey
+	}
+	r.scenarios++
+	status := "init_startup"
+	created := scenario.Scenario{Uuid: arg.Uuid, CameraID: arg.CameraID, Url: arg.Url, Status: &status}
+	r.active[arg.CameraID] = created
+	return created, nil
+}
+
+func (r *fakeRepository) GetActiveScenarioByCameraIDForUpdate(ctx context.Context, arg scenario.GetActiveScenarioByCameraIDForUpdateParams) (scenario.Scenario, error) {
+	existing, ok := r.active[arg.CameraID]
+	if !ok || !slices.Contains(arg.Statuses, *existing.Status) {
+		return scenario.Scenario{}, modelerror.ErrNotFound
+	}
+	return existing, nil
+}
+
+func (r *fakeRepository) CreateOutboxScenario(ctx context.Context, arg outbox.CreateOutboxScenarioParams) (outbox.OutboxScenario, error) {
+	return outbox.OutboxScenario{OutboxUuid: arg.OutboxUuid, ScenarioUuid: arg.ScenarioUuid}, nil
+}
+
+func (r *fakeRepository) CreateIdempotencyKey(ctx context.Context, arg idempotency.CreateIdempotencyKeyParams) (idempotency.IdempotencyKey, error) {
+	if _, ok := r.keys[arg.Key]; ok {
+		return idempotency.IdempotencyKey{}, modelerror.ErrDuplicateKey
+	}
+	key := idempotency.IdempotencyKey{Key: arg.Key, RequestHash: arg.RequestHash}
+	r.keys[arg.Key] = key
+	return key, nil
+}
+
+func (r *fakeRepository) GetIdempotencyKey(ctx context.Context, key string) (idempotency.IdempotencyKey, error) {
+	stored, ok := r.keys[key]
+	if !ok {
+		return idempotency.IdempotencyKey{}, modelerror.ErrNotFound
+	}
+	return stored, nil
+}
+
+func (r *fakeRepository) SetIdempotencyKeyResponse(ctx context.Context, arg idempotency.SetIdempotencyKeyResponseParams) error {
+	stored := r.keys[arg.Key]
+	stored.Response = arg.Response
+	r.keys[arg.Key] = stored
+	return nil
+}
+
+func (r *fakeRepository) WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error {
+	return tFunc(ctx)
+}
+
+type fakeStopper struct {
+	repo    *fakeRepository
+	stopped []uuid.UUID
+}
+
+func (s *fakeStopper) StopScenario(ctx context.Context, scenarioUUID uuid.UUID) (*dto.StopScenarioResponse, error) {
+	s.stopped = append(s.stopped, scenarioUUID)
+	for cameraID, existing := range s.repo.active {
+		if uuid.UUID(existing.Uuid.Bytes) == scenarioUUID {
+			delete(s.repo.active, cameraID)
+		}
+	}
+	return &dto.StopScenarioResponse{ScenarioUUID: scenarioUUID.String(), Status: "init_shutdown"}, nil
+}
+
+func newTestUseCase() (*UseCase, *fakeRepository, *fakeStopper) {
+	repo := newFakeRepository()
+	stopper := &fakeStopper{repo: repo}
+	return NewUseCase(repo, stopper), repo, stopper
+}
+
+func TestInitScenarioReplaysIdempotentRequest(t *testing.T) {
+	uc, repo, _ := newTestUseCase()
+	req := dto.InitScenarioRequest{CameraID: 1, URL: "rtsp://camera/1"}
+
+	first, err := uc.InitScenario(context.Background(), req, "key-1")
+	if err != nil {
+		t.Fatalf("first request failed: %v", err)
+	}
+
+	second, err := uc.InitScenario(context.Background(), req, "key-1")
+	if err != nil {
+		t.Fatalf("repeated request failed: %v", err)
+	}
+
+	if *first != *second {
+		t.Fatalf("expected replayed response %+v, got %+v", *first, *second)
+	}
+	if repo.scenarios != 1 {
+		t.Fatalf("expected 1 scenario to be created, got %d", repo.scenarios)
+	}
+}
+
+func TestInitScenarioRejectsReusedKeyWithDifferentBody(t *testing.T) {
+	uc, _, _ := newTestUseCase()
+
+	if _, err := uc.InitScenario(context.Background(), dto.InitScenarioRequest{CameraID: 1, URL: "rtsp://camera/1"}, "key-1"); err != nil {
+		t.Fatalf("first request failed: %v", err)
+	}
+
+	_, err := uc.InitScenario(context.Background(), dto.InitScenarioRequest{CameraID: 2, URL: "rtsp://camera/1"}, "key-1")
+	if !errors.Is(err, modelerror.ErrIdempotencyKeyReused) {
+		t.Fatalf("expected ErrIdempotencyKeyReused, got %v", err)
+	}
+}
+
+func TestInitScenarioWithoutKeyIsNotDeduplicated(t *testing.T) {
+	uc, repo, _ := newTestUseCase()
+
+	for cameraID := int32(1); cameraID <= 2; cameraID++ {
+		req := dto.InitScenarioRequest{CameraID: cameraID, URL: "rtsp://camera/1"}
+		if _, err := uc.InitScenario(context.Background(), req, ""); err != nil {
+			t.Fatalf("request for camera %d failed: %v", cameraID, err)
+		}
+	}
+
+	if repo.scenarios != 2 {
+		t.Fatalf("expected 2 scenarios to be created, got %d", repo.scenarios)
+	}
+}
+
+func TestInitScenarioConflictsWithActiveScenario(t *testing.T) {
+	uc, _, stopper := newTestUseCase()
+	req := dto.InitScenarioRequest{CameraID: 1, URL: "rtsp://camera/1"}
+
+	first, err := uc.InitScenario(context.Background(), req, "")
+	if err != nil {
+		t.Fatalf("first request failed: %v", err)
+	}
+
+	_, err = uc.InitScenario(context.Background(), req, "")
+	var conflict *modelerror.ScenarioConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected ScenarioConflictError, got %v", err)
+	}
+	if conflict.ScenarioUUID != first.ScenarioUUID {
+		t.Fatalf("expected conflicting scenario %s, got %s", first.ScenarioUUID, conflict.ScenarioUUID)
+	}
+	if len(stopper.stopped) != 0 {
+		t.Fatalf("expected no scenario to be stopped, got %v", stopper.stopped)
+	}
+}
+
+func TestInitScenarioReplaceStopsActiveScenario(t *testing.T) {
+	uc, repo, stopper := newTestUseCase()
+
+	first, err := uc.InitScenario(context.Background(), dto.InitScenarioRequest{CameraID: 1, URL: "rtsp://camera/1"}, "")
+	if err != nil {
+		t.Fatalf("first request failed: %v", err)
+	}
+
+	second, err := uc.InitScenario(context.Background(), dto.InitScenarioRequest{CameraID: 1, URL: "rtsp://camera/1", Replace: true}, "")
+	if err != nil {
+		t.Fatalf("replace request failed: %v", err)
+	}
+
+	if len(stopper.stopped) != 1 || stopper.stopped[0].String() != first.ScenarioUUID {
+		t.Fatalf("expected scenario %s to be stopped, got %v", first.ScenarioUUID, stopper.stopped)
+	}
+	if second.ScenarioUUID == first.ScenarioUUID {
+		t.Fatalf("expected a new scenario, got the replaced one %s", second.ScenarioUUID)
+	}
+	if repo.scenarios != 2 {
+		t.Fatalf("expected 2 scenarios to be created, got %d", repo.scenarios)
+	}
+}
+
+func TestInitScenarioAllowsCameraWithStoppingScenario(t *testing.T) {
+	for _, status := range []string{entity.StatusInitShutdown, entity.StatusInShutdownProcessing} {
+		t.Run(status, func(t *testing.T) {
+			uc, repo, stopper := newTestUseCase()
+
+			stopping := status
+			repo.active[1] = scenario.Scenario{Uuid: uuidToUUIDV7(uuid.New()), CameraID: 1, Status: &stopping}
+
+			if _, err := uc.InitScenario(context.Background(), dto.InitScenarioRequest{CameraID: 1, URL: "rtsp://camera/1"}, ""); err != nil {
+				t.Fatalf("expected camera with %s scenario to accept a new one, got %v", status, err)
+			}
+			if len(stopper.stopped) != 0 {
+				t.Fatalf("expected no scenario to be stopped, got %v", stopper.stopped)
+			}
+			if repo.scenarios != 1 {
+				t.Fatalf("expected 1 scenario to be created, got %d", repo.scenarios)
+			}
+		})
+	}
+}
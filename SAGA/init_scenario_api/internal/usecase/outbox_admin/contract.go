@@ -0,0 +1,13 @@
+package outbox_admin
+
+import (
+	"context"
+	"init_scenario_api/internal/infastructure/repository/queries/outbox"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Repository interface {
+	ListFailedOutboxScenarios(ctx context.Context, arg outbox.ListFailedOutboxScenariosParams) ([]outbox.OutboxScenario, error)
+	RequeueFailedOutboxScenarios(ctx context.Context, outboxUUIDs []pgtype.UUID) ([]pgtype.UUID, error)
+}
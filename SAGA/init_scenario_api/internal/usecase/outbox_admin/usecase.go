@@ -0,0 +1,91 @@
+package outbox_admin
+
+import (
+	"context"
+	"fmt"
+	"init_scenario_api/internal/infastructure/repository/queries/outbox"
+	"init_scenario_api/internal/models/convert"
+	"init_scenario_api/internal/models/dto"
+	"init_scenario_api/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 500
+)
+
+type UseCase struct {
+	repo Repository
+}
+
+func NewUseCase(repo Repository) *UseCase {
+	return &UseCase{
+		repo: repo,
+	}
+}
+
+// ListFailed возвращает страницу outbox записей в статусе failed, от последних упавших к ранним
+func (uc *UseCase) ListFailed(ctx context.Context, input dto.ListFailedOutboxRequest) (*dto.ListFailedOutboxResponse, error) {
+	log := logger.FromContext(ctx)
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	if limit > MaxListLimit {
+		limit = MaxListLimit
+	}
+
+	outboxDB, err := uc.repo.ListFailedOutboxScenarios(ctx, outbox.ListFailedOutboxScenariosParams{
+		PageLimit:  limit,
+		PageOffset: max(input.Offset, 0),
+	})
+	if err != nil {
+		log.Error("failed to list failed outbox scenarios", zap.Error(err))
+		return nil, fmt.Errorf("list failed outbox scenarios: %w", err)
+	}
+
+	result := &dto.ListFailedOutboxResponse{
+		Items: make([]dto.FailedOutboxScenarioResponse, 0, len(outboxDB)),
+	}
+	for _, record := range outboxDB {
+		result.Items = append(result.Items, convert.OutboxScenarioToDTO(convert.OutboxScenarioFromDB(record)))
+	}
+
+	return result, nil
+}
+
+// Requeue возвращает failed записи в pending со сброшенным счетчиком попыток,
+// relay подхватит их на следующем проходе
+func (uc *UseCase) Requeue(ctx context.Context, outboxUUIDs []uuid.UUID) (*dto.RequeueOutboxResponse, error) {
+	log := logger.FromContext(ctx)
+
+	ids := make([]pgtype.UUID, len(outboxUUIDs))
+	for i, id := range outboxUUIDs {
+		ids[i] = pgtype.UUID{Bytes: id, Valid: true}
+	}
+
+	requeued, err := uc.repo.RequeueFailedOutboxScenarios(ctx, ids)
+	if err != nil {
+		log.Error("failed to requeue outbox scenarios", zap.Error(err))
+		return nil, fmt.Errorf("requeue outbox scenarios: %w", err)
+	}
+
+	result := &dto.RequeueOutboxResponse{
+		OutboxUUIDs: make([]string, 0, len(requeued)),
+	}
+	for _, id := range requeued {
+		result.OutboxUUIDs = append(result.OutboxUUIDs, uuid.UUID(id.Bytes).String())
+	}
+
+	log.Info("outbox scenarios requeued",
+		zap.Int("requested_count", len(outboxUUIDs)),
+		zap.Int("requeued_count", len(requeued)),
+	)
+
+	return result, nil
+}
@@ -0,0 +1,16 @@
+package outbox_cdc_relay
+
+import (
+	"context"
+	"init_scenario_api/internal/infastructure/replication"
+	"init_scenario_api/internal/infastructure/repository/queries/outbox"
+)
+
+type Stream interface {
+	Receive(ctx context.Context) (*replication.Transaction, error)
+	Confirm(lsn replication.LSN) error
+}
+
+type Publisher interface {
+	PublishScenarioOutboxRecords(ctx context.Context, topic string, records []outbox.OutboxScenario) ([]outbox.OutboxScenario, error)
+}
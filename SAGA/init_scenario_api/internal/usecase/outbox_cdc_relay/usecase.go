@@ -0,0 +1,215 @@
+package outbox_cdc_relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"init_scenario_api/internal/infastructure/replication"
+	"init_scenario_api/internal/infastructure/repository/queries/outbox"
+	"init_scenario_api/pkg/logger"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const outboxScenarioTable = "outbox_scenario"
+
+type Config struct {
+	Topic string
+	// BatchSize - максимальное количество записей в одной публикации
+	BatchSize int
+	// FlushInterval - сколько ждать следующих транзакций перед публикацией неполного батча
+	FlushInterval time.Duration
+	// RetryBaseDelay и RetryMaxDelay задают экспоненциальную задержку повторной публикации
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+}
+
+type UseCase struct {
+	publisher Publisher
+	cfg       Config
+}
+
+func NewUseCase(publisher Publisher, cfg Config) *UseCase {
+	return &UseCase{
+		publisher: publisher,
+		cfg:       cfg,
+	}
+}
+
+// Run читает вставки в outbox_scenario из слота репликации и публикует их в Kafka батчами до
+// отмены ctx или ошибки стрима. Позиция слота подтверждается только после того, как весь
+// батч доставлен, поэтому после падения неподтвержденные транзакции придут повторно.
+// Транзакции приходят в порядке коммита, что сохраняет порядок событий одного ключа.
+//
+// Публикация повторяется с задержкой, пока не пройдет: пропустить запись нельзя, иначе
+// подтверждение позиции ее потеряет.
+func (uc *UseCase) Run(ctx context.Context, stream Stream) error {
+	log := logger.FromContext(ctx)
+
+	for {
+		// Первую транзакцию батча ждем без ограничения по времени
+		tx, err := stream.Receive(ctx)
+		if err != nil {
+			return err
+		}
+
+		records, err := recordsFromTransaction(tx)
+		if err != nil {
+			return err
+		}
+		lastLSN := tx.EndLSN
+
+		flushAt := time.Now().Add(uc.cfg.FlushInterval)
+		for len(records) < uc.cfg.BatchSize {
+			receiveCtx, cancel := context.WithDeadline(ctx, flushAt)
+			tx, err := stream.Receive(receiveCtx)
+			cancel()
+			if err != nil {
+				if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+					break
+				}
+				return err
+			}
+
+			txRecords, err := recordsFromTransaction(tx)
+			if err != nil {
+				return err
+			}
+			records = append(records, txRecords...)
+			lastLSN = tx.EndLSN
+		}
+
+		if err := uc.publish(ctx, records); err != nil {
+			return err
+		}
+
+		if err := stream.Confirm(lastLSN); err != nil {
+			return fmt.Errorf("confirm lsn %s: %w", lastLSN, err)
+		}
+
+		log.Info("cdc outbox batch published",
+			zap.Int("processed_count", len(records)),
+			zap.String("lsn", lastLSN.String()),
+		)
+	}
+}
+
+func (uc *UseCase) publish(ctx context.Context, records []outbox.OutboxScenario) error {
+	log := logger.FromContext(ctx)
+
+	pending := records
+	for attempt := 1; ; attempt++ {
+		undelivered, err := uc.publisher.PublishScenarioOutboxRecords(ctx, uc.cfg.Topic, pending)
+		if err == nil {
+			return nil
+		}
+		pending = resendFrom(pending, undelivered)
+
+		delay := uc.backoff(attempt)
+		log.Error("failed to publish cdc outbox batch, retrying",
+			zap.Int("undelivered_count", len(pending)),
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", delay),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// resendFrom выбирает записи для повторной публикации: у каждого ключа с недоставленными
+// записями - все его записи, начиная с первой недоставленной. Доставленные после нее записи
+// того же ключа отправляются заново, иначе повтор оказался бы в партиции после них и нарушил
+// порядок событий ключа. Дубли отсекает inbox получателя по outbox_uuid
+func resendFrom(records, undelivered []outbox.OutboxScenario) []outbox.OutboxScenario {
+	failed := make(map[[16]byte]struct{}, len(undelivered))
+	for _, record := range undelivered {
+		failed[record.OutboxUuid.Bytes] = struct{}{}
+	}
+
+	affected := make(map[string]struct{})
+	resend := make([]outbox.OutboxScenario, 0, len(undelivered))
+	for _, record := range records {
+		if _, ok := failed[record.OutboxUuid.Bytes]; ok {
+			affected[record.PartitionKey] = struct{}{}
+		}
+		if _, ok := affected[record.PartitionKey]; ok {
+			resend = append(resend, record)
+		}
+	}
+	return resend
+}
+
+func (uc *UseCase) backoff(attempt int) time.Duration {
+	delay := uc.cfg.RetryBaseDelay << min(attempt-1, 30)
+	if delay <= 0 || delay > uc.cfg.RetryMaxDelay {
+		return uc.cfg.RetryMaxDelay
+	}
+	return delay
+}
+
+// recordsFromTransaction собирает outbox записи из вставок транзакции. Relay нужны только
+// поля для публикации, остальные колонки не разбираются
+func recordsFromTransaction(tx *replication.Transaction) ([]outbox.OutboxScenario, error) {
+	records := make([]outbox.OutboxScenario, 0, len(tx.Inserts))
+	for _, insert := range tx.Inserts {
+		if insert.Table != outboxScenarioTable {
+			continue
+		}
+
+		record, err := recordFromRow(insert.Row)
+		if err != nil {
+			return nil, fmt.Errorf("decode outbox row at lsn %s: %w", tx.EndLSN, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func recordFromRow(row replication.Row) (outbox.OutboxScenario, error) {
+	var record outbox.OutboxScenario
+
+	for column, dst := range map[string]*pgtype.UUID{
+		"outbox_uuid":   &record.OutboxUuid,
+		"scenario_uuid": &record.ScenarioUuid,
+	} {
+		value := row[column]
+		if value == nil {
+			return record, fmt.Errorf("column %s is null", column)
+		}
+		if err := dst.Scan(*value); err != nil {
+			return record, fmt.Errorf("parse %s: %w", column, err)
+		}
+	}
+
+	for column, dst := range map[string]*string{
+		"event_type":    &record.EventType,
+		"partition_key": &record.PartitionKey,
+	} {
+		value := row[column]
+		if value == nil {
+			return record, fmt.Errorf("column %s is null", column)
+		}
+		*dst = *value
+	}
+
+	if payload := row["payload"]; payload != nil {
+		record.Payload = []byte(*payload)
+	}
+	record.State = row["state"]
+
+	// created_at становится OccurredAt события: без него время события подменилось бы временем публикации
+	if createdAt := row["created_at"]; createdAt != nil {
+		if err := record.CreatedAt.Scan(*createdAt); err != nil {
+			return record, fmt.Errorf("parse created_at: %w", err)
+		}
+	}
+
+	return record, nil
+}
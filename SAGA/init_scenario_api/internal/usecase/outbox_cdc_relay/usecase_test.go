@@ -0,0 +1,202 @@
+package outbox_cdc_relay
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"init_scenario_api/internal/infastructure/replication"
+	"init_scenario_api/internal/infastructure/repository/queries/outbox"
+
+	"github.com/google/uuid"
+)
+
+// fakeStream отдает заранее заданные транзакции, затем блокируется до отмены ctx
+type fakeStream struct {
+	txs       []*replication.Transaction
+	confirmed []replication.LSN
+	cancel    context.CancelFunc
+}
+
+func (s *fakeStream) Receive(ctx context.Context) (*replication.Transaction, error) {
+	if len(s.txs) > 0 {
+		tx := s.txs[0]
+		s.txs = s.txs[1:]
+		return tx, nil
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (s *fakeStream) Confirm(lsn replication.LSN) error {
+	s.confirmed = append(s.confirmed, lsn)
+	if len(s.txs) == 0 {
+		s.cancel()
+	}
+	return nil
+}
+
+// fakePublisher отклоняет первые failures вызовов
+type fakePublisher struct {
+	failures  int
+	calls     int
+	published []outbox.OutboxScenario
+}
+
+func (p *fakePublisher) PublishScenarioOutboxRecords(ctx context.Context, topic string, records []outbox.OutboxScenario) ([]outbox.OutboxScenario, error) {
+	p.calls++
+	if p.calls <= p.failures {
+		return records, errors.New("broker unavailable")
+	}
+	p.published = append(p.published, records...)
+	return nil, nil
+}
+
+func outboxInsert(outboxUUID, key string) replication.Insert {
+	payload := `{"camera_id":1}`
+	eventType := "init_scenario"
+	scenarioUUID := "0193a6f0-0000-7000-8000-0000000000ff"
+	return replication.Insert{
+		Table: outboxScenarioTable,
+		Row: replication.Row{
+			"outbox_uuid":   &outboxUUID,
+			"scenario_uuid": &scenarioUUID,
+			"payload":       &payload,
+			"event_type":    &eventType,
+			"partition_key": &key,
+		},
+	}
+}
+
+func TestRunConfirmsAfterPublish(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := &fakeStream{
+		cancel: cancel,
+		txs: []*replication.Transaction{
+			{EndLSN: 100, Inserts: []replication.Insert{outboxInsert("0193a6f0-0000-7000-8000-000000000001", "1")}},
+			{EndLSN: 200, Inserts: []replication.Insert{
+				outboxInsert("0193a6f0-0000-7000-8000-000000000002", "1"),
+				outboxInsert("0193a6f0-0000-7000-8000-000000000003", "2"),
+			}},
+		},
+	}
+	publisher := &fakePublisher{failures: 2}
+	uc := NewUseCase(publisher, Config{
+		Topic:          "scenario",
+		BatchSize:      10,
+		FlushInterval:  10 * time.Millisecond,
+		RetryBaseDelay: time.Millisecond,
+		RetryMaxDelay:  time.Millisecond,
+	})
+
+	if err := uc.Run(ctx, stream); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled, got %v", err)
+	}
+
+	if publisher.calls != 3 {
+		t.Fatalf("expected 3 publish attempts, got %d", publisher.calls)
+	}
+	if len(publisher.published) != 3 {
+		t.Fatalf("expected 3 published records, got %d", len(publisher.published))
+	}
+	if publisher.published[0].PartitionKey != "1" || publisher.published[2].PartitionKey != "2" {
+		t.Fatalf("records published out of commit order: %+v", publisher.published)
+	}
+	if len(stream.confirmed) != 1 || stream.confirmed[0] != 200 {
+		t.Fatalf("expected single confirm of lsn 200, got %v", stream.confirmed)
+	}
+}
+
+// partialPublisher при первом вызове не доставляет запись rejected, остальные доставляет
+type partialPublisher struct {
+	rejected string
+	calls    [][]string
+}
+
+func (p *partialPublisher) PublishScenarioOutboxRecords(ctx context.Context, topic string, records []outbox.OutboxScenario) ([]outbox.OutboxScenario, error) {
+	var uuids []string
+	var undelivered []outbox.OutboxScenario
+	for _, record := range records {
+		id := uuid.UUID(record.OutboxUuid.Bytes).String()
+		uuids = append(uuids, id)
+		if id == p.rejected && len(p.calls) == 0 {
+			undelivered = append(undelivered, record)
+		}
+	}
+	p.calls = append(p.calls, uuids)
+
+	if len(undelivered) > 0 {
+		return undelivered, errors.New("message too large")
+	}
+	return nil, nil
+}
+
+func TestRunResendsKeyFromFirstUndeliveredRecord(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const (
+		first  = "0193a6f0-0000-7000-8000-000000000001"
+		second = "0193a6f0-0000-7000-8000-000000000002"
+		other  = "0193a6f0-0000-7000-8000-000000000003"
+	)
+	stream := &fakeStream{
+		cancel: cancel,
+		txs: []*replication.Transaction{
+			{EndLSN: 100, Inserts: []replication.Insert{
+				outboxInsert(first, "1"),
+				outboxInsert(other, "2"),
+				outboxInsert(second, "1"),
+			}},
+		},
+	}
+	publisher := &partialPublisher{rejected: first}
+	uc := NewUseCase(publisher, Config{
+		Topic:          "scenario",
+		BatchSize:      10,
+		FlushInterval:  10 * time.Millisecond,
+		RetryBaseDelay: time.Millisecond,
+		RetryMaxDelay:  time.Millisecond,
+	})
+
+	if err := uc.Run(ctx, stream); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled, got %v", err)
+	}
+
+	if len(publisher.calls) != 2 {
+		t.Fatalf("expected 2 publish attempts, got %d", len(publisher.calls))
+	}
+	// Доставленная запись того же ключа отправляется повторно после недоставленной, запись
+	// другого ключа - нет
+	if retry := publisher.calls[1]; len(retry) != 2 || retry[0] != first || retry[1] != second {
+		t.Fatalf("expected key 1 resent from its first undelivered record, got %v", retry)
+	}
+}
+
+func TestRecordFromRowParsesCreatedAt(t *testing.T) {
+	insert := outboxInsert("0193a6f0-0000-7000-8000-000000000001", "1")
+	createdAt := "2025-12-13 10:15:30.123456"
+	insert.Row["created_at"] = &createdAt
+
+	record, err := recordFromRow(insert.Row)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := time.Date(2025, 12, 13, 10, 15, 30, 123456000, time.UTC)
+	if !record.CreatedAt.Valid || !record.CreatedAt.Time.Equal(want) {
+		t.Fatalf("expected created_at %s, got %+v", want, record.CreatedAt)
+	}
+}
+
+func TestRecordFromRowRequiresKeyColumns(t *testing.T) {
+	insert := outboxInsert("0193a6f0-0000-7000-8000-000000000001", "1")
+	delete(insert.Row, "partition_key")
+
+	if _, err := recordFromRow(insert.Row); err == nil {
+		t.Fatalf("expected error for row without partition_key")
+	}
+}
@@ -13,7 +13,8 @@ type Repository interface {
 	GetPendingOutboxScenarios(ctx context.Context, limit int32) ([]outbox.OutboxScenario, error)
 	LockOutboxScenariosBatch(ctx context.Context, outboxUUIDs []pgtype.UUID) error
 	MarkOutboxScenariosAsSentBatch(ctx context.Context, outboxUUIDs []pgtype.UUID) error
-	UpdateScenarioStatusBatch(ctx context.Context, arg scenario.UpdateScenarioStatusBatchParams) error
+	RescheduleOutboxScenariosBatch(ctx context.Context, arg outbox.RescheduleOutboxScenariosBatchParams) ([]outbox.RescheduleOutboxScenariosBatchRow, error)
+	TransitionScenarioStatusBatch(ctx context.Context, arg scenario.TransitionScenarioStatusBatchParams) error
 	WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error
 }
 
@@ -2,34 +2,69 @@ package outbox_scenario_processor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"init_scenario_api/internal/infastructure/kafka"
 	"init_scenario_api/internal/infastructure/repository/queries/outbox"
 	"init_scenario_api/internal/infastructure/repository/queries/scenario"
 	"init_scenario_api/internal/models/entity"
+	kafkaModels "init_scenario_api/internal/models/kafka"
 	"init_scenario_api/pkg/logger"
+	"shared/envelope"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"go.uber.org/zap"
 )
 
+// scenarioTransition описывает смену статуса сценария после успешной отправки события в Kafka.
+// События без перехода (например, compensate_scenario) статус сценария не меняют
+type scenarioTransition struct {
+	from string
+	to   string
+}
+
+var scenarioTransitions = map[string]scenarioTransition{
+	entity.OutboxEventInitScenario: {from: entity.StatusInitStartup, to: entity.StatusInStartupProcessing},
+	entity.OutboxEventStopScenario: {from: entity.StatusInitShutdown, to: entity.StatusInShutdownProcessing},
+}
+
+// RetryPolicy задает бюджет попыток публикации outbox записи и экспоненциальную задержку между ними
+type RetryPolicy struct {
+	MaxAttempts int32
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
 type UseCase struct {
 	repo     Repository
 	producer KafkaProducer
+	retry    RetryPolicy
 }
 
-func NewUseCase(repo Repository, producer KafkaProducer) *UseCase {
+func NewUseCase(repo Repository, producer KafkaProducer, retry RetryPolicy) *UseCase {
 	return &UseCase{
 		repo:     repo,
 		producer: producer,
+		retry:    retry,
 	}
 }
 
 // ProcessScenarioOutboxMessages вычитывает n записей из outbox и отправляет их в Kafka батчем
 // topic - название топика для отправки сообщений
 // batchSize - количество записей для обработки за один раз
-func (uc *UseCase) ProcessScenarioOutboxMessages(ctx context.Context, topic string, batchSize int32) error {
+//
+// Записи публикуются с ключом partition_key, а GetPendingOutboxScenarios отдает только самое
+// раннее неотправленное событие каждого ключа, поэтому события одной камеры уходят по порядку.
+// Каждый захват записи считается попыткой. При ошибке Kafka записи откладываются с
+// экспоненциальной задержкой через locked_until, а после RetryPolicy.MaxAttempts попыток
+// переводятся в failed с последней ошибкой
+//
+// Возвращает количество записей, доставленных в Kafka. Отправка события может открыть
+// следующее событие того же ключа, поэтому вызывающему стоит повторить вызов, пока он
+// доставляет записи
+func (uc *UseCase) ProcessScenarioOutboxMessages(ctx context.Context, topic string, batchSize int32) (int, error) {
 	log := logger.FromContext(ctx)
 
 	log.Info("starting scenario outbox processing",
@@ -68,70 +103,283 @@ func (uc *UseCase) ProcessScenarioOutboxMessages(ctx context.Context, topic stri
 
 	if err != nil {
 		log.Error("failed to get and lock outbox records", zap.Error(err))
-		return fmt.Errorf("get and lock records: %w", err)
+		return 0, fmt.Errorf("get and lock records: %w", err)
 	}
 
 	if len(outboxRecords) == 0 {
-		return nil
+		return 0, nil
+	}
+
+	messages, encoded, invalid := buildMessages(topic, outboxRecords)
+	if len(invalid) > 0 {
+		if err := uc.fail(ctx, invalid); err != nil {
+			log.Error("failed to mark invalid outbox records failed", zap.Error(err))
+		}
+	}
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	log.Info("sending batch to kafka", zap.Int("messages_count", len(messages)))
+
+	sendErr := uc.producer.SendMessages(ctx, messages)
+	delivered, failed := splitSendResult(encoded, sendErr)
+
+	// Доставленные записи помечаются sent даже при частичной ошибке батча, чтобы не
+	// отправлять их повторно. Повторно уходят только сообщения, отклоненные брокером,
+	// и весь батч, если результат отправки по сообщениям неизвестен
+	var markErr error
+	if len(delivered) > 0 {
+		if markErr = uc.markSent(ctx, delivered); markErr != nil {
+			log.Error("failed to update outbox and scenario statuses", zap.Error(markErr))
+		}
+	}
+
+	for lastError, uuids := range failed {
+		if err := uc.reschedule(ctx, uuids, lastError); err != nil {
+			log.Error("failed to reschedule outbox records", zap.Error(err))
+		}
+	}
+
+	if sendErr != nil {
+		log.Error("failed to send messages batch to kafka",
+			zap.Int("delivered_count", len(delivered)),
+			zap.Int("failed_count", len(encoded)-len(delivered)),
+			zap.Error(sendErr),
+		)
+		return len(delivered), fmt.Errorf("send messages to kafka: %w", sendErr)
+	}
+
+	if markErr != nil {
+		return len(delivered), fmt.Errorf("update statuses: %w", markErr)
+	}
+
+	log.Info("outbox batch processing completed successfully",
+		zap.Int("processed_count", len(outboxRecords)),
+		zap.String("topic", topic),
+	)
+
+	return len(delivered), nil
+}
+
+// PublishScenarioOutboxRecords публикует уже выбранные записи без захвата через locked_until
+// и без бюджета попыток (для CDC relay, который сам повторяет отправку). Доставленные записи
+// помечаются sent, а сценарии переводятся в следующий статус. Возвращает недоставленные записи.
+// Если не удалось записать статусы, недоставленными считаются все записи: повторная отправка
+// безопасна, потребители дедуплицируют события по outbox_uuid
+func (uc *UseCase) PublishScenarioOutboxRecords(ctx context.Context, topic string, records []outbox.OutboxScenario) ([]outbox.OutboxScenario, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	messages, encoded, invalid := buildMessages(topic, records)
+	if len(invalid) > 0 {
+		if err := uc.fail(ctx, invalid); err != nil {
+			return records, fmt.Errorf("mark invalid records failed: %w", err)
+		}
+	}
+	if len(messages) == 0 {
+		return nil, nil
+	}
+
+	sendErr := uc.producer.SendMessages(ctx, messages)
+	delivered, _ := splitSendResult(encoded, sendErr)
+
+	if len(delivered) > 0 {
+		if err := uc.markSent(ctx, delivered); err != nil {
+			return records, fmt.Errorf("update statuses: %w", err)
+		}
+	}
+
+	if sendErr != nil {
+		var batchErr *kafka.BatchError
+		partial := errors.As(sendErr, &batchErr)
+
+		undelivered := make([]outbox.OutboxScenario, 0, len(records)-len(delivered))
+		for i, record := range encoded {
+			if !partial || batchErr.Failed(i) {
+				undelivered = append(undelivered, record)
+			}
+		}
+		return undelivered, fmt.Errorf("send messages to kafka: %w", sendErr)
 	}
 
-	messages := make([]*kafka.Message, 0, len(outboxRecords))
-	for _, record := range outboxRecords {
+	return nil, nil
+}
+
+// buildMessages упаковывает payload записей в конверт events.v1.Envelope по схеме из
+// kafkaModels.Schemas. Возвращает сообщения и записи, для которых они собраны, в одном порядке.
+// Записи, payload которых не соответствует схеме события, в батч не попадают: они сгруппированы
+// по тексту ошибки, как недоставленные записи в splitSendResult
+func buildMessages(topic string, records []outbox.OutboxScenario) ([]*kafka.Message, []outbox.OutboxScenario, map[string][]pgtype.UUID) {
+	messages := make([]*kafka.Message, 0, len(records))
+	encoded := make([]outbox.OutboxScenario, 0, len(records))
+	invalid := make(map[string][]pgtype.UUID)
+
+	for _, record := range records {
+		value, err := encodeRecord(record)
+		if err != nil {
+			invalid[err.Error()] = append(invalid[err.Error()], record.OutboxUuid)
+			continue
+		}
+
 		headers := make(map[string][]byte)
-		headers["outbox_uuid"] = []byte(uuidToString(record.OutboxUuid))
+		headers[kafkaModels.OutboxUUIDHeader] = []byte(uuidToString(record.OutboxUuid))
+		headers[kafkaModels.EventTypeHeader] = []byte(record.EventType)
+		headers[envelope.ContentTypeHeader] = []byte(envelope.ContentType)
 
-		msg := kafka.NewMessage(
+		key := record.PartitionKey
+		messages = append(messages, kafka.NewMessage(
 			topic,
-			nil, // key
-			record.Payload,
+			&key,
+			value,
 			headers,
-		)
-		messages = append(messages, msg)
+		))
+		encoded = append(encoded, record)
 	}
+	return messages, encoded, invalid
+}
 
-	log.Info("sending batch to kafka", zap.Int("messages_count", len(messages)))
-	if err := uc.producer.SendMessages(ctx, messages); err != nil {
-		log.Error("failed to send messages batch to kafka", zap.Error(err))
-		return fmt.Errorf("send messages to kafka: %w", err)
+// encodeRecord упаковывает JSON payload записи в конверт. Корреляционным идентификатором
+// служит UUID сценария: все события саги одного сценария связаны им
+func encodeRecord(record outbox.OutboxScenario) ([]byte, error) {
+	payload, err := kafkaModels.Schemas.PayloadFromJSON(record.EventType, record.Payload)
+	if err != nil {
+		return nil, err
 	}
 
-	uuids := make([]pgtype.UUID, len(outboxRecords))
-	for i, record := range outboxRecords {
+	occurredAt := time.Now()
+	if record.CreatedAt.Valid {
+		occurredAt = record.CreatedAt.Time
+	}
+
+	return kafkaModels.Schemas.Encode(envelope.Event{
+		ID:            uuidToString(record.OutboxUuid),
+		Type:          record.EventType,
+		OccurredAt:    occurredAt,
+		CorrelationID: uuidToString(record.ScenarioUuid),
+		Payload:       payload,
+	})
+}
+
+// splitSendResult делит записи батча на доставленные и недоставленные. Недоставленные
+// сгруппированы по тексту ошибки, который сохраняется в last_error. Если ошибка не содержит
+// результата по сообщениям, недоставленными считаются все записи
+func splitSendResult(records []outbox.OutboxScenario, sendErr error) ([]outbox.OutboxScenario, map[string][]pgtype.UUID) {
+	failed := make(map[string][]pgtype.UUID)
+	if sendErr == nil {
+		return records, failed
+	}
+
+	var batchErr *kafka.BatchError
+	if !errors.As(sendErr, &batchErr) {
+		for _, record := range records {
+			failed[sendErr.Error()] = append(failed[sendErr.Error()], record.OutboxUuid)
+		}
+		return nil, failed
+	}
+
+	delivered := make([]outbox.OutboxScenario, 0, len(records))
+	for i, record := range records {
+		if batchErr.Failed(i) {
+			lastError := batchErr.Errors[i].Error()
+			failed[lastError] = append(failed[lastError], record.OutboxUuid)
+			continue
+		}
+		delivered = append(delivered, record)
+	}
+	return delivered, failed
+}
+
+// markSent помечает outbox записи как sent, снимает блокировку и переводит сценарии
+// доставленных событий в следующий статус
+func (uc *UseCase) markSent(ctx context.Context, records []outbox.OutboxScenario) error {
+	uuids := make([]pgtype.UUID, len(records))
+	for i, record := range records {
 		uuids[i] = record.OutboxUuid
 	}
 
-	// Помечаем outbox записи как sent и снимаем блокировку
-	err = uc.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+	return uc.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
 		if err := uc.repo.MarkOutboxScenariosAsSentBatch(txCtx, uuids); err != nil {
 			return fmt.Errorf("mark outbox as sent: %w", err)
 		}
 
-		scenarioUUIDs := make([]pgtype.UUID, len(outboxRecords))
-		for i, record := range outboxRecords {
-			scenarioUUIDs[i] = record.ScenarioUuid
+		scenarioUUIDsByEvent := make(map[string][]pgtype.UUID)
+		for _, record := range records {
+			scenarioUUIDsByEvent[record.EventType] = append(scenarioUUIDsByEvent[record.EventType], record.ScenarioUuid)
 		}
 
-		status := entity.StatusInStartupProcessing
-		if err := uc.repo.UpdateScenarioStatusBatch(txCtx, scenario.UpdateScenarioStatusBatchParams{
-			Column1: scenarioUUIDs,
-			Status:  &status,
-		}); err != nil {
-			return fmt.Errorf("update scenario statuses: %w", err)
+		for eventType, scenarioUUIDs := range scenarioUUIDsByEvent {
+			transition, ok := scenarioTransitions[eventType]
+			if !ok {
+				continue
+			}
+
+			if err := uc.repo.TransitionScenarioStatusBatch(txCtx, scenario.TransitionScenarioStatusBatchParams{
+				Uuids:      scenarioUUIDs,
+				FromStatus: &transition.from,
+				ToStatus:   &transition.to,
+			}); err != nil {
+				return fmt.Errorf("update scenario statuses for %s: %w", eventType, err)
+			}
 		}
 
 		return nil
 	})
+}
 
+// reschedule откладывает неотправленные записи до следующей попытки или переводит в failed
+// записи, исчерпавшие бюджет попыток
+func (uc *UseCase) reschedule(ctx context.Context, uuids []pgtype.UUID, lastError string) error {
+	log := logger.FromContext(ctx)
+
+	rows, err := uc.repo.RescheduleOutboxScenariosBatch(ctx, outbox.RescheduleOutboxScenariosBatchParams{
+		MaxAttempts: uc.retry.MaxAttempts,
+		BaseDelayMs: uc.retry.BaseDelay.Milliseconds(),
+		MaxDelayMs:  uc.retry.MaxDelay.Milliseconds(),
+		LastError:   &lastError,
+		OutboxUuids: uuids,
+	})
 	if err != nil {
-		log.Error("failed to update outbox and scenario statuses", zap.Error(err))
-		return fmt.Errorf("update statuses: %w", err)
+		return fmt.Errorf("reschedule outbox scenarios: %w", err)
 	}
 
-	log.Info("outbox batch processing completed successfully",
-		zap.Int("processed_count", len(outboxRecords)),
-		zap.String("topic", topic),
+	failed := 0
+	for _, row := range rows {
+		if row.State != nil && *row.State == entity.OutboxStateFailed {
+			failed++
+			log.Error("outbox record exhausted publish attempts, marked failed",
+				zap.String("outbox_uuid", uuidToString(row.OutboxUuid)),
+				zap.Int32("attempts", row.Attempts),
+			)
+		}
+	}
+
+	log.Warn("outbox records rescheduled",
+		zap.Int("rescheduled_count", len(rows)-failed),
+		zap.Int("failed_count", failed),
 	)
+	return nil
+}
 
+// fail переводит в failed записи, payload которых не соответствует схеме события. Повтор
+// публикации такой записи не поможет, после исправления ее можно вернуть через outbox_admin
+func (uc *UseCase) fail(ctx context.Context, invalid map[string][]pgtype.UUID) error {
+	log := logger.FromContext(ctx)
+
+	for lastError, uuids := range invalid {
+		if _, err := uc.repo.RescheduleOutboxScenariosBatch(ctx, outbox.RescheduleOutboxScenariosBatchParams{
+			MaxAttempts: 0,
+			LastError:   &lastError,
+			OutboxUuids: uuids,
+		}); err != nil {
+			return fmt.Errorf("fail outbox scenarios: %w", err)
+		}
+		log.Error("outbox records do not match event schema, marked failed",
+			zap.Int("failed_count", len(uuids)),
+			zap.String("error", lastError),
+		)
+	}
 	return nil
 }
 
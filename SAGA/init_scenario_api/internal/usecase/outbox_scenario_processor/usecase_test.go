@@ -0,0 +1,217 @@
+package outbox_scenario_processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"init_scenario_api/internal/infastructure/kafka"
+	"init_scenario_api/internal/infastructure/repository/queries/outbox"
+	"init_scenario_api/internal/infastructure/repository/queries/scenario"
+	kafkaModels "init_scenario_api/internal/models/kafka"
+	"shared/envelope"
+	eventspb "shared/proto/events/v1"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type fakeRepository struct {
+	pending     []outbox.OutboxScenario
+	sent        []pgtype.UUID
+	rescheduled []outbox.RescheduleOutboxScenariosBatchParams
+}
+
+func (r *fakeRepository) GetPendingOutboxScenarios(ctx context.Context, limit int32) ([]outbox.OutboxScenario, error) {
+	return r.pending, nil
+}
+
+func (r *fakeRepository) LockOutboxScenariosBatch(ctx context.Context, outboxUUIDs []pgtype.UUID) error {
+	return nil
+}
+
+func (r *fakeRepository) MarkOutboxScenariosAsSentBatch(ctx context.Context, outboxUUIDs []pgtype.UUID) error {
+	r.sent = append(r.sent, outboxUUIDs...)
+	return nil
+}
+
+func (r *fakeRepository) RescheduleOutboxScenariosBatch(ctx context.Context, arg outbox.RescheduleOutboxScenariosBatchParams) ([]outbox.RescheduleOutboxScenariosBatchRow, error) {
+	r.rescheduled = append(r.rescheduled, arg)
+	rows := make([]outbox.RescheduleOutboxScenariosBatchRow, 0, len(arg.OutboxUuids))
+	for _, id := range arg.OutboxUuids {
+		state := "pending"
+		rows = append(rows, outbox.RescheduleOutboxScenariosBatchRow{OutboxUuid: id, State: &state, Attempts: 1})
+	}
+	return rows, nil
+}
+
+func (r *fakeRepository) TransitionScenarioStatusBatch(ctx context.Context, arg scenario.TransitionScenarioStatusBatchParams) error {
+	return nil
+}
+
+func (r *fakeRepository) WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error {
+	return tFunc(ctx)
+}
+
+type fakeProducer struct {
+	err  error
+	sent []*kafka.Message
+}
+
+func (p *fakeProducer) SendMessage(ctx context.Context, msg *kafka.Message) error {
+	return p.err
+}
+
+func (p *fakeProducer) SendMessages(ctx context.Context, msgs []*kafka.Message) error {
+	p.sent = append(p.sent, msgs...)
+	return p.err
+}
+
+var (
+	initPayload = []byte(`{"scenario_uuid":"6f1c2d3e-4a5b-4c6d-8e7f-901a2b3c4d5e","camera_id":7,"url":"rtsp://camera/7"}`)
+	stopPayload = []byte(`{"scenario_uuid":"6f1c2d3e-4a5b-4c6d-8e7f-901a2b3c4d5e","camera_id":7}`)
+)
+
+func TestProcessScenarioOutboxMessagesReschedulesOnSendError(t *testing.T) {
+	repo := &fakeRepository{
+		pending: []outbox.OutboxScenario{
+			{OutboxUuid: pgtype.UUID{Bytes: uuid.New(), Valid: true}, EventType: "init_scenario", Payload: initPayload},
+			{OutboxUuid: pgtype.UUID{Bytes: uuid.New(), Valid: true}, EventType: "stop_scenario", Payload: stopPayload},
+		},
+	}
+	sendErr := errors.New("broker unavailable")
+	uc := NewUseCase(repo, &fakeProducer{err: sendErr}, RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   2 * time.Second,
+		MaxDelay:    time.Minute,
+	})
+
+	_, err := uc.ProcessScenarioOutboxMessages(context.Background(), "scenario", 10)
+	if !errors.Is(err, sendErr) {
+		t.Fatalf("expected send error, got %v", err)
+	}
+
+	if len(repo.sent) != 0 {
+		t.Fatalf("expected no records marked sent, got %d", len(repo.sent))
+	}
+	if len(repo.rescheduled) != 1 {
+		t.Fatalf("expected one reschedule call, got %d", len(repo.rescheduled))
+	}
+	rescheduled := repo.rescheduled[0]
+	if len(rescheduled.OutboxUuids) != 2 {
+		t.Fatalf("expected 2 rescheduled records, got %d", len(rescheduled.OutboxUuids))
+	}
+	if rescheduled.MaxAttempts != 3 || rescheduled.BaseDelayMs != 2000 || rescheduled.MaxDelayMs != 60000 {
+		t.Fatalf("unexpected retry params: %+v", rescheduled)
+	}
+	if rescheduled.LastError == nil || *rescheduled.LastError != sendErr.Error() {
+		t.Fatalf("expected last error %q, got %v", sendErr.Error(), rescheduled.LastError)
+	}
+}
+
+func TestProcessScenarioOutboxMessagesMarksSent(t *testing.T) {
+	repo := &fakeRepository{
+		pending: []outbox.OutboxScenario{
+			{OutboxUuid: pgtype.UUID{Bytes: uuid.New(), Valid: true}, EventType: "init_scenario", Payload: initPayload, PartitionKey: "7"},
+		},
+	}
+	producer := &fakeProducer{}
+	uc := NewUseCase(repo, producer, RetryPolicy{MaxAttempts: 3})
+
+	processed, err := uc.ProcessScenarioOutboxMessages(context.Background(), "scenario", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if processed != 1 {
+		t.Fatalf("expected 1 processed record, got %d", processed)
+	}
+
+	if len(producer.sent) != 1 || producer.sent[0].Key == nil || *producer.sent[0].Key != "7" {
+		t.Fatalf("expected message keyed by partition key")
+	}
+	if !envelope.IsEnvelope(producer.sent[0].Headers) {
+		t.Fatalf("expected message in envelope")
+	}
+	event, err := kafkaModels.Schemas.Decode(producer.sent[0].Value)
+	if err != nil {
+		t.Fatalf("failed to decode envelope: %v", err)
+	}
+	if payload, ok := event.Payload.(*eventspb.InitScenario); !ok || payload.GetCameraId() != 7 {
+		t.Fatalf("unexpected envelope payload: %v", event.Payload)
+	}
+
+	if len(repo.sent) != 1 {
+		t.Fatalf("expected 1 record marked sent, got %d", len(repo.sent))
+	}
+	if len(repo.rescheduled) != 0 {
+		t.Fatalf("expected no reschedule on success")
+	}
+}
+
+func TestProcessScenarioOutboxMessagesPartialFailure(t *testing.T) {
+	delivered := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	rejected := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	repo := &fakeRepository{
+		pending: []outbox.OutboxScenario{
+			{OutboxUuid: delivered, EventType: "init_scenario", Payload: initPayload},
+			{OutboxUuid: rejected, EventType: "init_scenario", Payload: initPayload},
+		},
+	}
+	rejectErr := errors.New("message too large")
+	producer := &fakeProducer{err: fmt.Errorf("failed to send messages batch: %w", &kafka.BatchError{
+		Errors: []error{nil, rejectErr},
+	})}
+	uc := NewUseCase(repo, producer, RetryPolicy{MaxAttempts: 3})
+
+	processed, err := uc.ProcessScenarioOutboxMessages(context.Background(), "scenario", 10)
+	if err == nil {
+		t.Fatalf("expected error for partially failed batch")
+	}
+	if processed != 1 {
+		t.Fatalf("expected 1 delivered record, got %d", processed)
+	}
+
+	if len(repo.sent) != 1 || repo.sent[0] != delivered {
+		t.Fatalf("expected only delivered record marked sent, got %v", repo.sent)
+	}
+	if len(repo.rescheduled) != 1 {
+		t.Fatalf("expected one reschedule call, got %d", len(repo.rescheduled))
+	}
+	rescheduled := repo.rescheduled[0]
+	if len(rescheduled.OutboxUuids) != 1 || rescheduled.OutboxUuids[0] != rejected {
+		t.Fatalf("expected only rejected record rescheduled, got %v", rescheduled.OutboxUuids)
+	}
+	if rescheduled.LastError == nil || *rescheduled.LastError != rejectErr.Error() {
+		t.Fatalf("expected last error %q, got %v", rejectErr.Error(), rescheduled.LastError)
+	}
+}
+
+func TestProcessScenarioOutboxMessagesFailsRecordsNotMatchingSchema(t *testing.T) {
+	valid := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	invalid := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	repo := &fakeRepository{
+		pending: []outbox.OutboxScenario{
+			{OutboxUuid: invalid, EventType: "init_scenario", Payload: []byte(`{"scenario_id":"renamed","camera_id":7}`)},
+			{OutboxUuid: valid, EventType: "init_scenario", Payload: initPayload},
+		},
+	}
+	producer := &fakeProducer{}
+	uc := NewUseCase(repo, producer, RetryPolicy{MaxAttempts: 3})
+
+	processed, err := uc.ProcessScenarioOutboxMessages(context.Background(), "scenario", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if processed != 1 || len(producer.sent) != 1 {
+		t.Fatalf("expected only valid record sent, got processed=%d sent=%d", processed, len(producer.sent))
+	}
+	if len(repo.rescheduled) != 1 {
+		t.Fatalf("expected invalid record marked failed, got %d reschedule calls", len(repo.rescheduled))
+	}
+	failed := repo.rescheduled[0]
+	if failed.MaxAttempts != 0 || len(failed.OutboxUuids) != 1 || failed.OutboxUuids[0] != invalid {
+		t.Fatalf("expected invalid record failed without retries, got %+v", failed)
+	}
+}
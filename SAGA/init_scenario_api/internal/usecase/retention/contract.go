@@ -0,0 +1,28 @@
+package retention
+
+import (
+	"context"
+	"init_scenario_api/internal/infastructure/repository/queries/inbox"
+	"init_scenario_api/internal/infastructure/repository/queries/outbox"
+	genericOutbox "shared/outbox"
+	"time"
+)
+
+type Repository interface {
+	PurgeSentOutboxScenarios(ctx context.Context, arg outbox.PurgeSentOutboxScenariosParams) ([]outbox.OutboxScenario, error)
+	PurgeInboxScenarioResults(ctx context.Context, arg inbox.PurgeInboxScenarioResultsParams) ([]inbox.InboxScenarioResult, error)
+	WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error
+}
+
+type OutboxStore interface {
+	PurgeSent(ctx context.Context, olderThan time.Duration, batchSize int32) ([]genericOutbox.Event, error)
+}
+
+// Archiver сохраняет удаляемые строки таблицы до коммита удаления
+type Archiver interface {
+	Archive(ctx context.Context, table string, rows []any) error
+}
+
+type Metrics interface {
+	AddPurged(table string, rows int)
+}
@@ -0,0 +1,162 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"init_scenario_api/internal/infastructure/repository/queries/inbox"
+	"init_scenario_api/internal/infastructure/repository/queries/outbox"
+	"init_scenario_api/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	outboxScenarioTable      = "outbox_scenario"
+	inboxScenarioResultTable = "inbox_scenario_result"
+	genericOutboxTable       = "outbox"
+)
+
+type Config struct {
+	// OlderThan - возраст строки, после которого она удаляется
+	OlderThan time.Duration
+	BatchSize int32
+	// MaxBatches - ограничение количества батчей на таблицу за один вызов Purge
+	MaxBatches int
+	// BatchPause - пауза между батчами, чтобы очистка не забирала ресурсы базы у relay
+	BatchPause time.Duration
+}
+
+type UseCase struct {
+	repo     Repository
+	outbox   OutboxStore
+	archiver Archiver
+	metrics  Metrics
+	cfg      Config
+}
+
+// NewUseCase создает очистку. Если archiver равен nil, строки удаляются без архивации
+func NewUseCase(repo Repository, outbox OutboxStore, archiver Archiver, metrics Metrics, cfg Config) *UseCase {
+	return &UseCase{
+		repo:     repo,
+		outbox:   outbox,
+		archiver: archiver,
+		metrics:  metrics,
+		cfg:      cfg,
+	}
+}
+
+type purgeFunc func(ctx context.Context, olderThanSeconds, batchSize int32) ([]any, error)
+
+// Purge удаляет sent записи outbox_scenario и общего outbox и записи inbox старше cfg.OlderThan. Каждая таблица
+// чистится батчами по cfg.BatchSize строк, каждый батч в своей короткой транзакции, поэтому
+// блокировки держатся только на время одного DELETE. Возвращает количество удаленных строк.
+func (uc *UseCase) Purge(ctx context.Context) (int, error) {
+	tables := []struct {
+		name  string
+		purge purgeFunc
+	}{
+		{name: outboxScenarioTable, purge: uc.purgeOutbox},
+		{name: inboxScenarioResultTable, purge: uc.purgeInbox},
+		{name: genericOutboxTable, purge: uc.purgeGenericOutbox},
+	}
+
+	var total int
+	for _, table := range tables {
+		purged, err := uc.purgeTable(ctx, table.name, table.purge)
+		total += purged
+		if err != nil {
+			return total, fmt.Errorf("purge %s: %w", table.name, err)
+		}
+	}
+	return total, nil
+}
+
+func (uc *UseCase) purgeTable(ctx context.Context, table string, purge purgeFunc) (int, error) {
+	log := logger.FromContext(ctx).With(zap.String("table", table))
+	olderThanSeconds := int32(uc.cfg.OlderThan / time.Second)
+
+	var total int
+	for batch := 0; batch < uc.cfg.MaxBatches; batch++ {
+		if batch > 0 {
+			select {
+			case <-ctx.Done():
+				return total, ctx.Err()
+			case <-time.After(uc.cfg.BatchPause):
+			}
+		}
+
+		var purged int
+		// Архив пишется до коммита: если запись не удалась, удаление откатывается. Если не удался
+		// коммит, строки останутся в таблице и попадут в архив повторно на следующем проходе
+		err := uc.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+			rows, err := purge(txCtx, olderThanSeconds, uc.cfg.BatchSize)
+			if err != nil {
+				return err
+			}
+			if len(rows) > 0 && uc.archiver != nil {
+				if err := uc.archiver.Archive(txCtx, table, rows); err != nil {
+					return fmt.Errorf("archive rows: %w", err)
+				}
+			}
+			purged = len(rows)
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+
+		total += purged
+		uc.metrics.AddPurged(table, purged)
+
+		if purged < int(uc.cfg.BatchSize) {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Info("retention purged rows",
+			zap.Int("rows_purged", total),
+			zap.Bool("archived", uc.archiver != nil),
+		)
+	}
+	return total, nil
+}
+
+func (uc *UseCase) purgeOutbox(ctx context.Context, olderThanSeconds, batchSize int32) ([]any, error) {
+	records, err := uc.repo.PurgeSentOutboxScenarios(ctx, outbox.PurgeSentOutboxScenariosParams{
+		OlderThanSeconds: olderThanSeconds,
+		BatchSize:        batchSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("purge sent outbox scenarios: %w", err)
+	}
+	return toAny(records), nil
+}
+
+func (uc *UseCase) purgeInbox(ctx context.Context, olderThanSeconds, batchSize int32) ([]any, error) {
+	records, err := uc.repo.PurgeInboxScenarioResults(ctx, inbox.PurgeInboxScenarioResultsParams{
+		OlderThanSeconds: olderThanSeconds,
+		BatchSize:        batchSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("purge inbox scenario results: %w", err)
+	}
+	return toAny(records), nil
+}
+
+func (uc *UseCase) purgeGenericOutbox(ctx context.Context, olderThanSeconds, batchSize int32) ([]any, error) {
+	events, err := uc.outbox.PurgeSent(ctx, time.Duration(olderThanSeconds)*time.Second, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	return toAny(events), nil
+}
+
+func toAny[T any](records []T) []any {
+	rows := make([]any, len(records))
+	for i := range records {
+		rows[i] = records[i]
+	}
+	return rows
+}
@@ -0,0 +1,132 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"init_scenario_api/internal/infastructure/repository/queries/inbox"
+	"init_scenario_api/internal/infastructure/repository/queries/outbox"
+	genericOutbox "shared/outbox"
+)
+
+type fakeRepository struct {
+	// outboxLeft и inboxLeft - сколько строк каждой таблицы еще подлежит удалению
+	outboxLeft int
+	inboxLeft  int
+	calls      int
+}
+
+func (r *fakeRepository) PurgeSentOutboxScenarios(ctx context.Context, arg outbox.PurgeSentOutboxScenariosParams) ([]outbox.OutboxScenario, error) {
+	r.calls++
+	n := min(r.outboxLeft, int(arg.BatchSize))
+	r.outboxLeft -= n
+	return make([]outbox.OutboxScenario, n), nil
+}
+
+func (r *fakeRepository) PurgeInboxScenarioResults(ctx context.Context, arg inbox.PurgeInboxScenarioResultsParams) ([]inbox.InboxScenarioResult, error) {
+	r.calls++
+	n := min(r.inboxLeft, int(arg.BatchSize))
+	r.inboxLeft -= n
+	return make([]inbox.InboxScenarioResult, n), nil
+}
+
+func (r *fakeRepository) PurgeSent(ctx context.Context, olderThan time.Duration, batchSize int32) ([]genericOutbox.Event, error) {
+	r.calls++
+	return []genericOutbox.Event{}, nil
+}
+
+func (r *fakeRepository) WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error {
+	return tFunc(ctx)
+}
+
+type fakeArchiver struct {
+	err      error
+	archived map[string]int
+}
+
+func (a *fakeArchiver) Archive(ctx context.Context, table string, rows []any) error {
+	if a.err != nil {
+		return a.err
+	}
+	a.archived[table] += len(rows)
+	return nil
+}
+
+type fakeMetrics map[string]int
+
+func (m fakeMetrics) AddPurged(table string, rows int) {
+	m[table] += rows
+}
+
+func testConfig() Config {
+	return Config{OlderThan: time.Hour, BatchSize: 10, MaxBatches: 100}
+}
+
+func TestPurgeDeletesInBatchesUntilTableIsClean(t *testing.T) {
+	repo := &fakeRepository{outboxLeft: 25, inboxLeft: 10}
+	metrics := fakeMetrics{}
+	uc := NewUseCase(repo, repo, nil, metrics, testConfig())
+
+	purged, err := uc.Purge(context.Background())
+	if err != nil {
+		t.Fatalf("Purge returned error: %v", err)
+	}
+	if purged != 35 {
+		t.Fatalf("expected 35 purged rows, got %d", purged)
+	}
+	// outbox: 10, 10, 5; inbox: 10, 0; generic outbox: 0
+	if repo.calls != 6 {
+		t.Fatalf("expected 6 batches, got %d", repo.calls)
+	}
+	if metrics[outboxScenarioTable] != 25 || metrics[inboxScenarioResultTable] != 10 {
+		t.Fatalf("unexpected metrics: %v", metrics)
+	}
+}
+
+func TestPurgeStopsAtMaxBatches(t *testing.T) {
+	repo := &fakeRepository{outboxLeft: 100}
+	cfg := testConfig()
+	cfg.MaxBatches = 2
+	uc := NewUseCase(repo, repo, nil, fakeMetrics{}, cfg)
+
+	purged, err := uc.Purge(context.Background())
+	if err != nil {
+		t.Fatalf("Purge returned error: %v", err)
+	}
+	if purged != 20 {
+		t.Fatalf("expected 20 purged rows, got %d", purged)
+	}
+	if repo.outboxLeft != 80 {
+		t.Fatalf("expected 80 rows left for the next run, got %d", repo.outboxLeft)
+	}
+}
+
+func TestPurgeArchivesRowsBeforeCommit(t *testing.T) {
+	repo := &fakeRepository{outboxLeft: 3, inboxLeft: 2}
+	archiver := &fakeArchiver{archived: map[string]int{}}
+	uc := NewUseCase(repo, repo, archiver, fakeMetrics{}, testConfig())
+
+	if _, err := uc.Purge(context.Background()); err != nil {
+		t.Fatalf("Purge returned error: %v", err)
+	}
+	if archiver.archived[outboxScenarioTable] != 3 || archiver.archived[inboxScenarioResultTable] != 2 {
+		t.Fatalf("unexpected archived rows: %v", archiver.archived)
+	}
+}
+
+func TestPurgeFailsWhenArchiveFails(t *testing.T) {
+	repo := &fakeRepository{outboxLeft: 3}
+	archiveErr := errors.New("disk full")
+	metrics := fakeMetrics{}
+	uc := NewUseCase(repo, repo, &fakeArchiver{err: archiveErr}, metrics, testConfig())
+
+	_, err := uc.Purge(context.Background())
+	if !errors.Is(err, archiveErr) {
+		t.Fatalf("expected archive error, got %v", err)
+	}
+	if metrics[outboxScenarioTable] != 0 {
+		t.Fatalf("rows must not be counted as purged when archive fails, got %d", metrics[outboxScenarioTable])
+	}
+}
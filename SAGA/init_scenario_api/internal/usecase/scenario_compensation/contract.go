@@ -0,0 +1,14 @@
+package scenario_compensation
+
+import (
+	"context"
+	"init_scenario_api/internal/infastructure/repository/queries/outbox"
+	"init_scenario_api/internal/infastructure/repository/queries/scenario"
+)
+
+type Repository interface {
+	GetStuckStartupScenarios(ctx context.Context, arg scenario.GetStuckStartupScenariosParams) ([]scenario.Scenario, error)
+	FailScenarioStartup(ctx context.Context, arg scenario.FailScenarioStartupParams) error
+	CreateOutboxScenario(ctx context.Context, arg outbox.CreateOutboxScenarioParams) (outbox.OutboxScenario, error)
+	WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error
+}
@@ -0,0 +1,107 @@
+package scenario_compensation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"init_scenario_api/internal/infastructure/repository/queries/outbox"
+	"init_scenario_api/internal/infastructure/repository/queries/scenario"
+	"init_scenario_api/internal/models/entity"
+	"init_scenario_api/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+type UseCase struct {
+	repo Repository
+}
+
+func NewUseCase(repo Repository) *UseCase {
+	return &UseCase{
+		repo: repo,
+	}
+}
+
+// CompensateScenario переводит сценарий, который еще запускается, в статус start_failed с причиной
+// ошибки и создает outbox событие compensate_scenario, освобождающее ресурсы runner'а.
+// Для сценария вне статусов запуска статус не меняется, но событие компенсации все равно создается:
+// так runner остановит воркер, подтверждение запуска которого пришло слишком поздно.
+// Если ctx содержит транзакцию, все изменения попадают в нее.
+func (uc *UseCase) CompensateScenario(ctx context.Context, scenarioDB scenario.Scenario, reason string) error {
+	log := logger.FromContext(ctx).With(
+		zap.String("scenario_uuid", uuid.UUID(scenarioDB.Uuid.Bytes).String()),
+		zap.String("reason", reason),
+	)
+
+	return uc.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+		if scenarioDB.Status != nil && entity.IsStartupStatus(*scenarioDB.Status) {
+			if err := uc.repo.FailScenarioStartup(txCtx, scenario.FailScenarioStartupParams{
+				Uuid:          scenarioDB.Uuid,
+				FailureReason: &reason,
+			}); err != nil {
+				return fmt.Errorf("fail scenario startup: %w", err)
+			}
+		}
+
+		payload := entity.NewCompensateScenarioPayload(scenarioDB.Uuid, scenarioDB.CameraID, reason)
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("marshal payload: %w", err)
+		}
+
+		if _, err := uc.repo.CreateOutboxScenario(txCtx, outbox.CreateOutboxScenarioParams{
+			OutboxUuid:   pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			ScenarioUuid: scenarioDB.Uuid,
+			Payload:      payloadBytes,
+			EventType:    entity.OutboxEventCompensateScenario,
+			PartitionKey: entity.OutboxPartitionKey(scenarioDB.CameraID),
+		}); err != nil {
+			return fmt.Errorf("create outbox scenario: %w", err)
+		}
+
+		log.Info("scenario compensated")
+		return nil
+	})
+}
+
+// SweepStuckScenarios компенсирует до batchSize сценариев, которые находятся в статусе запуска
+// дольше timeout. Возвращает количество скомпенсированных сценариев.
+func (uc *UseCase) SweepStuckScenarios(ctx context.Context, timeout time.Duration, batchSize int32) (int, error) {
+	log := logger.FromContext(ctx)
+
+	reason := fmt.Sprintf("startup timeout exceeded (%s)", timeout)
+
+	var compensated int
+	err := uc.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+		stuck, err := uc.repo.GetStuckStartupScenarios(txCtx, scenario.GetStuckStartupScenariosParams{
+			Statuses:       entity.StartupStatuses,
+			TimeoutSeconds: int32(timeout / time.Second),
+			BatchLimit:     batchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("get stuck startup scenarios: %w", err)
+		}
+
+		for _, scenarioDB := range stuck {
+			if err := uc.CompensateScenario(txCtx, scenarioDB, reason); err != nil {
+				return err
+			}
+		}
+
+		compensated = len(stuck)
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to sweep stuck scenarios", zap.Error(err))
+		return 0, fmt.Errorf("sweep stuck scenarios: %w", err)
+	}
+
+	if compensated > 0 {
+		log.Info("stuck scenarios compensated", zap.Int("count", compensated))
+	}
+
+	return compensated, nil
+}
@@ -0,0 +1,133 @@
+package scenario_compensation
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"init_scenario_api/internal/infastructure/repository/queries/outbox"
+	"init_scenario_api/internal/infastructure/repository/queries/scenario"
+	"init_scenario_api/internal/models/entity"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fakeRepository запоминает проваленные запуски и созданные outbox события. stuck - сценарии,
+// которые вернет GetStuckStartupScenarios
+type fakeRepository struct {
+	stuck       []scenario.Scenario
+	stuckParams scenario.GetStuckStartupScenariosParams
+
+	failed map[pgtype.UUID]string
+	events []outbox.CreateOutboxScenarioParams
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{failed: make(map[pgtype.UUID]string)}
+}
+
+func (r *fakeRepository) GetStuckStartupScenarios(ctx context.Context, arg scenario.GetStuckStartupScenariosParams) ([]scenario.Scenario, error) {
+	r.stuckParams = arg
+	return r.stuck, nil
+}
+
+func (r *fakeRepository) FailScenarioStartup(ctx context.Context, arg scenario.FailScenarioStartupParams) error {
+	r.failed[arg.Uuid] = *arg.FailureReason
+	return nil
+}
+
+func (r *fakeRepository) CreateOutboxScenario(ctx context.Context, arg outbox.CreateOutboxScenarioParams) (outbox.OutboxScenario, error) {
+	r.events = append(r.events, arg)
+	return outbox.OutboxScenario{OutboxUuid: arg.OutboxUuid, ScenarioUuid: arg.ScenarioUuid}, nil
+}
+
+func (r *fakeRepository) WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error {
+	return tFunc(ctx)
+}
+
+func newScenario(status string) scenario.Scenario {
+	return scenario.Scenario{Uuid: pgtype.UUID{Bytes: uuid.New(), Valid: true}, CameraID: 7, Status: &status}
+}
+
+// compensationReason проверяет, что событие - compensate_scenario сценария scenarioDB, и возвращает его причину
+func compensationReason(t *testing.T, event outbox.CreateOutboxScenarioParams, scenarioDB scenario.Scenario) string {
+	t.Helper()
+	if event.EventType != entity.OutboxEventCompensateScenario || event.ScenarioUuid != scenarioDB.Uuid {
+		t.Fatalf("expected compensate_scenario of %v, got %s of %v", scenarioDB.Uuid, event.EventType, event.ScenarioUuid)
+	}
+	if event.PartitionKey != entity.OutboxPartitionKey(scenarioDB.CameraID) {
+		t.Fatalf("expected partition key of camera %d, got %s", scenarioDB.CameraID, event.PartitionKey)
+	}
+	var payload entity.CompensateScenarioPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	return payload.Reason
+}
+
+func TestCompensateScenario(t *testing.T) {
+	cases := map[string]struct {
+		status     string
+		wantFailed bool
+	}{
+		"waiting for relay":   {status: entity.StatusInitStartup, wantFailed: true},
+		"waiting for runner":  {status: entity.StatusInStartupProcessing, wantFailed: true},
+		"already compensated": {status: entity.StatusStartFailed},
+		"already active":      {status: entity.StatusActive},
+	}
+
+	for name, tc := range cases {
+		repo := newFakeRepository()
+		scenarioDB := newScenario(tc.status)
+
+		if err := NewUseCase(repo).CompensateScenario(context.Background(), scenarioDB, "no alive runners"); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+
+		reason, failed := repo.failed[scenarioDB.Uuid]
+		if failed != tc.wantFailed {
+			t.Fatalf("%s: expected start_failed %v, got %v", name, tc.wantFailed, failed)
+		}
+		if failed && reason != "no alive runners" {
+			t.Fatalf("%s: unexpected failure reason %q", name, reason)
+		}
+		// Событие компенсации создается в любом статусе: runner должен остановить воркер
+		if len(repo.events) != 1 {
+			t.Fatalf("%s: expected one compensate_scenario event, got %d", name, len(repo.events))
+		}
+		if reason := compensationReason(t, repo.events[0], scenarioDB); reason != "no alive runners" {
+			t.Fatalf("%s: unexpected compensation reason %q", name, reason)
+		}
+	}
+}
+
+func TestSweepStuckScenarios(t *testing.T) {
+	repo := newFakeRepository()
+	repo.stuck = []scenario.Scenario{newScenario(entity.StatusInitStartup), newScenario(entity.StatusInStartupProcessing)}
+
+	compensated, err := NewUseCase(repo).SweepStuckScenarios(context.Background(), 5*time.Minute, 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if compensated != 2 {
+		t.Fatalf("expected 2 compensated scenarios, got %d", compensated)
+	}
+
+	if !slices.Equal(repo.stuckParams.Statuses, entity.StartupStatuses) ||
+		repo.stuckParams.TimeoutSeconds != 300 || repo.stuckParams.BatchLimit != 100 {
+		t.Fatalf("unexpected stuck scenarios query: %+v", repo.stuckParams)
+	}
+
+	want := "startup timeout exceeded (5m0s)"
+	for i, scenarioDB := range repo.stuck {
+		if repo.failed[scenarioDB.Uuid] != want {
+			t.Fatalf("scenario %d: expected failure reason %q, got %q", i, want, repo.failed[scenarioDB.Uuid])
+		}
+		if reason := compensationReason(t, repo.events[i], scenarioDB); reason != want {
+			t.Fatalf("scenario %d: unexpected compensation reason %q", i, reason)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package scenario_result_processor
+
+import (
+	"context"
+	"init_scenario_api/internal/infastructure/repository/queries/inbox"
+	"init_scenario_api/internal/infastructure/repository/queries/scenario"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Repository interface {
+	CreateInboxScenarioResult(ctx context.Context, arg inbox.CreateInboxScenarioResultParams) (inbox.InboxScenarioResult, error)
+	GetScenarioByUUIDForUpdate(ctx context.Context, uuid pgtype.UUID) (scenario.Scenario, error)
+	UpdateScenarioStatusByUUID(ctx context.Context, arg scenario.UpdateScenarioStatusByUUIDParams) error
+	WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error
+}
+
+type Compensator interface {
+	CompensateScenario(ctx context.Context, scenarioDB scenario.Scenario, reason string) error
+}
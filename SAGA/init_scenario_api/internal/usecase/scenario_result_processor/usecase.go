@@ -0,0 +1,159 @@
+package scenario_result_processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"init_scenario_api/internal/infastructure/repository/queries/inbox"
+	"init_scenario_api/internal/infastructure/repository/queries/scenario"
+	"init_scenario_api/internal/models/entity"
+	modelerror "init_scenario_api/internal/models/error"
+	"init_scenario_api/internal/models/kafka"
+	"init_scenario_api/pkg/logger"
+	"shared/envelope"
+	eventspb "shared/proto/events/v1"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// lateStartReason - причина компенсации, если runner подтвердил запуск уже скомпенсированного сценария
+const lateStartReason = "scenario started after startup was compensated"
+
+type UseCase struct {
+	repo        Repository
+	compensator Compensator
+}
+
+func NewUseCase(repo Repository, compensator Compensator) *UseCase {
+	return &UseCase{
+		repo:        repo,
+		compensator: compensator,
+	}
+}
+
+// ProcessScenarioResult сохраняет событие в inbox и в той же транзакции применяет его к сценарию:
+// scenario_started переводит сценарий в active, scenario_start_failed - в start_failed с компенсацией,
+// scenario_stopped переводит останавливаемый сценарий в inactive.
+// Повторная доставка того же сообщения (duplicate key) не считается ошибкой. Событие, которое нельзя
+// применить ни при какой попытке (неизвестный тип, неверный payload, несуществующий сценарий),
+// возвращается как ErrInvalidMessage.
+func (uc *UseCase) ProcessScenarioResult(ctx context.Context, outboxUUID uuid.UUID, event envelope.Event) error {
+	eventType := event.Type
+	log := logger.FromContext(ctx).With(
+		zap.String("outbox_uuid", outboxUUID.String()),
+		zap.String("event_type", eventType),
+	)
+
+	switch eventType {
+	case kafka.EventTypeScenarioStarted, kafka.EventTypeScenarioStartFailed, kafka.EventTypeScenarioStopped:
+	default:
+		return fmt.Errorf("%w: unknown event type: %s", modelerror.ErrInvalidMessage, eventType)
+	}
+
+	payload, ok := event.Payload.(*eventspb.ScenarioResult)
+	if !ok {
+		return fmt.Errorf("%w: unexpected payload %T of event %s", modelerror.ErrInvalidMessage, event.Payload, eventType)
+	}
+	scenarioUUID, err := uuid.Parse(payload.GetScenarioUuid())
+	if err != nil {
+		return fmt.Errorf("%w: parse scenario_uuid: %v", modelerror.ErrInvalidMessage, err)
+	}
+
+	// В inbox payload хранится в JSON, как в событиях старого формата
+	value, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	log = log.With(
+		zap.String("scenario_uuid", scenarioUUID.String()),
+		zap.Int32("camera_id", payload.GetCameraId()),
+		zap.Uint32("schema_version", event.Version.Major),
+	)
+	if payload.GetError() != "" {
+		log = log.With(zap.String("reason", payload.GetError()))
+	}
+
+	err = uc.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+		_, err := uc.repo.CreateInboxScenarioResult(txCtx, inbox.CreateInboxScenarioResultParams{
+			OutboxUuid:   pgtype.UUID{Bytes: outboxUUID, Valid: true},
+			ScenarioUuid: pgtype.UUID{Bytes: scenarioUUID, Valid: true},
+			EventType:    eventType,
+			Payload:      value,
+		})
+		if err != nil {
+			return fmt.Errorf("create inbox scenario result: %w", err)
+		}
+
+		scenarioDB, err := uc.repo.GetScenarioByUUIDForUpdate(txCtx, pgtype.UUID{Bytes: scenarioUUID, Valid: true})
+		if err != nil {
+			return fmt.Errorf("get scenario: %w", err)
+		}
+
+		var status string
+		if scenarioDB.Status != nil {
+			status = *scenarioDB.Status
+		}
+
+		switch {
+		case eventType == kafka.EventTypeScenarioStarted && entity.IsStartupStatus(status):
+			active := entity.StatusActive
+			if err := uc.repo.UpdateScenarioStatusByUUID(txCtx, scenario.UpdateScenarioStatusByUUIDParams{
+				Uuid:   scenarioDB.Uuid,
+				Status: &active,
+			}); err != nil {
+				return fmt.Errorf("update scenario status: %w", err)
+			}
+			log.Info("scenario status updated", zap.String("status", active))
+
+		case eventType == kafka.EventTypeScenarioStarted && status == entity.StatusStartFailed:
+			// Запуск уже скомпенсирован (например, по таймауту), а воркер все-таки поднялся - освобождаем его
+			log.Warn("scenario started after compensation, compensating again")
+			if err := uc.compensator.CompensateScenario(txCtx, scenarioDB, lateStartReason); err != nil {
+				return fmt.Errorf("compensate scenario: %w", err)
+			}
+
+		case eventType == kafka.EventTypeScenarioStartFailed && entity.IsStartupStatus(status):
+			reason := payload.GetError()
+			if reason == "" {
+				reason = "runner failed to start scenario"
+			}
+			if err := uc.compensator.CompensateScenario(txCtx, scenarioDB, reason); err != nil {
+				return fmt.Errorf("compensate scenario: %w", err)
+			}
+
+		case eventType == kafka.EventTypeScenarioStopped && entity.IsShutdownStatus(status):
+			// Причина в событии означает, что runner не остановил воркер: камера все равно освобождена,
+			// а воркер будет отозван по fencing token'у следующего размещения
+			inactive := entity.StatusInactive
+			if err := uc.repo.UpdateScenarioStatusByUUID(txCtx, scenario.UpdateScenarioStatusByUUIDParams{
+				Uuid:   scenarioDB.Uuid,
+				Status: &inactive,
+			}); err != nil {
+				return fmt.Errorf("update scenario status: %w", err)
+			}
+			log.Info("scenario status updated", zap.String("status", inactive))
+
+		default:
+			// Например, scenario_stopped после компенсации: сценарий остается в start_failed
+			log.Warn("result does not apply to scenario status, ignored", zap.String("status", status))
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, modelerror.ErrDuplicateKey) {
+			log.Warn("message already processed (duplicate key), skipping")
+			return nil
+		}
+		if errors.Is(err, modelerror.ErrNotFound) {
+			return fmt.Errorf("%w: scenario %s not found", modelerror.ErrInvalidMessage, scenarioUUID)
+		}
+		return err
+	}
+
+	return nil
+}
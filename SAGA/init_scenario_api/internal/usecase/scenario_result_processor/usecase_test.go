@@ -0,0 +1,214 @@
+package scenario_result_processor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"init_scenario_api/internal/infastructure/repository/queries/inbox"
+	"init_scenario_api/internal/infastructure/repository/queries/scenario"
+	"init_scenario_api/internal/models/entity"
+	modelerror "init_scenario_api/internal/models/error"
+	"init_scenario_api/internal/models/kafka"
+	"shared/envelope"
+	eventspb "shared/proto/events/v1"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fakeRepository хранит inbox и статусы сценариев в памяти. Как и таблица inbox_scenario_result,
+// inbox отклоняет повторный outbox_uuid и результат несуществующего сценария
+type fakeRepository struct {
+	inbox     map[pgtype.UUID]inbox.CreateInboxScenarioResultParams
+	scenarios map[pgtype.UUID]scenario.Scenario
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{
+		inbox:     make(map[pgtype.UUID]inbox.CreateInboxScenarioResultParams),
+		scenarios: make(map[pgtype.UUID]scenario.Scenario),
+	}
+}
+
+func (r *fakeRepository) addScenario(status string) scenario.Scenario {
+	created := scenario.Scenario{Uuid: pgtype.UUID{Bytes: uuid.New(), Valid: true}, CameraID: 7, Status: &status}
+	r.scenarios[created.Uuid] = created
+	return created
+}
+
+func (r *fakeRepository) status(scenarioUUID pgtype.UUID) string {
+	return *r.scenarios[scenarioUUID].Status
+}
+
+func (r *fakeRepository) CreateInboxScenarioResult(ctx context.Context, arg inbox.CreateInboxScenarioResultParams) (inbox.InboxScenarioResult, error) {
+	if _, ok := r.inbox[arg.OutboxUuid]; ok {
+		return inbox.InboxScenarioResult{}, modelerror.ErrDuplicateKey
+	}
+	if _, ok := r.scenarios[arg.ScenarioUuid]; !ok {
+		return inbox.InboxScenarioResult{}, modelerror.ErrNotFound
+	}
+	r.inbox[arg.OutboxUuid] = arg
+	return inbox.InboxScenarioResult{OutboxUuid: arg.OutboxUuid, ScenarioUuid: arg.ScenarioUuid}, nil
+}
+
+func (r *fakeRepository) GetScenarioByUUIDForUpdate(ctx context.Context, scenarioUUID pgtype.UUID) (scenario.Scenario, error) {
+	stored, ok := r.scenarios[scenarioUUID]
+	if !ok {
+		return scenario.Scenario{}, modelerror.ErrNotFound
+	}
+	return stored, nil
+}
+
+func (r *fakeRepository) UpdateScenarioStatusByUUID(ctx context.Context, arg scenario.UpdateScenarioStatusByUUIDParams) error {
+	stored := r.scenarios[arg.Uuid]
+	stored.Status = arg.Status
+	r.scenarios[arg.Uuid] = stored
+	return nil
+}
+
+func (r *fakeRepository) WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error {
+	return tFunc(ctx)
+}
+
+// fakeCompensator переводит сценарий в start_failed, как scenario_compensation, и запоминает причины
+type fakeCompensator struct {
+	repo    *fakeRepository
+	reasons []string
+}
+
+func (c *fakeCompensator) CompensateScenario(ctx context.Context, scenarioDB scenario.Scenario, reason string) error {
+	c.reasons = append(c.reasons, reason)
+	if entity.IsStartupStatus(*scenarioDB.Status) {
+		failed := entity.StatusStartFailed
+		return c.repo.UpdateScenarioStatusByUUID(ctx, scenario.UpdateScenarioStatusByUUIDParams{Uuid: scenarioDB.Uuid, Status: &failed})
+	}
+	return nil
+}
+
+func newTestUseCase() (*UseCase, *fakeRepository, *fakeCompensator) {
+	repo := newFakeRepository()
+	compensator := &fakeCompensator{repo: repo}
+	return NewUseCase(repo, compensator), repo, compensator
+}
+
+func resultEvent(eventType string, scenarioDB scenario.Scenario, reason string) envelope.Event {
+	return envelope.Event{
+		Type:    eventType,
+		Version: envelope.Version{Major: 1},
+		Payload: &eventspb.ScenarioResult{
+			ScenarioUuid: uuid.UUID(scenarioDB.Uuid.Bytes).String(),
+			CameraId:     scenarioDB.CameraID,
+			Error:        reason,
+		},
+	}
+}
+
+func TestProcessScenarioResult(t *testing.T) {
+	cases := map[string]struct {
+		status    string
+		eventType string
+		reason    string
+
+		wantStatus      string
+		wantCompensated string
+	}{
+		"started":                         {status: entity.StatusInStartupProcessing, eventType: kafka.EventTypeScenarioStarted, wantStatus: entity.StatusActive},
+		"started before relay ack":        {status: entity.StatusInitStartup, eventType: kafka.EventTypeScenarioStarted, wantStatus: entity.StatusActive},
+		"start failed":                    {status: entity.StatusInStartupProcessing, eventType: kafka.EventTypeScenarioStartFailed, reason: "no alive runners", wantStatus: entity.StatusStartFailed, wantCompensated: "no alive runners"},
+		"start failed without reason":     {status: entity.StatusInStartupProcessing, eventType: kafka.EventTypeScenarioStartFailed, wantStatus: entity.StatusStartFailed, wantCompensated: "runner failed to start scenario"},
+		"started after compensation":      {status: entity.StatusStartFailed, eventType: kafka.EventTypeScenarioStarted, wantStatus: entity.StatusStartFailed, wantCompensated: lateStartReason},
+		"start failed after compensation": {status: entity.StatusStartFailed, eventType: kafka.EventTypeScenarioStartFailed, wantStatus: entity.StatusStartFailed},
+		"started while stopping":          {status: entity.StatusInitShutdown, eventType: kafka.EventTypeScenarioStarted, wantStatus: entity.StatusInitShutdown},
+		"stopped":                         {status: entity.StatusInShutdownProcessing, eventType: kafka.EventTypeScenarioStopped, wantStatus: entity.StatusInactive},
+		"stopped before relay ack":        {status: entity.StatusInitShutdown, eventType: kafka.EventTypeScenarioStopped, wantStatus: entity.StatusInactive},
+		"stopped, worker not removed":     {status: entity.StatusInShutdownProcessing, eventType: kafka.EventTypeScenarioStopped, reason: "unavailable", wantStatus: entity.StatusInactive},
+		"stopped after compensation":      {status: entity.StatusStartFailed, eventType: kafka.EventTypeScenarioStopped, wantStatus: entity.StatusStartFailed},
+	}
+
+	for name, tc := range cases {
+		uc, repo, compensator := newTestUseCase()
+		scenarioDB := repo.addScenario(tc.status)
+
+		if err := uc.ProcessScenarioResult(context.Background(), uuid.New(), resultEvent(tc.eventType, scenarioDB, tc.reason)); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+
+		if status := repo.status(scenarioDB.Uuid); status != tc.wantStatus {
+			t.Fatalf("%s: expected status %s, got %s", name, tc.wantStatus, status)
+		}
+		switch {
+		case tc.wantCompensated == "" && len(compensator.reasons) != 0:
+			t.Fatalf("%s: expected no compensation, got %v", name, compensator.reasons)
+		case tc.wantCompensated != "" && (len(compensator.reasons) != 1 || compensator.reasons[0] != tc.wantCompensated):
+			t.Fatalf("%s: expected compensation %q, got %v", name, tc.wantCompensated, compensator.reasons)
+		}
+	}
+}
+
+func TestProcessScenarioResultLateStartAfterCompensation(t *testing.T) {
+	uc, repo, compensator := newTestUseCase()
+	scenarioDB := repo.addScenario(entity.StatusInStartupProcessing)
+
+	err := uc.ProcessScenarioResult(context.Background(), uuid.New(), resultEvent(kafka.EventTypeScenarioStartFailed, scenarioDB, "worker crashed"))
+	if err != nil {
+		t.Fatalf("start failed: unexpected error: %v", err)
+	}
+	err = uc.ProcessScenarioResult(context.Background(), uuid.New(), resultEvent(kafka.EventTypeScenarioStarted, scenarioDB, ""))
+	if err != nil {
+		t.Fatalf("late start: unexpected error: %v", err)
+	}
+
+	if status := repo.status(scenarioDB.Uuid); status != entity.StatusStartFailed {
+		t.Fatalf("expected scenario to stay start_failed, got %s", status)
+	}
+	if len(compensator.reasons) != 2 || compensator.reasons[1] != lateStartReason {
+		t.Fatalf("expected late start to be compensated again, got %v", compensator.reasons)
+	}
+}
+
+func TestProcessScenarioResultSkipsDuplicateDelivery(t *testing.T) {
+	uc, repo, compensator := newTestUseCase()
+	scenarioDB := repo.addScenario(entity.StatusInStartupProcessing)
+	outboxUUID := uuid.New()
+	event := resultEvent(kafka.EventTypeScenarioStartFailed, scenarioDB, "no alive runners")
+
+	for delivery := 1; delivery <= 2; delivery++ {
+		if err := uc.ProcessScenarioResult(context.Background(), outboxUUID, event); err != nil {
+			t.Fatalf("delivery %d: unexpected error: %v", delivery, err)
+		}
+	}
+
+	if len(repo.inbox) != 1 {
+		t.Fatalf("expected one inbox record, got %d", len(repo.inbox))
+	}
+	if len(compensator.reasons) != 1 {
+		t.Fatalf("expected duplicate delivery to be skipped, got compensations %v", compensator.reasons)
+	}
+}
+
+func TestProcessScenarioResultRejectsInvalidMessage(t *testing.T) {
+	uc, repo, _ := newTestUseCase()
+	scenarioDB := repo.addScenario(entity.StatusInStartupProcessing)
+
+	unknownScenario := resultEvent(kafka.EventTypeScenarioStarted, scenarioDB, "")
+	unknownScenario.Payload = &eventspb.ScenarioResult{ScenarioUuid: uuid.NewString(), CameraId: 7}
+
+	cases := map[string]envelope.Event{
+		"unknown event type": resultEvent("scenario_paused", scenarioDB, ""),
+		"unexpected payload": {Type: kafka.EventTypeScenarioStarted, Payload: &eventspb.StopScenario{}},
+		"invalid uuid":       {Type: kafka.EventTypeScenarioStarted, Payload: &eventspb.ScenarioResult{ScenarioUuid: "not-a-uuid"}},
+		"unknown scenario":   unknownScenario,
+	}
+
+	for name, event := range cases {
+		err := uc.ProcessScenarioResult(context.Background(), uuid.New(), event)
+		if !errors.Is(err, modelerror.ErrInvalidMessage) {
+			t.Fatalf("%s: expected ErrInvalidMessage, got %v", name, err)
+		}
+	}
+
+	if status := repo.status(scenarioDB.Uuid); status != entity.StatusInStartupProcessing {
+		t.Fatalf("expected invalid messages to leave the scenario unchanged, got %s", status)
+	}
+}
@@ -0,0 +1,16 @@
+package stop_scenario
+
+import (
+	"context"
+	"init_scenario_api/internal/infastructure/repository/queries/outbox"
+	"init_scenario_api/internal/infastructure/repository/queries/scenario"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Repository interface {
+	GetScenarioByUUIDForUpdate(ctx context.Context, uuid pgtype.UUID) (scenario.Scenario, error)
+	UpdateScenarioStatusByUUID(ctx context.Context, arg scenario.UpdateScenarioStatusByUUIDParams) error
+	CreateOutboxScenario(ctx context.Context, arg outbox.CreateOutboxScenarioParams) (outbox.OutboxScenario, error)
+	WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error
+}
@@ -0,0 +1,108 @@
+package stop_scenario
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"init_scenario_api/internal/infastructure/repository/queries/outbox"
+	"init_scenario_api/internal/infastructure/repository/queries/scenario"
+	"init_scenario_api/internal/models/convert"
+	"init_scenario_api/internal/models/dto"
+	"init_scenario_api/internal/models/entity"
+	modelerror "init_scenario_api/internal/models/error"
+	"init_scenario_api/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+type UseCase struct {
+	repo Repository
+}
+
+func NewUseCase(repo Repository) *UseCase {
+	return &UseCase{
+		repo: repo,
+	}
+}
+
+// StopScenario переводит сценарий в статус init_shutdown и в той же транзакции
+// создает outbox событие stop_scenario. Повторный вызов для сценария, который
+// уже останавливается или остановлен, не создает новых событий.
+func (uc *UseCase) StopScenario(ctx context.Context, scenarioUUID uuid.UUID) (*dto.StopScenarioResponse, error) {
+	log := logger.FromContext(ctx).With(zap.String("scenario_uuid", scenarioUUID.String()))
+
+	log.Info("stopping scenario")
+
+	var result *dto.StopScenarioResponse
+	err := uc.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+		scenarioDB, err := uc.repo.GetScenarioByUUIDForUpdate(txCtx, pgtype.UUID{Bytes: scenarioUUID, Valid: true})
+		if err != nil {
+			return fmt.Errorf("get scenario: %w", err)
+		}
+
+		scenarioEntity := convert.ScenarioFromDB(scenarioDB)
+
+		switch scenarioEntity.Status {
+		case entity.StatusInitShutdown, entity.StatusInShutdownProcessing, entity.StatusInactive:
+			log.Info("scenario is already stopping", zap.String("status", scenarioEntity.Status))
+			result = &dto.StopScenarioResponse{
+				ScenarioUUID: scenarioEntity.UUID.String(),
+				Status:       scenarioEntity.Status,
+			}
+			return nil
+		case entity.StatusInitStartup, entity.StatusInStartupProcessing, entity.StatusActive:
+		default:
+			return fmt.Errorf("%w: %s", modelerror.ErrScenarioNotStoppable, scenarioEntity.Status)
+		}
+
+		status := entity.StatusInitShutdown
+		if err := uc.repo.UpdateScenarioStatusByUUID(txCtx, scenario.UpdateScenarioStatusByUUIDParams{
+			Uuid:   scenarioDB.Uuid,
+			Status: &status,
+		}); err != nil {
+			log.Error("failed to update scenario status", zap.Error(err))
+			return fmt.Errorf("update scenario status: %w", err)
+		}
+
+		payload := entity.NewStopScenarioPayload(scenarioDB.Uuid, scenarioDB.CameraID)
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			log.Error("failed to marshal payload", zap.Error(err))
+			return fmt.Errorf("marshal payload: %w", err)
+		}
+
+		createdOutboxDB, err := uc.repo.CreateOutboxScenario(txCtx, outbox.CreateOutboxScenarioParams{
+			OutboxUuid:   pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			ScenarioUuid: scenarioDB.Uuid,
+			Payload:      payloadBytes,
+			EventType:    entity.OutboxEventStopScenario,
+			PartitionKey: entity.OutboxPartitionKey(scenarioDB.CameraID),
+		})
+		if err != nil {
+			log.Error("failed to create outbox scenario", zap.Error(err))
+			return fmt.Errorf("create outbox scenario: %w", err)
+		}
+
+		log.Info("outbox scenario created",
+			zap.String("outbox_uuid", convert.OutboxScenarioFromDB(createdOutboxDB).OutboxUUID.String()),
+			zap.String("event_type", entity.OutboxEventStopScenario),
+		)
+
+		result = &dto.StopScenarioResponse{
+			ScenarioUUID: scenarioEntity.UUID.String(),
+			Status:       status,
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Error("transaction failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction failed: %w", err)
+	}
+
+	log.Info("scenario stop requested", zap.String("status", result.Status))
+	return result, nil
+}
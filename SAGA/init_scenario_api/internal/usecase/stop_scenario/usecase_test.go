@@ -0,0 +1,110 @@
+package stop_scenario
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"init_scenario_api/internal/infastructure/repository/queries/outbox"
+	"init_scenario_api/internal/infastructure/repository/queries/scenario"
+	"init_scenario_api/internal/models/entity"
+	modelerror "init_scenario_api/internal/models/error"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fakeRepository хранит сценарии в памяти и запоминает созданные outbox события
+type fakeRepository struct {
+	scenarios map[pgtype.UUID]scenario.Scenario
+	events    []outbox.CreateOutboxScenarioParams
+}
+
+func (r *fakeRepository) GetScenarioByUUIDForUpdate(ctx context.Context, scenarioUUID pgtype.UUID) (scenario.Scenario, error) {
+	stored, ok := r.scenarios[scenarioUUID]
+	if !ok {
+		return scenario.Scenario{}, modelerror.ErrNotFound
+	}
+	return stored, nil
+}
+
+func (r *fakeRepository) UpdateScenarioStatusByUUID(ctx context.Context, arg scenario.UpdateScenarioStatusByUUIDParams) error {
+	stored := r.scenarios[arg.Uuid]
+	stored.Status = arg.Status
+	r.scenarios[arg.Uuid] = stored
+	return nil
+}
+
+func (r *fakeRepository) CreateOutboxScenario(ctx context.Context, arg outbox.CreateOutboxScenarioParams) (outbox.OutboxScenario, error) {
+	r.events = append(r.events, arg)
+	return outbox.OutboxScenario{OutboxUuid: arg.OutboxUuid, ScenarioUuid: arg.ScenarioUuid}, nil
+}
+
+func (r *fakeRepository) WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error {
+	return tFunc(ctx)
+}
+
+func TestStopScenario(t *testing.T) {
+	cases := map[string]struct {
+		status string
+
+		wantStatus string
+		wantEvent  bool
+		wantErr    error
+	}{
+		"starting":          {status: entity.StatusInitStartup, wantStatus: entity.StatusInitShutdown, wantEvent: true},
+		"waiting for start": {status: entity.StatusInStartupProcessing, wantStatus: entity.StatusInitShutdown, wantEvent: true},
+		"active":            {status: entity.StatusActive, wantStatus: entity.StatusInitShutdown, wantEvent: true},
+		"already stopping":  {status: entity.StatusInitShutdown, wantStatus: entity.StatusInitShutdown},
+		"stop sent":         {status: entity.StatusInShutdownProcessing, wantStatus: entity.StatusInShutdownProcessing},
+		"already stopped":   {status: entity.StatusInactive, wantStatus: entity.StatusInactive},
+		"start failed":      {status: entity.StatusStartFailed, wantStatus: entity.StatusStartFailed, wantErr: modelerror.ErrScenarioNotStoppable},
+	}
+
+	for name, tc := range cases {
+		scenarioUUID := uuid.New()
+		key := pgtype.UUID{Bytes: scenarioUUID, Valid: true}
+		status := tc.status
+		repo := &fakeRepository{scenarios: map[pgtype.UUID]scenario.Scenario{key: {Uuid: key, CameraID: 7, Status: &status}}}
+
+		resp, err := NewUseCase(repo).StopScenario(context.Background(), scenarioUUID)
+		if tc.wantErr != nil {
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("%s: expected %v, got %v", name, tc.wantErr, err)
+			}
+		} else {
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", name, err)
+			}
+			if resp.ScenarioUUID != scenarioUUID.String() || resp.Status != tc.wantStatus {
+				t.Fatalf("%s: unexpected response %+v", name, resp)
+			}
+		}
+
+		if stored := *repo.scenarios[key].Status; stored != tc.wantStatus {
+			t.Fatalf("%s: expected status %s, got %s", name, tc.wantStatus, stored)
+		}
+		if sent := len(repo.events) == 1; sent != tc.wantEvent || len(repo.events) > 1 {
+			t.Fatalf("%s: expected stop_scenario event %v, got %d events", name, tc.wantEvent, len(repo.events))
+		}
+		if tc.wantEvent {
+			event := repo.events[0]
+			if event.EventType != entity.OutboxEventStopScenario || event.ScenarioUuid != key ||
+				event.PartitionKey != entity.OutboxPartitionKey(7) {
+				t.Fatalf("%s: unexpected outbox event %+v", name, event)
+			}
+		}
+	}
+}
+
+func TestStopScenarioNotFound(t *testing.T) {
+	repo := &fakeRepository{scenarios: make(map[pgtype.UUID]scenario.Scenario)}
+
+	_, err := NewUseCase(repo).StopScenario(context.Background(), uuid.New())
+	if !errors.Is(err, modelerror.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if len(repo.events) != 0 {
+		t.Fatalf("expected no outbox events, got %d", len(repo.events))
+	}
+}
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE outbox_scenario ADD COLUMN event_type TEXT NOT NULL DEFAULT 'init_scenario';
+
+COMMENT ON COLUMN outbox_scenario.event_type IS 'Type of the event (init_scenario, stop_scenario)';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE outbox_scenario DROP COLUMN IF EXISTS event_type;
+
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Индексы для keyset пагинации списка сценариев и фильтра по камере
+CREATE INDEX IF NOT EXISTS scenario_created_at_uuid_idx ON scenario (created_at DESC, uuid DESC);
+CREATE INDEX IF NOT EXISTS scenario_camera_id_created_at_idx ON scenario (camera_id, created_at DESC, uuid DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS scenario_camera_id_created_at_idx;
+DROP INDEX IF EXISTS scenario_created_at_uuid_idx;
+
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS inbox_scenario_result (
+    outbox_uuid UUID PRIMARY KEY,
+    scenario_uuid UUID NOT NULL REFERENCES scenario(uuid),
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE inbox_scenario_result IS 'Inbox pattern table for deduplicating scenario start results from runner_scheduler';
+COMMENT ON COLUMN inbox_scenario_result.outbox_uuid IS 'UUID of the message in runner_scheduler outbox (idempotency key)';
+COMMENT ON COLUMN inbox_scenario_result.scenario_uuid IS 'UUID of the associated scenario from scenario table';
+COMMENT ON COLUMN inbox_scenario_result.event_type IS 'Type of the event (scenario_started, scenario_start_failed)';
+COMMENT ON COLUMN inbox_scenario_result.payload IS 'JSON data of the message payload';
+COMMENT ON COLUMN inbox_scenario_result.created_at IS 'Timestamp when the message was received';
+
+COMMENT ON COLUMN scenario.status IS 'Status of the scenario (init_startup, in_startup_processing, active, start_failed, init_shutdown, in_shutdown_processing, inactive)';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+COMMENT ON COLUMN scenario.status IS 'Status of the scenario (init_startup, in_startup_processing, active, init_shutdown, in_shutdown_processing, inactive)';
+
+DROP TABLE IF EXISTS inbox_scenario_result;
+
+-- +goose StatementEnd
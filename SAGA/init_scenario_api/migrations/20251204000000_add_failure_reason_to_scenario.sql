@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE scenario ADD COLUMN failure_reason TEXT;
+
+COMMENT ON COLUMN scenario.failure_reason IS 'Reason why the scenario failed to start (set together with start_failed status)';
+COMMENT ON COLUMN outbox_scenario.event_type IS 'Type of the event (init_scenario, stop_scenario, compensate_scenario)';
+
+CREATE INDEX IF NOT EXISTS scenario_startup_status_updated_at_idx ON scenario (status, (COALESCE(updated_at, created_at)))
+    WHERE status IN ('init_startup', 'in_startup_processing');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS scenario_startup_status_updated_at_idx;
+
+COMMENT ON COLUMN outbox_scenario.event_type IS 'Type of the event (init_scenario, stop_scenario)';
+
+ALTER TABLE scenario DROP COLUMN IF EXISTS failure_reason;
+
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS idempotency_key (
+    key TEXT PRIMARY KEY,
+    request_hash TEXT NOT NULL,
+    response JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE idempotency_key IS 'Idempotency keys of POST /scenario/init requests with the stored responses for replay';
+COMMENT ON COLUMN idempotency_key.key IS 'Value of the Idempotency-Key request header';
+COMMENT ON COLUMN idempotency_key.request_hash IS 'SHA-256 of the normalized request body the key was first used with';
+COMMENT ON COLUMN idempotency_key.response IS 'JSON response returned for the first request, replayed on repeats';
+COMMENT ON COLUMN idempotency_key.created_at IS 'Timestamp when the key was first used';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS idempotency_key;
+
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Не больше одного запускаемого или активного сценария на камеру. Индекс намеренно не покрывает
+-- init_shutdown и in_shutdown_processing: иначе новый сценарий камеры, в том числе с replace=true,
+-- ждал бы, пока runner подтвердит остановку, а при потерянном подтверждении камера осталась бы
+-- занятой. Второго воркера на камере при этом не будет: новый запуск размещает камеру с большим
+-- fencing token'ом, runner того же узла заменяет им старый воркер, а воркер на другом узле
+-- отзывается при его следующем heartbeat'е
+CREATE UNIQUE INDEX IF NOT EXISTS scenario_camera_id_active_uidx ON scenario (camera_id)
+    WHERE status IN ('init_startup', 'in_startup_processing', 'active');
+COMMENT ON INDEX scenario_camera_id_active_uidx IS 'One starting or active scenario per camera. Shutdown statuses are excluded: the worker of a stopping scenario is replaced or revoked by the fencing token of the next placement, so a new start does not wait for the stop to be acknowledged';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS scenario_camera_id_active_uidx;
+
+-- +goose StatementEnd
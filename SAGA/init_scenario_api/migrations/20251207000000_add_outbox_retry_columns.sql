@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE outbox_scenario
+    ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN last_error TEXT;
+
+COMMENT ON COLUMN outbox_scenario.attempts IS 'Number of attempts to publish the message to Kafka';
+COMMENT ON COLUMN outbox_scenario.last_error IS 'Error of the last failed publish attempt';
+COMMENT ON COLUMN outbox_scenario.locked_until IS 'Timestamp until which the outbox message is locked from being processed (publish in progress or retry backoff)';
+
+CREATE INDEX IF NOT EXISTS outbox_scenario_failed_updated_at_idx ON outbox_scenario (updated_at DESC)
+    WHERE state = 'failed';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS outbox_scenario_failed_updated_at_idx;
+
+COMMENT ON COLUMN outbox_scenario.locked_until IS 'Timestamp until which the outbox message is locked from being processed';
+
+ALTER TABLE outbox_scenario
+    DROP COLUMN IF EXISTS last_error,
+    DROP COLUMN IF EXISTS attempts;
+
+-- +goose StatementEnd
@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE outbox_scenario
+    ADD COLUMN partition_key TEXT,
+    ADD COLUMN seq BIGINT;
+
+UPDATE outbox_scenario o
+SET partition_key = s.camera_id::text
+FROM scenario s
+WHERE s.uuid = o.scenario_uuid;
+
+UPDATE outbox_scenario
+SET partition_key = scenario_uuid::text
+WHERE partition_key IS NULL;
+
+-- created_at одинаков для событий одной транзакции (stop и init в режиме replace),
+-- поэтому порядок событий ключа задается последовательностью
+UPDATE outbox_scenario o
+SET seq = ordered.rn
+FROM (
+    SELECT outbox_uuid, row_number() OVER (ORDER BY created_at, outbox_uuid) AS rn
+    FROM outbox_scenario
+) ordered
+WHERE ordered.outbox_uuid = o.outbox_uuid;
+
+CREATE SEQUENCE IF NOT EXISTS outbox_scenario_seq_seq OWNED BY outbox_scenario.seq;
+SELECT setval('outbox_scenario_seq_seq', COALESCE((SELECT MAX(seq) FROM outbox_scenario), 0) + 1, false);
+
+ALTER TABLE outbox_scenario
+    ALTER COLUMN partition_key SET NOT NULL,
+    ALTER COLUMN seq SET DEFAULT nextval('outbox_scenario_seq_seq'),
+    ALTER COLUMN seq SET NOT NULL;
+
+COMMENT ON COLUMN outbox_scenario.partition_key IS 'Kafka message key (camera_id of the scenario); events with the same key are published in seq order';
+COMMENT ON COLUMN outbox_scenario.seq IS 'Monotonic insertion order of the message, defines publish order within a partition_key';
+
+CREATE INDEX IF NOT EXISTS outbox_scenario_unsent_partition_key_idx ON outbox_scenario (partition_key, seq)
+    WHERE state <> 'sent';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS outbox_scenario_unsent_partition_key_idx;
+
+ALTER TABLE outbox_scenario
+    DROP COLUMN IF EXISTS seq,
+    DROP COLUMN IF EXISTS partition_key;
+
+-- +goose StatementEnd
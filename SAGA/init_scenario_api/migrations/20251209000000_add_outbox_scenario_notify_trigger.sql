@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE OR REPLACE FUNCTION notify_outbox_scenario() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('outbox_scenario', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMENT ON FUNCTION notify_outbox_scenario() IS 'Wakes up the outbox relay listening on the outbox_scenario channel';
+
+CREATE TRIGGER outbox_scenario_notify_trg
+    AFTER INSERT ON outbox_scenario
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION notify_outbox_scenario();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS outbox_scenario_notify_trg ON outbox_scenario;
+DROP FUNCTION IF EXISTS notify_outbox_scenario();
+
+-- +goose StatementEnd
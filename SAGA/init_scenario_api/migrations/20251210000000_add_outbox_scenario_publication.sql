@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Публикация для CDC relay (OUTBOX_RELAY_MODE=cdc). Требует wal_level = logical.
+-- Слот репликации relay создает сам при первом запуске
+CREATE PUBLICATION outbox_scenario_pub FOR TABLE outbox_scenario WITH (publish = 'insert');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Слот репликации relay удаляется отдельно: SELECT pg_drop_replication_slot('<OUTBOX_REPLICATION_SLOT>')
+DROP PUBLICATION IF EXISTS outbox_scenario_pub;
+
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE INDEX IF NOT EXISTS outbox_scenario_sent_updated_at_idx ON outbox_scenario (updated_at)
+    WHERE state = 'sent';
+
+CREATE INDEX IF NOT EXISTS inbox_scenario_result_created_at_idx ON inbox_scenario_result (created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS inbox_scenario_result_created_at_idx;
+DROP INDEX IF EXISTS outbox_scenario_sent_updated_at_idx;
+
+-- +goose StatementEnd
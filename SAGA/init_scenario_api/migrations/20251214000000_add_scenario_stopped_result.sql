@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+COMMENT ON TABLE inbox_scenario_result IS 'Inbox pattern table for deduplicating scenario start and stop results from runner_scheduler';
+COMMENT ON COLUMN inbox_scenario_result.event_type IS 'Type of the event (scenario_started, scenario_start_failed, scenario_stopped)';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+COMMENT ON TABLE inbox_scenario_result IS 'Inbox pattern table for deduplicating scenario start results from runner_scheduler';
+COMMENT ON COLUMN inbox_scenario_result.event_type IS 'Type of the event (scenario_started, scenario_start_failed)';
+
+-- +goose StatementEnd
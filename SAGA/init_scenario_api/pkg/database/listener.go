@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"init_scenario_api/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// listenReconnectDelay - пауза перед повторным LISTEN после обрыва соединения
+const listenReconnectDelay = time.Second
+
+// Listener держит выделенное соединение из пула с LISTEN на канале Postgres
+type Listener struct {
+	pool    *pgxpool.Pool
+	channel string
+}
+
+func NewListener(pool *pgxpool.Pool, channel string) *Listener {
+	return &Listener{
+		pool:    pool,
+		channel: channel,
+	}
+}
+
+// Run слушает канал до отмены ctx и на каждое уведомление неблокирующе пишет в wake, поэтому
+// пачка уведомлений схлопывается в один сигнал, если у wake буфер 1. После каждого (пере)подключения
+// Run тоже отправляет сигнал: уведомления, пришедшие без соединения, теряются, и вызывающий
+// должен догнать их обычной выборкой.
+func (l *Listener) Run(ctx context.Context, wake chan<- struct{}) error {
+	log := logger.FromContext(ctx).With(zap.String("channel", l.channel))
+
+	for {
+		err := l.listen(ctx, wake)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		log.Warn("postgres listener disconnected, reconnecting", zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(listenReconnectDelay):
+		}
+	}
+}
+
+func (l *Listener) listen(ctx context.Context, wake chan<- struct{}) error {
+	pooled, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	// Соединение в состоянии LISTEN не возвращается в пул
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+		return fmt.Errorf("listen %s: %w", l.channel, err)
+	}
+
+	logger.FromContext(ctx).Info("postgres listener started", zap.String("channel", l.channel))
+	signal(wake)
+
+	for {
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return fmt.Errorf("wait for notification: %w", err)
+		}
+		signal(wake)
+	}
+}
+
+func signal(wake chan<- struct{}) {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
@@ -9,8 +9,9 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func NewPool(ctx context.Context, dbConfig config.DatabaseConfig, poolConfig config.PoolConfig) (*pgxpool.Pool, error) {
-	dsn := fmt.Sprintf(
+// DSN возвращает строку подключения к базе из конфига
+func DSN(dbConfig config.DatabaseConfig) string {
+	return fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
 		dbConfig.Host,
 		dbConfig.Port,
@@ -18,8 +19,10 @@ func NewPool(ctx context.Context, dbConfig config.DatabaseConfig, poolConfig con
 		dbConfig.Password,
 		dbConfig.Name,
 	)
+}
 
-	poolCfg, err := pgxpool.ParseConfig(dsn)
+func NewPool(ctx context.Context, dbConfig config.DatabaseConfig, poolConfig config.PoolConfig) (*pgxpool.Pool, error) {
+	poolCfg, err := pgxpool.ParseConfig(DSN(dbConfig))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse database config: %w", err)
 	}
@@ -0,0 +1,20 @@
+-- name: CreateIdempotencyKey :one
+-- Занимает ключ. При конфликте строка не возвращается: конкурентный запрос с тем же ключом
+-- дожидается фиксации транзакции первого запроса, после чего ключ читается GetIdempotencyKey
+INSERT INTO idempotency_key (
+    key,
+    request_hash
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (key) DO NOTHING
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_key
+WHERE key = $1;
+
+-- name: SetIdempotencyKeyResponse :exec
+UPDATE idempotency_key
+SET response = $2
+WHERE key = $1;
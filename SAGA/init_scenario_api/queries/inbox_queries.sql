@@ -0,0 +1,24 @@
+-- name: CreateInboxScenarioResult :one
+INSERT INTO inbox_scenario_result (
+    outbox_uuid,
+    scenario_uuid,
+    event_type,
+    payload
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: PurgeInboxScenarioResults :many
+-- Удаляет до batch_size записей inbox старше older_than_seconds и возвращает их для архива.
+-- Записи inbox обрабатываются в момент вставки, поэтому все они считаются processed.
+-- После удаления повторная доставка того же события будет обработана заново
+DELETE FROM inbox_scenario_result
+WHERE outbox_uuid IN (
+    SELECT outbox_uuid FROM inbox_scenario_result
+    WHERE created_at < NOW() - sqlc.arg(older_than_seconds)::integer * INTERVAL '1 second'
+    ORDER BY created_at
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
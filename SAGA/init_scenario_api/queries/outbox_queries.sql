@@ -2,9 +2,11 @@
 INSERT INTO outbox_scenario (
     outbox_uuid,
     scenario_uuid,
-    payload
+    payload,
+    event_type,
+    partition_key
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5
 ) RETURNING *;
 
 -- name: UpdateOutboxScenarioState :exec
@@ -20,16 +22,27 @@ SET locked_until = $2,
 WHERE outbox_uuid = $1;
 
 -- name: GetPendingOutboxScenarios :many
-SELECT * FROM outbox_scenario
-WHERE state = 'pending'
-  AND (locked_until IS NULL OR locked_until <= NOW())
-ORDER BY created_at ASC
+-- Берется только самое раннее неотправленное событие каждого partition_key: следующее
+-- событие ключа не публикуется, пока предыдущее не отправлено (в том числе пока оно
+-- в backoff, захвачено другим relay или в failed)
+SELECT o.* FROM outbox_scenario o
+WHERE o.state = 'pending'
+  AND (o.locked_until IS NULL OR o.locked_until <= NOW())
+  AND NOT EXISTS (
+      SELECT 1 FROM outbox_scenario prev
+      WHERE prev.partition_key = o.partition_key
+        AND prev.state <> 'sent'
+        AND prev.seq < o.seq
+  )
+ORDER BY o.seq ASC
 LIMIT $1
 FOR UPDATE SKIP LOCKED;
 
 -- name: LockOutboxScenariosBatch :exec
+-- Захват записей на отправку считается попыткой публикации
 UPDATE outbox_scenario
 SET locked_until = NOW() + INTERVAL '1 minute',
+    attempts = attempts + 1,
     updated_at = NOW()
 WHERE outbox_uuid = ANY($1::uuid[]);
 
@@ -40,3 +53,53 @@ SET state = 'sent',
     updated_at = NOW()
 WHERE outbox_uuid = ANY($1::uuid[]);
 
+-- name: RescheduleOutboxScenariosBatch :many
+-- Откладывает записи после неудачной публикации с экспоненциальной задержкой
+-- base_delay_ms * 2^(attempts-1), ограниченной max_delay_ms. Записи, исчерпавшие
+-- max_attempts попыток, переводятся в терминальное состояние failed
+UPDATE outbox_scenario
+SET state = CASE WHEN attempts >= sqlc.arg(max_attempts)::integer THEN 'failed' ELSE 'pending' END,
+    locked_until = CASE
+        WHEN attempts >= sqlc.arg(max_attempts)::integer THEN NULL
+        ELSE NOW() + LEAST(
+            sqlc.arg(base_delay_ms)::bigint * POWER(2, LEAST(GREATEST(attempts - 1, 0), 30))::bigint,
+            sqlc.arg(max_delay_ms)::bigint
+        ) * INTERVAL '1 millisecond'
+    END,
+    last_error = sqlc.arg(last_error),
+    updated_at = NOW()
+WHERE outbox_uuid = ANY(sqlc.arg(outbox_uuids)::uuid[])
+RETURNING outbox_uuid, state, attempts;
+
+-- name: ListFailedOutboxScenarios :many
+SELECT * FROM outbox_scenario
+WHERE state = 'failed'
+ORDER BY updated_at DESC, outbox_uuid DESC
+LIMIT sqlc.arg(page_limit)
+OFFSET sqlc.arg(page_offset);
+
+-- name: RequeueFailedOutboxScenarios :many
+-- Возвращает failed записи в очередь публикации с новым бюджетом попыток.
+-- last_error сохраняется до следующей попытки
+UPDATE outbox_scenario
+SET state = 'pending',
+    attempts = 0,
+    locked_until = NULL,
+    updated_at = NOW()
+WHERE state = 'failed'
+  AND outbox_uuid = ANY(sqlc.arg(outbox_uuids)::uuid[])
+RETURNING outbox_uuid;
+
+-- name: PurgeSentOutboxScenarios :many
+-- Удаляет до batch_size отправленных записей старше older_than_seconds и возвращает их
+-- для архива. SKIP LOCKED не ждет строк, которые держат другие транзакции
+DELETE FROM outbox_scenario
+WHERE outbox_uuid IN (
+    SELECT outbox_uuid FROM outbox_scenario
+    WHERE state = 'sent'
+      AND updated_at < NOW() - sqlc.arg(older_than_seconds)::integer * INTERVAL '1 second'
+    ORDER BY updated_at
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
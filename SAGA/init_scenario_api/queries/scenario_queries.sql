@@ -25,3 +25,51 @@ SET status = $2,
     updated_at = NOW()
 WHERE uuid = ANY($1::uuid[]);
 
+
+-- name: GetScenarioByUUIDForUpdate :one
+SELECT * FROM scenario
+WHERE uuid = $1
+FOR UPDATE;
+
+-- name: TransitionScenarioStatusBatch :exec
+UPDATE scenario
+SET status = sqlc.arg(to_status),
+    updated_at = NOW()
+WHERE uuid = ANY(sqlc.arg(uuids)::uuid[])
+  AND status = sqlc.arg(from_status);
+
+-- name: GetScenarioByUUID :one
+SELECT * FROM scenario
+WHERE uuid = $1;
+
+-- name: ListScenarios :many
+SELECT * FROM scenario
+WHERE (sqlc.narg(camera_id)::integer IS NULL OR camera_id = sqlc.narg(camera_id)::integer)
+  AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status)::text)
+  AND (
+    sqlc.narg(cursor_created_at)::timestamp IS NULL
+    OR (created_at, uuid) < (sqlc.narg(cursor_created_at)::timestamp, sqlc.narg(cursor_uuid)::uuid)
+  )
+ORDER BY created_at DESC, uuid DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: FailScenarioStartup :exec
+UPDATE scenario
+SET status = 'start_failed',
+    failure_reason = sqlc.arg(failure_reason),
+    updated_at = NOW()
+WHERE uuid = sqlc.arg(uuid);
+
+-- name: GetStuckStartupScenarios :many
+SELECT * FROM scenario
+WHERE status = ANY(sqlc.arg(statuses)::text[])
+  AND COALESCE(updated_at, created_at) < NOW() - sqlc.arg(timeout_seconds)::integer * INTERVAL '1 second'
+ORDER BY COALESCE(updated_at, created_at)
+LIMIT sqlc.arg(batch_limit)
+FOR UPDATE SKIP LOCKED;
+
+-- name: GetActiveScenarioByCameraIDForUpdate :one
+SELECT * FROM scenario
+WHERE camera_id = sqlc.arg(camera_id)
+  AND status = ANY(sqlc.arg(statuses)::text[])
+FOR UPDATE;
@@ -6,7 +6,8 @@ CREATE TABLE IF NOT EXISTS scenario (
     predict_id INTEGER,
     status TEXT DEFAULT 'init_startup',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP
+    updated_at TIMESTAMP,
+    failure_reason TEXT
 );
 
 COMMENT ON TABLE scenario IS 'Scenario table for storing scenario state and camera prediction';
@@ -14,9 +15,18 @@ COMMENT ON COLUMN scenario.uuid IS 'Unique identifier for the scenario (UUID for
 COMMENT ON COLUMN scenario.camera_id IS 'ID of the camera being used in the scenario';
 COMMENT ON COLUMN scenario.url IS 'URL to connect to camera';
 COMMENT ON COLUMN scenario.predict_id IS 'ID of the predicted person';
-COMMENT ON COLUMN scenario.status IS 'Status of the scenario (init_startup, in_startup_processing, active, init_shutdown, in_shutdown_processing, inactive)';
+COMMENT ON COLUMN scenario.status IS 'Status of the scenario (init_startup, in_startup_processing, active, start_failed, init_shutdown, in_shutdown_processing, inactive)';
 COMMENT ON COLUMN scenario.created_at IS 'Timestamp when the scenario was created';
 COMMENT ON COLUMN scenario.updated_at IS 'Timestamp when the scenario was last updated';
+COMMENT ON COLUMN scenario.failure_reason IS 'Reason why the scenario failed to start (set together with start_failed status)';
+
+CREATE INDEX IF NOT EXISTS scenario_created_at_uuid_idx ON scenario (created_at DESC, uuid DESC);
+CREATE INDEX IF NOT EXISTS scenario_camera_id_created_at_idx ON scenario (camera_id, created_at DESC, uuid DESC);
+CREATE INDEX IF NOT EXISTS scenario_startup_status_updated_at_idx ON scenario (status, (COALESCE(updated_at, created_at)))
+    WHERE status IN ('init_startup', 'in_startup_processing');
+CREATE UNIQUE INDEX IF NOT EXISTS scenario_camera_id_active_uidx ON scenario (camera_id)
+    WHERE status IN ('init_startup', 'in_startup_processing', 'active');
+COMMENT ON INDEX scenario_camera_id_active_uidx IS 'One starting or active scenario per camera. Shutdown statuses are excluded: the worker of a stopping scenario is replaced or revoked by the fencing token of the next placement, so a new start does not wait for the stop to be acknowledged';
 
 -- Outbox table for scenario related events
 CREATE TABLE IF NOT EXISTS outbox_scenario (
@@ -26,7 +36,12 @@ CREATE TABLE IF NOT EXISTS outbox_scenario (
     state TEXT DEFAULT 'pending',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP,
-    locked_until TIMESTAMP DEFAULT NULL
+    locked_until TIMESTAMP DEFAULT NULL,
+    event_type TEXT NOT NULL DEFAULT 'init_scenario',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    partition_key TEXT NOT NULL,
+    seq BIGSERIAL NOT NULL
 );
 
 COMMENT ON TABLE outbox_scenario IS 'Outbox pattern table for reliable message publishing in SAGA';
@@ -35,4 +50,110 @@ COMMENT ON COLUMN outbox_scenario.payload IS 'JSON data of the message payload';
 COMMENT ON COLUMN outbox_scenario.state IS 'State of the message (pending, sent, failed)';
 COMMENT ON COLUMN outbox_scenario.created_at IS 'Timestamp when the message was created';
 COMMENT ON COLUMN outbox_scenario.updated_at IS 'Timestamp when the message was last updated';
-COMMENT ON COLUMN outbox_scenario.locked_until IS 'Timestamp until which the outbox message is locked from being processed';
+COMMENT ON COLUMN outbox_scenario.locked_until IS 'Timestamp until which the outbox message is locked from being processed (publish in progress or retry backoff)';
+COMMENT ON COLUMN outbox_scenario.event_type IS 'Type of the event (init_scenario, stop_scenario, compensate_scenario)';
+COMMENT ON COLUMN outbox_scenario.attempts IS 'Number of attempts to publish the message to Kafka';
+COMMENT ON COLUMN outbox_scenario.last_error IS 'Error of the last failed publish attempt';
+COMMENT ON COLUMN outbox_scenario.partition_key IS 'Kafka message key (camera_id of the scenario); events with the same key are published in seq order';
+COMMENT ON COLUMN outbox_scenario.seq IS 'Monotonic insertion order of the message, defines publish order within a partition_key';
+
+CREATE INDEX IF NOT EXISTS outbox_scenario_failed_updated_at_idx ON outbox_scenario (updated_at DESC)
+    WHERE state = 'failed';
+
+CREATE INDEX IF NOT EXISTS outbox_scenario_unsent_partition_key_idx ON outbox_scenario (partition_key, seq)
+    WHERE state <> 'sent';
+
+CREATE INDEX IF NOT EXISTS outbox_scenario_sent_updated_at_idx ON outbox_scenario (updated_at)
+    WHERE state = 'sent';
+
+-- Notifies the outbox relay about new messages; NOTIFY is delivered on commit
+-- and deduplicated within a transaction
+CREATE OR REPLACE FUNCTION notify_outbox_scenario() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('outbox_scenario', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMENT ON FUNCTION notify_outbox_scenario() IS 'Wakes up the outbox relay listening on the outbox_scenario channel';
+
+CREATE TRIGGER outbox_scenario_notify_trg
+    AFTER INSERT ON outbox_scenario
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION notify_outbox_scenario();
+
+-- Publication for the CDC outbox relay (requires wal_level = logical)
+CREATE PUBLICATION outbox_scenario_pub FOR TABLE outbox_scenario WITH (publish = 'insert');
+
+-- Inbox table for scenario start and stop results from runner_scheduler
+CREATE TABLE IF NOT EXISTS inbox_scenario_result (
+    outbox_uuid UUID PRIMARY KEY,
+    scenario_uuid UUID NOT NULL REFERENCES scenario(uuid),
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE inbox_scenario_result IS 'Inbox pattern table for deduplicating scenario start and stop results from runner_scheduler';
+COMMENT ON COLUMN inbox_scenario_result.outbox_uuid IS 'UUID of the message in runner_scheduler outbox (idempotency key)';
+COMMENT ON COLUMN inbox_scenario_result.scenario_uuid IS 'UUID of the associated scenario from scenario table';
+COMMENT ON COLUMN inbox_scenario_result.event_type IS 'Type of the event (scenario_started, scenario_start_failed, scenario_stopped)';
+COMMENT ON COLUMN inbox_scenario_result.payload IS 'JSON data of the message payload';
+COMMENT ON COLUMN inbox_scenario_result.created_at IS 'Timestamp when the message was received';
+
+CREATE INDEX IF NOT EXISTS inbox_scenario_result_created_at_idx ON inbox_scenario_result (created_at);
+
+-- Idempotency keys of POST /scenario/init
+CREATE TABLE IF NOT EXISTS idempotency_key (
+    key TEXT PRIMARY KEY,
+    request_hash TEXT NOT NULL,
+    response JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE idempotency_key IS 'Idempotency keys of POST /scenario/init requests with the stored responses for replay';
+COMMENT ON COLUMN idempotency_key.key IS 'Value of the Idempotency-Key request header';
+COMMENT ON COLUMN idempotency_key.request_hash IS 'SHA-256 of the normalized request body the key was first used with';
+COMMENT ON COLUMN idempotency_key.response IS 'JSON response returned for the first request, replayed on repeats';
+COMMENT ON COLUMN idempotency_key.created_at IS 'Timestamp when the key was first used';
+
+-- Generic outbox table for publishing aggregate events to Kafka
+CREATE TABLE IF NOT EXISTS outbox (
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    aggregate_type TEXT NOT NULL,
+    aggregate_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    topic TEXT NOT NULL,
+    headers JSONB NOT NULL DEFAULT '{}',
+    payload BYTEA NOT NULL,
+    state TEXT NOT NULL DEFAULT 'pending' CHECK (state IN ('pending', 'sent', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGSERIAL NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+COMMENT ON TABLE outbox IS 'Generic outbox for reliable publishing of aggregate events to Kafka';
+COMMENT ON COLUMN outbox.id IS 'Unique identifier of the event, sent in the outbox_uuid header for deduplication';
+COMMENT ON COLUMN outbox.aggregate_type IS 'Type of the aggregate the event belongs to (scenario, camera, ...)';
+COMMENT ON COLUMN outbox.aggregate_id IS 'Identifier of the aggregate; used as the Kafka message key, events of one aggregate are published in order';
+COMMENT ON COLUMN outbox.event_type IS 'Type of the event';
+COMMENT ON COLUMN outbox.topic IS 'Kafka topic the event is published to';
+COMMENT ON COLUMN outbox.headers IS 'Additional Kafka message headers';
+COMMENT ON COLUMN outbox.payload IS 'Message payload';
+COMMENT ON COLUMN outbox.state IS 'State of the event (pending, sent, failed)';
+COMMENT ON COLUMN outbox.attempts IS 'Number of publish attempts';
+COMMENT ON COLUMN outbox.last_error IS 'Error of the last failed publish attempt';
+COMMENT ON COLUMN outbox.next_attempt_at IS 'Timestamp before which the event must not be claimed (lease of the relay or retry backoff)';
+COMMENT ON COLUMN outbox.seq IS 'Insertion order of events, defines publish order within an aggregate';
+COMMENT ON COLUMN outbox.created_at IS 'Timestamp when the event was created';
+COMMENT ON COLUMN outbox.updated_at IS 'Timestamp when the event was last updated';
+
+CREATE INDEX IF NOT EXISTS outbox_unsent_aggregate_seq_idx ON outbox (aggregate_type, aggregate_id, seq)
+    WHERE state <> 'sent';
+CREATE INDEX IF NOT EXISTS outbox_pending_seq_idx ON outbox (seq)
+    WHERE state = 'pending';
+CREATE INDEX IF NOT EXISTS outbox_sent_updated_at_idx ON outbox (updated_at)
+    WHERE state = 'sent';
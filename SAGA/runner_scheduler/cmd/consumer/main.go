@@ -2,24 +2,22 @@ package main
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"runner_scheduler/internal/config"
 	"runner_scheduler/internal/infrastructure/kafka"
 	"runner_scheduler/internal/infrastructure/repository"
-	"runner_scheduler/internal/infrastructure/repository/queries/inbox_start_scenario"
-	modelerror "runner_scheduler/internal/models/error"
+	"runner_scheduler/internal/processors/inbox_processor"
 	"runner_scheduler/pkg/closer"
 	"runner_scheduler/pkg/database"
+	"runner_scheduler/pkg/inbox"
 	"runner_scheduler/pkg/logger"
 
 	modelKafka "runner_scheduler/internal/models/kafka"
 
-	"github.com/jackc/pgx/v5/pgtype"
 	"go.uber.org/zap"
 )
 
@@ -28,8 +26,6 @@ func main() {
 }
 
 func run() int {
-	ctx := context.Background()
-
 	log, err := logger.InitLogger()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to init logger: %v\n", err)
@@ -37,6 +33,9 @@ func run() int {
 	}
 	defer log.Sync()
 
+	ctx, cancel := context.WithCancel(logger.WithContext(context.Background(), log))
+	defer cancel()
+
 	cfg, err := config.Load()
 	if err != nil {
 		log.Error("failed to load config", zap.Error(err))
@@ -58,116 +57,91 @@ func run() int {
 
 	repo := repository.NewRepository(dbPool)
 
-	kafkaCfg := kafka.DefaultConfig(cfg.Consumer.KafkaBrokers...)
-	kafkaCfg.ConsumerGroup = cfg.Consumer.KafkaConsumerGroup
-
-	consumer, err := kafka.NewKafkaConsumer(
-		kafkaCfg,
-		[]string{modelKafka.OutboxScenarioApi},
-		modelKafka.KafkaConsumerGroup,
-		log,
-	)
+	producer, err := kafka.NewKafkaProducer(newKafkaConfig(cfg), log)
 	if err != nil {
-		log.Error("failed to create kafka consumer", zap.Error(err))
+		log.Error("failed to create kafka producer", zap.Error(err))
 		return 1
 	}
 	cls.Add(func() error {
-		log.Info("closing kafka consumer")
-		return consumer.Close()
+		log.Info("closing kafka producer")
+		return producer.Close()
 	})
 
-	go func() {
-		for {
-			time.Sleep(time.Millisecond * 500)
+	inboxCfg := inbox.Config{
+		IDHeader:         modelKafka.OutboxUUIDHeader,
+		EventTypeHeader:  modelKafka.EventTypeHeader,
+		DefaultEventType: modelKafka.EventTypeInitScenario,
+		Retry: inbox.RetryPolicy{
+			MaxAttempts: cfg.Consumer.MaxAttempts,
+			BaseDelay:   cfg.Consumer.RetryBaseDelay,
+			MaxDelay:    cfg.Consumer.RetryMaxDelay,
+		},
+		Workers:      cfg.Consumer.Workers,
+		QueueSize:    cfg.Consumer.QueueSize,
+		DrainTimeout: cfg.Consumer.DrainTimeout,
+		BatchSize:    cfg.Consumer.BatchSize,
+		BatchWait:    cfg.Consumer.BatchWait,
+	}
 
-			if cls.IsShutdown() {
-				log.Info("shutdown signal received, stopping message processing")
-				break
-			}
-			log.Info("message processing")
-			msg, err := consumer.ReadMessage(ctx)
-			if err != nil {
-				log.Error("failed to read message", zap.Error(err))
-				time.Sleep(time.Second)
-				continue
-			}
-			log.Info("message read successfully")
+	// Основной топик и каждый топик повторов читаются отдельным kafka consumer'ом со своей группой:
+	// ожидание задержки повтора не должно задерживать новые сообщения и повторы других уровней
+	type source struct {
+		topic string
+		group string
+	}
+	sources := []source{{topic: modelKafka.OutboxScenarioApi, group: cfg.Consumer.KafkaConsumerGroup}}
 
-			var payload struct {
-				CameraID     int32  `json:"camera_id"`
-				ScenarioUUID string `json:"scenario_uuid"`
-				URL          string `json:"url"`
-			}
+	var publisher inbox.Publisher
+	if cfg.Consumer.DeadLetterTopic != "" {
+		publisher = kafka.NewInboxPublisher(producer)
+		inboxCfg.DeadLetterTopic = cfg.Consumer.DeadLetterTopic
 
-			if err := json.Unmarshal(msg.Value, &payload); err != nil {
-				log.Error("failed to unmarshal message", zap.Error(err))
-				continue
-			}
+		for i, delay := range cfg.Consumer.RetryTopicDelays {
+			topic := modelKafka.RetryTopic(modelKafka.OutboxScenarioApi, i+1)
+			inboxCfg.RetryTopics = append(inboxCfg.RetryTopics, inbox.RetryTopic{Topic: topic, Delay: delay})
+			sources = append(sources, source{topic: topic, group: fmt.Sprintf("%s_retry_%d", cfg.Consumer.KafkaConsumerGroup, i+1)})
+		}
 
-			outboxUUIDBytes, ok := msg.Headers["outbox_uuid"]
-			if !ok {
-				log.Error("outbox_uuid header not found")
-				continue
-			}
-			outboxUUIDStr := string(outboxUUIDBytes)
-
-			log.Debug("parsed message payload",
-				zap.String("outbox_uuid", outboxUUIDStr),
-				zap.Int32("camera_id", payload.CameraID),
-				zap.String("scenario_uuid", payload.ScenarioUUID),
-				zap.String("url", payload.URL),
-				zap.String("raw_message", string(msg.Value)),
-			)
-
-			outboxUUID := pgtype.UUID{}
-			if err := outboxUUID.Scan(outboxUUIDStr); err != nil {
-				log.Error("failed to parse outbox_uuid",
-					zap.Error(err),
-					zap.String("outbox_uuid_string", outboxUUIDStr))
-				continue
+		for _, topic := range append([]string{inboxCfg.DeadLetterTopic}, retryTopicNames(inboxCfg.RetryTopics)...) {
+			if err := kafka.EnsureTopic(ctx, newKafkaConfig(cfg), topic, 3, 3); err != nil {
+				log.Error("failed to ensure topic exists", zap.String("topic", topic), zap.Error(err))
 			}
+		}
+	}
 
-			scenarioUUID := pgtype.UUID{}
-			if err := scenarioUUID.Scan(payload.ScenarioUUID); err != nil {
-				log.Error("failed to parse scenario_uuid", zap.Error(err))
-				continue
-			}
+	processor := inbox_processor.NewProcessor(repo)
 
-			_, err = repo.CreateInboxStartScenario(ctx, inbox_start_scenario.CreateInboxStartScenarioParams{
-				OutboxUuid:   outboxUUID,
-				CameraID:     payload.CameraID,
-				ScenarioUuid: scenarioUUID,
-				Url:          payload.URL,
-			})
-			if err != nil {
-				if errors.Is(err, modelerror.ErrDuplicateKey) {
-					log.Warn("message already processed (duplicate key), skipping",
-						zap.String("outbox_uuid", outboxUUIDStr),
-						zap.Int32("camera_id", payload.CameraID),
-						zap.String("scenario_uuid", payload.ScenarioUUID),
-						zap.String("url", payload.URL),
-					)
-				} else {
-					log.Error("failed to save message to db", zap.Error(err))
-					continue
-				}
-			} else {
-				log.Info("message saved to db successfully")
-			}
+	var wg sync.WaitGroup
+	for _, src := range sources {
+		kafkaCfg := newKafkaConfig(cfg)
+		kafkaCfg.ConsumerGroup = src.group
 
-			if err := consumer.CommitMessages(ctx, msg); err != nil {
-				log.Error("failed to commit message", zap.Error(err))
-				continue
-			}
-			log.Info("message committed successfully")
-			log.Info("message processed and committed successfully",
-				zap.String("outbox_uuid", outboxUUIDStr),
-				zap.Int32("camera_id", payload.CameraID),
-				zap.String("scenario_uuid", payload.ScenarioUUID),
-				zap.String("url", payload.URL),
-			)
+		source, sourcePublisher, err := newInboxSource(cfg, kafkaCfg, src.topic, publisher, cls, log)
+		if err != nil {
+			log.Error("failed to create kafka consumer", zap.String("topic", src.topic), zap.Error(err))
+			return 1
 		}
-	}()
+
+		consumer := inbox.NewConsumer(repo.Inbox(), repo, source, sourcePublisher, inboxCfg)
+		processor.Register(consumer)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := consumer.Run(ctx); err != nil {
+				log.Error("consumer stopped with error", zap.String("topic", src.topic), zap.Error(err))
+			}
+		}()
+	}
+
+	// Добавляется последним, поэтому выполняется первым: чтение останавливается, а начатые
+	// обработки завершаются до закрытия kafka consumer'ов, producer'а и пула соединений
+	cls.Add(func() error {
+		log.Info("stopping message processing")
+		cancel()
+		wg.Wait()
+		return nil
+	})
 
 	log.Info("consumer started successfully")
 
@@ -175,3 +149,67 @@ func run() int {
 
 	return 0
 }
+
+// newInboxSource создает источник сообщений топика. В exactly-once режиме топик читается в
+// транзакционной сессии со своим transactional.id: копия сообщения в топике повторов или DLQ
+// публикуется в одной транзакции с подтверждением исходного сообщения
+func newInboxSource(
+	cfg *config.Config,
+	kafkaCfg *kafka.Config,
+	topic string,
+	publisher inbox.Publisher,
+	cls *closer.Closer,
+	log *zap.Logger,
+) (inbox.Source, inbox.Publisher, error) {
+	if cfg.Kafka.TransactionalID == "" {
+		kafkaConsumer, err := kafka.NewKafkaConsumer(kafkaCfg, []string{topic}, log)
+		if err != nil {
+			return nil, nil, err
+		}
+		cls.Add(func() error {
+			log.Info("closing kafka consumer", zap.String("topic", topic))
+			return kafkaConsumer.Close()
+		})
+		return kafka.NewInboxSource(kafkaConsumer), publisher, nil
+	}
+
+	kafkaCfg.WithTransactionalID(cfg.Kafka.TransactionalIDFor("consumer", topic))
+	session, err := kafka.NewTransactSession(kafkaCfg, []string{topic}, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	cls.Add(func() error {
+		log.Info("closing kafka transact session", zap.String("topic", topic))
+		return session.Close()
+	})
+
+	if publisher != nil {
+		publisher = kafka.NewInboxPublisher(session)
+	}
+	return kafka.NewTransactSource(session), publisher, nil
+}
+
+func retryTopicNames(topics []inbox.RetryTopic) []string {
+	names := make([]string, len(topics))
+	for i, topic := range topics {
+		names[i] = topic.Topic
+	}
+	return names
+}
+
+// newKafkaConfig создает настройки подключения к Kafka с аутентификацией и TLS из cfg.
+// transactional.id задает newInboxSource: он свой у сессии каждого топика
+func newKafkaConfig(cfg *config.Config) *kafka.Config {
+	kafkaCfg := kafka.DefaultConfig(cfg.Consumer.KafkaBrokers...).
+		WithSASL(cfg.Kafka.SASLMechanism, cfg.Kafka.Username, cfg.Kafka.Password).
+		WithTLS(kafka.TLSConfig{
+			Enabled:            cfg.Kafka.TLS.Enabled,
+			CAFile:             cfg.Kafka.TLS.CAFile,
+			CertFile:           cfg.Kafka.TLS.CertFile,
+			KeyFile:            cfg.Kafka.TLS.KeyFile,
+			InsecureSkipVerify: cfg.Kafka.TLS.InsecureSkipVerify,
+		})
+
+	kafkaCfg.TransactionTimeout = int(cfg.Kafka.TransactionTimeout.Seconds())
+	return kafkaCfg
+}
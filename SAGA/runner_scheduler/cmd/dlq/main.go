@@ -0,0 +1,212 @@
+// Команда dlq показывает сообщения DLQ consumer'а и возвращает их в исходный топик.
+//
+//	dlq list [-limit N] [-payload]
+//	dlq replay [-id MESSAGE_ID] [-limit N] [-wait 10s]
+//
+// replay без -id читает DLQ группой runner_scheduler_dlq_replay и подтверждает каждое
+// возвращенное сообщение, поэтому повторный запуск продолжает с места остановки. replay с -id
+// возвращает только сообщения с этим outbox_uuid и ничего не подтверждает. Повторный возврат
+// уже обработанного сообщения безопасен: consumer дедуплицирует его по inbox
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"runner_scheduler/internal/config"
+	"runner_scheduler/internal/infrastructure/kafka"
+	"runner_scheduler/pkg/inbox"
+
+	modelKafka "runner_scheduler/internal/models/kafka"
+
+	"go.uber.org/zap"
+)
+
+const replayConsumerGroup = "runner_scheduler_dlq_replay"
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return 1
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		return 1
+	}
+	if cfg.Consumer.DeadLetterTopic == "" {
+		fmt.Fprintln(os.Stderr, "CONSUMER_DEAD_LETTER_TOPIC is empty, dead letter topic is disabled")
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch args[0] {
+	case "list":
+		err = list(ctx, cfg, args[1:])
+	case "replay":
+		err = replay(ctx, cfg, args[1:])
+	default:
+		usage()
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage:")
+	fmt.Fprintln(os.Stderr, "  dlq list [-limit N] [-payload]")
+	fmt.Fprintln(os.Stderr, "  dlq replay [-id MESSAGE_ID] [-limit N] [-wait 10s]")
+}
+
+func list(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	limit := flags.Int("limit", 100, "maximum number of messages to show, 0 - all")
+	payload := flags.Bool("payload", false, "print message payload")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PARTITION\tOFFSET\tMESSAGE_ID\tEVENT_TYPE\tATTEMPTS\tFAILED_AT\tORIGINAL_TOPIC\tERROR")
+
+	shown := 0
+	err := kafka.ReadTopic(ctx, newKafkaConfig(cfg), cfg.Consumer.DeadLetterTopic, func(record kafka.Record) bool {
+		h := record.Message.Headers
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			record.Partition, record.Offset,
+			h[modelKafka.OutboxUUIDHeader], h[modelKafka.EventTypeHeader], h[inbox.HeaderAttempts],
+			h[inbox.HeaderFailedAt], h[inbox.HeaderOriginalTopic], h[inbox.HeaderError],
+		)
+		if *payload {
+			fmt.Fprintf(w, "\t\t%q\n", record.Message.Value)
+		}
+		shown++
+		return *limit == 0 || shown < *limit
+	})
+	w.Flush()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d message(s) in %s\n", shown, cfg.Consumer.DeadLetterTopic)
+	return nil
+}
+
+func replay(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	id := flags.String("id", "", "replay only messages with this outbox_uuid")
+	limit := flags.Int("limit", 0, "maximum number of messages to replay, 0 - all")
+	wait := flags.Duration("wait", 10*time.Second, "stop after no new messages for this long")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	producer, err := kafka.NewKafkaProducer(newKafkaConfig(cfg), zap.NewNop())
+	if err != nil {
+		return fmt.Errorf("create kafka producer: %w", err)
+	}
+	defer producer.Close()
+
+	replayed := 0
+	if *id != "" {
+		var replayErr error
+		err = kafka.ReadTopic(ctx, newKafkaConfig(cfg), cfg.Consumer.DeadLetterTopic, func(record kafka.Record) bool {
+			if string(record.Message.Headers[modelKafka.OutboxUUIDHeader]) != *id {
+				return true
+			}
+			if replayErr = replayMessage(ctx, producer, record.Message); replayErr != nil {
+				return false
+			}
+			replayed++
+			return *limit == 0 || replayed < *limit
+		})
+		if err == nil {
+			err = replayErr
+		}
+	} else {
+		replayed, err = replayAll(ctx, cfg, producer, *limit, *wait)
+	}
+
+	fmt.Printf("%d message(s) replayed from %s\n", replayed, cfg.Consumer.DeadLetterTopic)
+	return err
+}
+
+// replayAll возвращает сообщения DLQ, подтверждая каждое после публикации
+func replayAll(ctx context.Context, cfg *config.Config, producer kafka.Producer, limit int, wait time.Duration) (int, error) {
+	kafkaCfg := newKafkaConfig(cfg)
+	kafkaCfg.ConsumerGroup = replayConsumerGroup
+	kafkaCfg.StartFromBeginning = true
+
+	consumer, err := kafka.NewKafkaConsumer(kafkaCfg, []string{cfg.Consumer.DeadLetterTopic}, zap.NewNop())
+	if err != nil {
+		return 0, fmt.Errorf("create kafka consumer: %w", err)
+	}
+	defer consumer.Close()
+
+	replayed := 0
+	for limit == 0 || replayed < limit {
+		readCtx, cancel := context.WithTimeout(ctx, wait)
+		msg, err := consumer.ReadMessage(readCtx)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				return replayed, nil
+			}
+			return replayed, err
+		}
+
+		if err := replayMessage(ctx, producer, msg); err != nil {
+			return replayed, err
+		}
+		if err := consumer.CommitMessages(ctx, msg); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+// replayMessage публикует сообщение DLQ в исходный топик без заголовков повторов и DLQ
+func replayMessage(ctx context.Context, producer kafka.Producer, msg *kafka.Message) error {
+	topic := string(msg.Headers[inbox.HeaderOriginalTopic])
+	if topic == "" {
+		topic = modelKafka.OutboxScenarioApi
+	}
+
+	if err := producer.SendMessage(ctx, kafka.NewMessage(topic, msg.Key, msg.Value, inbox.StripHeaders(msg.Headers))); err != nil {
+		return fmt.Errorf("publish message %s: %w", msg.Headers[modelKafka.OutboxUUIDHeader], err)
+	}
+	fmt.Printf("replayed %s to %s\n", msg.Headers[modelKafka.OutboxUUIDHeader], topic)
+	return nil
+}
+
+// newKafkaConfig создает настройки подключения к Kafka с аутентификацией и TLS из cfg
+func newKafkaConfig(cfg *config.Config) *kafka.Config {
+	return kafka.DefaultConfig(cfg.Consumer.KafkaBrokers...).
+		WithSASL(cfg.Kafka.SASLMechanism, cfg.Kafka.Username, cfg.Kafka.Password).
+		WithTLS(kafka.TLSConfig{
+			Enabled:            cfg.Kafka.TLS.Enabled,
+			CAFile:             cfg.Kafka.TLS.CAFile,
+			CertFile:           cfg.Kafka.TLS.CertFile,
+			KeyFile:            cfg.Kafka.TLS.KeyFile,
+			InsecureSkipVerify: cfg.Kafka.TLS.InsecureSkipVerify,
+		})
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"runner_scheduler/internal/config"
+	"runner_scheduler/internal/infrastructure/kafka"
+	"runner_scheduler/internal/infrastructure/repository"
+	"runner_scheduler/pkg/closer"
+	"runner_scheduler/pkg/database"
+	"runner_scheduler/pkg/logger"
+	"shared/outbox"
+
+	modelKafka "runner_scheduler/internal/models/kafka"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	log, err := logger.InitLogger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to init logger: %v\n", err)
+		return 1
+	}
+	defer log.Sync()
+
+	ctx, cancel := context.WithCancel(logger.WithContext(context.Background(), log))
+	defer cancel()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Error("failed to load config", zap.Error(err))
+		return 1
+	}
+
+	cls := closer.New(10 * time.Second)
+	cls.Add(func() error {
+		log.Info("cancelling producer context")
+		cancel()
+		return nil
+	})
+
+	dbPool, err := database.NewPool(ctx, cfg.Database, cfg.Pool)
+	if err != nil {
+		log.Error("failed to create db pool", zap.Error(err))
+		return 1
+	}
+	cls.Add(func() error {
+		log.Info("closing database connection")
+		database.Close(dbPool)
+		return nil
+	})
+
+	repo := repository.NewRepository(dbPool)
+
+	producer, err := kafka.NewKafkaProducer(newKafkaConfig(cfg), log)
+	if err != nil {
+		log.Error("failed to create kafka producer", zap.Error(err))
+		return 1
+	}
+	cls.Add(func() error {
+		log.Info("closing kafka producer")
+		return producer.Close()
+	})
+
+	if err := kafka.EnsureTopic(ctx, newKafkaConfig(cfg), modelKafka.OutboxScenarioResultTopic, 3, 3); err != nil {
+		log.Error("failed to ensure topic exists", zap.Error(err))
+	}
+
+	relay := outbox.NewRelay(repo.Outbox(), repo, kafka.NewOutboxSender(producer), outbox.Config{
+		BatchSize: cfg.Outbox.BatchSize,
+		Lease:     cfg.Outbox.Lease,
+		Retry: outbox.RetryPolicy{
+			MaxAttempts: cfg.Outbox.MaxAttempts,
+			BaseDelay:   cfg.Outbox.RetryBaseDelay,
+			MaxDelay:    cfg.Outbox.RetryMaxDelay,
+		},
+	})
+
+	go func() {
+		ticker := time.NewTicker(cfg.Outbox.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info("producer worker stopping")
+				return
+			case <-ticker.C:
+				// Полный батч означает, что в outbox могли остаться события, выбираем до опустошения
+				for {
+					processed, err := relay.ProcessBatch(ctx)
+					if err != nil {
+						log.Error("failed to process outbox events", zap.Error(err))
+					}
+					if err != nil || processed < int(cfg.Outbox.BatchSize) {
+						break
+					}
+				}
+			}
+		}
+	}()
+
+	log.Info("producer started successfully")
+
+	cls.Wait()
+
+	return 0
+}
+
+// newKafkaConfig создает настройки подключения к Kafka с аутентификацией, TLS и
+// exactly-once режимом из cfg
+func newKafkaConfig(cfg *config.Config) *kafka.Config {
+	kafkaCfg := kafka.DefaultConfig(cfg.Consumer.KafkaBrokers...).
+		WithSASL(cfg.Kafka.SASLMechanism, cfg.Kafka.Username, cfg.Kafka.Password).
+		WithTLS(kafka.TLSConfig{
+			Enabled:            cfg.Kafka.TLS.Enabled,
+			CAFile:             cfg.Kafka.TLS.CAFile,
+			CertFile:           cfg.Kafka.TLS.CertFile,
+			KeyFile:            cfg.Kafka.TLS.KeyFile,
+			InsecureSkipVerify: cfg.Kafka.TLS.InsecureSkipVerify,
+		})
+
+	if cfg.Kafka.TransactionalID != "" {
+		kafkaCfg.WithTransactionalID(cfg.Kafka.TransactionalIDFor("producer"))
+		kafkaCfg.TransactionTimeout = int(cfg.Kafka.TransactionTimeout.Seconds())
+	}
+	return kafkaCfg
+}
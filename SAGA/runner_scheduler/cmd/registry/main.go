@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"runner_scheduler/internal/config"
+	"runner_scheduler/internal/grpc_api/v1/registry"
+	"runner_scheduler/internal/infrastructure/repository"
+	"runner_scheduler/pkg/closer"
+	"runner_scheduler/pkg/database"
+	"runner_scheduler/pkg/logger"
+	pb "runner_scheduler/proto/server/registry/v1"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	log, err := logger.InitLogger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to init logger: %v\n", err)
+		return 1
+	}
+	defer log.Sync()
+
+	ctx := logger.WithContext(context.Background(), log)
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Error("failed to load config", zap.Error(err))
+		return 1
+	}
+
+	cls := closer.New(10 * time.Second)
+
+	dbPool, err := database.NewPool(ctx, cfg.Database, cfg.Pool)
+	if err != nil {
+		log.Error("failed to create db pool", zap.Error(err))
+		return 1
+	}
+	cls.Add(func() error {
+		log.Info("closing database connection")
+		database.Close(dbPool)
+		return nil
+	})
+
+	repo := repository.NewRepository(dbPool)
+
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Registry.GRPCPort))
+	if err != nil {
+		log.Error("failed to listen", zap.Error(err))
+		return 1
+	}
+
+	// Логгер прокидывается в контекст каждого запроса, чтобы handler мог использовать logger.FromContext
+	s := grpc.NewServer(grpc.UnaryInterceptor(func(reqCtx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(logger.WithContext(reqCtx, log), req)
+	}))
+	pb.RegisterRunnerRegistryServiceServer(s, registry.NewHandler(repo))
+	reflection.Register(s)
+
+	cls.Add(func() error {
+		log.Info("stopping grpc server")
+		s.GracefulStop()
+		return nil
+	})
+
+	go func() {
+		log.Info("registry server listening", zap.String("address", lis.Addr().String()))
+		if err := s.Serve(lis); err != nil {
+			log.Error("failed to serve", zap.Error(err))
+		}
+	}()
+
+	cls.Wait()
+
+	return 0
+}
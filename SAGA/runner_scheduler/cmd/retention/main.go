@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"runner_scheduler/internal/config"
+	"runner_scheduler/internal/infrastructure/archive"
+	"runner_scheduler/internal/infrastructure/metrics"
+	"runner_scheduler/internal/infrastructure/repository"
+	"runner_scheduler/internal/processors/retention_processor"
+	"runner_scheduler/pkg/closer"
+	"runner_scheduler/pkg/database"
+	"runner_scheduler/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	log, err := logger.InitLogger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to init logger: %v\n", err)
+		return 1
+	}
+	defer log.Sync()
+
+	ctx, cancel := context.WithCancel(logger.WithContext(context.Background(), log))
+	defer cancel()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Error("failed to load config", zap.Error(err))
+		return 1
+	}
+
+	cls := closer.New(10 * time.Second)
+	cls.Add(func() error {
+		log.Info("cancelling retention context")
+		cancel()
+		return nil
+	})
+
+	dbPool, err := database.NewPool(ctx, cfg.Database, cfg.Pool)
+	if err != nil {
+		log.Error("failed to create db pool", zap.Error(err))
+		return 1
+	}
+	cls.Add(func() error {
+		log.Info("closing database connection")
+		database.Close(dbPool)
+		return nil
+	})
+
+	repo := repository.NewRepository(dbPool)
+
+	var archiver retention_processor.Archiver
+	if cfg.Retention.Mode == config.RetentionModeArchive {
+		archiver = archive.NewJSONLArchiver(cfg.Retention.ArchiveDir)
+	}
+
+	retentionProcessor := retention_processor.NewProcessor(repo, repo.Outbox(), repo.Inbox(), archiver, metrics.NewRetention(), retention_processor.Config{
+		OlderThan:  cfg.Retention.OlderThan,
+		BatchSize:  cfg.Retention.BatchSize,
+		MaxBatches: cfg.Retention.MaxBatches,
+		BatchPause: cfg.Retention.BatchPause,
+	})
+
+	// Счетчики expvar, в том числе retention_rows_purged, отдаются на /debug/vars
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	metricsServer := &http.Server{
+		Addr:         fmt.Sprintf(":%d", cfg.Retention.MetricsPort),
+		Handler:      mux,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+	}
+	cls.Add(func() error {
+		log.Info("stopping metrics server")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return metricsServer.Shutdown(shutdownCtx)
+	})
+	go func() {
+		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error("metrics server error", zap.Error(err))
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(cfg.Retention.Interval)
+		defer ticker.Stop()
+
+		for {
+			purged, err := retentionProcessor.Purge(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Error("retention run failed", zap.Int("rows_purged", purged), zap.Error(err))
+			}
+
+			select {
+			case <-ctx.Done():
+				log.Info("retention worker stopping")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	log.Info("retention started successfully",
+		zap.String("mode", cfg.Retention.Mode),
+		zap.Duration("interval", cfg.Retention.Interval),
+		zap.Duration("older_than", cfg.Retention.OlderThan),
+	)
+
+	cls.Wait()
+
+	return 0
+}
@@ -2,21 +2,19 @@ package main
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"os"
 	"time"
 
 	"runner_scheduler/internal/config"
 	"runner_scheduler/internal/infrastructure/repository"
-	"runner_scheduler/internal/infrastructure/repository/queries/inbox_start_scenario"
-	modelerror "runner_scheduler/internal/models/error"
+	"runner_scheduler/internal/infrastructure/runner_service"
+	"runner_scheduler/internal/processors/scenario_result_processor"
+	"runner_scheduler/internal/processors/scheduler_processor"
 	"runner_scheduler/pkg/closer"
 	"runner_scheduler/pkg/database"
 	"runner_scheduler/pkg/logger"
 
-	"github.com/jackc/pgx/v5/pgtype"
 	"go.uber.org/zap"
 )
 
@@ -25,8 +23,6 @@ func main() {
 }
 
 func run() int {
-	ctx := context.Background()
-
 	log, err := logger.InitLogger()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to init logger: %v\n", err)
@@ -34,6 +30,9 @@ func run() int {
 	}
 	defer log.Sync()
 
+	ctx, cancel := context.WithCancel(logger.WithContext(context.Background(), log))
+	defer cancel()
+
 	cfg, err := config.Load()
 	if err != nil {
 		log.Error("failed to load config", zap.Error(err))
@@ -41,6 +40,11 @@ func run() int {
 	}
 
 	cls := closer.New(10 * time.Second)
+	cls.Add(func() error {
+		log.Info("cancelling scheduler context")
+		cancel()
+		return nil
+	})
 
 	dbPool, err := database.NewPool(ctx, cfg.Database, cfg.Pool)
 	if err != nil {
@@ -55,100 +59,68 @@ func run() int {
 
 	repo := repository.NewRepository(dbPool)
 
-	go func() {
-		for {
-			time.Sleep(time.Millisecond * 500)
-
-			if cls.IsShutdown() {
-				log.Info("shutdown signal received, stopping message processing")
-				break
-			}
-			log.Info("message processing")
-			msg, err := consumer.ReadMessage(ctx)
-			if err != nil {
-				log.Error("failed to read message", zap.Error(err))
-				time.Sleep(time.Second)
-				continue
-			}
-			log.Info("message read successfully")
-
-			var payload struct {
-				CameraID     int32  `json:"camera_id"`
-				ScenarioUUID string `json:"scenario_uuid"`
-				URL          string `json:"url"`
-			}
+	runnerService := runner_service.New(runner_service.Config{
+		Timeout: cfg.Scheduler.RunnerTimeout,
+	})
+	cls.Add(func() error {
+		log.Info("closing runner service connections")
+		return runnerService.Close()
+	})
 
-			if err := json.Unmarshal(msg.Value, &payload); err != nil {
-				log.Error("failed to unmarshal message", zap.Error(err))
-				continue
-			}
+	placement, err := scheduler_processor.NewPlacementStrategy(cfg.Scheduler.PlacementStrategy)
+	if err != nil {
+		log.Error("failed to create placement strategy", zap.Error(err))
+		return 1
+	}
 
-			outboxUUIDBytes, ok := msg.Headers["outbox_uuid"]
-			if !ok {
-				log.Error("outbox_uuid header not found")
-				continue
-			}
-			outboxUUIDStr := string(outboxUUIDBytes)
-
-			log.Debug("parsed message payload",
-				zap.String("outbox_uuid", outboxUUIDStr),
-				zap.Int32("camera_id", payload.CameraID),
-				zap.String("scenario_uuid", payload.ScenarioUUID),
-				zap.String("url", payload.URL),
-				zap.String("raw_message", string(msg.Value)),
-			)
-
-			outboxUUID := pgtype.UUID{}
-			if err := outboxUUID.Scan(outboxUUIDStr); err != nil {
-				log.Error("failed to parse outbox_uuid",
-					zap.Error(err),
-					zap.String("outbox_uuid_string", outboxUUIDStr))
-				continue
-			}
+	// Результаты запуска пишутся в outbox, отправкой в Kafka занимается cmd/producer
+	resultReporter := scenario_result_processor.NewReporter(repo.Outbox())
+
+	schedulerProcessor := scheduler_processor.NewProcessor(
+		repo,
+		runnerService,
+		resultReporter,
+		placement,
+		scheduler_processor.Config{
+			Retry: scheduler_processor.RetryPolicy{
+				MaxAttempts: cfg.Scheduler.MaxAttempts,
+				BaseDelay:   cfg.Scheduler.RetryBaseDelay,
+				MaxDelay:    cfg.Scheduler.RetryMaxDelay,
+			},
+			Lease:            cfg.Scheduler.Lease,
+			HeartbeatTimeout: cfg.Scheduler.HeartbeatTimeout,
+		},
+	)
 
-			scenarioUUID := pgtype.UUID{}
-			if err := scenarioUUID.Scan(payload.ScenarioUUID); err != nil {
-				log.Error("failed to parse scenario_uuid", zap.Error(err))
-				continue
-			}
+	go func() {
+		ticker := time.NewTicker(cfg.Scheduler.Interval)
+		defer ticker.Stop()
 
-			_, err = repo.CreateInboxStartScenario(ctx, inbox_start_scenario.CreateInboxStartScenarioParams{
-				OutboxUuid:   outboxUUID,
-				CameraID:     payload.CameraID,
-				ScenarioUuid: scenarioUUID,
-				Url:          payload.URL,
-			})
-			if err != nil {
-				if errors.Is(err, modelerror.ErrDuplicateKey) {
-					log.Warn("message already processed (duplicate key), skipping",
-						zap.String("outbox_uuid", outboxUUIDStr),
-						zap.Int32("camera_id", payload.CameraID),
-						zap.String("scenario_uuid", payload.ScenarioUUID),
-						zap.String("url", payload.URL),
-					)
-				} else {
-					log.Error("failed to save message to db", zap.Error(err))
-					continue
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info("scheduler worker stopping")
+				return
+			case <-ticker.C:
+				// Камеры с dead runner'ов переносятся до обработки inbox, чтобы новые камеры
+				// размещались с учетом уже перенесенных
+				if _, err := schedulerProcessor.RebalanceCameras(ctx, cfg.Scheduler.BatchSize); err != nil {
+					log.Error("failed to rebalance cameras", zap.Error(err))
+				}
+				// Стоп-команды обрабатываются первыми, чтобы освободить камеры до запуска новых воркеров
+				if _, err := schedulerProcessor.ProcessStopScenarios(ctx, cfg.Scheduler.BatchSize); err != nil {
+					log.Error("failed to process stop scenarios", zap.Error(err))
+				}
+				if _, err := schedulerProcessor.ProcessStartScenarios(ctx, cfg.Scheduler.BatchSize); err != nil {
+					log.Error("failed to process start scenarios", zap.Error(err))
 				}
-			} else {
-				log.Info("message saved to db successfully")
-			}
-
-			if err := consumer.CommitMessages(ctx, msg); err != nil {
-				log.Error("failed to commit message", zap.Error(err))
-				continue
 			}
-			log.Info("message committed successfully")
-			log.Info("message processed and committed successfully",
-				zap.String("outbox_uuid", outboxUUIDStr),
-				zap.Int32("camera_id", payload.CameraID),
-				zap.String("scenario_uuid", payload.ScenarioUUID),
-				zap.String("url", payload.URL),
-			)
 		}
 	}()
 
-	log.Info("consumer started successfully")
+	log.Info("scheduler started successfully",
+		zap.String("placement_strategy", cfg.Scheduler.PlacementStrategy),
+	)
 
 	cls.Wait()
 
@@ -9,11 +9,15 @@ import (
 )
 
 type Config struct {
-	API      APIConfig
-	Consumer ConsumerConfig
-	Database DatabaseConfig
-	Pool     PoolConfig
-	Kafka    KafkaConfig
+	API       APIConfig
+	Consumer  ConsumerConfig
+	Scheduler SchedulerConfig
+	Registry  RegistryConfig
+	Retention RetentionConfig
+	Outbox    OutboxConfig
+	Database  DatabaseConfig
+	Pool      PoolConfig
+	Kafka     KafkaConfig
 }
 
 type APIConfig struct {
@@ -28,6 +32,75 @@ type ConsumerConfig struct {
 	KafkaPassword            string
 	KafkaConsumerGroup       string
 	KafkaInboxInferenceTopic string
+	// MaxAttempts - количество попыток обработки сообщения, после которого оно сохраняется как poison.
+	// Используется, только если DeadLetterTopic пустой
+	MaxAttempts    int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// RetryTopicDelays - задержки топиков отложенных повторов: по топику на каждую задержку
+	RetryTopicDelays []time.Duration
+	// DeadLetterTopic - топик для сообщений, которые не удалось обработать. Если пустой, повторы
+	// выполняются на месте, а poison сообщения сохраняются в таблицу inbox
+	DeadLetterTopic string
+	// Workers - количество параллельных обработчиков на каждый читаемый топик
+	Workers int
+	// QueueSize - размер очереди каждого обработчика, при заполнении чтение из Kafka приостанавливается
+	QueueSize int
+	// DrainTimeout - сколько при остановке ждать завершения начатых обработок
+	DrainTimeout time.Duration
+	// BatchSize - сколько сообщений читать и сохранять в одной транзакции. 1 отключает пачки
+	BatchSize int
+	// BatchWait - сколько ждать остальные сообщения пачки после первого
+	BatchWait time.Duration
+}
+
+type SchedulerConfig struct {
+	RunnerTimeout     time.Duration
+	Interval          time.Duration
+	BatchSize         int32
+	Lease             time.Duration
+	MaxAttempts       int32
+	RetryBaseDelay    time.Duration
+	RetryMaxDelay     time.Duration
+	PlacementStrategy string
+	HeartbeatTimeout  time.Duration
+}
+
+type RegistryConfig struct {
+	GRPCPort int
+}
+
+// RetentionConfig задает очистку processed записей inbox и отправленных событий outbox
+type RetentionConfig struct {
+	// Mode - delete: строки удаляются; archive: перед удалением пишутся в gzip JSONL в ArchiveDir
+	Mode       string
+	ArchiveDir string
+	Interval   time.Duration
+	// OlderThan - возраст строки, после которого она удаляется. Для inbox должен быть больше
+	// окна повторной доставки из Kafka, иначе дубль события будет обработан заново
+	OlderThan time.Duration
+	BatchSize int32
+	// MaxBatches - ограничение количества батчей на таблицу за один проход
+	MaxBatches  int
+	BatchPause  time.Duration
+	MetricsPort int
+}
+
+const (
+	RetentionModeDelete  = "delete"
+	RetentionModeArchive = "archive"
+)
+
+// OutboxConfig задает публикацию событий общего outbox в cmd/producer
+type OutboxConfig struct {
+	PollInterval time.Duration
+	BatchSize    int32
+	// Lease - на сколько захваченные relay события скрываются от других экземпляров producer
+	Lease time.Duration
+	// MaxAttempts - количество попыток публикации события, после которого оно переводится в failed
+	MaxAttempts    int32
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
 }
 
 type DatabaseConfig struct {
@@ -40,7 +113,29 @@ type DatabaseConfig struct {
 
 type KafkaConfig struct {
 	Brokers []string
+	// SASLMechanism - PLAIN, SCRAM-SHA-256 или SCRAM-SHA-512. Пустой - без аутентификации
+	SASLMechanism string
+	Username      string
+	Password      string
+	TLS           KafkaTLSConfig
+	// TransactionalID - префикс transactional.id, включает exactly-once режим: producer'ы пишут в
+	// транзакциях Kafka, а consumer'ы читают только закоммиченные транзакции. Пустой - режим выключен.
+	// Полный transactional.id строит TransactionalIDFor
+	TransactionalID    string
+	TransactionTimeout time.Duration
+	// InstanceID отличает экземпляры сервиса в transactional.id, по умолчанию - имя хоста (пода)
+	InstanceID string
+}
+
+type KafkaTLSConfig struct {
+	Enabled  bool
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	// InsecureSkipVerify отключает проверку сертификата брокера, только для разработки
+	InsecureSkipVerify bool
 }
+
 type PoolConfig struct {
 	MaxConns          int32
 	MinConns          int32
@@ -73,9 +168,176 @@ func Load() (*Config, error) {
 
 	cfg.Consumer.KafkaUsername = getEnv("KAFKA_USERNAME", "")
 	cfg.Consumer.KafkaPassword = getEnv("KAFKA_PASSWORD", "")
-	cfg.Consumer.KafkaConsumerGroup = getEnv("KAFKA_CONSUMER_GROUP", "runner_scheduler_group")
+	cfg.Consumer.KafkaConsumerGroup = getEnv("KAFKA_CONSUMER_GROUP", "runner_scheduler_start_scenario_consumer_group")
 	cfg.Consumer.KafkaInboxInferenceTopic = getEnv("KAFKA_INBOX_INFERENCE_TOPIC", "inbox_inference")
 
+	cfg.Consumer.MaxAttempts, err = getEnvAsInt("CONSUMER_MAX_ATTEMPTS", 5)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONSUMER_MAX_ATTEMPTS: %w", err)
+	}
+
+	cfg.Consumer.RetryBaseDelay, err = getEnvAsDuration("CONSUMER_RETRY_BASE_DELAY", 500*time.Millisecond)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONSUMER_RETRY_BASE_DELAY: %w", err)
+	}
+
+	cfg.Consumer.RetryMaxDelay, err = getEnvAsDuration("CONSUMER_RETRY_MAX_DELAY", 30*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONSUMER_RETRY_MAX_DELAY: %w", err)
+	}
+
+	cfg.Consumer.RetryTopicDelays, err = parseDurations(getEnv("CONSUMER_RETRY_TOPIC_DELAYS", "10s,1m,10m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONSUMER_RETRY_TOPIC_DELAYS: %w", err)
+	}
+
+	cfg.Consumer.DeadLetterTopic = getEnv("CONSUMER_DEAD_LETTER_TOPIC", "outbox_scenario_api.dlq")
+
+	cfg.Consumer.Workers, err = getEnvAsInt("CONSUMER_WORKERS", 8)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONSUMER_WORKERS: %w", err)
+	}
+
+	cfg.Consumer.QueueSize, err = getEnvAsInt("CONSUMER_QUEUE_SIZE", 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONSUMER_QUEUE_SIZE: %w", err)
+	}
+
+	cfg.Consumer.DrainTimeout, err = getEnvAsDuration("CONSUMER_DRAIN_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONSUMER_DRAIN_TIMEOUT: %w", err)
+	}
+
+	cfg.Consumer.BatchSize, err = getEnvAsInt("CONSUMER_BATCH_SIZE", 100)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONSUMER_BATCH_SIZE: %w", err)
+	}
+
+	cfg.Consumer.BatchWait, err = getEnvAsDuration("CONSUMER_BATCH_WAIT", 100*time.Millisecond)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONSUMER_BATCH_WAIT: %w", err)
+	}
+
+	cfg.Scheduler.RunnerTimeout, err = getEnvAsDuration("RUNNER_GRPC_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RUNNER_GRPC_TIMEOUT: %w", err)
+	}
+
+	cfg.Scheduler.Interval, err = getEnvAsDuration("SCHEDULER_INTERVAL", time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCHEDULER_INTERVAL: %w", err)
+	}
+
+	schedulerBatchSize, err := getEnvAsInt("SCHEDULER_BATCH_SIZE", 50)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCHEDULER_BATCH_SIZE: %w", err)
+	}
+	cfg.Scheduler.BatchSize = int32(schedulerBatchSize)
+
+	cfg.Scheduler.Lease, err = getEnvAsDuration("SCHEDULER_LEASE", 2*time.Minute)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCHEDULER_LEASE: %w", err)
+	}
+
+	schedulerMaxAttempts, err := getEnvAsInt("SCHEDULER_MAX_ATTEMPTS", 5)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCHEDULER_MAX_ATTEMPTS: %w", err)
+	}
+	cfg.Scheduler.MaxAttempts = int32(schedulerMaxAttempts)
+
+	cfg.Scheduler.RetryBaseDelay, err = getEnvAsDuration("SCHEDULER_RETRY_BASE_DELAY", time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCHEDULER_RETRY_BASE_DELAY: %w", err)
+	}
+
+	cfg.Scheduler.RetryMaxDelay, err = getEnvAsDuration("SCHEDULER_RETRY_MAX_DELAY", time.Minute)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCHEDULER_RETRY_MAX_DELAY: %w", err)
+	}
+
+	cfg.Scheduler.PlacementStrategy = getEnv("SCHEDULER_PLACEMENT_STRATEGY", "least_loaded")
+
+	cfg.Scheduler.HeartbeatTimeout, err = getEnvAsDuration("RUNNER_HEARTBEAT_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RUNNER_HEARTBEAT_TIMEOUT: %w", err)
+	}
+
+	registryPort, err := getEnvAsInt("REGISTRY_GRPC_PORT", 50053)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REGISTRY_GRPC_PORT: %w", err)
+	}
+	cfg.Registry.GRPCPort = registryPort
+
+	cfg.Retention.Mode = getEnv("RETENTION_MODE", RetentionModeDelete)
+	if cfg.Retention.Mode != RetentionModeDelete && cfg.Retention.Mode != RetentionModeArchive {
+		return nil, fmt.Errorf("invalid RETENTION_MODE: %q, expected %s or %s",
+			cfg.Retention.Mode, RetentionModeDelete, RetentionModeArchive)
+	}
+	cfg.Retention.ArchiveDir = getEnv("RETENTION_ARCHIVE_DIR", "./archive")
+
+	cfg.Retention.Interval, err = getEnvAsDuration("RETENTION_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_INTERVAL: %w", err)
+	}
+
+	cfg.Retention.OlderThan, err = getEnvAsDuration("RETENTION_OLDER_THAN", 7*24*time.Hour)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_OLDER_THAN: %w", err)
+	}
+
+	retentionBatchSize, err := getEnvAsInt("RETENTION_BATCH_SIZE", 1000)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_BATCH_SIZE: %w", err)
+	}
+	cfg.Retention.BatchSize = int32(retentionBatchSize)
+
+	cfg.Retention.MaxBatches, err = getEnvAsInt("RETENTION_MAX_BATCHES", 100)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_MAX_BATCHES: %w", err)
+	}
+
+	cfg.Retention.BatchPause, err = getEnvAsDuration("RETENTION_BATCH_PAUSE", 100*time.Millisecond)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_BATCH_PAUSE: %w", err)
+	}
+
+	cfg.Retention.MetricsPort, err = getEnvAsInt("RETENTION_METRICS_PORT", 3002)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_METRICS_PORT: %w", err)
+	}
+
+	cfg.Outbox.PollInterval, err = getEnvAsDuration("OUTBOX_POLL_INTERVAL", 3*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOX_POLL_INTERVAL: %w", err)
+	}
+
+	outboxBatchSize, err := getEnvAsInt("OUTBOX_BATCH_SIZE", 1000)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOX_BATCH_SIZE: %w", err)
+	}
+	cfg.Outbox.BatchSize = int32(outboxBatchSize)
+
+	cfg.Outbox.Lease, err = getEnvAsDuration("OUTBOX_LEASE", time.Minute)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOX_LEASE: %w", err)
+	}
+
+	outboxMaxAttempts, err := getEnvAsInt("OUTBOX_MAX_ATTEMPTS", 10)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOX_MAX_ATTEMPTS: %w", err)
+	}
+	cfg.Outbox.MaxAttempts = int32(outboxMaxAttempts)
+
+	cfg.Outbox.RetryBaseDelay, err = getEnvAsDuration("OUTBOX_RETRY_BASE_DELAY", 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOX_RETRY_BASE_DELAY: %w", err)
+	}
+
+	cfg.Outbox.RetryMaxDelay, err = getEnvAsDuration("OUTBOX_RETRY_MAX_DELAY", 5*time.Minute)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOX_RETRY_MAX_DELAY: %w", err)
+	}
+
 	cfg.Database.Host = getEnv("DB_HOST", "localhost")
 
 	dbPort, err := getEnvAsInt("DB_PORT", 5433)
@@ -125,9 +387,52 @@ func Load() (*Config, error) {
 	if len(cfg.Kafka.Brokers) == 0 {
 		cfg.Kafka.Brokers = []string{"localhost:9092", "localhost:9093", "localhost:9094"}
 	}
+
+	cfg.Kafka.Username = getEnv("KAFKA_USERNAME", "")
+	cfg.Kafka.Password = getEnv("KAFKA_PASSWORD", "")
+	// Заданные учетные данные без механизма означают SASL PLAIN
+	defaultSASLMechanism := ""
+	if cfg.Kafka.Username != "" {
+		defaultSASLMechanism = "PLAIN"
+	}
+	cfg.Kafka.SASLMechanism = strings.ToUpper(getEnv("KAFKA_SASL_MECHANISM", defaultSASLMechanism))
+
+	cfg.Kafka.TLS.Enabled, err = getEnvAsBool("KAFKA_TLS_ENABLED", false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KAFKA_TLS_ENABLED: %w", err)
+	}
+	cfg.Kafka.TLS.CAFile = getEnv("KAFKA_TLS_CA_FILE", "")
+	cfg.Kafka.TLS.CertFile = getEnv("KAFKA_TLS_CERT_FILE", "")
+	cfg.Kafka.TLS.KeyFile = getEnv("KAFKA_TLS_KEY_FILE", "")
+	cfg.Kafka.TLS.InsecureSkipVerify, err = getEnvAsBool("KAFKA_TLS_INSECURE_SKIP_VERIFY", false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KAFKA_TLS_INSECURE_SKIP_VERIFY: %w", err)
+	}
+
+	cfg.Kafka.TransactionalID = getEnv("KAFKA_TRANSACTIONAL_ID", "")
+	cfg.Kafka.TransactionTimeout, err = getEnvAsDuration("KAFKA_TRANSACTION_TIMEOUT", time.Minute)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KAFKA_TRANSACTION_TIMEOUT: %w", err)
+	}
+	cfg.Kafka.InstanceID = getEnv("INSTANCE_ID", "")
+	if cfg.Kafka.InstanceID == "" {
+		cfg.Kafka.InstanceID, err = os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hostname for INSTANCE_ID: %w", err)
+		}
+	}
+
 	return cfg, nil
 }
 
+// TransactionalIDFor строит transactional.id экземпляра: префикс, InstanceID и parts - команда и,
+// для consumer'а, читаемый топик. Producer'ы с одинаковым transactional.id отменяют транзакции друг
+// друга, поэтому у каждого экземпляра и каждой сессии он свой. Отделять экземпляры по назначенным
+// партициям не нужно: подтверждения смещений в транзакциях защищены поколением consumer group
+func (c KafkaConfig) TransactionalIDFor(parts ...string) string {
+	return strings.Join(append([]string{c.TransactionalID, c.InstanceID}, parts...), "-")
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return strings.TrimSpace(value)
@@ -147,6 +452,18 @@ func getEnvAsInt(key string, defaultValue int) (int, error) {
 	return value, nil
 }
 
+func getEnvAsBool(key string, defaultValue bool) (bool, error) {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.ParseBool(strings.TrimSpace(valueStr))
+	if err != nil {
+		return false, err
+	}
+	return value, nil
+}
+
 func parseList(s string) []string {
 	if s == "" {
 		return []string{}
@@ -161,6 +478,19 @@ func parseList(s string) []string {
 	return result
 }
 
+func parseDurations(s string) ([]time.Duration, error) {
+	parts := parseList(s)
+	result := make([]time.Duration, 0, len(parts))
+	for _, part := range parts {
+		d, err := time.ParseDuration(part)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
 // getEnvAsDuration retrieves an environment variable as a time.Duration or returns a default value
 func getEnvAsDuration(key string, defaultValue time.Duration) (time.Duration, error) {
 	valueStr := os.Getenv(key)
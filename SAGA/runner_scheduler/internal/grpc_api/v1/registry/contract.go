@@ -0,0 +1,13 @@
+package registry
+
+import (
+	"context"
+	"runner_scheduler/internal/infrastructure/repository/queries/camera_assignment"
+	"runner_scheduler/internal/infrastructure/repository/queries/runner_node"
+)
+
+type Repository interface {
+	UpsertRunnerNode(ctx context.Context, arg runner_node.UpsertRunnerNodeParams) (runner_node.RunnerNode, error)
+	DeleteRunnerNode(ctx context.Context, nodeID string) error
+	ListCameraAssignmentsByCameraIDs(ctx context.Context, cameraIDs []int32) ([]camera_assignment.CameraAssignment, error)
+}
@@ -0,0 +1,150 @@
+package registry
+
+import (
+	"context"
+
+	"runner_scheduler/internal/infrastructure/repository/queries/camera_assignment"
+	"runner_scheduler/internal/infrastructure/repository/queries/runner_node"
+	"runner_scheduler/pkg/logger"
+	pb "runner_scheduler/proto/server/registry/v1"
+
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	pb.UnimplementedRunnerRegistryServiceServer
+	repo Repository
+}
+
+func NewHandler(repo Repository) *Handler {
+	return &Handler{
+		repo: repo,
+	}
+}
+
+func (h *Handler) Heartbeat(ctx context.Context, req *pb.HeartbeatRequest) (*pb.HeartbeatResponse, error) {
+	if req.GetNodeId() == "" || req.GetAddress() == "" {
+		return &pb.HeartbeatResponse{
+			Success: false,
+			Error:   "node_id and address are required",
+		}, nil
+	}
+	if req.GetCapacity() < 0 || req.GetWorkerCount() < 0 {
+		return &pb.HeartbeatResponse{
+			Success: false,
+			Error:   "capacity and worker_count cannot be negative",
+		}, nil
+	}
+
+	_, err := h.repo.UpsertRunnerNode(ctx, runner_node.UpsertRunnerNodeParams{
+		NodeID:      req.GetNodeId(),
+		Address:     req.GetAddress(),
+		Capacity:    req.GetCapacity(),
+		WorkerCount: req.GetWorkerCount(),
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to upsert runner node",
+			zap.String("node_id", req.GetNodeId()),
+			zap.Error(err),
+		)
+		return &pb.HeartbeatResponse{
+			Success: false,
+			Error:   "failed to register runner node",
+		}, nil
+	}
+
+	revoked, err := h.revokedCameras(ctx, req.GetNodeId(), req.GetWorkers())
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to check runner worker leases",
+			zap.String("node_id", req.GetNodeId()),
+			zap.Error(err),
+		)
+		return &pb.HeartbeatResponse{
+			Success: false,
+			Error:   "failed to check worker leases",
+		}, nil
+	}
+	if len(revoked) > 0 {
+		logger.FromContext(ctx).Warn("revoking stale workers on runner node",
+			zap.String("node_id", req.GetNodeId()),
+			zap.Int32s("camera_ids", revoked),
+		)
+	}
+
+	return &pb.HeartbeatResponse{
+		Success:          true,
+		RevokedCameraIds: revoked,
+	}, nil
+}
+
+func (h *Handler) revokedCameras(ctx context.Context, nodeID string, leases []*pb.WorkerLease) ([]int32, error) {
+	if len(leases) == 0 {
+		return nil, nil
+	}
+
+	cameraIDs := make([]int32, 0, len(leases))
+	for _, lease := range leases {
+		cameraIDs = append(cameraIDs, lease.GetCameraId())
+	}
+
+	assignments, err := h.repo.ListCameraAssignmentsByCameraIDs(ctx, cameraIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return staleLeases(nodeID, leases, assignments), nil
+}
+
+// staleLeases возвращает камеры, воркеры которых runner nodeID должен остановить:
+//   - камера больше не размещена (сценарий остановлен);
+//   - камера переразмещена с более новым fencing token'ом;
+//   - камера размещена с тем же токеном на другом runner'е.
+//
+// Токен воркера, больший токена размещения, означает, что перенос камеры на этот runner еще
+// не зафиксирован, и такой воркер не отзывается.
+func staleLeases(nodeID string, leases []*pb.WorkerLease, assignments []camera_assignment.CameraAssignment) []int32 {
+	byCamera := make(map[int32]camera_assignment.CameraAssignment, len(assignments))
+	for _, assignment := range assignments {
+		byCamera[assignment.CameraID] = assignment
+	}
+
+	var revoked []int32
+	for _, lease := range leases {
+		assignment, ok := byCamera[lease.GetCameraId()]
+		switch {
+		case !ok:
+			revoked = append(revoked, lease.GetCameraId())
+		case assignment.FencingToken > lease.GetFencingToken():
+			revoked = append(revoked, lease.GetCameraId())
+		case assignment.FencingToken == lease.GetFencingToken() && assignment.NodeID != nodeID:
+			revoked = append(revoked, lease.GetCameraId())
+		}
+	}
+	return revoked
+}
+
+func (h *Handler) Deregister(ctx context.Context, req *pb.DeregisterRequest) (*pb.DeregisterResponse, error) {
+	if req.GetNodeId() == "" {
+		return &pb.DeregisterResponse{
+			Success: false,
+			Error:   "node_id is required",
+		}, nil
+	}
+
+	if err := h.repo.DeleteRunnerNode(ctx, req.GetNodeId()); err != nil {
+		logger.FromContext(ctx).Error("failed to delete runner node",
+			zap.String("node_id", req.GetNodeId()),
+			zap.Error(err),
+		)
+		return &pb.DeregisterResponse{
+			Success: false,
+			Error:   "failed to deregister runner node",
+		}, nil
+	}
+
+	logger.FromContext(ctx).Info("runner node deregistered", zap.String("node_id", req.GetNodeId()))
+
+	return &pb.DeregisterResponse{
+		Success: true,
+	}, nil
+}
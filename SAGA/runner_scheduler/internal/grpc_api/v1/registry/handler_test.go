@@ -0,0 +1,41 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+
+	"runner_scheduler/internal/infrastructure/repository/queries/camera_assignment"
+	pb "runner_scheduler/proto/server/registry/v1"
+)
+
+func TestStaleLeases(t *testing.T) {
+	assignments := []camera_assignment.CameraAssignment{
+		{CameraID: 1, NodeID: "node-a", FencingToken: 3},
+		{CameraID: 2, NodeID: "node-b", FencingToken: 5},
+		{CameraID: 3, NodeID: "node-b", FencingToken: 2},
+		{CameraID: 4, NodeID: "node-b", FencingToken: 7},
+	}
+
+	leases := []*pb.WorkerLease{
+		{CameraId: 1, FencingToken: 3}, // актуальное размещение
+		{CameraId: 2, FencingToken: 4}, // камера перенесена на node-b
+		{CameraId: 3, FencingToken: 2}, // тот же токен, но камера на node-b
+		{CameraId: 4, FencingToken: 8}, // перенос на node-a еще не зафиксирован
+		{CameraId: 5, FencingToken: 1}, // сценарий камеры остановлен
+	}
+
+	got := staleLeases("node-a", leases, assignments)
+	want := []int32{2, 3, 5}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected revoked %v, got %v", want, got)
+	}
+}
+
+func TestStaleLeasesNoLeases(t *testing.T) {
+	got := staleLeases("node-a", nil, []camera_assignment.CameraAssignment{
+		{CameraID: 1, NodeID: "node-b", FencingToken: 1},
+	})
+	if len(got) != 0 {
+		t.Fatalf("expected nothing revoked, got %v", got)
+	}
+}
@@ -12,6 +12,20 @@ type Config struct {
 	MaxAttempts            int
 	Async                  bool
 	AllowAutoTopicCreation bool
+	// StartFromBeginning - группа без подтвержденных смещений читает топик с начала, а не
+	// только новые сообщения
+	StartFromBeginning bool
+	// SASL - аутентификация на брокерах. Пустой Mechanism - без аутентификации
+	SASL SASLConfig
+	// TLS - шифрование соединения с брокерами
+	TLS TLSConfig
+	// TransactionalID включает exactly-once режим: producer пишет сообщения в транзакциях Kafka
+	// с этим transactional.id, а consumer'ы читают только закоммиченные транзакции
+	// (read_committed). Должен быть уникальным для каждого экземпляра producer'а: экземпляр с тем
+	// же TransactionalID отменяет незавершенные транзакции предыдущего
+	TransactionalID string
+	// TransactionTimeout - секунды, после которых брокер отменяет незавершенную транзакцию
+	TransactionTimeout int
 }
 
 func DefaultConfig(brokers ...string) *Config {
@@ -27,6 +41,7 @@ func DefaultConfig(brokers ...string) *Config {
 		MaxAttempts:            3,
 		Async:                  false,
 		AllowAutoTopicCreation: true,
+		TransactionTimeout:     60,
 	}
 }
 
@@ -61,6 +76,22 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("max attempts must be greater than 0")
 	}
 
+	if err := c.SASL.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.TLS.Validate(); err != nil {
+		return err
+	}
+
+	if c.TransactionalID != "" && c.RequiredAcks != -1 {
+		return fmt.Errorf("transactional producer requires acks from all replicas (-1)")
+	}
+
+	if c.TransactionTimeout < 0 {
+		return fmt.Errorf("transaction timeout cannot be negative")
+	}
+
 	return nil
 }
 
@@ -83,3 +114,18 @@ func (c *Config) WithAsync(async bool) *Config {
 	c.Async = async
 	return c
 }
+
+func (c *Config) WithSASL(mechanism, username, password string) *Config {
+	c.SASL = SASLConfig{Mechanism: mechanism, Username: username, Password: password}
+	return c
+}
+
+func (c *Config) WithTLS(tls TLSConfig) *Config {
+	c.TLS = tls
+	return c
+}
+
+func (c *Config) WithTransactionalID(id string) *Config {
+	c.TransactionalID = id
+	return c
+}
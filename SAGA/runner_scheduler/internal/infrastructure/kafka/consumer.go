@@ -3,6 +3,9 @@ package kafka
 import (
 	"context"
 	"fmt"
+	"shared/offsets"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/segmentio/kafka-go"
@@ -11,17 +14,30 @@ import (
 
 type Consumer interface {
 	ReadMessage(ctx context.Context) (*Message, error)
+	// ReadBatch ждет первое сообщение, а затем дочитывает до max сообщений, пока не
+	// пройдет maxWait с момента получения первого
+	ReadBatch(ctx context.Context, max int, maxWait time.Duration) ([]*Message, error)
 	CommitMessages(ctx context.Context, msgs ...*Message) error
 	Close() error
 }
 
 type KafkaConsumer struct {
-	reader      *kafka.Reader
-	logger      *zap.Logger
-	lastMessage *kafka.Message
+	reader  *kafka.Reader
+	logger  *zap.Logger
+	offsets *offsets.Tracker
+	// commitMu упорядочивает подтверждения: смещения считаются по состоянию трекера и применяются
+	// к нему только после коммита в брокере, параллельный CommitMessages между этими шагами
+	// посчитал бы смещения по устаревшему состоянию
+	commitMu sync.Mutex
+
+	// client запрашивает подтвержденные в группе смещения после ребалансировки
+	client *kafka.Client
+	// rebalanced - была ребалансировка, после которой смещения трекера еще не сверены с группой
+	rebalanced atomic.Bool
 }
 
-func NewKafkaConsumer(cfg *Config, topics []string, consumerGroup string, logger *zap.Logger) (Consumer, error) {
+// NewKafkaConsumer создает consumer, читающий topics в группе cfg.ConsumerGroup
+func NewKafkaConsumer(cfg *Config, topics []string, logger *zap.Logger) (Consumer, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid kafka config: %w", err)
 	}
@@ -34,16 +50,34 @@ func NewKafkaConsumer(cfg *Config, topics []string, consumerGroup string, logger
 		return nil, fmt.Errorf("consumer group cannot be empty")
 	}
 
+	startOffset := kafka.LastOffset
+	if cfg.StartFromBeginning {
+		startOffset = kafka.FirstOffset
+	}
+
+	dialer, err := cfg.dialer()
+	if err != nil {
+		return nil, fmt.Errorf("invalid kafka security config: %w", err)
+	}
+
+	transport, err := cfg.transport()
+	if err != nil {
+		return nil, fmt.Errorf("invalid kafka security config: %w", err)
+	}
+
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:        cfg.Brokers,
-		GroupID:        consumerGroup,
+		Dialer:         dialer,
+		GroupID:        cfg.ConsumerGroup,
 		GroupTopics:    topics,
 		MinBytes:       10e3, // 10KB
 		MaxBytes:       10e6, // 10MB
 		MaxWait:        1 * time.Second,
 		ReadBackoffMin: 100 * time.Millisecond,
 		ReadBackoffMax: 1 * time.Second,
-		StartOffset:    kafka.LastOffset,
+		StartOffset:    startOffset,
+		// Сообщения отмененных транзакций exactly-once режима не должны попадать в обработку
+		IsolationLevel: kafka.ReadCommitted,
 		CommitInterval: 0, // Отключаем автоматический commit
 		Logger:         kafka.LoggerFunc(logger.Sugar().Debugf),
 		ErrorLogger:    kafka.LoggerFunc(logger.Sugar().Errorf),
@@ -56,8 +90,14 @@ func NewKafkaConsumer(cfg *Config, topics []string, consumerGroup string, logger
 	)
 
 	return &KafkaConsumer{
-		reader: reader,
-		logger: logger,
+		reader:  reader,
+		logger:  logger,
+		offsets: offsets.NewTracker(),
+		client: &kafka.Client{
+			Addr:      kafka.TCP(cfg.Brokers...),
+			Timeout:   10 * time.Second,
+			Transport: transport,
+		},
 	}, nil
 }
 
@@ -70,26 +110,10 @@ func (c *KafkaConsumer) ReadMessage(ctx context.Context) (*Message, error) {
 		return nil, fmt.Errorf("failed to fetch message: %w", err)
 	}
 
-	// Сохраняем последнее сообщение для commit
-	c.lastMessage = &kafkaMsg
-
-	var key *string
-	if len(kafkaMsg.Key) > 0 {
-		keyStr := string(kafkaMsg.Key)
-		key = &keyStr
-	}
-
-	headers := make(map[string][]byte)
-	for _, header := range kafkaMsg.Headers {
-		headers[header.Key] = header.Value
-	}
+	c.syncAfterRebalance(ctx)
+	c.offsets.Fetched(kafkaMsg.Topic, kafkaMsg.Partition, kafkaMsg.Offset)
 
-	msg := &Message{
-		Topic:   kafkaMsg.Topic,
-		Key:     key,
-		Value:   kafkaMsg.Value,
-		Headers: headers,
-	}
+	msg := convertMessage(kafkaMsg)
 
 	logFields := []zap.Field{
 		zap.String("topic", msg.Topic),
@@ -104,30 +128,120 @@ func (c *KafkaConsumer) ReadMessage(ctx context.Context) (*Message, error) {
 	return msg, nil
 }
 
+func (c *KafkaConsumer) ReadBatch(ctx context.Context, max int, maxWait time.Duration) ([]*Message, error) {
+	first, err := c.ReadMessage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := []*Message{first}
+	if max <= 1 {
+		return batch, nil
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, maxWait)
+	defer cancel()
+
+	for len(batch) < max {
+		msg, err := c.reader.FetchMessage(waitCtx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("failed to fetch message: %w", ctx.Err())
+			}
+			if waitCtx.Err() != nil {
+				break
+			}
+			c.logger.Error("failed to fetch message from kafka", zap.Error(err))
+			break
+		}
+
+		c.syncAfterRebalance(ctx)
+		c.offsets.Fetched(msg.Topic, msg.Partition, msg.Offset)
+		batch = append(batch, convertMessage(msg))
+	}
+
+	c.logger.Debug("batch fetched successfully", zap.Int("size", len(batch)))
+
+	return batch, nil
+}
+
+// CommitMessages подтверждает переданные сообщения. Сообщения могут обрабатываться не по
+// порядку: смещение партиции продвигается только до старшего сообщения, перед которым все
+// выданные сообщения этой партиции уже подтверждены
 func (c *KafkaConsumer) CommitMessages(ctx context.Context, msgs ...*Message) error {
-	if c.lastMessage == nil {
+	if len(msgs) == 0 {
 		return fmt.Errorf("no message to commit")
 	}
 
-	if err := c.reader.CommitMessages(ctx, *c.lastMessage); err != nil {
-		c.logger.Error("failed to commit message",
+	processed := make([]offsets.Offset, len(msgs))
+	for i, msg := range msgs {
+		if msg == nil {
+			return fmt.Errorf("message cannot be nil")
+		}
+		processed[i] = offsets.Offset{Topic: msg.Topic, Partition: msg.Partition, Offset: msg.Offset}
+	}
+
+	c.commitMu.Lock()
+	defer c.commitMu.Unlock()
+
+	commits, err := c.offsets.Pending(processed...)
+	if err != nil {
+		return fmt.Errorf("failed to commit message: %w", err)
+	}
+
+	if len(commits) == 0 {
+		c.offsets.Processed(processed...)
+		return nil
+	}
+
+	kafkaMsgs := make([]kafka.Message, 0, len(commits))
+	for key, offset := range commits {
+		kafkaMsgs = append(kafkaMsgs, kafka.Message{Topic: key.Topic, Partition: key.Partition, Offset: offset})
+	}
+
+	if err := c.reader.CommitMessages(ctx, kafkaMsgs...); err != nil {
+		c.logger.Error("failed to commit messages",
 			zap.Error(err),
-			zap.String("topic", c.lastMessage.Topic),
-			zap.Int("partition", c.lastMessage.Partition),
-			zap.Int64("offset", c.lastMessage.Offset),
+			zap.Int("partitions", len(kafkaMsgs)),
 		)
-		return fmt.Errorf("failed to commit message: %w", err)
+		return fmt.Errorf("failed to commit messages: %w", err)
 	}
+	c.offsets.Processed(processed...)
 
-	c.logger.Debug("message committed successfully",
-		zap.String("topic", c.lastMessage.Topic),
-		zap.Int("partition", c.lastMessage.Partition),
-		zap.Int64("offset", c.lastMessage.Offset),
-	)
+	for _, kafkaMsg := range kafkaMsgs {
+		c.logger.Debug("offset committed successfully",
+			zap.String("topic", kafkaMsg.Topic),
+			zap.Int("partition", kafkaMsg.Partition),
+			zap.Int64("offset", kafkaMsg.Offset),
+		)
+	}
 
 	return nil
 }
 
+// convertMessage переводит сообщение kafka-go в Message
+func convertMessage(kafkaMsg kafka.Message) *Message {
+	var key *string
+	if len(kafkaMsg.Key) > 0 {
+		keyStr := string(kafkaMsg.Key)
+		key = &keyStr
+	}
+
+	headers := make(map[string][]byte)
+	for _, header := range kafkaMsg.Headers {
+		headers[header.Key] = header.Value
+	}
+
+	return &Message{
+		Topic:     kafkaMsg.Topic,
+		Key:       key,
+		Value:     kafkaMsg.Value,
+		Headers:   headers,
+		Partition: kafkaMsg.Partition,
+		Offset:    kafkaMsg.Offset,
+	}
+}
+
 func (c *KafkaConsumer) Close() error {
 	if c == nil || c.reader == nil {
 		return nil
@@ -140,6 +254,10 @@ func (c *KafkaConsumer) Close() error {
 		return fmt.Errorf("close kafka reader: %w", err)
 	}
 
+	if transport, ok := c.client.Transport.(*kafka.Transport); ok {
+		transport.CloseIdleConnections()
+	}
+
 	if c.logger != nil {
 		c.logger.Info("kafka consumer closed")
 	}
@@ -25,10 +25,10 @@ func TestKafkaConsumerReadMessage(t *testing.T) {
 
 	// Setup consumer
 	consumerCfg := DefaultConfig(brokers...)
-	consumerCfg.ConsumerGroup = modelKafka.KafkaConsumerGroup
+	consumerCfg.ConsumerGroup = "runner_scheduler_start_scenario_consumer_group"
 	consumerCfg.AllowAutoTopicCreation = false
 
-	consumer, err := NewKafkaConsumer(consumerCfg, []string{topicName}, modelKafka.KafkaConsumerGroup, logger)
+	consumer, err := NewKafkaConsumer(consumerCfg, []string{topicName}, logger)
 	if err != nil {
 		t.Fatalf("failed to create Kafka consumer: %v", err)
 	}
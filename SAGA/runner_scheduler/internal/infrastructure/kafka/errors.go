@@ -0,0 +1,44 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// BatchError - часть сообщений батча не доставлена. Errors выровнен по индексам сообщений,
+// переданных в SendMessages: nil означает, что сообщение подтверждено брокером
+type BatchError struct {
+	Errors []error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d of %d messages failed", e.Count(), len(e.Errors))
+}
+
+// Count возвращает количество недоставленных сообщений
+func (e *BatchError) Count() int {
+	count := 0
+	for _, err := range e.Errors {
+		if err != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// Failed сообщает, не доставлено ли сообщение с индексом i
+func (e *BatchError) Failed(i int) bool {
+	return i < len(e.Errors) && e.Errors[i] != nil
+}
+
+// newBatchError переводит kafka.WriteErrors в BatchError. Для остальных ошибок результат
+// отправки каждого сообщения неизвестен, и возвращается nil
+func newBatchError(err error, count int) *BatchError {
+	var writeErrors kafka.WriteErrors
+	if !errors.As(err, &writeErrors) || len(writeErrors) != count {
+		return nil
+	}
+	return &BatchError{Errors: []error(writeErrors)}
+}
@@ -0,0 +1,115 @@
+package kafka
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"shared/kafkasecurity"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+	"go.uber.org/zap"
+)
+
+// franzOpts создает опции клиента franz-go для exactly-once режима: в kafka-go нет транзакций.
+// Подключение, аутентификация и распределение сообщений по партициям совпадают с kafka-go
+// producer'ом, поэтому сообщения одного ключа попадают в ту же партицию в обоих режимах
+func (c *Config) franzOpts(logger *zap.Logger) ([]kgo.Opt, error) {
+	tlsCfg, err := c.TLS.Config()
+	if err != nil {
+		return nil, fmt.Errorf("invalid kafka security config: %w", err)
+	}
+
+	compression, err := franzCompression(c.Compression)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(c.Brokers...),
+		kgo.DialTimeout(kafkasecurity.DialTimeout),
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		kgo.ProducerBatchCompression(compression),
+		kgo.ProduceRequestTimeout(time.Duration(c.WriteTimeout) * time.Second),
+		kgo.RecordRetries(c.MaxAttempts),
+		// Как keyedBalancer: ключ - FNV-1a по модулю числа партиций, без ключа - любая партиция
+		kgo.RecordPartitioner(kgo.StickyKeyPartitioner(kgo.SaramaCompatHasher(fnv32a))),
+		kgo.WithLogger(franzLogger{logger: logger.Sugar()}),
+	}
+	if tlsCfg != nil {
+		opts = append(opts, kgo.DialTLSConfig(tlsCfg))
+	}
+	if mechanism := franzSASL(c.SASL); mechanism != nil {
+		opts = append(opts, kgo.SASL(mechanism))
+	}
+	if c.AllowAutoTopicCreation {
+		opts = append(opts, kgo.AllowAutoTopicCreation())
+	}
+	if c.TransactionalID != "" {
+		opts = append(opts, kgo.TransactionalID(c.TransactionalID))
+	}
+	if c.TransactionTimeout > 0 {
+		opts = append(opts, kgo.TransactionTimeout(time.Duration(c.TransactionTimeout)*time.Second))
+	}
+	return opts, nil
+}
+
+func franzSASL(cfg SASLConfig) sasl.Mechanism {
+	switch cfg.Mechanism {
+	case kafkasecurity.SASLPlain:
+		return plain.Auth{User: cfg.Username, Pass: cfg.Password}.AsMechanism()
+	case kafkasecurity.SASLScramSHA256:
+		return scram.Auth{User: cfg.Username, Pass: cfg.Password}.AsSha256Mechanism()
+	case kafkasecurity.SASLScramSHA512:
+		return scram.Auth{User: cfg.Username, Pass: cfg.Password}.AsSha512Mechanism()
+	default:
+		return nil
+	}
+}
+
+// franzCompression переводит кодек сжатия kafka-go (Config.Compression) в кодек franz-go
+func franzCompression(compression int) (kgo.CompressionCodec, error) {
+	switch compression {
+	case 0:
+		return kgo.NoCompression(), nil
+	case 1:
+		return kgo.GzipCompression(), nil
+	case 2:
+		return kgo.SnappyCompression(), nil
+	case 3:
+		return kgo.Lz4Compression(), nil
+	case 4:
+		return kgo.ZstdCompression(), nil
+	default:
+		return kgo.CompressionCodec{}, fmt.Errorf("unsupported compression %d", compression)
+	}
+}
+
+func fnv32a(key []byte) uint32 {
+	h := fnv.New32a()
+	h.Write(key)
+	return h.Sum32()
+}
+
+// franzLogger пишет логи franz-go в zap
+type franzLogger struct {
+	logger *zap.SugaredLogger
+}
+
+func (l franzLogger) Level() kgo.LogLevel {
+	return kgo.LogLevelInfo
+}
+
+func (l franzLogger) Log(level kgo.LogLevel, msg string, keyvals ...any) {
+	switch level {
+	case kgo.LogLevelError:
+		l.logger.Errorw(msg, keyvals...)
+	case kgo.LogLevelWarn:
+		l.logger.Warnw(msg, keyvals...)
+	default:
+		l.logger.Debugw(msg, keyvals...)
+	}
+}
@@ -0,0 +1,24 @@
+package kafka
+
+import (
+	"context"
+
+	"runner_scheduler/pkg/inbox"
+)
+
+// InboxPublisher публикует сообщения inbox.Consumer в топики повторов и DLQ через Producer
+type InboxPublisher struct {
+	producer Producer
+}
+
+func NewInboxPublisher(producer Producer) *InboxPublisher {
+	return &InboxPublisher{producer: producer}
+}
+
+func (p *InboxPublisher) Publish(ctx context.Context, msg inbox.Message) error {
+	var key *string
+	if msg.Key != "" {
+		key = &msg.Key
+	}
+	return p.producer.SendMessage(ctx, NewMessage(msg.Topic, key, msg.Value, msg.Headers))
+}
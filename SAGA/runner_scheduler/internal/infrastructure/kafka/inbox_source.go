@@ -0,0 +1,115 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	"runner_scheduler/pkg/inbox"
+)
+
+// InboxSource читает сообщения inbox.Consumer из Consumer
+type InboxSource struct {
+	consumer Consumer
+}
+
+func NewInboxSource(consumer Consumer) *InboxSource {
+	return &InboxSource{consumer: consumer}
+}
+
+func (s *InboxSource) Fetch(ctx context.Context) (inbox.Message, error) {
+	msg, err := s.consumer.ReadMessage(ctx)
+	if err != nil {
+		return inbox.Message{}, err
+	}
+	return toInboxMessage(msg), nil
+}
+
+func (s *InboxSource) FetchBatch(ctx context.Context, max int, maxWait time.Duration) ([]inbox.Message, error) {
+	batch, err := s.consumer.ReadBatch(ctx, max, maxWait)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := make([]inbox.Message, len(batch))
+	for i, msg := range batch {
+		msgs[i] = toInboxMessage(msg)
+	}
+	return msgs, nil
+}
+
+func (s *InboxSource) Commit(ctx context.Context, msg inbox.Message) error {
+	raw, _ := msg.Raw.(*Message)
+	return s.consumer.CommitMessages(ctx, raw)
+}
+
+// CommitBatch подтверждает пачку одним запросом к Kafka
+func (s *InboxSource) CommitBatch(ctx context.Context, msgs []inbox.Message) error {
+	raws := make([]*Message, len(msgs))
+	for i, msg := range msgs {
+		raws[i], _ = msg.Raw.(*Message)
+	}
+	return s.consumer.CommitMessages(ctx, raws...)
+}
+
+func toInboxMessage(msg *Message) inbox.Message {
+	var key string
+	if msg.Key != nil {
+		key = *msg.Key
+	}
+	return inbox.Message{
+		Topic:     msg.Topic,
+		Key:       key,
+		Value:     msg.Value,
+		Headers:   msg.Headers,
+		Partition: msg.Partition,
+		Raw:       msg,
+	}
+}
+
+// TransactSource читает сообщения inbox.Consumer из TransactSession. Смещения подтверждаются
+// в транзакции сессии вместе с публикациями в топики повторов и DLQ, поэтому Commit и
+// CommitBatch ничего не делают
+type TransactSource struct {
+	session *TransactSession
+}
+
+func NewTransactSource(session *TransactSession) *TransactSource {
+	return &TransactSource{session: session}
+}
+
+func (s *TransactSource) Fetch(ctx context.Context) (inbox.Message, error) {
+	msgs, err := s.FetchBatch(ctx, 1, 0)
+	if err != nil {
+		return inbox.Message{}, err
+	}
+	return msgs[0], nil
+}
+
+func (s *TransactSource) FetchBatch(ctx context.Context, max int, maxWait time.Duration) ([]inbox.Message, error) {
+	batch, err := s.session.ReadBatch(ctx, max, maxWait)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := make([]inbox.Message, len(batch))
+	for i, msg := range batch {
+		msgs[i] = toInboxMessage(msg)
+	}
+	return msgs, nil
+}
+
+func (s *TransactSource) Commit(ctx context.Context, msg inbox.Message) error {
+	return nil
+}
+
+func (s *TransactSource) CommitBatch(ctx context.Context, msgs []inbox.Message) error {
+	return nil
+}
+
+func (s *TransactSource) Begin() error {
+	return s.session.Begin()
+}
+
+func (s *TransactSource) End(ctx context.Context, commit bool) (bool, error) {
+	return s.session.End(ctx, commit)
+}
@@ -20,14 +20,26 @@ type KafkaProducer struct {
 	logger *zap.Logger
 }
 
+// NewKafkaProducer создает producer. Если в cfg задан TransactionalID, возвращается
+// TransactionalProducer, и каждый вызов SendMessages выполняется в своей транзакции
 func NewKafkaProducer(cfg *Config, logger *zap.Logger) (Producer, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid kafka config: %w", err)
 	}
 
+	if cfg.TransactionalID != "" {
+		return NewTransactionalProducer(cfg, logger)
+	}
+
+	transport, err := cfg.transport()
+	if err != nil {
+		return nil, fmt.Errorf("invalid kafka security config: %w", err)
+	}
+
 	writer := &kafka.Writer{
 		Addr:                   kafka.TCP(cfg.Brokers...),
-		Balancer:               &kafka.LeastBytes{},
+		Transport:              transport,
+		Balancer:               &keyedBalancer{keyed: &kafka.Hash{}, unkeyed: &kafka.LeastBytes{}},
 		ReadTimeout:            time.Duration(cfg.ReadTimeout) * time.Second,
 		WriteTimeout:           time.Duration(cfg.WriteTimeout) * time.Second,
 		RequiredAcks:           kafka.RequiredAcks(cfg.RequiredAcks),
@@ -127,6 +139,14 @@ func (p KafkaProducer) SendMessages(ctx context.Context, msgs []*Message) error
 
 	err := p.writer.WriteMessages(ctx, kafkaMsgs...)
 	if err != nil {
+		if batchErr := newBatchError(err, len(msgs)); batchErr != nil {
+			p.logger.Error("failed to send part of messages batch to kafka",
+				zap.Int("messages_count", len(msgs)),
+				zap.Int("failed_count", batchErr.Count()),
+			)
+			return fmt.Errorf("failed to send messages batch: %w", batchErr)
+		}
+
 		p.logger.Error("failed to send messages batch to kafka",
 			zap.Int("messages_count", len(msgs)),
 			zap.Error(err),
@@ -153,6 +173,11 @@ func (p *KafkaProducer) Close() error {
 		return fmt.Errorf("close kafka writer: %w", err)
 	}
 
+	// Writer закрывает соединения только своего транспорта по умолчанию
+	if transport, ok := p.writer.Transport.(*kafka.Transport); ok {
+		transport.CloseIdleConnections()
+	}
+
 	if p.logger != nil {
 		p.logger.Info("kafka producer closed")
 	}
@@ -170,3 +195,17 @@ func convertHeaders(headers map[string][]byte) []kafka.Header {
 	}
 	return kafkaHeaders
 }
+
+// keyedBalancer распределяет сообщения с ключом по хэшу ключа, чтобы сообщения одного ключа
+// попадали в одну партицию и читались по порядку, а сообщения без ключа - по LeastBytes
+type keyedBalancer struct {
+	keyed   kafka.Balancer
+	unkeyed kafka.Balancer
+}
+
+func (b *keyedBalancer) Balance(msg kafka.Message, partitions ...int) int {
+	if msg.Key != nil {
+		return b.keyed.Balance(msg, partitions...)
+	}
+	return b.unkeyed.Balance(msg, partitions...)
+}
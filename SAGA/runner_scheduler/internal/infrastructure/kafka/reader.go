@@ -0,0 +1,132 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Record - сообщение вместе с его положением в топике
+type Record struct {
+	Partition int
+	Offset    int64
+	Message   *Message
+}
+
+// readIdleTimeout - сколько ждать следующего сообщения партиции, прежде чем считать ее
+// прочитанной. В конце партиции могут лежать маркеры транзакций и сообщения отмененных
+// транзакций, которые read_committed reader не отдает, поэтому до последнего стабильного
+// смещения он может так и не дойти
+const readIdleTimeout = 5 * time.Second
+
+// ReadTopic читает все сообщения подтвержденных транзакций, записанные к моменту вызова, без
+// consumer group и без подтверждения смещений. Партиции читаются по очереди, fn вызывается для
+// каждого сообщения; если fn возвращает false, чтение прекращается
+func ReadTopic(ctx context.Context, cfg *Config, topic string, fn func(Record) bool) error {
+	dialer, err := adminDialer(cfg)
+	if err != nil {
+		return err
+	}
+
+	transport, err := cfg.transport()
+	if err != nil {
+		return fmt.Errorf("invalid kafka security config: %w", err)
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", cfg.Brokers[0])
+	if err != nil {
+		return fmt.Errorf("failed to dial kafka: %w", err)
+	}
+	partitions, err := conn.ReadPartitions(topic)
+	conn.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read partitions: %w", err)
+	}
+
+	for _, partition := range partitions {
+		more, err := readPartition(ctx, dialer, transport, partition, topic, fn)
+		if err != nil {
+			return fmt.Errorf("partition %d: %w", partition.ID, err)
+		}
+		if !more {
+			return nil
+		}
+	}
+	return nil
+}
+
+func readPartition(ctx context.Context, dialer *kafka.Dialer, transport *kafka.Transport, partition kafka.Partition, topic string, fn func(Record) bool) (bool, error) {
+	leader := fmt.Sprintf("%s:%d", partition.Leader.Host, partition.Leader.Port)
+	first, last, err := stableOffsets(ctx, transport, leader, topic, partition.ID)
+	if err != nil {
+		return false, err
+	}
+	if first >= last {
+		return true, nil
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        []string{leader},
+		Dialer:         dialer,
+		Topic:          topic,
+		Partition:      partition.ID,
+		MaxBytes:       10e6, // 10MB
+		IsolationLevel: kafka.ReadCommitted,
+	})
+	defer reader.Close()
+
+	if err := reader.SetOffset(first); err != nil {
+		return false, fmt.Errorf("failed to set offset: %w", err)
+	}
+
+	for {
+		readCtx, cancel := context.WithTimeout(ctx, readIdleTimeout)
+		kafkaMsg, err := reader.ReadMessage(readCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return false, nil
+			}
+			if errors.Is(err, context.DeadlineExceeded) {
+				return true, nil
+			}
+			return false, fmt.Errorf("failed to read message: %w", err)
+		}
+
+		if !fn(Record{Partition: kafkaMsg.Partition, Offset: kafkaMsg.Offset, Message: convertMessage(kafkaMsg)}) {
+			return false, nil
+		}
+		if kafkaMsg.Offset+1 >= last {
+			return true, nil
+		}
+	}
+}
+
+// stableOffsets возвращает первое смещение партиции и последнее стабильное: дальше него лежат
+// сообщения незавершенных транзакций, которые read_committed reader не отдаст
+func stableOffsets(ctx context.Context, transport *kafka.Transport, leader, topic string, partition int) (int64, int64, error) {
+	client := &kafka.Client{Addr: kafka.TCP(leader), Transport: transport}
+	resp, err := client.ListOffsets(ctx, &kafka.ListOffsetsRequest{
+		Topics: map[string][]kafka.OffsetRequest{
+			topic: {kafka.FirstOffsetOf(partition), kafka.LastOffsetOf(partition)},
+		},
+		IsolationLevel: kafka.ReadCommitted,
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read offsets: %w", err)
+	}
+
+	for _, offsets := range resp.Topics[topic] {
+		if offsets.Partition != partition {
+			continue
+		}
+		if offsets.Error != nil {
+			return 0, 0, fmt.Errorf("failed to read offsets: %w", offsets.Error)
+		}
+		return offsets.FirstOffset, offsets.LastOffset, nil
+	}
+	return 0, 0, fmt.Errorf("failed to read offsets: partition %d missing in response", partition)
+}
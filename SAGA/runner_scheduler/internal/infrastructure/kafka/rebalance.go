@@ -0,0 +1,54 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// syncAfterRebalance снимает с трекера смещения, оставшиеся от прежнего владения партициями.
+// kafka-go не сообщает о ребалансировке, но считает ее в статистике reader'а вместе с
+// переподключениями к лидеру партиции. После любого из этих событий трекер забывает смещения
+// ниже подтвержденных в группе: сообщения до них уже обработаны, поэтому сброс безопасен и
+// при переподключении без ребалансировки. Если запросить смещения не удалось, запрос
+// повторяется при следующем чтении
+func (c *KafkaConsumer) syncAfterRebalance(ctx context.Context) {
+	if c.reader.Stats().Rebalances > 0 {
+		c.rebalanced.Store(true)
+	}
+	if !c.rebalanced.Load() {
+		return
+	}
+
+	topics := make(map[string][]int)
+	for _, p := range c.offsets.Partitions() {
+		topics[p.Topic] = append(topics[p.Topic], p.Partition)
+	}
+	if len(topics) == 0 {
+		c.rebalanced.Store(false)
+		return
+	}
+
+	resp, err := c.client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{
+		GroupID: c.reader.Config().GroupID,
+		Topics:  topics,
+	})
+	if err == nil {
+		err = resp.Error
+	}
+	if err != nil {
+		c.logger.Warn("failed to fetch committed offsets after rebalance", zap.Error(err))
+		return
+	}
+
+	for topic, partitions := range resp.Topics {
+		for _, p := range partitions {
+			if p.Error != nil || p.CommittedOffset < 0 {
+				continue
+			}
+			c.offsets.Committed(topic, p.Partition, p.CommittedOffset)
+		}
+	}
+	c.rebalanced.Store(false)
+}
@@ -0,0 +1,320 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
+)
+
+// TransactionalProducer пишет каждый вызов SendMessages в отдельной транзакции Kafka: сообщения
+// вызова становятся видны read_committed consumer'ам все вместе, а если транзакция отменена - не
+// видны вовсе. Работает на franz-go, в kafka-go транзакций нет
+type TransactionalProducer struct {
+	client *kgo.Client
+	logger *zap.Logger
+
+	// mu упорядочивает транзакции: у producer'а с transactional.id открыта не больше одной.
+	// Под mu выполняются только запросы к брокерам
+	mu sync.Mutex
+}
+
+func NewTransactionalProducer(cfg *Config, logger *zap.Logger) (*TransactionalProducer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid kafka config: %w", err)
+	}
+
+	if cfg.TransactionalID == "" {
+		return nil, fmt.Errorf("transactional id cannot be empty")
+	}
+
+	opts, err := cfg.franzOpts(logger)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create kafka client: %w", err)
+	}
+
+	logger.Info("kafka transactional producer initialized",
+		zap.Strings("brokers", cfg.Brokers),
+		zap.String("transactional_id", cfg.TransactionalID),
+	)
+
+	return &TransactionalProducer{client: client, logger: logger}, nil
+}
+
+func (p *TransactionalProducer) SendMessage(ctx context.Context, msg *Message) error {
+	return p.SendMessages(ctx, []*Message{msg})
+}
+
+// SendMessages отправляет msgs в одной транзакции. При ошибке транзакция отменяется, и
+// недоставленными считаются все сообщения
+func (p *TransactionalProducer) SendMessages(ctx context.Context, msgs []*Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	records, err := toRecords(msgs)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.client.BeginTransaction(); err != nil {
+		return fmt.Errorf("begin kafka transaction: %w", err)
+	}
+
+	produceErr := p.client.ProduceSync(ctx, records...).FirstErr()
+	endErr := p.client.EndTransaction(ctx, kgo.TransactionEndTry(produceErr == nil))
+	if err := errors.Join(produceErr, endErr); err != nil {
+		p.logger.Error("kafka transaction aborted",
+			zap.Int("messages_count", len(msgs)),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to send messages in transaction: %w", err)
+	}
+
+	p.logger.Debug("kafka transaction committed", zap.Int("messages_count", len(msgs)))
+	return nil
+}
+
+func (p *TransactionalProducer) Close() error {
+	p.client.Close()
+	p.logger.Info("kafka transactional producer closed")
+	return nil
+}
+
+// TransactSession читает топики в consumer group и публикует сообщения в транзакциях Kafka, в
+// которых подтверждаются и смещения прочитанных сообщений (consume-transform-produce).
+//
+// Транзакция коммитится, только если с ее начала группа не ребалансировалась, а смещения в ней
+// подтверждаются с поколением группы: экземпляр, у которого забрали партицию, не может
+// закоммитить ее сообщения. Отмененная транзакция возвращает чтение к последним подтвержденным
+// смещениям, и сообщения приходят повторно. Методы не вызываются параллельно
+type TransactSession struct {
+	session *kgo.GroupTransactSession
+	topics  []string
+	logger  *zap.Logger
+}
+
+// NewTransactSession создает сессию, читающую topics в группе cfg.ConsumerGroup.
+// cfg.TransactionalID должен быть уникальным для экземпляра сервиса и читаемых топиков: сессия
+// с тем же transactional.id отменяет транзакции предыдущей
+func NewTransactSession(cfg *Config, topics []string, logger *zap.Logger) (*TransactSession, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid kafka config: %w", err)
+	}
+
+	if cfg.TransactionalID == "" {
+		return nil, fmt.Errorf("transactional id cannot be empty")
+	}
+
+	if len(topics) == 0 {
+		return nil, fmt.Errorf("topics list cannot be empty")
+	}
+
+	if cfg.ConsumerGroup == "" {
+		return nil, fmt.Errorf("consumer group cannot be empty")
+	}
+
+	opts, err := cfg.franzOpts(logger)
+	if err != nil {
+		return nil, err
+	}
+
+	startOffset := kgo.NewOffset().AtEnd()
+	if cfg.StartFromBeginning {
+		startOffset = kgo.NewOffset().AtStart()
+	}
+
+	opts = append(opts,
+		kgo.ConsumerGroup(cfg.ConsumerGroup),
+		kgo.ConsumeTopics(topics...),
+		kgo.ConsumeResetOffset(startOffset),
+		kgo.FetchIsolationLevel(kgo.ReadCommitted()),
+		// Новый владелец партиции не читает смещения, пока транзакция прежнего не завершена
+		kgo.RequireStableFetchOffsets(),
+		kgo.FetchMaxWait(time.Second),
+	)
+
+	session, err := kgo.NewGroupTransactSession(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create kafka transact session: %w", err)
+	}
+
+	logger.Info("kafka transact session initialized",
+		zap.Strings("brokers", cfg.Brokers),
+		zap.String("consumer_group", cfg.ConsumerGroup),
+		zap.Strings("topics", topics),
+		zap.String("transactional_id", cfg.TransactionalID),
+	)
+
+	return &TransactSession{session: session, topics: topics, logger: logger}, nil
+}
+
+// ReadBatch ждет первое сообщение и затем до maxWait дочитывает пачку до max сообщений
+func (s *TransactSession) ReadBatch(ctx context.Context, max int, maxWait time.Duration) ([]*Message, error) {
+	batch, err := s.poll(ctx, max)
+	if err != nil {
+		return nil, err
+	}
+	if len(batch) >= max || maxWait <= 0 {
+		return batch, nil
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, maxWait)
+	defer cancel()
+
+	for len(batch) < max {
+		fetches := s.session.PollRecords(waitCtx, max-len(batch))
+		if fetches.IsClientClosed() {
+			return nil, fmt.Errorf("failed to fetch messages: client closed")
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("failed to fetch messages: %w", err)
+		}
+
+		records := fetches.Records()
+		for _, record := range records {
+			batch = append(batch, convertRecord(record))
+		}
+		if waitCtx.Err() != nil {
+			break
+		}
+		if len(records) == 0 {
+			if err := fetches.Err(); err != nil {
+				s.logger.Error("failed to fetch messages from kafka", zap.Error(err))
+				break
+			}
+		}
+	}
+
+	s.logger.Debug("batch fetched successfully", zap.Int("size", len(batch)))
+
+	return batch, nil
+}
+
+// poll ждет, пока не придет хотя бы одно сообщение, и возвращает до max уже полученных
+func (s *TransactSession) poll(ctx context.Context, max int) ([]*Message, error) {
+	for {
+		fetches := s.session.PollRecords(ctx, max)
+		if fetches.IsClientClosed() {
+			return nil, fmt.Errorf("failed to fetch messages: client closed")
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("failed to fetch messages: %w", err)
+		}
+
+		records := fetches.Records()
+		if len(records) == 0 {
+			if err := fetches.Err(); err != nil {
+				s.logger.Error("failed to fetch messages from kafka", zap.Error(err))
+				return nil, fmt.Errorf("failed to fetch messages: %w", err)
+			}
+			continue
+		}
+
+		msgs := make([]*Message, len(records))
+		for i, record := range records {
+			msgs[i] = convertRecord(record)
+		}
+		return msgs, nil
+	}
+}
+
+// Begin начинает транзакцию для прочитанных сообщений
+func (s *TransactSession) Begin() error {
+	if err := s.session.Begin(); err != nil {
+		return fmt.Errorf("begin kafka transaction: %w", err)
+	}
+	return nil
+}
+
+func (s *TransactSession) SendMessage(ctx context.Context, msg *Message) error {
+	return s.SendMessages(ctx, []*Message{msg})
+}
+
+// SendMessages отправляет сообщения в текущей транзакции. Ошибка означает, что транзакцию
+// нужно отменить
+func (s *TransactSession) SendMessages(ctx context.Context, msgs []*Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	records, err := toRecords(msgs)
+	if err != nil {
+		return err
+	}
+
+	if err := s.session.ProduceSync(ctx, records...).FirstErr(); err != nil {
+		return fmt.Errorf("failed to send messages in transaction: %w", err)
+	}
+	return nil
+}
+
+// End коммитит транзакцию вместе со смещениями всех прочитанных сообщений или отменяет ее.
+// committed равен false, если транзакция отменена, в том числе из-за ребалансировки группы
+func (s *TransactSession) End(ctx context.Context, commit bool) (committed bool, err error) {
+	committed, err = s.session.End(ctx, kgo.TransactionEndTry(commit))
+	if err != nil {
+		return false, fmt.Errorf("end kafka transaction: %w", err)
+	}
+	return committed, nil
+}
+
+// Close покидает группу и закрывает клиент. Незавершенная транзакция отменяется брокером
+func (s *TransactSession) Close() error {
+	s.session.Close()
+	s.logger.Info("kafka transact session closed", zap.Strings("topics", s.topics))
+	return nil
+}
+
+func toRecords(msgs []*Message) ([]*kgo.Record, error) {
+	records := make([]*kgo.Record, len(msgs))
+	for i, msg := range msgs {
+		if err := msg.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid message at index %d: %w", i, err)
+		}
+
+		record := &kgo.Record{Topic: msg.Topic, Value: msg.Value}
+		if msg.Key != nil {
+			record.Key = []byte(*msg.Key)
+		}
+		for name, value := range msg.Headers {
+			record.Headers = append(record.Headers, kgo.RecordHeader{Key: name, Value: value})
+		}
+		records[i] = record
+	}
+	return records, nil
+}
+
+func convertRecord(record *kgo.Record) *Message {
+	var key *string
+	if record.Key != nil {
+		k := string(record.Key)
+		key = &k
+	}
+
+	headers := make(map[string][]byte, len(record.Headers))
+	for _, header := range record.Headers {
+		headers[header.Key] = header.Value
+	}
+
+	return &Message{
+		Topic:     record.Topic,
+		Key:       key,
+		Value:     record.Value,
+		Headers:   headers,
+		Partition: int(record.Partition),
+		Offset:    record.Offset,
+	}
+}
@@ -0,0 +1,187 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
+)
+
+func TestConfigValidateTransactions(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     *Config
+		wantErr bool
+	}{
+		{name: "transactional", cfg: DefaultConfig().WithTransactionalID("runner-scheduler-1")},
+		{name: "leader acks", cfg: DefaultConfig().WithTransactionalID("runner-scheduler-1").WithRequiredAcks(1), wantErr: true},
+		{name: "negative timeout", cfg: &Config{Brokers: []string{"localhost:9092"}, MaxAttempts: 1, RequiredAcks: -1, TransactionTimeout: -1}, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewKafkaProducerReturnsTransactionalProducer(t *testing.T) {
+	producer, err := NewKafkaProducer(DefaultConfig().WithTransactionalID("runner-scheduler-1"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewKafkaProducer returned error: %v", err)
+	}
+	defer producer.Close()
+
+	if _, ok := producer.(*TransactionalProducer); !ok {
+		t.Fatalf("expected transactional producer, got %T", producer)
+	}
+}
+
+func TestFranzOptsRejectsUnknownCompression(t *testing.T) {
+	cfg := DefaultConfig().WithTransactionalID("runner-scheduler-1")
+	cfg.Compression = 5
+
+	if _, err := cfg.franzOpts(zap.NewNop()); err == nil {
+		t.Fatalf("expected error for unknown compression")
+	}
+}
+
+func TestFranzSASL(t *testing.T) {
+	cases := []struct {
+		mechanism string
+		want      string
+	}{
+		{mechanism: SASLPlain, want: "PLAIN"},
+		{mechanism: SASLScramSHA256, want: "SCRAM-SHA-256"},
+		{mechanism: SASLScramSHA512, want: "SCRAM-SHA-512"},
+	}
+
+	for _, tc := range cases {
+		mechanism := franzSASL(SASLConfig{Mechanism: tc.mechanism, Username: "user", Password: "secret"})
+		if mechanism == nil || mechanism.Name() != tc.want {
+			t.Fatalf("franzSASL(%s) = %v, want %s", tc.mechanism, mechanism, tc.want)
+		}
+	}
+
+	if mechanism := franzSASL(SASLConfig{}); mechanism != nil {
+		t.Fatalf("expected no SASL mechanism without config, got %s", mechanism.Name())
+	}
+}
+
+func TestTransactSessionCommitAndAbort(t *testing.T) {
+	brokers := []string{"localhost:9092", "localhost:9093", "localhost:9094"}
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("failed to create test logger: %v", err)
+	}
+	defer func() { _ = logger.Sync() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	suffix := time.Now().UnixNano()
+	sourceTopic := fmt.Sprintf("transact-session-source-%d", suffix)
+	targetTopic := fmt.Sprintf("transact-session-target-%d", suffix)
+
+	producer, err := NewTransactionalProducer(DefaultConfig(brokers...).WithTransactionalID(fmt.Sprintf("transact-session-producer-%d", suffix)), logger)
+	if err != nil {
+		t.Fatalf("failed to create transactional producer: %v", err)
+	}
+	defer func() { _ = producer.Close() }()
+
+	if err := producer.SendMessage(ctx, NewMessage(sourceTopic, nil, []byte("source"), nil)); err != nil {
+		t.Fatalf("failed to send source message: %v", err)
+	}
+
+	sessionCfg := DefaultConfig(brokers...).WithTransactionalID(fmt.Sprintf("transact-session-consumer-%d", suffix))
+	sessionCfg.StartFromBeginning = true
+	sessionCfg.ConsumerGroup = fmt.Sprintf("transact-session-group-%d", suffix)
+
+	session, err := NewTransactSession(sessionCfg, []string{sourceTopic}, logger)
+	if err != nil {
+		t.Fatalf("failed to create transact session: %v", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	// Отмененная транзакция возвращает чтение к подтвержденному смещению, и то же сообщение
+	// читается во второй транзакции
+	for _, commit := range []bool{false, true} {
+		msgs, err := session.ReadBatch(ctx, 10, 0)
+		if err != nil {
+			t.Fatalf("failed to read source messages: %v", err)
+		}
+		if len(msgs) != 1 || string(msgs[0].Value) != "source" {
+			t.Fatalf("expected the source message, got %d messages", len(msgs))
+		}
+
+		if err := session.Begin(); err != nil {
+			t.Fatalf("failed to begin transaction: %v", err)
+		}
+		if err := session.SendMessage(ctx, NewMessage(targetTopic, nil, []byte(fmt.Sprint(commit)), nil)); err != nil {
+			t.Fatalf("failed to send message in transaction: %v", err)
+		}
+
+		committed, err := session.End(ctx, commit)
+		if err != nil {
+			t.Fatalf("failed to end transaction: %v", err)
+		}
+		if committed != commit {
+			t.Fatalf("End(%v) committed = %v", commit, committed)
+		}
+	}
+
+	// read_committed чтение видит только сообщение закоммиченной транзакции
+	reader, err := kgo.NewClient(
+		kgo.SeedBrokers(brokers...),
+		kgo.ConsumeTopics(targetTopic),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+		kgo.FetchIsolationLevel(kgo.ReadCommitted()),
+	)
+	if err != nil {
+		t.Fatalf("failed to create reader: %v", err)
+	}
+	defer reader.Close()
+
+	var values []string
+	for len(values) == 0 {
+		fetches := reader.PollFetches(ctx)
+		if err := ctx.Err(); err != nil {
+			t.Fatalf("failed to read target messages: %v", err)
+		}
+		fetches.EachRecord(func(record *kgo.Record) {
+			values = append(values, string(record.Value))
+		})
+	}
+	if len(values) != 1 || values[0] != "true" {
+		t.Fatalf("expected only the committed message, got %v", values)
+	}
+
+	// Смещение исходного сообщения подтверждено в транзакции: новая сессия группы его не читает
+	if err := session.Close(); err != nil {
+		t.Fatalf("failed to close session: %v", err)
+	}
+	if err := producer.SendMessage(ctx, NewMessage(sourceTopic, nil, []byte("next"), nil)); err != nil {
+		t.Fatalf("failed to send next source message: %v", err)
+	}
+
+	next, err := NewTransactSession(sessionCfg, []string{sourceTopic}, logger)
+	if err != nil {
+		t.Fatalf("failed to create transact session: %v", err)
+	}
+	defer func() { _ = next.Close() }()
+
+	msgs, err := next.ReadBatch(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("failed to read source messages: %v", err)
+	}
+	if len(msgs) != 1 || string(msgs[0].Value) != "next" {
+		t.Fatalf("expected only the message after the committed offset, got %d messages", len(msgs))
+	}
+}
@@ -0,0 +1,162 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: camera_assignment_queries.sql
+
+package camera_assignment
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const deleteCameraAssignment = `-- name: DeleteCameraAssignment :exec
+DELETE FROM camera_assignment
+WHERE camera_id = $1
+`
+
+func (q *Queries) DeleteCameraAssignment(ctx context.Context, cameraID int32) error {
+	_, err := q.db.Exec(ctx, deleteCameraAssignment, cameraID)
+	return err
+}
+
+const getCameraAssignmentForUpdate = `-- name: GetCameraAssignmentForUpdate :one
+SELECT camera_id, scenario_uuid, node_id, url, fencing_token, created_at, updated_at FROM camera_assignment
+WHERE camera_id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetCameraAssignmentForUpdate(ctx context.Context, cameraID int32) (CameraAssignment, error) {
+	row := q.db.QueryRow(ctx, getCameraAssignmentForUpdate, cameraID)
+	var i CameraAssignment
+	err := row.Scan(
+		&i.CameraID,
+		&i.ScenarioUuid,
+		&i.NodeID,
+		&i.Url,
+		&i.FencingToken,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listCameraAssignmentsByCameraIDs = `-- name: ListCameraAssignmentsByCameraIDs :many
+SELECT camera_id, scenario_uuid, node_id, url, fencing_token, created_at, updated_at FROM camera_assignment
+WHERE camera_id = ANY($1::integer[])
+`
+
+func (q *Queries) ListCameraAssignmentsByCameraIDs(ctx context.Context, cameraIds []int32) ([]CameraAssignment, error) {
+	rows, err := q.db.Query(ctx, listCameraAssignmentsByCameraIDs, cameraIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CameraAssignment{}
+	for rows.Next() {
+		var i CameraAssignment
+		if err := rows.Scan(
+			&i.CameraID,
+			&i.ScenarioUuid,
+			&i.NodeID,
+			&i.Url,
+			&i.FencingToken,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrphanedCameraAssignments = `-- name: ListOrphanedCameraAssignments :many
+SELECT a.camera_id, a.scenario_uuid, a.node_id, a.url, a.fencing_token, a.created_at, a.updated_at FROM camera_assignment a
+LEFT JOIN runner_node n ON n.node_id = a.node_id
+WHERE n.node_id IS NULL
+   OR n.status = 'dead'
+ORDER BY a.camera_id
+LIMIT $1
+FOR UPDATE OF a SKIP LOCKED
+`
+
+// Возвращает размещения на dead или снятых с учета runner'ах, которые нужно перенести
+func (q *Queries) ListOrphanedCameraAssignments(ctx context.Context, batchLimit int32) ([]CameraAssignment, error) {
+	rows, err := q.db.Query(ctx, listOrphanedCameraAssignments, batchLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CameraAssignment{}
+	for rows.Next() {
+		var i CameraAssignment
+		if err := rows.Scan(
+			&i.CameraID,
+			&i.ScenarioUuid,
+			&i.NodeID,
+			&i.Url,
+			&i.FencingToken,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertCameraAssignment = `-- name: UpsertCameraAssignment :one
+INSERT INTO camera_assignment (
+    camera_id,
+    scenario_uuid,
+    node_id,
+    url
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (camera_id) DO UPDATE
+SET scenario_uuid = EXCLUDED.scenario_uuid,
+    node_id = EXCLUDED.node_id,
+    url = EXCLUDED.url,
+    fencing_token = camera_assignment.fencing_token + 1,
+    updated_at = NOW()
+RETURNING camera_id, scenario_uuid, node_id, url, fencing_token, created_at, updated_at
+`
+
+type UpsertCameraAssignmentParams struct {
+	CameraID     int32       `json:"camera_id"`
+	ScenarioUuid pgtype.UUID `json:"scenario_uuid"`
+	NodeID       string      `json:"node_id"`
+	Url          string      `json:"url"`
+}
+
+// Каждое новое размещение камеры увеличивает fencing_token, поэтому воркер, запущенный
+// по предыдущему размещению, можно отличить и отозвать
+func (q *Queries) UpsertCameraAssignment(ctx context.Context, arg UpsertCameraAssignmentParams) (CameraAssignment, error) {
+	row := q.db.QueryRow(ctx, upsertCameraAssignment,
+		arg.CameraID,
+		arg.ScenarioUuid,
+		arg.NodeID,
+		arg.Url,
+	)
+	var i CameraAssignment
+	err := row.Scan(
+		&i.CameraID,
+		&i.ScenarioUuid,
+		&i.NodeID,
+		&i.Url,
+		&i.FencingToken,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
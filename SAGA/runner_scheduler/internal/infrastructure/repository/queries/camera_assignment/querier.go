@@ -0,0 +1,22 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package camera_assignment
+
+import (
+	"context"
+)
+
+type Querier interface {
+	DeleteCameraAssignment(ctx context.Context, cameraID int32) error
+	GetCameraAssignmentForUpdate(ctx context.Context, cameraID int32) (CameraAssignment, error)
+	ListCameraAssignmentsByCameraIDs(ctx context.Context, cameraIds []int32) ([]CameraAssignment, error)
+	// Возвращает размещения на dead или снятых с учета runner'ах, которые нужно перенести
+	ListOrphanedCameraAssignments(ctx context.Context, batchLimit int32) ([]CameraAssignment, error)
+	// Каждое новое размещение камеры увеличивает fencing_token, поэтому воркер, запущенный
+	// по предыдущему размещению, можно отличить и отозвать
+	UpsertCameraAssignment(ctx context.Context, arg UpsertCameraAssignmentParams) (CameraAssignment, error)
+}
+
+var _ Querier = (*Queries)(nil)
@@ -11,6 +11,97 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const bulkCreateInboxStartScenarios = `-- name: BulkCreateInboxStartScenarios :execrows
+INSERT INTO inbox_start_scenario (
+    outbox_uuid,
+    camera_id,
+    scenario_uuid,
+    url
+)
+SELECT
+    unnest($1::uuid[]),
+    unnest($2::integer[]),
+    unnest($3::uuid[]),
+    unnest($4::text[])
+ON CONFLICT (outbox_uuid) DO NOTHING
+`
+
+type BulkCreateInboxStartScenariosParams struct {
+	OutboxUuids   []pgtype.UUID `json:"outbox_uuids"`
+	CameraIds     []int32       `json:"camera_ids"`
+	ScenarioUuids []pgtype.UUID `json:"scenario_uuids"`
+	Urls          []string      `json:"urls"`
+}
+
+// Сохраняет пачку событий одним запросом: массивы параметров одной длины, i-е элементы -
+// одно событие. Уже сохраненные события пропускаются
+func (q *Queries) BulkCreateInboxStartScenarios(ctx context.Context, arg BulkCreateInboxStartScenariosParams) (int64, error) {
+	result, err := q.db.Exec(ctx, bulkCreateInboxStartScenarios,
+		arg.OutboxUuids,
+		arg.CameraIds,
+		arg.ScenarioUuids,
+		arg.Urls,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const claimInboxStartScenarios = `-- name: ClaimInboxStartScenarios :many
+UPDATE inbox_start_scenario
+SET status = 'in_process',
+    attempts = attempts + 1,
+    updated_at = NOW()
+WHERE outbox_uuid IN (
+    SELECT outbox_uuid FROM inbox_start_scenario
+    WHERE (status = 'received' AND next_attempt_at <= NOW())
+       OR (status = 'in_process' AND updated_at < NOW() - $1::integer * INTERVAL '1 second')
+    ORDER BY created_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING outbox_uuid, camera_id, scenario_uuid, url, status, created_at, updated_at, attempts, next_attempt_at, last_error
+`
+
+type ClaimInboxStartScenariosParams struct {
+	LeaseSeconds int32 `json:"lease_seconds"`
+	BatchLimit   int32 `json:"batch_limit"`
+}
+
+// Захватывает пачку сообщений для обработки scheduler'ом: received, у которых наступило время
+// следующей попытки, и in_process, зависшие дольше lease (scheduler упал посреди обработки)
+func (q *Queries) ClaimInboxStartScenarios(ctx context.Context, arg ClaimInboxStartScenariosParams) ([]InboxStartScenario, error) {
+	rows, err := q.db.Query(ctx, claimInboxStartScenarios, arg.LeaseSeconds, arg.BatchLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InboxStartScenario{}
+	for rows.Next() {
+		var i InboxStartScenario
+		if err := rows.Scan(
+			&i.OutboxUuid,
+			&i.CameraID,
+			&i.ScenarioUuid,
+			&i.Url,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastError,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createInboxStartScenario = `-- name: CreateInboxStartScenario :one
 INSERT INTO inbox_start_scenario (
     outbox_uuid,
@@ -19,7 +110,7 @@ INSERT INTO inbox_start_scenario (
     url
 ) VALUES (
     $1, $2, $3, $4
-) RETURNING outbox_uuid, camera_id, scenario_uuid, url, status, created_at, updated_at
+) RETURNING outbox_uuid, camera_id, scenario_uuid, url, status, created_at, updated_at, attempts, next_attempt_at, last_error
 `
 
 type CreateInboxStartScenarioParams struct {
@@ -45,6 +136,111 @@ func (q *Queries) CreateInboxStartScenario(ctx context.Context, arg CreateInboxS
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.LastError,
 	)
 	return i, err
 }
+
+const markInboxStartScenarioFailed = `-- name: MarkInboxStartScenarioFailed :exec
+UPDATE inbox_start_scenario
+SET status = 'failed',
+    last_error = $1,
+    updated_at = NOW()
+WHERE outbox_uuid = $2
+`
+
+type MarkInboxStartScenarioFailedParams struct {
+	LastError  *string     `json:"last_error"`
+	OutboxUuid pgtype.UUID `json:"outbox_uuid"`
+}
+
+func (q *Queries) MarkInboxStartScenarioFailed(ctx context.Context, arg MarkInboxStartScenarioFailedParams) error {
+	_, err := q.db.Exec(ctx, markInboxStartScenarioFailed, arg.LastError, arg.OutboxUuid)
+	return err
+}
+
+const markInboxStartScenarioProcessed = `-- name: MarkInboxStartScenarioProcessed :exec
+UPDATE inbox_start_scenario
+SET status = 'processed',
+    last_error = NULL,
+    updated_at = NOW()
+WHERE outbox_uuid = $1
+`
+
+func (q *Queries) MarkInboxStartScenarioProcessed(ctx context.Context, outboxUuid pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, markInboxStartScenarioProcessed, outboxUuid)
+	return err
+}
+
+const purgeProcessedInboxStartScenarios = `-- name: PurgeProcessedInboxStartScenarios :many
+DELETE FROM inbox_start_scenario
+WHERE outbox_uuid IN (
+    SELECT outbox_uuid FROM inbox_start_scenario
+    WHERE status = 'processed'
+      AND updated_at < NOW() - $1::integer * INTERVAL '1 second'
+    ORDER BY updated_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING outbox_uuid, camera_id, scenario_uuid, url, status, created_at, updated_at, attempts, next_attempt_at, last_error
+`
+
+type PurgeProcessedInboxStartScenariosParams struct {
+	OlderThanSeconds int32 `json:"older_than_seconds"`
+	BatchSize        int32 `json:"batch_size"`
+}
+
+// Удаляет до batch_size processed записей старше older_than_seconds и возвращает их для архива.
+// После удаления повторная доставка того же события будет обработана заново
+func (q *Queries) PurgeProcessedInboxStartScenarios(ctx context.Context, arg PurgeProcessedInboxStartScenariosParams) ([]InboxStartScenario, error) {
+	rows, err := q.db.Query(ctx, purgeProcessedInboxStartScenarios, arg.OlderThanSeconds, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InboxStartScenario{}
+	for rows.Next() {
+		var i InboxStartScenario
+		if err := rows.Scan(
+			&i.OutboxUuid,
+			&i.CameraID,
+			&i.ScenarioUuid,
+			&i.Url,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastError,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rescheduleInboxStartScenario = `-- name: RescheduleInboxStartScenario :exec
+UPDATE inbox_start_scenario
+SET status = 'received',
+    last_error = $1,
+    next_attempt_at = NOW() + $2::integer * INTERVAL '1 millisecond',
+    updated_at = NOW()
+WHERE outbox_uuid = $3
+`
+
+type RescheduleInboxStartScenarioParams struct {
+	LastError  *string     `json:"last_error"`
+	DelayMs    int32       `json:"delay_ms"`
+	OutboxUuid pgtype.UUID `json:"outbox_uuid"`
+}
+
+func (q *Queries) RescheduleInboxStartScenario(ctx context.Context, arg RescheduleInboxStartScenarioParams) error {
+	_, err := q.db.Exec(ctx, rescheduleInboxStartScenario, arg.LastError, arg.DelayMs, arg.OutboxUuid)
+	return err
+}
@@ -6,10 +6,24 @@ package inbox_start_scenario
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	// Сохраняет пачку событий одним запросом: массивы параметров одной длины, i-е элементы -
+	// одно событие. Уже сохраненные события пропускаются
+	BulkCreateInboxStartScenarios(ctx context.Context, arg BulkCreateInboxStartScenariosParams) (int64, error)
+	// Захватывает пачку сообщений для обработки scheduler'ом: received, у которых наступило время
+	// следующей попытки, и in_process, зависшие дольше lease (scheduler упал посреди обработки)
+	ClaimInboxStartScenarios(ctx context.Context, arg ClaimInboxStartScenariosParams) ([]InboxStartScenario, error)
 	CreateInboxStartScenario(ctx context.Context, arg CreateInboxStartScenarioParams) (InboxStartScenario, error)
+	MarkInboxStartScenarioFailed(ctx context.Context, arg MarkInboxStartScenarioFailedParams) error
+	MarkInboxStartScenarioProcessed(ctx context.Context, outboxUuid pgtype.UUID) error
+	// Удаляет до batch_size processed записей старше older_than_seconds и возвращает их для архива.
+	// После удаления повторная доставка того же события будет обработана заново
+	PurgeProcessedInboxStartScenarios(ctx context.Context, arg PurgeProcessedInboxStartScenariosParams) ([]InboxStartScenario, error)
+	RescheduleInboxStartScenario(ctx context.Context, arg RescheduleInboxStartScenarioParams) error
 }
 
 var _ Querier = (*Queries)(nil)
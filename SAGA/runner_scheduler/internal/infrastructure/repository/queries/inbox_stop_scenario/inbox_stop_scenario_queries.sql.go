@@ -0,0 +1,228 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: inbox_stop_scenario_queries.sql
+
+package inbox_stop_scenario
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const bulkCreateInboxStopScenarios = `-- name: BulkCreateInboxStopScenarios :execrows
+INSERT INTO inbox_stop_scenario (
+    outbox_uuid,
+    camera_id,
+    scenario_uuid
+)
+SELECT
+    unnest($1::uuid[]),
+    unnest($2::integer[]),
+    unnest($3::uuid[])
+ON CONFLICT (outbox_uuid) DO NOTHING
+`
+
+type BulkCreateInboxStopScenariosParams struct {
+	OutboxUuids   []pgtype.UUID `json:"outbox_uuids"`
+	CameraIds     []int32       `json:"camera_ids"`
+	ScenarioUuids []pgtype.UUID `json:"scenario_uuids"`
+}
+
+// Сохраняет пачку событий одним запросом: массивы параметров одной длины, i-е элементы -
+// одно событие. Уже сохраненные события пропускаются
+func (q *Queries) BulkCreateInboxStopScenarios(ctx context.Context, arg BulkCreateInboxStopScenariosParams) (int64, error) {
+	result, err := q.db.Exec(ctx, bulkCreateInboxStopScenarios, arg.OutboxUuids, arg.CameraIds, arg.ScenarioUuids)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const claimInboxStopScenarios = `-- name: ClaimInboxStopScenarios :many
+UPDATE inbox_stop_scenario
+SET status = 'in_process',
+    attempts = attempts + 1,
+    updated_at = NOW()
+WHERE outbox_uuid IN (
+    SELECT outbox_uuid FROM inbox_stop_scenario
+    WHERE (status = 'received' AND next_attempt_at <= NOW())
+       OR (status = 'in_process' AND updated_at < NOW() - $1::integer * INTERVAL '1 second')
+    ORDER BY created_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING outbox_uuid, camera_id, scenario_uuid, status, created_at, updated_at, attempts, next_attempt_at, last_error
+`
+
+type ClaimInboxStopScenariosParams struct {
+	LeaseSeconds int32 `json:"lease_seconds"`
+	BatchLimit   int32 `json:"batch_limit"`
+}
+
+// Захватывает пачку сообщений для обработки scheduler'ом: received, у которых наступило время
+// следующей попытки, и in_process, зависшие дольше lease (scheduler упал посреди обработки)
+func (q *Queries) ClaimInboxStopScenarios(ctx context.Context, arg ClaimInboxStopScenariosParams) ([]InboxStopScenario, error) {
+	rows, err := q.db.Query(ctx, claimInboxStopScenarios, arg.LeaseSeconds, arg.BatchLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InboxStopScenario{}
+	for rows.Next() {
+		var i InboxStopScenario
+		if err := rows.Scan(
+			&i.OutboxUuid,
+			&i.CameraID,
+			&i.ScenarioUuid,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastError,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createInboxStopScenario = `-- name: CreateInboxStopScenario :one
+INSERT INTO inbox_stop_scenario (
+    outbox_uuid,
+    camera_id,
+    scenario_uuid
+) VALUES (
+    $1, $2, $3
+) RETURNING outbox_uuid, camera_id, scenario_uuid, status, created_at, updated_at, attempts, next_attempt_at, last_error
+`
+
+type CreateInboxStopScenarioParams struct {
+	OutboxUuid   pgtype.UUID `json:"outbox_uuid"`
+	CameraID     int32       `json:"camera_id"`
+	ScenarioUuid pgtype.UUID `json:"scenario_uuid"`
+}
+
+func (q *Queries) CreateInboxStopScenario(ctx context.Context, arg CreateInboxStopScenarioParams) (InboxStopScenario, error) {
+	row := q.db.QueryRow(ctx, createInboxStopScenario, arg.OutboxUuid, arg.CameraID, arg.ScenarioUuid)
+	var i InboxStopScenario
+	err := row.Scan(
+		&i.OutboxUuid,
+		&i.CameraID,
+		&i.ScenarioUuid,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.LastError,
+	)
+	return i, err
+}
+
+const markInboxStopScenarioFailed = `-- name: MarkInboxStopScenarioFailed :exec
+UPDATE inbox_stop_scenario
+SET status = 'failed',
+    last_error = $1,
+    updated_at = NOW()
+WHERE outbox_uuid = $2
+`
+
+type MarkInboxStopScenarioFailedParams struct {
+	LastError  *string     `json:"last_error"`
+	OutboxUuid pgtype.UUID `json:"outbox_uuid"`
+}
+
+func (q *Queries) MarkInboxStopScenarioFailed(ctx context.Context, arg MarkInboxStopScenarioFailedParams) error {
+	_, err := q.db.Exec(ctx, markInboxStopScenarioFailed, arg.LastError, arg.OutboxUuid)
+	return err
+}
+
+const markInboxStopScenarioProcessed = `-- name: MarkInboxStopScenarioProcessed :exec
+UPDATE inbox_stop_scenario
+SET status = 'processed',
+    last_error = NULL,
+    updated_at = NOW()
+WHERE outbox_uuid = $1
+`
+
+func (q *Queries) MarkInboxStopScenarioProcessed(ctx context.Context, outboxUuid pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, markInboxStopScenarioProcessed, outboxUuid)
+	return err
+}
+
+const purgeProcessedInboxStopScenarios = `-- name: PurgeProcessedInboxStopScenarios :many
+DELETE FROM inbox_stop_scenario
+WHERE outbox_uuid IN (
+    SELECT outbox_uuid FROM inbox_stop_scenario
+    WHERE status = 'processed'
+      AND updated_at < NOW() - $1::integer * INTERVAL '1 second'
+    ORDER BY updated_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING outbox_uuid, camera_id, scenario_uuid, status, created_at, updated_at, attempts, next_attempt_at, last_error
+`
+
+type PurgeProcessedInboxStopScenariosParams struct {
+	OlderThanSeconds int32 `json:"older_than_seconds"`
+	BatchSize        int32 `json:"batch_size"`
+}
+
+// Удаляет до batch_size processed записей старше older_than_seconds и возвращает их для архива.
+// После удаления повторная доставка того же события будет обработана заново
+func (q *Queries) PurgeProcessedInboxStopScenarios(ctx context.Context, arg PurgeProcessedInboxStopScenariosParams) ([]InboxStopScenario, error) {
+	rows, err := q.db.Query(ctx, purgeProcessedInboxStopScenarios, arg.OlderThanSeconds, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InboxStopScenario{}
+	for rows.Next() {
+		var i InboxStopScenario
+		if err := rows.Scan(
+			&i.OutboxUuid,
+			&i.CameraID,
+			&i.ScenarioUuid,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastError,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rescheduleInboxStopScenario = `-- name: RescheduleInboxStopScenario :exec
+UPDATE inbox_stop_scenario
+SET status = 'received',
+    last_error = $1,
+    next_attempt_at = NOW() + $2::integer * INTERVAL '1 millisecond',
+    updated_at = NOW()
+WHERE outbox_uuid = $3
+`
+
+type RescheduleInboxStopScenarioParams struct {
+	LastError  *string     `json:"last_error"`
+	DelayMs    int32       `json:"delay_ms"`
+	OutboxUuid pgtype.UUID `json:"outbox_uuid"`
+}
+
+func (q *Queries) RescheduleInboxStopScenario(ctx context.Context, arg RescheduleInboxStopScenarioParams) error {
+	_, err := q.db.Exec(ctx, rescheduleInboxStopScenario, arg.LastError, arg.DelayMs, arg.OutboxUuid)
+	return err
+}
@@ -0,0 +1,29 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package inbox_stop_scenario
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Querier interface {
+	// Сохраняет пачку событий одним запросом: массивы параметров одной длины, i-е элементы -
+	// одно событие. Уже сохраненные события пропускаются
+	BulkCreateInboxStopScenarios(ctx context.Context, arg BulkCreateInboxStopScenariosParams) (int64, error)
+	// Захватывает пачку сообщений для обработки scheduler'ом: received, у которых наступило время
+	// следующей попытки, и in_process, зависшие дольше lease (scheduler упал посреди обработки)
+	ClaimInboxStopScenarios(ctx context.Context, arg ClaimInboxStopScenariosParams) ([]InboxStopScenario, error)
+	CreateInboxStopScenario(ctx context.Context, arg CreateInboxStopScenarioParams) (InboxStopScenario, error)
+	MarkInboxStopScenarioFailed(ctx context.Context, arg MarkInboxStopScenarioFailedParams) error
+	MarkInboxStopScenarioProcessed(ctx context.Context, outboxUuid pgtype.UUID) error
+	// Удаляет до batch_size processed записей старше older_than_seconds и возвращает их для архива.
+	// После удаления повторная доставка того же события будет обработана заново
+	PurgeProcessedInboxStopScenarios(ctx context.Context, arg PurgeProcessedInboxStopScenariosParams) ([]InboxStopScenario, error)
+	RescheduleInboxStopScenario(ctx context.Context, arg RescheduleInboxStopScenarioParams) error
+}
+
+var _ Querier = (*Queries)(nil)
@@ -0,0 +1,23 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package runner_node
+
+import (
+	"context"
+)
+
+type Querier interface {
+	DeleteRunnerNode(ctx context.Context, nodeID string) error
+	GetRunnerNode(ctx context.Context, nodeID string) (RunnerNode, error)
+	// Возвращает живые runner'ы, приславшие heartbeat не позже heartbeat_timeout_seconds назад,
+	// вместе с количеством размещенных на них камер
+	ListAliveRunnerNodes(ctx context.Context, heartbeatTimeoutSeconds int32) ([]ListAliveRunnerNodesRow, error)
+	// Помечает dead runner'ы, не приславшие heartbeat дольше heartbeat_timeout_seconds.
+	// Heartbeat от такого runner'а возвращает его в статус alive
+	MarkDeadRunnerNodes(ctx context.Context, heartbeatTimeoutSeconds int32) ([]string, error)
+	UpsertRunnerNode(ctx context.Context, arg UpsertRunnerNodeParams) (RunnerNode, error)
+}
+
+var _ Querier = (*Queries)(nil)
@@ -0,0 +1,176 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: runner_node_queries.sql
+
+package runner_node
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const deleteRunnerNode = `-- name: DeleteRunnerNode :exec
+DELETE FROM runner_node
+WHERE node_id = $1
+`
+
+func (q *Queries) DeleteRunnerNode(ctx context.Context, nodeID string) error {
+	_, err := q.db.Exec(ctx, deleteRunnerNode, nodeID)
+	return err
+}
+
+const getRunnerNode = `-- name: GetRunnerNode :one
+SELECT node_id, address, capacity, worker_count, last_heartbeat_at, status, created_at, updated_at FROM runner_node
+WHERE node_id = $1
+`
+
+func (q *Queries) GetRunnerNode(ctx context.Context, nodeID string) (RunnerNode, error) {
+	row := q.db.QueryRow(ctx, getRunnerNode, nodeID)
+	var i RunnerNode
+	err := row.Scan(
+		&i.NodeID,
+		&i.Address,
+		&i.Capacity,
+		&i.WorkerCount,
+		&i.LastHeartbeatAt,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listAliveRunnerNodes = `-- name: ListAliveRunnerNodes :many
+SELECT
+    n.node_id,
+    n.address,
+    n.capacity,
+    n.worker_count,
+    n.last_heartbeat_at,
+    COUNT(a.camera_id)::integer AS assigned_count
+FROM runner_node n
+LEFT JOIN camera_assignment a ON a.node_id = n.node_id
+WHERE n.status = 'alive'
+  AND n.last_heartbeat_at >= NOW() - $1::integer * INTERVAL '1 second'
+GROUP BY n.node_id
+ORDER BY n.node_id
+`
+
+type ListAliveRunnerNodesRow struct {
+	NodeID          string           `json:"node_id"`
+	Address         string           `json:"address"`
+	Capacity        int32            `json:"capacity"`
+	WorkerCount     int32            `json:"worker_count"`
+	LastHeartbeatAt pgtype.Timestamp `json:"last_heartbeat_at"`
+	AssignedCount   int32            `json:"assigned_count"`
+}
+
+// Возвращает живые runner'ы, приславшие heartbeat не позже heartbeat_timeout_seconds назад,
+// вместе с количеством размещенных на них камер
+func (q *Queries) ListAliveRunnerNodes(ctx context.Context, heartbeatTimeoutSeconds int32) ([]ListAliveRunnerNodesRow, error) {
+	rows, err := q.db.Query(ctx, listAliveRunnerNodes, heartbeatTimeoutSeconds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAliveRunnerNodesRow{}
+	for rows.Next() {
+		var i ListAliveRunnerNodesRow
+		if err := rows.Scan(
+			&i.NodeID,
+			&i.Address,
+			&i.Capacity,
+			&i.WorkerCount,
+			&i.LastHeartbeatAt,
+			&i.AssignedCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markDeadRunnerNodes = `-- name: MarkDeadRunnerNodes :many
+UPDATE runner_node
+SET status = 'dead',
+    updated_at = NOW()
+WHERE status = 'alive'
+  AND last_heartbeat_at < NOW() - $1::integer * INTERVAL '1 second'
+RETURNING node_id
+`
+
+// Помечает dead runner'ы, не приславшие heartbeat дольше heartbeat_timeout_seconds.
+// Heartbeat от такого runner'а возвращает его в статус alive
+func (q *Queries) MarkDeadRunnerNodes(ctx context.Context, heartbeatTimeoutSeconds int32) ([]string, error) {
+	rows, err := q.db.Query(ctx, markDeadRunnerNodes, heartbeatTimeoutSeconds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var node_id string
+		if err := rows.Scan(&node_id); err != nil {
+			return nil, err
+		}
+		items = append(items, node_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertRunnerNode = `-- name: UpsertRunnerNode :one
+INSERT INTO runner_node (
+    node_id,
+    address,
+    capacity,
+    worker_count,
+    last_heartbeat_at
+) VALUES (
+    $1, $2, $3, $4, NOW()
+)
+ON CONFLICT (node_id) DO UPDATE
+SET address = EXCLUDED.address,
+    capacity = EXCLUDED.capacity,
+    worker_count = EXCLUDED.worker_count,
+    last_heartbeat_at = NOW(),
+    status = 'alive',
+    updated_at = NOW()
+RETURNING node_id, address, capacity, worker_count, last_heartbeat_at, status, created_at, updated_at
+`
+
+type UpsertRunnerNodeParams struct {
+	NodeID      string `json:"node_id"`
+	Address     string `json:"address"`
+	Capacity    int32  `json:"capacity"`
+	WorkerCount int32  `json:"worker_count"`
+}
+
+func (q *Queries) UpsertRunnerNode(ctx context.Context, arg UpsertRunnerNodeParams) (RunnerNode, error) {
+	row := q.db.QueryRow(ctx, upsertRunnerNode,
+		arg.NodeID,
+		arg.Address,
+		arg.Capacity,
+		arg.WorkerCount,
+	)
+	var i RunnerNode
+	err := row.Scan(
+		&i.NodeID,
+		&i.Address,
+		&i.Capacity,
+		&i.WorkerCount,
+		&i.LastHeartbeatAt,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
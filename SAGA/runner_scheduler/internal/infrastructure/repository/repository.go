@@ -7,10 +7,16 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"runner_scheduler/internal/infrastructure/repository/queries/camera_assignment"
 	"runner_scheduler/internal/infrastructure/repository/queries/inbox_start_scenario"
+	"runner_scheduler/internal/infrastructure/repository/queries/inbox_stop_scenario"
+	"runner_scheduler/internal/infrastructure/repository/queries/runner_node"
 	modelerror "runner_scheduler/internal/models/error"
+	"runner_scheduler/pkg/inbox"
+	"shared/outbox"
 )
 
 // PostgreSQL error codes
@@ -23,13 +29,24 @@ const (
 type Repository struct {
 	dbPool                    *pgxpool.Pool
 	inboxStartScenarioQueries *inbox_start_scenario.Queries
+	inboxStopScenarioQueries  *inbox_stop_scenario.Queries
+	runnerNodeQueries         *runner_node.Queries
+	cameraAssignmentQueries   *camera_assignment.Queries
+	outbox                    *outbox.Store
+	inbox                     *inbox.Store
 }
 
 func NewRepository(dbPool *pgxpool.Pool) *Repository {
-	return &Repository{
+	r := &Repository{
 		dbPool:                    dbPool,
 		inboxStartScenarioQueries: inbox_start_scenario.New(dbPool),
+		inboxStopScenarioQueries:  inbox_stop_scenario.New(dbPool),
+		runnerNodeQueries:         runner_node.New(dbPool),
+		cameraAssignmentQueries:   camera_assignment.New(dbPool),
 	}
+	r.outbox = outbox.NewStore(r.conn)
+	r.inbox = inbox.NewStore(func(ctx context.Context) inbox.DBTX { return r.conn(ctx) })
+	return r
 }
 
 type txKey struct{}
@@ -53,6 +70,49 @@ func (r *Repository) getInboxStartScenarioQueries(ctx context.Context) inbox_sta
 	return r.inboxStartScenarioQueries
 }
 
+func (r *Repository) getInboxStopScenarioQueries(ctx context.Context) inbox_stop_scenario.Querier {
+	tx := extractTx(ctx)
+	if tx != nil {
+		return r.inboxStopScenarioQueries.WithTx(tx)
+	}
+	return r.inboxStopScenarioQueries
+}
+
+// conn возвращает транзакцию из ctx, если она есть, иначе пул
+func (r *Repository) conn(ctx context.Context) outbox.DBTX {
+	if tx := extractTx(ctx); tx != nil {
+		return tx
+	}
+	return r.dbPool
+}
+
+// Outbox возвращает общий outbox. События, записанные через Outbox().Publish внутри
+// WithinTransaction, коммитятся вместе с транзакцией
+func (r *Repository) Outbox() *outbox.Store {
+	return r.outbox
+}
+
+// Inbox возвращает общий inbox, через который consumer дедуплицирует сообщения
+func (r *Repository) Inbox() *inbox.Store {
+	return r.inbox
+}
+
+func (r *Repository) getRunnerNodeQueries(ctx context.Context) runner_node.Querier {
+	tx := extractTx(ctx)
+	if tx != nil {
+		return r.runnerNodeQueries.WithTx(tx)
+	}
+	return r.runnerNodeQueries
+}
+
+func (r *Repository) getCameraAssignmentQueries(ctx context.Context) camera_assignment.Querier {
+	tx := extractTx(ctx)
+	if tx != nil {
+		return r.cameraAssignmentQueries.WithTx(tx)
+	}
+	return r.cameraAssignmentQueries
+}
+
 func (r *Repository) CreateInboxStartScenario(ctx context.Context, arg inbox_start_scenario.CreateInboxStartScenarioParams) (inbox_start_scenario.InboxStartScenario, error) {
 	result, err := r.getInboxStartScenarioQueries(ctx).CreateInboxStartScenario(ctx, arg)
 	if err != nil {
@@ -65,6 +125,120 @@ func (r *Repository) CreateInboxStartScenario(ctx context.Context, arg inbox_sta
 	return result, nil
 }
 
+func (r *Repository) CreateInboxStopScenario(ctx context.Context, arg inbox_stop_scenario.CreateInboxStopScenarioParams) (inbox_stop_scenario.InboxStopScenario, error) {
+	result, err := r.getInboxStopScenarioQueries(ctx).CreateInboxStopScenario(ctx, arg)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgErrCodeUniqueViolation {
+			return result, modelerror.ErrDuplicateKey
+		}
+		return result, err
+	}
+	return result, nil
+}
+
+func (r *Repository) BulkCreateInboxStartScenarios(ctx context.Context, arg inbox_start_scenario.BulkCreateInboxStartScenariosParams) (int64, error) {
+	return r.getInboxStartScenarioQueries(ctx).BulkCreateInboxStartScenarios(ctx, arg)
+}
+
+func (r *Repository) BulkCreateInboxStopScenarios(ctx context.Context, arg inbox_stop_scenario.BulkCreateInboxStopScenariosParams) (int64, error) {
+	return r.getInboxStopScenarioQueries(ctx).BulkCreateInboxStopScenarios(ctx, arg)
+}
+
+func (r *Repository) ClaimInboxStartScenarios(ctx context.Context, arg inbox_start_scenario.ClaimInboxStartScenariosParams) ([]inbox_start_scenario.InboxStartScenario, error) {
+	return r.getInboxStartScenarioQueries(ctx).ClaimInboxStartScenarios(ctx, arg)
+}
+
+func (r *Repository) MarkInboxStartScenarioProcessed(ctx context.Context, outboxUUID pgtype.UUID) error {
+	return r.getInboxStartScenarioQueries(ctx).MarkInboxStartScenarioProcessed(ctx, outboxUUID)
+}
+
+func (r *Repository) RescheduleInboxStartScenario(ctx context.Context, arg inbox_start_scenario.RescheduleInboxStartScenarioParams) error {
+	return r.getInboxStartScenarioQueries(ctx).RescheduleInboxStartScenario(ctx, arg)
+}
+
+func (r *Repository) MarkInboxStartScenarioFailed(ctx context.Context, arg inbox_start_scenario.MarkInboxStartScenarioFailedParams) error {
+	return r.getInboxStartScenarioQueries(ctx).MarkInboxStartScenarioFailed(ctx, arg)
+}
+
+func (r *Repository) PurgeProcessedInboxStartScenarios(ctx context.Context, arg inbox_start_scenario.PurgeProcessedInboxStartScenariosParams) ([]inbox_start_scenario.InboxStartScenario, error) {
+	return r.getInboxStartScenarioQueries(ctx).PurgeProcessedInboxStartScenarios(ctx, arg)
+}
+
+func (r *Repository) ClaimInboxStopScenarios(ctx context.Context, arg inbox_stop_scenario.ClaimInboxStopScenariosParams) ([]inbox_stop_scenario.InboxStopScenario, error) {
+	return r.getInboxStopScenarioQueries(ctx).ClaimInboxStopScenarios(ctx, arg)
+}
+
+func (r *Repository) MarkInboxStopScenarioProcessed(ctx context.Context, outboxUUID pgtype.UUID) error {
+	return r.getInboxStopScenarioQueries(ctx).MarkInboxStopScenarioProcessed(ctx, outboxUUID)
+}
+
+func (r *Repository) RescheduleInboxStopScenario(ctx context.Context, arg inbox_stop_scenario.RescheduleInboxStopScenarioParams) error {
+	return r.getInboxStopScenarioQueries(ctx).RescheduleInboxStopScenario(ctx, arg)
+}
+
+func (r *Repository) MarkInboxStopScenarioFailed(ctx context.Context, arg inbox_stop_scenario.MarkInboxStopScenarioFailedParams) error {
+	return r.getInboxStopScenarioQueries(ctx).MarkInboxStopScenarioFailed(ctx, arg)
+}
+
+func (r *Repository) PurgeProcessedInboxStopScenarios(ctx context.Context, arg inbox_stop_scenario.PurgeProcessedInboxStopScenariosParams) ([]inbox_stop_scenario.InboxStopScenario, error) {
+	return r.getInboxStopScenarioQueries(ctx).PurgeProcessedInboxStopScenarios(ctx, arg)
+}
+
+func (r *Repository) UpsertRunnerNode(ctx context.Context, arg runner_node.UpsertRunnerNodeParams) (runner_node.RunnerNode, error) {
+	return r.getRunnerNodeQueries(ctx).UpsertRunnerNode(ctx, arg)
+}
+
+func (r *Repository) DeleteRunnerNode(ctx context.Context, nodeID string) error {
+	return r.getRunnerNodeQueries(ctx).DeleteRunnerNode(ctx, nodeID)
+}
+
+func (r *Repository) GetRunnerNode(ctx context.Context, nodeID string) (runner_node.RunnerNode, error) {
+	result, err := r.getRunnerNodeQueries(ctx).GetRunnerNode(ctx, nodeID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, modelerror.ErrNotFound
+		}
+		return result, err
+	}
+	return result, nil
+}
+
+func (r *Repository) ListAliveRunnerNodes(ctx context.Context, heartbeatTimeoutSeconds int32) ([]runner_node.ListAliveRunnerNodesRow, error) {
+	return r.getRunnerNodeQueries(ctx).ListAliveRunnerNodes(ctx, heartbeatTimeoutSeconds)
+}
+
+func (r *Repository) MarkDeadRunnerNodes(ctx context.Context, heartbeatTimeoutSeconds int32) ([]string, error) {
+	return r.getRunnerNodeQueries(ctx).MarkDeadRunnerNodes(ctx, heartbeatTimeoutSeconds)
+}
+
+func (r *Repository) UpsertCameraAssignment(ctx context.Context, arg camera_assignment.UpsertCameraAssignmentParams) (camera_assignment.CameraAssignment, error) {
+	return r.getCameraAssignmentQueries(ctx).UpsertCameraAssignment(ctx, arg)
+}
+
+func (r *Repository) GetCameraAssignmentForUpdate(ctx context.Context, cameraID int32) (camera_assignment.CameraAssignment, error) {
+	result, err := r.getCameraAssignmentQueries(ctx).GetCameraAssignmentForUpdate(ctx, cameraID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, modelerror.ErrNotFound
+		}
+		return result, err
+	}
+	return result, nil
+}
+
+func (r *Repository) DeleteCameraAssignment(ctx context.Context, cameraID int32) error {
+	return r.getCameraAssignmentQueries(ctx).DeleteCameraAssignment(ctx, cameraID)
+}
+
+func (r *Repository) ListOrphanedCameraAssignments(ctx context.Context, batchLimit int32) ([]camera_assignment.CameraAssignment, error) {
+	return r.getCameraAssignmentQueries(ctx).ListOrphanedCameraAssignments(ctx, batchLimit)
+}
+
+func (r *Repository) ListCameraAssignmentsByCameraIDs(ctx context.Context, cameraIDs []int32) ([]camera_assignment.CameraAssignment, error) {
+	return r.getCameraAssignmentQueries(ctx).ListCameraAssignmentsByCameraIDs(ctx, cameraIDs)
+}
+
 // WithinTransaction executes a function within a database transaction
 func (r *Repository) WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error {
 	tx := extractTx(ctx)
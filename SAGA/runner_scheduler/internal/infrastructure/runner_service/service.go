@@ -0,0 +1,123 @@
+package runner_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	runnerpb "runner_scheduler/proto/client/runner/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// RunnerService - клиент к RunnerService на runner'ах. Соединения создаются лениво
+// для каждого адреса runner'а и переиспользуются между вызовами.
+type RunnerService struct {
+	mu      sync.Mutex
+	conns   map[string]*grpc.ClientConn
+	timeout time.Duration
+}
+
+type Config struct {
+	Timeout time.Duration
+}
+
+func New(cfg Config) *RunnerService {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+
+	return &RunnerService{
+		conns:   make(map[string]*grpc.ClientConn),
+		timeout: cfg.Timeout,
+	}
+}
+
+func (s *RunnerService) client(address string) (runnerpb.RunnerServiceClient, error) {
+	if address == "" {
+		return nil, fmt.Errorf("address cannot be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conn, ok := s.conns[address]
+	if !ok {
+		var err error
+		conn, err = grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to runner %s: %w", address, err)
+		}
+		s.conns[address] = conn
+	}
+
+	return runnerpb.NewRunnerServiceClient(conn), nil
+}
+
+// StartWorker запускает воркер камеры на runner'е по адресу address с fencing token'ом
+// текущего размещения камеры. Отказ runner'а (success=false) возвращается как ошибка с текстом из ответа.
+func (s *RunnerService) StartWorker(ctx context.Context, address string, cameraID int32, url string, fencingToken int64) error {
+	client, err := s.client(address)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	resp, err := client.StartWorker(ctx, &runnerpb.StartWorkerRequest{
+		CameraId:     strconv.Itoa(int(cameraID)),
+		Url:          url,
+		FencingToken: fencingToken,
+	})
+	if err != nil {
+		return fmt.Errorf("start worker on %s: %w", address, err)
+	}
+	if !resp.GetSuccess() {
+		return fmt.Errorf("runner %s refused to start worker: %s", address, resp.GetError())
+	}
+
+	return nil
+}
+
+// RemoveWorker останавливает воркер камеры на runner'е по адресу address.
+// Отказ runner'а (success=false) возвращается как ошибка с текстом из ответа.
+func (s *RunnerService) RemoveWorker(ctx context.Context, address string, cameraID int32) error {
+	client, err := s.client(address)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	resp, err := client.RemoveWorker(ctx, &runnerpb.RemoveWorkerRequest{
+		CameraId: strconv.Itoa(int(cameraID)),
+	})
+	if err != nil {
+		return fmt.Errorf("remove worker on %s: %w", address, err)
+	}
+	if !resp.GetSuccess() {
+		return fmt.Errorf("runner %s refused to remove worker: %s", address, resp.GetError())
+	}
+
+	return nil
+}
+
+func (s *RunnerService) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for address, conn := range s.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close connection to %s: %w", address, err))
+		}
+		delete(s.conns, address)
+	}
+	return errors.Join(errs...)
+}
@@ -6,4 +6,10 @@ var (
 	// ErrDuplicateKey возвращается когда происходит нарушение уникального ключа (duplicate key)
 	// Это нормальная ситуация при повторной обработке сообщения (идемпотентность)
 	ErrDuplicateKey = errors.New("duplicate key: record already exists")
+
+	// ErrNotFound возвращается когда запись не найдена в БД
+	ErrNotFound = errors.New("record not found")
+
+	// ErrNoAvailableRunners возвращается когда нет живых runner'ов со свободной емкостью
+	ErrNoAvailableRunners = errors.New("no available runner nodes")
 )
@@ -1,4 +1,34 @@
 package kafka
 
-var KafkaConsumerGroup = "runner_scheduler_start_scenario_consumer_group"
+import "fmt"
+
 var OutboxScenarioApi = "outbox_scenario_api"
+var OutboxScenarioResultTopic = "outbox_runner_scheduler"
+
+// Заголовки сообщений, которые выставляет outbox relay в init_scenario_api
+var OutboxUUIDHeader = "outbox_uuid"
+var EventTypeHeader = "event_type"
+
+// Типы событий сценария. Сообщения без заголовка event_type считаются init_scenario
+var EventTypeInitScenario = "init_scenario"
+var EventTypeStopScenario = "stop_scenario"
+
+// EventTypeCompensateScenario - компенсация неудачного запуска сценария. На стороне runner'а
+// обрабатывается так же, как stop_scenario: воркер камеры останавливается и ресурсы освобождаются
+var EventTypeCompensateScenario = "compensate_scenario"
+
+// AggregateTypeScenario - тип агрегата событий сценария в outbox
+var AggregateTypeScenario = "scenario"
+
+// Типы событий с результатом запуска сценария, публикуемые в OutboxScenarioResultTopic
+var EventTypeScenarioStarted = "scenario_started"
+var EventTypeScenarioStartFailed = "scenario_start_failed"
+
+// EventTypeScenarioStopped - воркер сценария остановлен и камера освобождена. Публикуется и после
+// исчерпания попыток остановки с причиной ошибки: воркер будет отозван по fencing token'у
+var EventTypeScenarioStopped = "scenario_stopped"
+
+// RetryTopic возвращает имя n-го топика отложенных повторов для topic (n начинается с 1)
+func RetryTopic(topic string, n int) string {
+	return fmt.Sprintf("%s.retry.%d", topic, n)
+}
@@ -0,0 +1,26 @@
+package kafka
+
+import (
+	"shared/envelope"
+	eventspb "shared/proto/events/v1"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Schemas - схемы payload событий саги сценария. Сообщения публикуются в конверте
+// events.v1.Envelope с версией схемы из этого реестра. При несовместимом изменении payload
+// мажорная версия повышается, и потребители старой версии отклоняют такие события
+var Schemas = newSchemas()
+
+func newSchemas() *envelope.Registry {
+	v1 := envelope.Version{Major: 1, Minor: 0}
+
+	r := envelope.NewRegistry()
+	r.Register(EventTypeInitScenario, v1, func() proto.Message { return &eventspb.InitScenario{} })
+	r.Register(EventTypeStopScenario, v1, func() proto.Message { return &eventspb.StopScenario{} })
+	r.Register(EventTypeCompensateScenario, v1, func() proto.Message { return &eventspb.StopScenario{} })
+	r.Register(EventTypeScenarioStarted, v1, func() proto.Message { return &eventspb.ScenarioResult{} })
+	r.Register(EventTypeScenarioStartFailed, v1, func() proto.Message { return &eventspb.ScenarioResult{} })
+	r.Register(EventTypeScenarioStopped, v1, func() proto.Message { return &eventspb.ScenarioResult{} })
+	return r
+}
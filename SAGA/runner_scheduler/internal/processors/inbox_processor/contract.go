@@ -0,0 +1,14 @@
+package inbox_processor
+
+import (
+	"context"
+	"runner_scheduler/internal/infrastructure/repository/queries/inbox_start_scenario"
+	"runner_scheduler/internal/infrastructure/repository/queries/inbox_stop_scenario"
+)
+
+type Repository interface {
+	CreateInboxStartScenario(ctx context.Context, arg inbox_start_scenario.CreateInboxStartScenarioParams) (inbox_start_scenario.InboxStartScenario, error)
+	CreateInboxStopScenario(ctx context.Context, arg inbox_stop_scenario.CreateInboxStopScenarioParams) (inbox_stop_scenario.InboxStopScenario, error)
+	BulkCreateInboxStartScenarios(ctx context.Context, arg inbox_start_scenario.BulkCreateInboxStartScenariosParams) (int64, error)
+	BulkCreateInboxStopScenarios(ctx context.Context, arg inbox_stop_scenario.BulkCreateInboxStopScenariosParams) (int64, error)
+}
@@ -0,0 +1,197 @@
+package inbox_processor
+
+import (
+	"context"
+	"fmt"
+	"runner_scheduler/internal/infrastructure/repository/queries/inbox_start_scenario"
+	"runner_scheduler/internal/infrastructure/repository/queries/inbox_stop_scenario"
+	"runner_scheduler/pkg/inbox"
+	"runner_scheduler/pkg/logger"
+	eventspb "shared/proto/events/v1"
+
+	modelKafka "runner_scheduler/internal/models/kafka"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// Processor сохраняет события сценариев из init_scenario_api в таблицы inbox_start_scenario
+// и inbox_stop_scenario, откуда их забирает scheduler. Дедупликацию выполняет inbox.Consumer
+type Processor struct {
+	repo Repository
+}
+
+func NewProcessor(repo Repository) *Processor {
+	return &Processor{
+		repo: repo,
+	}
+}
+
+// Register регистрирует обработчики событий сценария в consumer
+func (p *Processor) Register(consumer *inbox.Consumer) {
+	consumer.Handle(modelKafka.EventTypeInitScenario, decode(p.StartScenario))
+	consumer.Handle(modelKafka.EventTypeStopScenario, decode(p.StopScenario))
+	consumer.Handle(modelKafka.EventTypeCompensateScenario, decode(p.StopScenario))
+
+	consumer.HandleBatch(modelKafka.EventTypeInitScenario, decodeBatch(p.StartScenarios))
+	consumer.HandleBatch(modelKafka.EventTypeStopScenario, decodeBatch(p.StopScenarios))
+	consumer.HandleBatch(modelKafka.EventTypeCompensateScenario, decodeBatch(p.StopScenarios))
+}
+
+// decode распаковывает сообщение по схеме из modelKafka.Schemas и передает payload в handle
+func decode[T proto.Message](handle func(ctx context.Context, msg inbox.Message, payload T) error) inbox.Handler {
+	return func(ctx context.Context, msg inbox.Message) error {
+		payload, err := decodePayload[T](msg)
+		if err != nil {
+			return err
+		}
+		return handle(ctx, msg, payload)
+	}
+}
+
+// decodeBatch распаковывает пачку сообщений и передает payload'ы в handle в том же порядке
+func decodeBatch[T proto.Message](handle func(ctx context.Context, msgs []inbox.Message, payloads []T) error) inbox.BatchHandler {
+	return func(ctx context.Context, msgs []inbox.Message) error {
+		payloads := make([]T, len(msgs))
+		for i, msg := range msgs {
+			payload, err := decodePayload[T](msg)
+			if err != nil {
+				return err
+			}
+			payloads[i] = payload
+		}
+		return handle(ctx, msgs, payloads)
+	}
+}
+
+// decodePayload распаковывает payload сообщения. Сообщение неизвестной мажорной версии или не
+// соответствующее схеме повтором не исправится, поэтому ошибка разбора постоянная и сообщение
+// сохраняется как poison
+func decodePayload[T proto.Message](msg inbox.Message) (T, error) {
+	var zero T
+
+	event, err := modelKafka.Schemas.DecodeMessage(msg.Headers, msg.Value, modelKafka.OutboxUUIDHeader, modelKafka.EventTypeHeader)
+	if err != nil {
+		return zero, inbox.Permanent(fmt.Errorf("decode event: %w", err))
+	}
+
+	payload, ok := event.Payload.(T)
+	if !ok {
+		return zero, inbox.Permanent(fmt.Errorf("unexpected payload %T of event %s", event.Payload, event.Type))
+	}
+	return payload, nil
+}
+
+// StartScenario сохраняет событие init_scenario в inbox_start_scenario
+func (p *Processor) StartScenario(ctx context.Context, msg inbox.Message, payload *eventspb.InitScenario) error {
+	outboxUUID, scenarioUUID, err := parseUUIDs(msg, payload.GetScenarioUuid())
+	if err != nil {
+		return err
+	}
+
+	_, err = p.repo.CreateInboxStartScenario(ctx, inbox_start_scenario.CreateInboxStartScenarioParams{
+		OutboxUuid:   outboxUUID,
+		CameraID:     payload.GetCameraId(),
+		ScenarioUuid: scenarioUUID,
+		Url:          payload.GetUrl(),
+	})
+	if err != nil {
+		return fmt.Errorf("create inbox start scenario: %w", err)
+	}
+
+	logger.FromContext(ctx).Info("start scenario saved",
+		zap.Int32("camera_id", payload.GetCameraId()),
+		zap.String("scenario_uuid", payload.GetScenarioUuid()),
+		zap.String("url", payload.GetUrl()),
+	)
+	return nil
+}
+
+// StopScenario сохраняет событие stop_scenario или compensate_scenario в inbox_stop_scenario
+func (p *Processor) StopScenario(ctx context.Context, msg inbox.Message, payload *eventspb.StopScenario) error {
+	outboxUUID, scenarioUUID, err := parseUUIDs(msg, payload.GetScenarioUuid())
+	if err != nil {
+		return err
+	}
+
+	_, err = p.repo.CreateInboxStopScenario(ctx, inbox_stop_scenario.CreateInboxStopScenarioParams{
+		OutboxUuid:   outboxUUID,
+		CameraID:     payload.GetCameraId(),
+		ScenarioUuid: scenarioUUID,
+	})
+	if err != nil {
+		return fmt.Errorf("create inbox stop scenario: %w", err)
+	}
+
+	log := logger.FromContext(ctx).With(
+		zap.Int32("camera_id", payload.GetCameraId()),
+		zap.String("scenario_uuid", payload.GetScenarioUuid()),
+	)
+	if payload.GetReason() != "" {
+		log = log.With(zap.String("reason", payload.GetReason()))
+	}
+	log.Info("stop scenario saved")
+	return nil
+}
+
+// StartScenarios сохраняет пачку событий init_scenario в inbox_start_scenario одним запросом
+func (p *Processor) StartScenarios(ctx context.Context, msgs []inbox.Message, payloads []*eventspb.InitScenario) error {
+	arg := inbox_start_scenario.BulkCreateInboxStartScenariosParams{}
+	for i, msg := range msgs {
+		outboxUUID, scenarioUUID, err := parseUUIDs(msg, payloads[i].GetScenarioUuid())
+		if err != nil {
+			return err
+		}
+		arg.OutboxUuids = append(arg.OutboxUuids, outboxUUID)
+		arg.CameraIds = append(arg.CameraIds, payloads[i].GetCameraId())
+		arg.ScenarioUuids = append(arg.ScenarioUuids, scenarioUUID)
+		arg.Urls = append(arg.Urls, payloads[i].GetUrl())
+	}
+
+	saved, err := p.repo.BulkCreateInboxStartScenarios(ctx, arg)
+	if err != nil {
+		return fmt.Errorf("bulk create inbox start scenarios: %w", err)
+	}
+
+	logger.FromContext(ctx).Info("start scenarios saved", zap.Int64("count", saved))
+	return nil
+}
+
+// StopScenarios сохраняет пачку событий stop_scenario или compensate_scenario в
+// inbox_stop_scenario одним запросом
+func (p *Processor) StopScenarios(ctx context.Context, msgs []inbox.Message, payloads []*eventspb.StopScenario) error {
+	arg := inbox_stop_scenario.BulkCreateInboxStopScenariosParams{}
+	for i, msg := range msgs {
+		outboxUUID, scenarioUUID, err := parseUUIDs(msg, payloads[i].GetScenarioUuid())
+		if err != nil {
+			return err
+		}
+		arg.OutboxUuids = append(arg.OutboxUuids, outboxUUID)
+		arg.CameraIds = append(arg.CameraIds, payloads[i].GetCameraId())
+		arg.ScenarioUuids = append(arg.ScenarioUuids, scenarioUUID)
+	}
+
+	saved, err := p.repo.BulkCreateInboxStopScenarios(ctx, arg)
+	if err != nil {
+		return fmt.Errorf("bulk create inbox stop scenarios: %w", err)
+	}
+
+	logger.FromContext(ctx).Info("stop scenarios saved", zap.Int64("count", saved))
+	return nil
+}
+
+// parseUUIDs разбирает outbox_uuid сообщения и scenario_uuid из payload. Невалидные
+// идентификаторы не исправятся повтором, поэтому ошибка постоянная
+func parseUUIDs(msg inbox.Message, scenarioUUIDStr string) (pgtype.UUID, pgtype.UUID, error) {
+	outboxUUID := pgtype.UUID{}
+	if err := outboxUUID.Scan(string(msg.Headers[modelKafka.OutboxUUIDHeader])); err != nil {
+		return pgtype.UUID{}, pgtype.UUID{}, inbox.Permanent(fmt.Errorf("parse outbox_uuid: %w", err))
+	}
+
+	scenarioUUID := pgtype.UUID{}
+	if err := scenarioUUID.Scan(scenarioUUIDStr); err != nil {
+		return pgtype.UUID{}, pgtype.UUID{}, inbox.Permanent(fmt.Errorf("parse scenario_uuid: %w", err))
+	}
+	return outboxUUID, scenarioUUID, nil
+}
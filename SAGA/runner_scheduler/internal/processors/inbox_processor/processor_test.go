@@ -0,0 +1,147 @@
+package inbox_processor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"runner_scheduler/internal/infrastructure/repository/queries/inbox_start_scenario"
+	"runner_scheduler/internal/infrastructure/repository/queries/inbox_stop_scenario"
+	"runner_scheduler/pkg/inbox"
+	"shared/envelope"
+	eventspb "shared/proto/events/v1"
+
+	modelKafka "runner_scheduler/internal/models/kafka"
+
+	"google.golang.org/protobuf/proto"
+)
+
+type fakeRepository struct {
+	started     []inbox_start_scenario.CreateInboxStartScenarioParams
+	stopped     []inbox_stop_scenario.CreateInboxStopScenarioParams
+	bulkStarted []inbox_start_scenario.BulkCreateInboxStartScenariosParams
+}
+
+func (r *fakeRepository) CreateInboxStartScenario(ctx context.Context, arg inbox_start_scenario.CreateInboxStartScenarioParams) (inbox_start_scenario.InboxStartScenario, error) {
+	r.started = append(r.started, arg)
+	return inbox_start_scenario.InboxStartScenario{}, nil
+}
+
+func (r *fakeRepository) CreateInboxStopScenario(ctx context.Context, arg inbox_stop_scenario.CreateInboxStopScenarioParams) (inbox_stop_scenario.InboxStopScenario, error) {
+	r.stopped = append(r.stopped, arg)
+	return inbox_stop_scenario.InboxStopScenario{}, nil
+}
+
+func (r *fakeRepository) BulkCreateInboxStartScenarios(ctx context.Context, arg inbox_start_scenario.BulkCreateInboxStartScenariosParams) (int64, error) {
+	r.bulkStarted = append(r.bulkStarted, arg)
+	return int64(len(arg.OutboxUuids)), nil
+}
+
+func (r *fakeRepository) BulkCreateInboxStopScenarios(ctx context.Context, arg inbox_stop_scenario.BulkCreateInboxStopScenariosParams) (int64, error) {
+	return int64(len(arg.OutboxUuids)), nil
+}
+
+func message(outboxUUID string) inbox.Message {
+	return inbox.Message{Headers: map[string][]byte{modelKafka.OutboxUUIDHeader: []byte(outboxUUID)}}
+}
+
+func TestStartScenarioSavesInbox(t *testing.T) {
+	repo := &fakeRepository{}
+	p := NewProcessor(repo)
+
+	err := p.StartScenario(context.Background(), message("0b7e6a8c-5f0a-4f3e-9d61-0c1f1c2a3b4d"), &eventspb.InitScenario{
+		CameraId:     7,
+		ScenarioUuid: "6f1c2d3e-4a5b-4c6d-8e7f-901a2b3c4d5e",
+		Url:          "rtsp://camera/7",
+	})
+	if err != nil {
+		t.Fatalf("StartScenario returned error: %v", err)
+	}
+	if len(repo.started) != 1 || repo.started[0].CameraID != 7 || repo.started[0].Url != "rtsp://camera/7" {
+		t.Fatalf("unexpected inbox start scenario records: %+v", repo.started)
+	}
+	if !repo.started[0].OutboxUuid.Valid || !repo.started[0].ScenarioUuid.Valid {
+		t.Fatalf("expected parsed uuids, got %+v", repo.started[0])
+	}
+}
+
+func TestStartScenariosSavesBatchInOneCall(t *testing.T) {
+	repo := &fakeRepository{}
+	p := NewProcessor(repo)
+
+	msgs := []inbox.Message{
+		message("0b7e6a8c-5f0a-4f3e-9d61-0c1f1c2a3b4d"),
+		message("1c8f7b9d-6a1b-4a4f-8e72-1d2a2d3b4c5e"),
+	}
+	payloads := []*eventspb.InitScenario{
+		{CameraId: 7, ScenarioUuid: "6f1c2d3e-4a5b-4c6d-8e7f-901a2b3c4d5e", Url: "rtsp://camera/7"},
+		{CameraId: 8, ScenarioUuid: "7a2d3e4f-5b6c-4d7e-9f80-a12b3c4d5e6f", Url: "rtsp://camera/8"},
+	}
+	if err := p.StartScenarios(context.Background(), msgs, payloads); err != nil {
+		t.Fatalf("StartScenarios returned error: %v", err)
+	}
+
+	if len(repo.bulkStarted) != 1 {
+		t.Fatalf("expected one bulk insert, got %d", len(repo.bulkStarted))
+	}
+	arg := repo.bulkStarted[0]
+	if len(arg.OutboxUuids) != 2 || arg.CameraIds[1] != 8 || arg.Urls[1] != "rtsp://camera/8" {
+		t.Fatalf("unexpected bulk insert params: %+v", arg)
+	}
+}
+
+func TestStopScenarioRejectsInvalidUUIDPermanently(t *testing.T) {
+	repo := &fakeRepository{}
+	p := NewProcessor(repo)
+
+	err := p.StopScenario(context.Background(), message("0b7e6a8c-5f0a-4f3e-9d61-0c1f1c2a3b4d"), &eventspb.StopScenario{
+		CameraId:     7,
+		ScenarioUuid: "not-a-uuid",
+	})
+	if !inbox.IsPermanent(err) {
+		t.Fatalf("expected permanent error, got %v", err)
+	}
+	if len(repo.stopped) != 0 {
+		t.Fatalf("invalid message must not be saved")
+	}
+}
+
+func TestDecodeRejectsUnsupportedMajorVersion(t *testing.T) {
+	data, err := proto.Marshal(&eventspb.Envelope{
+		Id:      "0b7e6a8c-5f0a-4f3e-9d61-0c1f1c2a3b4d",
+		Type:    modelKafka.EventTypeInitScenario,
+		Version: &eventspb.Version{Major: 2},
+	})
+	if err != nil {
+		t.Fatalf("marshal envelope: %v", err)
+	}
+
+	repo := &fakeRepository{}
+	handler := decode(NewProcessor(repo).StartScenario)
+	msg := message("0b7e6a8c-5f0a-4f3e-9d61-0c1f1c2a3b4d")
+	msg.Headers[envelope.ContentTypeHeader] = []byte(envelope.ContentType)
+	msg.Value = data
+
+	err = handler(context.Background(), msg)
+	if !inbox.IsPermanent(err) || !errors.Is(err, envelope.ErrUnsupportedVersion) {
+		t.Fatalf("expected permanent unsupported version error, got %v", err)
+	}
+	if len(repo.started) != 0 {
+		t.Fatalf("event of unsupported version must not be saved")
+	}
+}
+
+func TestDecodeAcceptsLegacyJSON(t *testing.T) {
+	repo := &fakeRepository{}
+	handler := decode(NewProcessor(repo).StopScenario)
+	msg := message("0b7e6a8c-5f0a-4f3e-9d61-0c1f1c2a3b4d")
+	msg.Headers[modelKafka.EventTypeHeader] = []byte(modelKafka.EventTypeCompensateScenario)
+	msg.Value = []byte(`{"scenario_uuid":"6f1c2d3e-4a5b-4c6d-8e7f-901a2b3c4d5e","camera_id":7,"reason":"timeout"}`)
+
+	if err := handler(context.Background(), msg); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if len(repo.stopped) != 1 || repo.stopped[0].CameraID != 7 {
+		t.Fatalf("unexpected inbox stop scenario records: %+v", repo.stopped)
+	}
+}
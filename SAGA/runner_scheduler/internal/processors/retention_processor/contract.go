@@ -0,0 +1,33 @@
+package retention_processor
+
+import (
+	"context"
+	"runner_scheduler/internal/infrastructure/repository/queries/inbox_start_scenario"
+	"runner_scheduler/internal/infrastructure/repository/queries/inbox_stop_scenario"
+	"runner_scheduler/pkg/inbox"
+	"shared/outbox"
+	"time"
+)
+
+type Repository interface {
+	PurgeProcessedInboxStartScenarios(ctx context.Context, arg inbox_start_scenario.PurgeProcessedInboxStartScenariosParams) ([]inbox_start_scenario.InboxStartScenario, error)
+	PurgeProcessedInboxStopScenarios(ctx context.Context, arg inbox_stop_scenario.PurgeProcessedInboxStopScenariosParams) ([]inbox_stop_scenario.InboxStopScenario, error)
+	WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error
+}
+
+type OutboxStore interface {
+	PurgeSent(ctx context.Context, olderThan time.Duration, batchSize int32) ([]outbox.Event, error)
+}
+
+type InboxStore interface {
+	PurgeProcessed(ctx context.Context, olderThan time.Duration, batchSize int32) ([]inbox.Record, error)
+}
+
+// Archiver сохраняет удаляемые строки таблицы до коммита удаления
+type Archiver interface {
+	Archive(ctx context.Context, table string, rows []any) error
+}
+
+type Metrics interface {
+	AddPurged(table string, rows int)
+}
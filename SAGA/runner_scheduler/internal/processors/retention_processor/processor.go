@@ -0,0 +1,174 @@
+package retention_processor
+
+import (
+	"context"
+	"fmt"
+	"runner_scheduler/internal/infrastructure/repository/queries/inbox_start_scenario"
+	"runner_scheduler/internal/infrastructure/repository/queries/inbox_stop_scenario"
+	"runner_scheduler/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	inboxStartScenarioTable = "inbox_start_scenario"
+	inboxStopScenarioTable  = "inbox_stop_scenario"
+	outboxTable             = "outbox"
+	inboxTable              = "inbox"
+)
+
+type Config struct {
+	// OlderThan - возраст строки, после которого она удаляется
+	OlderThan time.Duration
+	BatchSize int32
+	// MaxBatches - ограничение количества батчей на таблицу за один вызов Purge
+	MaxBatches int
+	// BatchPause - пауза между батчами, чтобы очистка не забирала ресурсы базы у scheduler'а
+	BatchPause time.Duration
+}
+
+type Processor struct {
+	repo     Repository
+	outbox   OutboxStore
+	inbox    InboxStore
+	archiver Archiver
+	metrics  Metrics
+	cfg      Config
+}
+
+// NewProcessor создает очистку. Если archiver равен nil, строки удаляются без архивации
+func NewProcessor(repo Repository, outbox OutboxStore, inbox InboxStore, archiver Archiver, metrics Metrics, cfg Config) *Processor {
+	return &Processor{
+		repo:     repo,
+		outbox:   outbox,
+		inbox:    inbox,
+		archiver: archiver,
+		metrics:  metrics,
+		cfg:      cfg,
+	}
+}
+
+type purgeFunc func(ctx context.Context, olderThanSeconds, batchSize int32) ([]any, error)
+
+// Purge удаляет processed записи inbox и отправленные события outbox старше
+// cfg.OlderThan. Каждая таблица чистится батчами по cfg.BatchSize строк в отдельных коротких
+// транзакциях. Записи received, in_process и failed не удаляются. Возвращает количество удаленных строк.
+func (p *Processor) Purge(ctx context.Context) (int, error) {
+	tables := []struct {
+		name  string
+		purge purgeFunc
+	}{
+		{name: inboxStartScenarioTable, purge: p.purgeInboxStart},
+		{name: inboxStopScenarioTable, purge: p.purgeInboxStop},
+		{name: outboxTable, purge: p.purgeOutbox},
+		{name: inboxTable, purge: p.purgeInbox},
+	}
+
+	var total int
+	for _, table := range tables {
+		purged, err := p.purgeTable(ctx, table.name, table.purge)
+		total += purged
+		if err != nil {
+			return total, fmt.Errorf("purge %s: %w", table.name, err)
+		}
+	}
+	return total, nil
+}
+
+func (p *Processor) purgeTable(ctx context.Context, table string, purge purgeFunc) (int, error) {
+	log := logger.FromContext(ctx).With(zap.String("table", table))
+	olderThanSeconds := int32(p.cfg.OlderThan / time.Second)
+
+	var total int
+	for batch := 0; batch < p.cfg.MaxBatches; batch++ {
+		if batch > 0 {
+			select {
+			case <-ctx.Done():
+				return total, ctx.Err()
+			case <-time.After(p.cfg.BatchPause):
+			}
+		}
+
+		var purged int
+		// Архив пишется до коммита: если запись не удалась, удаление откатывается. Если не удался
+		// коммит, строки останутся в таблице и попадут в архив повторно на следующем проходе
+		err := p.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+			rows, err := purge(txCtx, olderThanSeconds, p.cfg.BatchSize)
+			if err != nil {
+				return err
+			}
+			if len(rows) > 0 && p.archiver != nil {
+				if err := p.archiver.Archive(txCtx, table, rows); err != nil {
+					return fmt.Errorf("archive rows: %w", err)
+				}
+			}
+			purged = len(rows)
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+
+		total += purged
+		p.metrics.AddPurged(table, purged)
+
+		if purged < int(p.cfg.BatchSize) {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Info("retention purged rows",
+			zap.Int("rows_purged", total),
+			zap.Bool("archived", p.archiver != nil),
+		)
+	}
+	return total, nil
+}
+
+func (p *Processor) purgeInboxStart(ctx context.Context, olderThanSeconds, batchSize int32) ([]any, error) {
+	records, err := p.repo.PurgeProcessedInboxStartScenarios(ctx, inbox_start_scenario.PurgeProcessedInboxStartScenariosParams{
+		OlderThanSeconds: olderThanSeconds,
+		BatchSize:        batchSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("purge processed inbox start scenarios: %w", err)
+	}
+	return toAny(records), nil
+}
+
+func (p *Processor) purgeInboxStop(ctx context.Context, olderThanSeconds, batchSize int32) ([]any, error) {
+	records, err := p.repo.PurgeProcessedInboxStopScenarios(ctx, inbox_stop_scenario.PurgeProcessedInboxStopScenariosParams{
+		OlderThanSeconds: olderThanSeconds,
+		BatchSize:        batchSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("purge processed inbox stop scenarios: %w", err)
+	}
+	return toAny(records), nil
+}
+
+func (p *Processor) purgeOutbox(ctx context.Context, olderThanSeconds, batchSize int32) ([]any, error) {
+	events, err := p.outbox.PurgeSent(ctx, time.Duration(olderThanSeconds)*time.Second, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	return toAny(events), nil
+}
+
+func (p *Processor) purgeInbox(ctx context.Context, olderThanSeconds, batchSize int32) ([]any, error) {
+	records, err := p.inbox.PurgeProcessed(ctx, time.Duration(olderThanSeconds)*time.Second, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	return toAny(records), nil
+}
+
+func toAny[T any](records []T) []any {
+	rows := make([]any, len(records))
+	for i := range records {
+		rows[i] = records[i]
+	}
+	return rows
+}
@@ -0,0 +1,105 @@
+package retention_processor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"runner_scheduler/internal/infrastructure/repository/queries/inbox_start_scenario"
+	"runner_scheduler/internal/infrastructure/repository/queries/inbox_stop_scenario"
+	"runner_scheduler/pkg/inbox"
+	"shared/outbox"
+)
+
+type fakeRepository struct {
+	// left - сколько строк каждой таблицы еще подлежит удалению
+	left map[string]int
+	err  error
+}
+
+func (r *fakeRepository) take(table string, batchSize int32) int {
+	n := min(r.left[table], int(batchSize))
+	r.left[table] -= n
+	return n
+}
+
+func (r *fakeRepository) PurgeProcessedInboxStartScenarios(ctx context.Context, arg inbox_start_scenario.PurgeProcessedInboxStartScenariosParams) ([]inbox_start_scenario.InboxStartScenario, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return make([]inbox_start_scenario.InboxStartScenario, r.take(inboxStartScenarioTable, arg.BatchSize)), nil
+}
+
+func (r *fakeRepository) PurgeProcessedInboxStopScenarios(ctx context.Context, arg inbox_stop_scenario.PurgeProcessedInboxStopScenariosParams) ([]inbox_stop_scenario.InboxStopScenario, error) {
+	return make([]inbox_stop_scenario.InboxStopScenario, r.take(inboxStopScenarioTable, arg.BatchSize)), nil
+}
+
+func (r *fakeRepository) PurgeSent(ctx context.Context, olderThan time.Duration, batchSize int32) ([]outbox.Event, error) {
+	return make([]outbox.Event, r.take(outboxTable, batchSize)), nil
+}
+
+func (r *fakeRepository) PurgeProcessed(ctx context.Context, olderThan time.Duration, batchSize int32) ([]inbox.Record, error) {
+	return make([]inbox.Record, r.take(inboxTable, batchSize)), nil
+}
+
+func (r *fakeRepository) WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error {
+	return tFunc(ctx)
+}
+
+type fakeArchiver map[string]int
+
+func (a fakeArchiver) Archive(ctx context.Context, table string, rows []any) error {
+	a[table] += len(rows)
+	return nil
+}
+
+type fakeMetrics map[string]int
+
+func (m fakeMetrics) AddPurged(table string, rows int) {
+	m[table] += rows
+}
+
+func TestPurgeCleansAllTablesInBatches(t *testing.T) {
+	repo := &fakeRepository{left: map[string]int{
+		inboxStartScenarioTable: 25,
+		inboxStopScenarioTable:  3,
+		outboxTable:             10,
+		inboxTable:              12,
+	}}
+	archiver := fakeArchiver{}
+	metrics := fakeMetrics{}
+	p := NewProcessor(repo, repo, repo, archiver, metrics, Config{OlderThan: time.Hour, BatchSize: 10, MaxBatches: 100})
+
+	purged, err := p.Purge(context.Background())
+	if err != nil {
+		t.Fatalf("Purge returned error: %v", err)
+	}
+	if purged != 50 {
+		t.Fatalf("expected 50 purged rows, got %d", purged)
+	}
+	for table, want := range map[string]int{
+		inboxStartScenarioTable: 25,
+		inboxStopScenarioTable:  3,
+		outboxTable:             10,
+		inboxTable:              12,
+	} {
+		if metrics[table] != want || archiver[table] != want {
+			t.Fatalf("%s: expected %d purged and archived rows, got metrics %d, archived %d",
+				table, want, metrics[table], archiver[table])
+		}
+	}
+}
+
+func TestPurgeStopsOnRepositoryError(t *testing.T) {
+	repoErr := errors.New("connection reset")
+	repo := &fakeRepository{left: map[string]int{inboxStopScenarioTable: 5}, err: repoErr}
+	p := NewProcessor(repo, repo, repo, nil, fakeMetrics{}, Config{OlderThan: time.Hour, BatchSize: 10, MaxBatches: 100})
+
+	if _, err := p.Purge(context.Background()); !errors.Is(err, repoErr) {
+		t.Fatalf("expected repository error, got %v", err)
+	}
+	if repo.left[inboxStopScenarioTable] != 5 {
+		t.Fatalf("tables after the failed one must not be purged in the same run")
+	}
+}
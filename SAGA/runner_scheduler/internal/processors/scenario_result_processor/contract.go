@@ -0,0 +1,10 @@
+package scenario_result_processor
+
+import (
+	"context"
+	"shared/outbox"
+)
+
+type OutboxPublisher interface {
+	Publish(ctx context.Context, event outbox.Event) error
+}
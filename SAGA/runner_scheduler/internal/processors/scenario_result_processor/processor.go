@@ -0,0 +1,92 @@
+package scenario_result_processor
+
+import (
+	"context"
+	"fmt"
+	modelKafka "runner_scheduler/internal/models/kafka"
+	"runner_scheduler/pkg/logger"
+	"shared/envelope"
+	"shared/outbox"
+	eventspb "shared/proto/events/v1"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// Reporter записывает результаты запуска и остановки сценариев в outbox.
+// Отправкой событий в Kafka занимается outbox.Relay в cmd/producer.
+type Reporter struct {
+	outbox OutboxPublisher
+}
+
+func NewReporter(outbox OutboxPublisher) *Reporter {
+	return &Reporter{
+		outbox: outbox,
+	}
+}
+
+// ReportScenarioStarted записывает в outbox событие об успешном запуске сценария.
+// Если ctx содержит транзакцию, запись попадает в нее.
+func (r *Reporter) ReportScenarioStarted(ctx context.Context, scenarioUUID pgtype.UUID, cameraID int32) error {
+	return r.report(ctx, modelKafka.EventTypeScenarioStarted, scenarioUUID, cameraID, "")
+}
+
+// ReportScenarioStartFailed записывает в outbox событие о неудачном запуске сценария с причиной ошибки.
+// Если ctx содержит транзакцию, запись попадает в нее.
+func (r *Reporter) ReportScenarioStartFailed(ctx context.Context, scenarioUUID pgtype.UUID, cameraID int32, reason string) error {
+	return r.report(ctx, modelKafka.EventTypeScenarioStartFailed, scenarioUUID, cameraID, reason)
+}
+
+// ReportScenarioStopped записывает в outbox событие об остановке сценария. reason пустой, если
+// воркер остановлен, и содержит ошибку, если попытки остановки исчерпаны.
+// Если ctx содержит транзакцию, запись попадает в нее.
+func (r *Reporter) ReportScenarioStopped(ctx context.Context, scenarioUUID pgtype.UUID, cameraID int32, reason string) error {
+	return r.report(ctx, modelKafka.EventTypeScenarioStopped, scenarioUUID, cameraID, reason)
+}
+
+func (r *Reporter) report(ctx context.Context, eventType string, scenarioUUID pgtype.UUID, cameraID int32, reason string) error {
+	id := uuid.NewString()
+	payload, err := modelKafka.Schemas.Encode(envelope.Event{
+		ID:            id,
+		Type:          eventType,
+		OccurredAt:    time.Now(),
+		CorrelationID: uuidToString(scenarioUUID),
+		Payload: &eventspb.ScenarioResult{
+			ScenarioUuid: uuidToString(scenarioUUID),
+			CameraId:     cameraID,
+			Error:        reason,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("encode payload: %w", err)
+	}
+
+	if err := r.outbox.Publish(ctx, outbox.Event{
+		ID:            id,
+		AggregateType: modelKafka.AggregateTypeScenario,
+		AggregateID:   uuidToString(scenarioUUID),
+		EventType:     eventType,
+		Topic:         modelKafka.OutboxScenarioResultTopic,
+		Headers:       map[string]string{envelope.ContentTypeHeader: envelope.ContentType},
+		Payload:       payload,
+	}); err != nil {
+		return fmt.Errorf("publish scenario result: %w", err)
+	}
+
+	logger.FromContext(ctx).Info("scenario result reported",
+		zap.String("event_type", eventType),
+		zap.String("scenario_uuid", uuidToString(scenarioUUID)),
+		zap.Int32("camera_id", cameraID),
+	)
+
+	return nil
+}
+
+func uuidToString(pgUUID pgtype.UUID) string {
+	if !pgUUID.Valid {
+		return ""
+	}
+	return uuid.UUID(pgUUID.Bytes).String()
+}
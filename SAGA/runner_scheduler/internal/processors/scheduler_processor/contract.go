@@ -1,7 +1,45 @@
 package scheduler_processor
 
-import "context"
+import (
+	"context"
+	"runner_scheduler/internal/infrastructure/repository/queries/camera_assignment"
+	"runner_scheduler/internal/infrastructure/repository/queries/inbox_start_scenario"
+	"runner_scheduler/internal/infrastructure/repository/queries/inbox_stop_scenario"
+	"runner_scheduler/internal/infrastructure/repository/queries/runner_node"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
 
 type Repository interface {
-	GetPendingOutboxScenarios(ctx context.Context, limit int32) ([]outbox.OutboxScenario, error)
+	ClaimInboxStartScenarios(ctx context.Context, arg inbox_start_scenario.ClaimInboxStartScenariosParams) ([]inbox_start_scenario.InboxStartScenario, error)
+	MarkInboxStartScenarioProcessed(ctx context.Context, outboxUUID pgtype.UUID) error
+	RescheduleInboxStartScenario(ctx context.Context, arg inbox_start_scenario.RescheduleInboxStartScenarioParams) error
+	MarkInboxStartScenarioFailed(ctx context.Context, arg inbox_start_scenario.MarkInboxStartScenarioFailedParams) error
+
+	ClaimInboxStopScenarios(ctx context.Context, arg inbox_stop_scenario.ClaimInboxStopScenariosParams) ([]inbox_stop_scenario.InboxStopScenario, error)
+	MarkInboxStopScenarioProcessed(ctx context.Context, outboxUUID pgtype.UUID) error
+	RescheduleInboxStopScenario(ctx context.Context, arg inbox_stop_scenario.RescheduleInboxStopScenarioParams) error
+	MarkInboxStopScenarioFailed(ctx context.Context, arg inbox_stop_scenario.MarkInboxStopScenarioFailedParams) error
+
+	ListAliveRunnerNodes(ctx context.Context, heartbeatTimeoutSeconds int32) ([]runner_node.ListAliveRunnerNodesRow, error)
+	GetRunnerNode(ctx context.Context, nodeID string) (runner_node.RunnerNode, error)
+	MarkDeadRunnerNodes(ctx context.Context, heartbeatTimeoutSeconds int32) ([]string, error)
+
+	UpsertCameraAssignment(ctx context.Context, arg camera_assignment.UpsertCameraAssignmentParams) (camera_assignment.CameraAssignment, error)
+	GetCameraAssignmentForUpdate(ctx context.Context, cameraID int32) (camera_assignment.CameraAssignment, error)
+	DeleteCameraAssignment(ctx context.Context, cameraID int32) error
+	ListOrphanedCameraAssignments(ctx context.Context, batchLimit int32) ([]camera_assignment.CameraAssignment, error)
+
+	WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error
+}
+
+type RunnerService interface {
+	StartWorker(ctx context.Context, address string, cameraID int32, url string, fencingToken int64) error
+	RemoveWorker(ctx context.Context, address string, cameraID int32) error
+}
+
+type ResultReporter interface {
+	ReportScenarioStarted(ctx context.Context, scenarioUUID pgtype.UUID, cameraID int32) error
+	ReportScenarioStartFailed(ctx context.Context, scenarioUUID pgtype.UUID, cameraID int32, reason string) error
+	ReportScenarioStopped(ctx context.Context, scenarioUUID pgtype.UUID, cameraID int32, reason string) error
 }
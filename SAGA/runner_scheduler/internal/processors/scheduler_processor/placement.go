@@ -0,0 +1,122 @@
+package scheduler_processor
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	modelerror "runner_scheduler/internal/models/error"
+)
+
+// Названия стратегий размещения для конфигурации
+const (
+	PlacementLeastLoaded    = "least_loaded"
+	PlacementConsistentHash = "consistent_hash"
+)
+
+// RunnerNode - живой runner, на который можно разместить камеру
+type RunnerNode struct {
+	NodeID   string
+	Address  string
+	Capacity int32
+	// Load - количество камер, уже размещенных на runner'е
+	Load int32
+}
+
+func (n RunnerNode) hasCapacity() bool {
+	return n.Load < n.Capacity
+}
+
+// PlacementStrategy выбирает runner для камеры среди живых runner'ов
+type PlacementStrategy interface {
+	Place(cameraID int32, nodes []RunnerNode) (RunnerNode, error)
+}
+
+// NewPlacementStrategy возвращает стратегию размещения по названию из конфигурации
+func NewPlacementStrategy(name string) (PlacementStrategy, error) {
+	switch name {
+	case PlacementLeastLoaded:
+		return LeastLoaded{}, nil
+	case PlacementConsistentHash:
+		return ConsistentHash{VirtualNodes: defaultVirtualNodes}, nil
+	default:
+		return nil, fmt.Errorf("unknown placement strategy: %s", name)
+	}
+}
+
+// LeastLoaded размещает камеру на runner с наименьшим количеством камер.
+// При равной нагрузке выбирается runner с меньшим node_id, чтобы выбор был детерминированным.
+type LeastLoaded struct{}
+
+func (LeastLoaded) Place(_ int32, nodes []RunnerNode) (RunnerNode, error) {
+	var (
+		best  RunnerNode
+		found bool
+	)
+	for _, node := range nodes {
+		if !node.hasCapacity() {
+			continue
+		}
+		if !found || node.Load < best.Load || (node.Load == best.Load && node.NodeID < best.NodeID) {
+			best = node
+			found = true
+		}
+	}
+
+	if !found {
+		return RunnerNode{}, modelerror.ErrNoAvailableRunners
+	}
+	return best, nil
+}
+
+const defaultVirtualNodes = 128
+
+// ConsistentHash размещает камеру по кольцу consistent hashing от camera_id, поэтому при
+// добавлении или удалении runner'а переезжает только небольшая часть камер.
+// Если выбранный runner заполнен, берется следующий по кольцу.
+type ConsistentHash struct {
+	VirtualNodes int
+}
+
+type ringPoint struct {
+	hash uint32
+	node int
+}
+
+func (c ConsistentHash) Place(cameraID int32, nodes []RunnerNode) (RunnerNode, error) {
+	virtualNodes := c.VirtualNodes
+	if virtualNodes <= 0 {
+		virtualNodes = defaultVirtualNodes
+	}
+
+	ring := make([]ringPoint, 0, len(nodes)*virtualNodes)
+	for i, node := range nodes {
+		for v := 0; v < virtualNodes; v++ {
+			ring = append(ring, ringPoint{hash: hashKey(node.NodeID + "#" + strconv.Itoa(v)), node: i})
+		}
+	}
+	if len(ring) == 0 {
+		return RunnerNode{}, modelerror.ErrNoAvailableRunners
+	}
+
+	sort.Slice(ring, func(i, j int) bool { return ring[i].hash < ring[j].hash })
+
+	key := hashKey(strconv.Itoa(int(cameraID)))
+	start := sort.Search(len(ring), func(i int) bool { return ring[i].hash >= key })
+
+	for i := 0; i < len(ring); i++ {
+		node := nodes[ring[(start+i)%len(ring)].node]
+		if node.hasCapacity() {
+			return node, nil
+		}
+	}
+
+	return RunnerNode{}, modelerror.ErrNoAvailableRunners
+}
+
+func hashKey(key string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return h.Sum32()
+}
@@ -0,0 +1,88 @@
+package scheduler_processor
+
+import (
+	"errors"
+	"testing"
+
+	modelerror "runner_scheduler/internal/models/error"
+)
+
+func TestLeastLoadedPlace(t *testing.T) {
+	nodes := []RunnerNode{
+		{NodeID: "runner-b", Capacity: 10, Load: 2},
+		{NodeID: "runner-a", Capacity: 10, Load: 2},
+		{NodeID: "runner-c", Capacity: 3, Load: 3},
+	}
+
+	node, err := LeastLoaded{}.Place(1, nodes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if node.NodeID != "runner-a" {
+		t.Fatalf("expected runner-a, got %s", node.NodeID)
+	}
+}
+
+func TestLeastLoadedPlaceNoCapacity(t *testing.T) {
+	nodes := []RunnerNode{{NodeID: "runner-a", Capacity: 1, Load: 1}}
+
+	if _, err := (LeastLoaded{}).Place(1, nodes); !errors.Is(err, modelerror.ErrNoAvailableRunners) {
+		t.Fatalf("expected ErrNoAvailableRunners, got %v", err)
+	}
+}
+
+func TestConsistentHashPlaceIsStable(t *testing.T) {
+	nodes := []RunnerNode{
+		{NodeID: "runner-a", Capacity: 100},
+		{NodeID: "runner-b", Capacity: 100},
+		{NodeID: "runner-c", Capacity: 100},
+	}
+	strategy := ConsistentHash{VirtualNodes: 64}
+
+	before := make(map[int32]string)
+	for cameraID := int32(0); cameraID < 300; cameraID++ {
+		node, err := strategy.Place(cameraID, nodes)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		before[cameraID] = node.NodeID
+	}
+
+	// Удаление одного runner'а не должно переносить камеры между оставшимися
+	remaining := []RunnerNode{nodes[0], nodes[2]}
+	for cameraID, nodeID := range before {
+		if nodeID == "runner-b" {
+			continue
+		}
+		node, err := strategy.Place(cameraID, remaining)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if node.NodeID != nodeID {
+			t.Fatalf("camera %d moved from %s to %s", cameraID, nodeID, node.NodeID)
+		}
+	}
+}
+
+func TestConsistentHashPlaceSkipsFullNodes(t *testing.T) {
+	nodes := []RunnerNode{
+		{NodeID: "runner-a", Capacity: 1, Load: 1},
+		{NodeID: "runner-b", Capacity: 1, Load: 0},
+	}
+
+	for cameraID := int32(0); cameraID < 20; cameraID++ {
+		node, err := (ConsistentHash{}).Place(cameraID, nodes)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if node.NodeID != "runner-b" {
+			t.Fatalf("camera %d placed on full node %s", cameraID, node.NodeID)
+		}
+	}
+}
+
+func TestNewPlacementStrategyUnknown(t *testing.T) {
+	if _, err := NewPlacementStrategy("random"); err == nil {
+		t.Fatalf("expected error for unknown strategy")
+	}
+}
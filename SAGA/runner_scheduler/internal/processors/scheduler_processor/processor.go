@@ -1,11 +1,376 @@
 package scheduler_processor
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runner_scheduler/internal/infrastructure/repository/queries/camera_assignment"
+	"runner_scheduler/internal/infrastructure/repository/queries/inbox_start_scenario"
+	"runner_scheduler/internal/infrastructure/repository/queries/inbox_stop_scenario"
+	"runner_scheduler/internal/infrastructure/repository/queries/runner_node"
+	modelerror "runner_scheduler/internal/models/error"
+	"runner_scheduler/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+type Config struct {
+	Retry RetryPolicy
+	// Lease - время, после которого сообщение в статусе in_process считается брошенным
+	// (scheduler упал во время обработки) и захватывается повторно. Должно быть больше
+	// таймаута gRPC вызова runner'а.
+	Lease time.Duration
+	// HeartbeatTimeout - runner без heartbeat'а дольше этого времени считается мертвым
+	HeartbeatTimeout time.Duration
+}
+
 type Processor struct {
-	repo Repository
+	repo      Repository
+	runner    RunnerService
+	reporter  ResultReporter
+	placement PlacementStrategy
+	cfg       Config
 }
 
-func NewProcessor(repo Repository) *Processor {
+func NewProcessor(repo Repository, runner RunnerService, reporter ResultReporter, placement PlacementStrategy, cfg Config) *Processor {
 	return &Processor{
-		repo: repo,
+		repo:      repo,
+		runner:    runner,
+		reporter:  reporter,
+		placement: placement,
+		cfg:       cfg,
+	}
+}
+
+// ProcessStartScenarios захватывает до batchSize сообщений из inbox_start_scenario, размещает
+// камеры на runner'ах и запускает на них воркеры. Успешный запуск помечает сообщение processed
+// и публикует scenario_started, ошибка откладывает сообщение с экспоненциальной задержкой,
+// а после исчерпания попыток помечает его failed, снимает размещение камеры и публикует
+// scenario_start_failed. Возвращает количество захваченных сообщений.
+func (p *Processor) ProcessStartScenarios(ctx context.Context, batchSize int32) (int, error) {
+	log := logger.FromContext(ctx)
+
+	records, err := p.repo.ClaimInboxStartScenarios(ctx, inbox_start_scenario.ClaimInboxStartScenariosParams{
+		LeaseSeconds: int32(p.cfg.Lease / time.Second),
+		BatchLimit:   batchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("claim inbox start scenarios: %w", err)
+	}
+
+	for _, record := range records {
+		recordLog := log.With(
+			zap.String("outbox_uuid", uuidToString(record.OutboxUuid)),
+			zap.String("scenario_uuid", uuidToString(record.ScenarioUuid)),
+			zap.Int32("camera_id", record.CameraID),
+			zap.Int32("attempt", record.Attempts),
+		)
+
+		if err := p.startScenario(logger.WithContext(ctx, recordLog), record); err != nil {
+			recordLog.Error("failed to finalize start scenario", zap.Error(err))
+		}
+	}
+
+	return len(records), nil
+}
+
+func (p *Processor) startScenario(ctx context.Context, record inbox_start_scenario.InboxStartScenario) error {
+	log := logger.FromContext(ctx)
+
+	node, fencingToken, startErr := p.placeCamera(ctx, record)
+	if startErr == nil {
+		log = log.With(
+			zap.String("node_id", node.NodeID),
+			zap.String("address", node.Address),
+			zap.Int64("fencing_token", fencingToken),
+		)
+		startErr = p.runner.StartWorker(ctx, node.Address, record.CameraID, record.Url, fencingToken)
+	}
+
+	if startErr == nil {
+		log.Info("worker started")
+		return p.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+			if err := p.repo.MarkInboxStartScenarioProcessed(txCtx, record.OutboxUuid); err != nil {
+				return fmt.Errorf("mark processed: %w", err)
+			}
+			if err := p.reporter.ReportScenarioStarted(txCtx, record.ScenarioUuid, record.CameraID); err != nil {
+				return fmt.Errorf("report scenario started: %w", err)
+			}
+			return nil
+		})
+	}
+
+	lastError := startErr.Error()
+
+	if p.cfg.Retry.Exhausted(record.Attempts) {
+		log.Error("worker start failed, retries exhausted", zap.Error(startErr))
+		return p.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+			if err := p.repo.MarkInboxStartScenarioFailed(txCtx, inbox_start_scenario.MarkInboxStartScenarioFailedParams{
+				OutboxUuid: record.OutboxUuid,
+				LastError:  &lastError,
+			}); err != nil {
+				return fmt.Errorf("mark failed: %w", err)
+			}
+			if err := p.releaseCamera(txCtx, record.CameraID, record.ScenarioUuid); err != nil {
+				return fmt.Errorf("release camera: %w", err)
+			}
+			if err := p.reporter.ReportScenarioStartFailed(txCtx, record.ScenarioUuid, record.CameraID, lastError); err != nil {
+				return fmt.Errorf("report scenario start failed: %w", err)
+			}
+			return nil
+		})
+	}
+
+	delay := p.cfg.Retry.Backoff(record.Attempts)
+	log.Warn("worker start failed, retry scheduled", zap.Error(startErr), zap.Duration("delay", delay))
+
+	if err := p.repo.RescheduleInboxStartScenario(ctx, inbox_start_scenario.RescheduleInboxStartScenarioParams{
+		OutboxUuid: record.OutboxUuid,
+		LastError:  &lastError,
+		DelayMs:    int32(delay / time.Millisecond),
+	}); err != nil {
+		return fmt.Errorf("reschedule: %w", err)
+	}
+	return nil
+}
+
+// placeCamera возвращает runner для камеры и fencing token размещения. Если камера уже
+// размещена на живом runner'е (например, при повторной попытке), используется он, иначе runner
+// выбирается стратегией размещения и размещение записывается в camera_assignment с новым токеном.
+func (p *Processor) placeCamera(ctx context.Context, record inbox_start_scenario.InboxStartScenario) (RunnerNode, int64, error) {
+	var (
+		placed       RunnerNode
+		fencingToken int64
+	)
+
+	err := p.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+		alive, err := p.aliveNodes(txCtx)
+		if err != nil {
+			return err
+		}
+
+		assignment, err := p.repo.GetCameraAssignmentForUpdate(txCtx, record.CameraID)
+		switch {
+		case err == nil:
+			for _, node := range alive {
+				if node.NodeID == assignment.NodeID && assignment.ScenarioUuid == record.ScenarioUuid {
+					placed = node
+					fencingToken = assignment.FencingToken
+					return nil
+				}
+			}
+		case !errors.Is(err, modelerror.ErrNotFound):
+			return fmt.Errorf("get camera assignment: %w", err)
+		}
+
+		node, err := p.placement.Place(record.CameraID, alive)
+		if err != nil {
+			return err
+		}
+
+		assignment, err = p.repo.UpsertCameraAssignment(txCtx, camera_assignment.UpsertCameraAssignmentParams{
+			CameraID:     record.CameraID,
+			ScenarioUuid: record.ScenarioUuid,
+			NodeID:       node.NodeID,
+			Url:          record.Url,
+		})
+		if err != nil {
+			return fmt.Errorf("upsert camera assignment: %w", err)
+		}
+
+		placed = node
+		fencingToken = assignment.FencingToken
+		return nil
+	})
+	if err != nil {
+		return RunnerNode{}, 0, fmt.Errorf("place camera: %w", err)
+	}
+
+	return placed, fencingToken, nil
+}
+
+// aliveNodes возвращает runner'ы, доступные для размещения, с текущим количеством размещенных камер
+func (p *Processor) aliveNodes(ctx context.Context) ([]RunnerNode, error) {
+	rows, err := p.repo.ListAliveRunnerNodes(ctx, int32(p.cfg.HeartbeatTimeout/time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("list alive runner nodes: %w", err)
+	}
+
+	alive := make([]RunnerNode, 0, len(rows))
+	for _, row := range rows {
+		alive = append(alive, RunnerNode{
+			NodeID:   row.NodeID,
+			Address:  row.Address,
+			Capacity: row.Capacity,
+			Load:     row.AssignedCount,
+		})
+	}
+	return alive, nil
+}
+
+// releaseCamera снимает размещение камеры, если оно относится к сценарию scenarioUUID.
+// Размещение другого (более нового) сценария той же камеры не трогается.
+func (p *Processor) releaseCamera(ctx context.Context, cameraID int32, scenarioUUID pgtype.UUID) error {
+	assignment, err := p.repo.GetCameraAssignmentForUpdate(ctx, cameraID)
+	if err != nil {
+		if errors.Is(err, modelerror.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if assignment.ScenarioUuid != scenarioUUID {
+		return nil
+	}
+
+	return p.repo.DeleteCameraAssignment(ctx, cameraID)
+}
+
+// ProcessStopScenarios захватывает до batchSize сообщений из inbox_stop_scenario (stop_scenario и
+// compensate_scenario) и останавливает воркеры на runner'ах, где размещены камеры, с той же
+// политикой повторов. Возвращает количество захваченных сообщений.
+func (p *Processor) ProcessStopScenarios(ctx context.Context, batchSize int32) (int, error) {
+	log := logger.FromContext(ctx)
+
+	records, err := p.repo.ClaimInboxStopScenarios(ctx, inbox_stop_scenario.ClaimInboxStopScenariosParams{
+		LeaseSeconds: int32(p.cfg.Lease / time.Second),
+		BatchLimit:   batchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("claim inbox stop scenarios: %w", err)
+	}
+
+	for _, record := range records {
+		recordLog := log.With(
+			zap.String("outbox_uuid", uuidToString(record.OutboxUuid)),
+			zap.String("scenario_uuid", uuidToString(record.ScenarioUuid)),
+			zap.Int32("camera_id", record.CameraID),
+			zap.Int32("attempt", record.Attempts),
+		)
+
+		if err := p.stopScenario(logger.WithContext(ctx, recordLog), record); err != nil {
+			recordLog.Error("failed to finalize stop scenario", zap.Error(err))
+		}
+	}
+
+	return len(records), nil
+}
+
+// stopScenario останавливает воркер сценария в одной транзакции с поиском и снятием размещения
+// камеры: размещение заблокировано, пока воркер останавливается, поэтому placeCamera не
+// переразместит камеру между проверкой и снятием. После исчерпания попыток размещение тоже
+// снимается: воркер, который runner не удалось остановить, будет отозван по fencing token'у
+// при следующем размещении камеры. В обоих случаях в той же транзакции публикуется scenario_stopped
+func (p *Processor) stopScenario(ctx context.Context, record inbox_stop_scenario.InboxStopScenario) error {
+	log := logger.FromContext(ctx)
+
+	return p.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+		node, err := p.assignedRunner(txCtx, record)
+		if err != nil {
+			return err
+		}
+
+		var stopErr error
+		if node != nil {
+			log = log.With(zap.String("node_id", node.NodeID), zap.String("address", node.Address))
+			stopErr = p.runner.RemoveWorker(txCtx, node.Address, record.CameraID)
+		}
+
+		if stopErr == nil {
+			if node != nil {
+				log.Info("worker removed")
+			}
+			if err := p.releaseCamera(txCtx, record.CameraID, record.ScenarioUuid); err != nil {
+				return fmt.Errorf("release camera: %w", err)
+			}
+			if err := p.reporter.ReportScenarioStopped(txCtx, record.ScenarioUuid, record.CameraID, ""); err != nil {
+				return fmt.Errorf("report scenario stopped: %w", err)
+			}
+			if err := p.repo.MarkInboxStopScenarioProcessed(txCtx, record.OutboxUuid); err != nil {
+				return fmt.Errorf("mark processed: %w", err)
+			}
+			return nil
+		}
+
+		lastError := stopErr.Error()
+
+		if p.cfg.Retry.Exhausted(record.Attempts) {
+			log.Error("worker removal failed, retries exhausted", zap.Error(stopErr))
+			if err := p.repo.MarkInboxStopScenarioFailed(txCtx, inbox_stop_scenario.MarkInboxStopScenarioFailedParams{
+				OutboxUuid: record.OutboxUuid,
+				LastError:  &lastError,
+			}); err != nil {
+				return fmt.Errorf("mark failed: %w", err)
+			}
+			if err := p.releaseCamera(txCtx, record.CameraID, record.ScenarioUuid); err != nil {
+				return fmt.Errorf("release camera: %w", err)
+			}
+			if err := p.reporter.ReportScenarioStopped(txCtx, record.ScenarioUuid, record.CameraID, lastError); err != nil {
+				return fmt.Errorf("report scenario stopped: %w", err)
+			}
+			return nil
+		}
+
+		delay := p.cfg.Retry.Backoff(record.Attempts)
+		log.Warn("worker removal failed, retry scheduled", zap.Error(stopErr), zap.Duration("delay", delay))
+
+		if err := p.repo.RescheduleInboxStopScenario(txCtx, inbox_stop_scenario.RescheduleInboxStopScenarioParams{
+			OutboxUuid: record.OutboxUuid,
+			LastError:  &lastError,
+			DelayMs:    int32(delay / time.Millisecond),
+		}); err != nil {
+			return fmt.Errorf("reschedule: %w", err)
+		}
+		return nil
+	})
+}
+
+// assignedRunner блокирует размещение камеры и возвращает живой runner, на котором работает
+// воркер сценария, или nil, если останавливать нечего
+func (p *Processor) assignedRunner(ctx context.Context, record inbox_stop_scenario.InboxStopScenario) (*runner_node.RunnerNode, error) {
+	log := logger.FromContext(ctx)
+
+	assignment, err := p.repo.GetCameraAssignmentForUpdate(ctx, record.CameraID)
+	if err != nil {
+		if errors.Is(err, modelerror.ErrNotFound) {
+			log.Info("camera is not placed on any runner, nothing to stop")
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get camera assignment: %w", err)
+	}
+
+	if assignment.ScenarioUuid != record.ScenarioUuid {
+		log.Info("camera is placed for another scenario, nothing to stop",
+			zap.String("assigned_scenario_uuid", uuidToString(assignment.ScenarioUuid)),
+		)
+		return nil, nil
+	}
+
+	node, err := p.repo.GetRunnerNode(ctx, assignment.NodeID)
+	if err != nil {
+		if errors.Is(err, modelerror.ErrNotFound) {
+			log.Info("runner of the camera is deregistered, worker is already gone", zap.String("node_id", assignment.NodeID))
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get runner node: %w", err)
+	}
+
+	// Воркер на dead runner'е будет отозван по fencing token'у, когда runner вернется
+	// и пришлет heartbeat, поэтому достаточно снять размещение
+	if node.Status == runnerNodeStatusDead {
+		log.Info("runner of the camera is dead, worker will be revoked on its next heartbeat", zap.String("node_id", assignment.NodeID))
+		return nil, nil
+	}
+
+	return &node, nil
+}
+
+func uuidToString(pgUUID pgtype.UUID) string {
+	if !pgUUID.Valid {
+		return ""
 	}
+	return uuid.UUID(pgUUID.Bytes).String()
 }
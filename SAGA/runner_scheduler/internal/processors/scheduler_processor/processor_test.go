@@ -0,0 +1,172 @@
+package scheduler_processor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"runner_scheduler/internal/infrastructure/repository/queries/camera_assignment"
+	"runner_scheduler/internal/infrastructure/repository/queries/inbox_stop_scenario"
+	"runner_scheduler/internal/infrastructure/repository/queries/runner_node"
+	modelerror "runner_scheduler/internal/models/error"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fakeRepository хранит размещения камер и запоминает, чем закончилась обработка inbox записей.
+// Размещения читаются только внутри транзакции, как того требует FOR UPDATE
+type fakeRepository struct {
+	Repository
+
+	t           *testing.T
+	inTx        bool
+	assignments map[int32]camera_assignment.CameraAssignment
+	nodes       map[string]runner_node.RunnerNode
+
+	processed   bool
+	failed      bool
+	rescheduled bool
+}
+
+func (r *fakeRepository) WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error {
+	r.inTx = true
+	defer func() { r.inTx = false }()
+	return tFunc(ctx)
+}
+
+func (r *fakeRepository) GetCameraAssignmentForUpdate(ctx context.Context, cameraID int32) (camera_assignment.CameraAssignment, error) {
+	if !r.inTx {
+		r.t.Fatalf("camera assignment locked outside of a transaction")
+	}
+	assignment, ok := r.assignments[cameraID]
+	if !ok {
+		return camera_assignment.CameraAssignment{}, modelerror.ErrNotFound
+	}
+	return assignment, nil
+}
+
+func (r *fakeRepository) DeleteCameraAssignment(ctx context.Context, cameraID int32) error {
+	delete(r.assignments, cameraID)
+	return nil
+}
+
+func (r *fakeRepository) GetRunnerNode(ctx context.Context, nodeID string) (runner_node.RunnerNode, error) {
+	node, ok := r.nodes[nodeID]
+	if !ok {
+		return runner_node.RunnerNode{}, modelerror.ErrNotFound
+	}
+	return node, nil
+}
+
+func (r *fakeRepository) MarkInboxStopScenarioProcessed(ctx context.Context, outboxUUID pgtype.UUID) error {
+	r.processed = true
+	return nil
+}
+
+func (r *fakeRepository) MarkInboxStopScenarioFailed(ctx context.Context, arg inbox_stop_scenario.MarkInboxStopScenarioFailedParams) error {
+	r.failed = true
+	return nil
+}
+
+func (r *fakeRepository) RescheduleInboxStopScenario(ctx context.Context, arg inbox_stop_scenario.RescheduleInboxStopScenarioParams) error {
+	r.rescheduled = true
+	return nil
+}
+
+// fakeRunner возвращает removeErr на каждую остановку воркера
+type fakeRunner struct {
+	removeErr error
+	removed   []string
+}
+
+func (r *fakeRunner) StartWorker(ctx context.Context, address string, cameraID int32, url string, fencingToken int64) error {
+	return nil
+}
+
+func (r *fakeRunner) RemoveWorker(ctx context.Context, address string, cameraID int32) error {
+	r.removed = append(r.removed, address)
+	return r.removeErr
+}
+
+// fakeReporter запоминает причины опубликованных scenario_stopped
+type fakeReporter struct {
+	stopped []string
+}
+
+func (r *fakeReporter) ReportScenarioStarted(ctx context.Context, scenarioUUID pgtype.UUID, cameraID int32) error {
+	return nil
+}
+
+func (r *fakeReporter) ReportScenarioStartFailed(ctx context.Context, scenarioUUID pgtype.UUID, cameraID int32, reason string) error {
+	return nil
+}
+
+func (r *fakeReporter) ReportScenarioStopped(ctx context.Context, scenarioUUID pgtype.UUID, cameraID int32, reason string) error {
+	r.stopped = append(r.stopped, reason)
+	return nil
+}
+
+func TestStopScenario(t *testing.T) {
+	scenario := pgtype.UUID{Bytes: [16]byte{1}, Valid: true}
+	other := pgtype.UUID{Bytes: [16]byte{2}, Valid: true}
+
+	cases := map[string]struct {
+		assigned   pgtype.UUID
+		nodeStatus string
+		removeErr  error
+		attempts   int32
+
+		wantRemoved     bool
+		wantProcessed   bool
+		wantFailed      bool
+		wantRescheduled bool
+		wantReleased    bool
+		wantStopped     bool
+	}{
+		"worker removed":               {assigned: scenario, nodeStatus: "alive", wantRemoved: true, wantProcessed: true, wantReleased: true, wantStopped: true},
+		"camera placed for another":    {assigned: other, nodeStatus: "alive", wantProcessed: true, wantStopped: true},
+		"runner dead":                  {assigned: scenario, nodeStatus: runnerNodeStatusDead, wantProcessed: true, wantReleased: true, wantStopped: true},
+		"removal failed, retry":        {assigned: scenario, nodeStatus: "alive", removeErr: errors.New("unavailable"), attempts: 1, wantRemoved: true, wantRescheduled: true},
+		"removal failed, out of tries": {assigned: scenario, nodeStatus: "alive", removeErr: errors.New("unavailable"), attempts: 3, wantRemoved: true, wantFailed: true, wantReleased: true, wantStopped: true},
+	}
+
+	for name, tc := range cases {
+		repo := &fakeRepository{
+			t:           t,
+			assignments: map[int32]camera_assignment.CameraAssignment{7: {CameraID: 7, ScenarioUuid: tc.assigned, NodeID: "runner-a"}},
+			nodes:       map[string]runner_node.RunnerNode{"runner-a": {NodeID: "runner-a", Address: "runner-a:50051", Status: tc.nodeStatus}},
+		}
+		runner := &fakeRunner{removeErr: tc.removeErr}
+		reporter := &fakeReporter{}
+		p := NewProcessor(repo, runner, reporter, LeastLoaded{}, Config{
+			Retry: RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Minute},
+		})
+
+		err := p.stopScenario(context.Background(), inbox_stop_scenario.InboxStopScenario{
+			ScenarioUuid: scenario,
+			CameraID:     7,
+			Attempts:     tc.attempts,
+		})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+
+		if removed := len(runner.removed) > 0; removed != tc.wantRemoved {
+			t.Fatalf("%s: expected worker removal %v, got %v", name, tc.wantRemoved, removed)
+		}
+		if repo.processed != tc.wantProcessed || repo.failed != tc.wantFailed || repo.rescheduled != tc.wantRescheduled {
+			t.Fatalf("%s: unexpected inbox outcome: processed=%v failed=%v rescheduled=%v",
+				name, repo.processed, repo.failed, repo.rescheduled)
+		}
+		if _, placed := repo.assignments[7]; placed == tc.wantReleased {
+			t.Fatalf("%s: expected camera released %v, still placed %v", name, tc.wantReleased, placed)
+		}
+		if stopped := len(reporter.stopped) == 1; stopped != tc.wantStopped {
+			t.Fatalf("%s: expected scenario_stopped reported %v, got %v", name, tc.wantStopped, reporter.stopped)
+		}
+		if tc.removeErr != nil && tc.wantStopped && reporter.stopped[0] != tc.removeErr.Error() {
+			t.Fatalf("%s: expected stop reason %q, got %q", name, tc.removeErr, reporter.stopped[0])
+		}
+	}
+}
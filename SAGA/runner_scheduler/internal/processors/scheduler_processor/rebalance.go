@@ -0,0 +1,111 @@
+package scheduler_processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runner_scheduler/internal/infrastructure/repository/queries/camera_assignment"
+	modelerror "runner_scheduler/internal/models/error"
+	"runner_scheduler/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const runnerNodeStatusDead = "dead"
+
+// RebalanceCameras помечает dead runner'ы без heartbeat'а дольше HeartbeatTimeout и переносит
+// до batchSize камер с dead и снятых с учета runner'ов на живые. Возвращает количество
+// перенесенных камер.
+//
+// Воркер запускается на новом runner'е с fencing token'ом, на единицу большим текущего, и
+// только после успешного запуска размещение обновляется (UpsertCameraAssignment увеличивает
+// токен). Строка размещения заблокирована на время переноса, поэтому токен не может измениться
+// между запуском воркера и записью. Если runner'у не удалось запустить воркер, камера остается
+// на старом размещении и будет перенесена на следующем проходе.
+func (p *Processor) RebalanceCameras(ctx context.Context, batchSize int32) (int, error) {
+	log := logger.FromContext(ctx)
+
+	dead, err := p.repo.MarkDeadRunnerNodes(ctx, int32(p.cfg.HeartbeatTimeout/time.Second))
+	if err != nil {
+		return 0, fmt.Errorf("mark dead runner nodes: %w", err)
+	}
+	for _, nodeID := range dead {
+		log.Warn("runner node missed heartbeats, marked dead", zap.String("node_id", nodeID))
+	}
+
+	moved := 0
+
+	err = p.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+		orphaned, err := p.repo.ListOrphanedCameraAssignments(txCtx, batchSize)
+		if err != nil {
+			return fmt.Errorf("list orphaned camera assignments: %w", err)
+		}
+		if len(orphaned) == 0 {
+			return nil
+		}
+
+		alive, err := p.aliveNodes(txCtx)
+		if err != nil {
+			return err
+		}
+
+		for _, assignment := range orphaned {
+			assignmentLog := log.With(
+				zap.Int32("camera_id", assignment.CameraID),
+				zap.String("scenario_uuid", uuidToString(assignment.ScenarioUuid)),
+				zap.String("from_node_id", assignment.NodeID),
+			)
+
+			node, err := p.placement.Place(assignment.CameraID, alive)
+			if err != nil {
+				if errors.Is(err, modelerror.ErrNoAvailableRunners) {
+					assignmentLog.Warn("no runner available to move camera to")
+					return nil
+				}
+				return err
+			}
+
+			fencingToken := assignment.FencingToken + 1
+			if err := p.runner.StartWorker(txCtx, node.Address, assignment.CameraID, assignment.Url, fencingToken); err != nil {
+				assignmentLog.Error("failed to start worker on new runner",
+					zap.String("to_node_id", node.NodeID),
+					zap.Error(err),
+				)
+				continue
+			}
+
+			// Ошибка записи откатывает весь батч: уже запущенные воркеры будут отозваны
+			// по fencing token'у после следующего переноса камеры
+			if _, err := p.repo.UpsertCameraAssignment(txCtx, camera_assignment.UpsertCameraAssignmentParams{
+				CameraID:     assignment.CameraID,
+				ScenarioUuid: assignment.ScenarioUuid,
+				NodeID:       node.NodeID,
+				Url:          assignment.Url,
+			}); err != nil {
+				return fmt.Errorf("upsert camera assignment: %w", err)
+			}
+
+			// Учитываем перенесенную камеру в загрузке, чтобы следующие камеры батча
+			// распределялись с учетом уже сделанных переносов
+			for i := range alive {
+				if alive[i].NodeID == node.NodeID {
+					alive[i].Load++
+				}
+			}
+
+			moved++
+			assignmentLog.Info("camera moved",
+				zap.String("to_node_id", node.NodeID),
+				zap.Int64("fencing_token", fencingToken),
+			)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return moved, nil
+}
@@ -0,0 +1,36 @@
+package scheduler_processor
+
+import "time"
+
+// RetryPolicy задает количество попыток отправки команды runner'у и экспоненциальную задержку между ними
+type RetryPolicy struct {
+	MaxAttempts int32
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Backoff возвращает задержку перед следующей попыткой после attempt неудачных попыток:
+// BaseDelay * 2^(attempt-1), но не больше MaxDelay
+func (p RetryPolicy) Backoff(attempt int32) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := p.BaseDelay
+	for i := int32(1); i < attempt; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+
+	if delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// Exhausted сообщает, что после attempt попыток повторять больше нельзя
+func (p RetryPolicy) Exhausted(attempt int32) bool {
+	return attempt >= p.MaxAttempts
+}
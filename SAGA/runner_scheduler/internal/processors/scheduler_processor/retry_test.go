@@ -0,0 +1,40 @@
+package scheduler_processor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	cases := []struct {
+		attempt int32
+		want    time.Duration
+	}{
+		{attempt: 0, want: time.Second},
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 3, want: 4 * time.Second},
+		{attempt: 4, want: 8 * time.Second},
+		{attempt: 5, want: 10 * time.Second},
+		{attempt: 50, want: 10 * time.Second},
+	}
+
+	for _, tc := range cases {
+		if got := policy.Backoff(tc.attempt); got != tc.want {
+			t.Fatalf("Backoff(%d) = %s, want %s", tc.attempt, got, tc.want)
+		}
+	}
+}
+
+func TestRetryPolicyExhausted(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Minute}
+
+	if policy.Exhausted(2) {
+		t.Fatalf("expected attempt 2 of 3 not to be exhausted")
+	}
+	if !policy.Exhausted(3) {
+		t.Fatalf("expected attempt 3 of 3 to be exhausted")
+	}
+}
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Таблица outbox_scenario_result для паттерна Outbox в SAGA (ответ в init_scenario_api)
+CREATE TABLE outbox_scenario_result (
+    outbox_uuid UUID NOT NULL PRIMARY KEY,
+    scenario_uuid UUID NOT NULL,
+    event_type TEXT NOT NULL CHECK (event_type IN ('scenario_started', 'scenario_start_failed')),
+    payload JSONB NOT NULL,
+    state TEXT NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP,
+    locked_until TIMESTAMP DEFAULT NULL
+);
+
+-- Комментарии к таблице
+COMMENT ON TABLE outbox_scenario_result IS 'Outbox pattern table for reporting scenario startup results back to init_scenario_api';
+COMMENT ON COLUMN outbox_scenario_result.outbox_uuid IS 'Unique identifier for the outbox message';
+COMMENT ON COLUMN outbox_scenario_result.scenario_uuid IS 'UUID of the scenario the result belongs to';
+COMMENT ON COLUMN outbox_scenario_result.event_type IS 'Type of the event (scenario_started, scenario_start_failed)';
+COMMENT ON COLUMN outbox_scenario_result.payload IS 'JSON data of the message payload';
+COMMENT ON COLUMN outbox_scenario_result.state IS 'State of the message (pending, sent, failed)';
+COMMENT ON COLUMN outbox_scenario_result.created_at IS 'Timestamp when the message was created';
+COMMENT ON COLUMN outbox_scenario_result.updated_at IS 'Timestamp when the message was last updated';
+COMMENT ON COLUMN outbox_scenario_result.locked_until IS 'Timestamp until which the outbox message is locked from being processed';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS outbox_scenario_result;
+
+-- +goose StatementEnd
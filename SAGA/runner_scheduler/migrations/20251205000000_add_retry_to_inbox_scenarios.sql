@@ -0,0 +1,64 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Счетчик попыток, время следующей попытки и последняя ошибка для повторной обработки inbox scheduler'ом
+ALTER TABLE inbox_start_scenario
+    ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN last_error TEXT;
+
+ALTER TABLE inbox_start_scenario DROP CONSTRAINT IF EXISTS inbox_start_scenario_status_check;
+ALTER TABLE inbox_start_scenario ADD CONSTRAINT inbox_start_scenario_status_check
+    CHECK (status IN ('received', 'in_process', 'processed', 'failed'));
+
+CREATE INDEX IF NOT EXISTS inbox_start_scenario_status_next_attempt_idx ON inbox_start_scenario (status, next_attempt_at);
+
+COMMENT ON COLUMN inbox_start_scenario.status IS 'Processing status: received, in_process, processed, failed';
+COMMENT ON COLUMN inbox_start_scenario.attempts IS 'Number of dispatch attempts made by the scheduler';
+COMMENT ON COLUMN inbox_start_scenario.next_attempt_at IS 'Timestamp before which the message must not be dispatched again (retry backoff)';
+COMMENT ON COLUMN inbox_start_scenario.last_error IS 'Error of the last failed dispatch attempt';
+
+ALTER TABLE inbox_stop_scenario
+    ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN last_error TEXT;
+
+ALTER TABLE inbox_stop_scenario DROP CONSTRAINT IF EXISTS inbox_stop_scenario_status_check;
+ALTER TABLE inbox_stop_scenario ADD CONSTRAINT inbox_stop_scenario_status_check
+    CHECK (status IN ('received', 'in_process', 'processed', 'failed'));
+
+CREATE INDEX IF NOT EXISTS inbox_stop_scenario_status_next_attempt_idx ON inbox_stop_scenario (status, next_attempt_at);
+
+COMMENT ON COLUMN inbox_stop_scenario.status IS 'Processing status: received, in_process, processed, failed';
+COMMENT ON COLUMN inbox_stop_scenario.attempts IS 'Number of dispatch attempts made by the scheduler';
+COMMENT ON COLUMN inbox_stop_scenario.next_attempt_at IS 'Timestamp before which the message must not be dispatched again (retry backoff)';
+COMMENT ON COLUMN inbox_stop_scenario.last_error IS 'Error of the last failed dispatch attempt';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS inbox_stop_scenario_status_next_attempt_idx;
+UPDATE inbox_stop_scenario SET status = 'processed' WHERE status = 'failed';
+ALTER TABLE inbox_stop_scenario DROP CONSTRAINT IF EXISTS inbox_stop_scenario_status_check;
+ALTER TABLE inbox_stop_scenario ADD CONSTRAINT inbox_stop_scenario_status_check
+    CHECK (status IN ('received', 'in_process', 'processed'));
+ALTER TABLE inbox_stop_scenario
+    DROP COLUMN IF EXISTS last_error,
+    DROP COLUMN IF EXISTS next_attempt_at,
+    DROP COLUMN IF EXISTS attempts;
+COMMENT ON COLUMN inbox_stop_scenario.status IS 'Processing status: received, in_process, processed';
+
+DROP INDEX IF EXISTS inbox_start_scenario_status_next_attempt_idx;
+UPDATE inbox_start_scenario SET status = 'processed' WHERE status = 'failed';
+ALTER TABLE inbox_start_scenario DROP CONSTRAINT IF EXISTS inbox_start_scenario_status_check;
+ALTER TABLE inbox_start_scenario ADD CONSTRAINT inbox_start_scenario_status_check
+    CHECK (status IN ('received', 'in_process', 'processed'));
+ALTER TABLE inbox_start_scenario
+    DROP COLUMN IF EXISTS last_error,
+    DROP COLUMN IF EXISTS next_attempt_at,
+    DROP COLUMN IF EXISTS attempts;
+COMMENT ON COLUMN inbox_start_scenario.status IS 'Processing status: received, in_process, processed';
+
+-- +goose StatementEnd
@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Реестр runner'ов, которые регистрируются через heartbeat
+CREATE TABLE runner_node (
+    node_id TEXT NOT NULL PRIMARY KEY,
+    address TEXT NOT NULL,
+    capacity INTEGER NOT NULL CHECK (capacity >= 0),
+    worker_count INTEGER NOT NULL DEFAULT 0,
+    last_heartbeat_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+COMMENT ON TABLE runner_node IS 'Registry of runner instances that host camera workers';
+COMMENT ON COLUMN runner_node.node_id IS 'Unique identifier of the runner instance';
+COMMENT ON COLUMN runner_node.address IS 'gRPC address (host:port) of the runner reachable from the scheduler';
+COMMENT ON COLUMN runner_node.capacity IS 'Maximum number of workers the runner can host';
+COMMENT ON COLUMN runner_node.worker_count IS 'Number of workers reported by the runner in the last heartbeat';
+COMMENT ON COLUMN runner_node.last_heartbeat_at IS 'Timestamp of the last heartbeat received from the runner';
+COMMENT ON COLUMN runner_node.created_at IS 'Timestamp when the runner was first registered';
+COMMENT ON COLUMN runner_node.updated_at IS 'Timestamp when the runner record was last updated';
+
+-- Размещение камер на runner'ах
+CREATE TABLE camera_assignment (
+    camera_id INTEGER NOT NULL PRIMARY KEY,
+    scenario_uuid UUID NOT NULL,
+    node_id TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS camera_assignment_node_id_idx ON camera_assignment (node_id);
+
+COMMENT ON TABLE camera_assignment IS 'Placement of camera workers on runner instances';
+COMMENT ON COLUMN camera_assignment.camera_id IS 'ID of the camera whose worker is placed on the runner';
+COMMENT ON COLUMN camera_assignment.scenario_uuid IS 'UUID of the scenario the worker was started for';
+COMMENT ON COLUMN camera_assignment.node_id IS 'Runner instance hosting the camera worker (may point to a deregistered node until the camera is re-placed)';
+COMMENT ON COLUMN camera_assignment.created_at IS 'Timestamp when the camera was placed';
+COMMENT ON COLUMN camera_assignment.updated_at IS 'Timestamp when the assignment was last updated';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS camera_assignment;
+DROP TABLE IF EXISTS runner_node;
+
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE runner_node
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'alive' CHECK (status IN ('alive', 'dead'));
+
+COMMENT ON COLUMN runner_node.status IS 'Liveness of the runner: alive, or dead after missing heartbeats (cameras are moved to other runners)';
+
+ALTER TABLE camera_assignment
+    ADD COLUMN url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN fencing_token BIGINT NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN camera_assignment.url IS 'RTSP stream URL of the camera, used to restart the worker on another runner';
+COMMENT ON COLUMN camera_assignment.fencing_token IS 'Monotonic token incremented on every placement of the camera; workers started with an older token are revoked';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE camera_assignment
+    DROP COLUMN IF EXISTS fencing_token,
+    DROP COLUMN IF EXISTS url;
+
+ALTER TABLE runner_node
+    DROP COLUMN IF EXISTS status;
+
+-- +goose StatementEnd
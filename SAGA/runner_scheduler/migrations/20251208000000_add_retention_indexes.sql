@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE INDEX IF NOT EXISTS inbox_start_scenario_processed_updated_at_idx ON inbox_start_scenario (updated_at)
+    WHERE status = 'processed';
+
+CREATE INDEX IF NOT EXISTS inbox_stop_scenario_processed_updated_at_idx ON inbox_stop_scenario (updated_at)
+    WHERE status = 'processed';
+
+CREATE INDEX IF NOT EXISTS outbox_scenario_result_sent_updated_at_idx ON outbox_scenario_result (updated_at)
+    WHERE state = 'sent';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS outbox_scenario_result_sent_updated_at_idx;
+DROP INDEX IF EXISTS inbox_stop_scenario_processed_updated_at_idx;
+DROP INDEX IF EXISTS inbox_start_scenario_processed_updated_at_idx;
+
+-- +goose StatementEnd
@@ -0,0 +1,80 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS outbox (
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    aggregate_type TEXT NOT NULL,
+    aggregate_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    topic TEXT NOT NULL,
+    headers JSONB NOT NULL DEFAULT '{}',
+    payload BYTEA NOT NULL,
+    state TEXT NOT NULL DEFAULT 'pending' CHECK (state IN ('pending', 'sent', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGSERIAL NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+COMMENT ON TABLE outbox IS 'Generic outbox for reliable publishing of aggregate events to Kafka';
+COMMENT ON COLUMN outbox.id IS 'Unique identifier of the event, sent in the outbox_uuid header for deduplication';
+COMMENT ON COLUMN outbox.aggregate_type IS 'Type of the aggregate the event belongs to (scenario, camera, ...)';
+COMMENT ON COLUMN outbox.aggregate_id IS 'Identifier of the aggregate; used as the Kafka message key, events of one aggregate are published in order';
+COMMENT ON COLUMN outbox.event_type IS 'Type of the event';
+COMMENT ON COLUMN outbox.topic IS 'Kafka topic the event is published to';
+COMMENT ON COLUMN outbox.headers IS 'Additional Kafka message headers';
+COMMENT ON COLUMN outbox.payload IS 'Message payload';
+COMMENT ON COLUMN outbox.state IS 'State of the event (pending, sent, failed)';
+COMMENT ON COLUMN outbox.attempts IS 'Number of publish attempts';
+COMMENT ON COLUMN outbox.last_error IS 'Error of the last failed publish attempt';
+COMMENT ON COLUMN outbox.next_attempt_at IS 'Timestamp before which the event must not be claimed (lease of the relay or retry backoff)';
+COMMENT ON COLUMN outbox.seq IS 'Insertion order of events, defines publish order within an aggregate';
+COMMENT ON COLUMN outbox.created_at IS 'Timestamp when the event was created';
+COMMENT ON COLUMN outbox.updated_at IS 'Timestamp when the event was last updated';
+
+CREATE INDEX IF NOT EXISTS outbox_unsent_aggregate_seq_idx ON outbox (aggregate_type, aggregate_id, seq)
+    WHERE state <> 'sent';
+CREATE INDEX IF NOT EXISTS outbox_pending_seq_idx ON outbox (seq)
+    WHERE state = 'pending';
+CREATE INDEX IF NOT EXISTS outbox_sent_updated_at_idx ON outbox (updated_at)
+    WHERE state = 'sent';
+
+-- Результаты запуска сценариев переезжают в общий outbox вместе с историей отправки
+INSERT INTO outbox (id, aggregate_type, aggregate_id, event_type, topic, payload, state, created_at, updated_at)
+SELECT outbox_uuid, 'scenario', scenario_uuid::text, event_type, 'outbox_runner_scheduler',
+       convert_to(payload::text, 'UTF8'), state, created_at, updated_at
+FROM outbox_scenario_result
+ORDER BY created_at;
+
+DROP TABLE IF EXISTS outbox_scenario_result;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS outbox_scenario_result (
+    outbox_uuid UUID NOT NULL PRIMARY KEY,
+    scenario_uuid UUID NOT NULL,
+    event_type TEXT NOT NULL CHECK (event_type IN ('scenario_started', 'scenario_start_failed')),
+    payload JSONB NOT NULL,
+    state TEXT NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP,
+    locked_until TIMESTAMP DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS outbox_scenario_result_sent_updated_at_idx ON outbox_scenario_result (updated_at)
+    WHERE state = 'sent';
+
+INSERT INTO outbox_scenario_result (outbox_uuid, scenario_uuid, event_type, payload, state, created_at, updated_at)
+SELECT id, aggregate_id::uuid, event_type, convert_from(payload, 'UTF8')::jsonb, state, created_at, updated_at
+FROM outbox
+WHERE aggregate_type = 'scenario'
+  AND event_type IN ('scenario_started', 'scenario_start_failed');
+
+DROP TABLE IF EXISTS outbox;
+
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS inbox (
+    message_id TEXT NOT NULL PRIMARY KEY,
+    event_type TEXT NOT NULL,
+    topic TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('processed', 'poison')),
+    attempts INTEGER NOT NULL DEFAULT 1,
+    last_error TEXT,
+    headers JSONB,
+    payload BYTEA,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE inbox IS 'Generic inbox for exactly-once processing of consumed Kafka messages';
+COMMENT ON COLUMN inbox.message_id IS 'Identifier of the message from the outbox_uuid header (serves as primary key for idempotency)';
+COMMENT ON COLUMN inbox.event_type IS 'Type of the event';
+COMMENT ON COLUMN inbox.topic IS 'Kafka topic the message was consumed from';
+COMMENT ON COLUMN inbox.status IS 'Processing status: processed, poison';
+COMMENT ON COLUMN inbox.attempts IS 'Number of processing attempts';
+COMMENT ON COLUMN inbox.last_error IS 'Error that made the message poison';
+COMMENT ON COLUMN inbox.headers IS 'Kafka message headers of a poison message';
+COMMENT ON COLUMN inbox.payload IS 'Payload of a poison message';
+COMMENT ON COLUMN inbox.created_at IS 'Timestamp when the message was processed';
+
+CREATE INDEX IF NOT EXISTS inbox_processed_created_at_idx ON inbox (created_at)
+    WHERE status = 'processed';
+
+-- Уже обработанные сообщения переносятся в inbox, чтобы их повторная доставка не обработалась заново
+INSERT INTO inbox (message_id, event_type, topic, status, created_at)
+SELECT outbox_uuid::text, 'init_scenario', 'outbox_scenario_api', 'processed', created_at
+FROM inbox_start_scenario
+ON CONFLICT (message_id) DO NOTHING;
+
+INSERT INTO inbox (message_id, event_type, topic, status, created_at)
+SELECT outbox_uuid::text, 'stop_scenario', 'outbox_scenario_api', 'processed', created_at
+FROM inbox_stop_scenario
+ON CONFLICT (message_id) DO NOTHING;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS inbox;
+
+-- +goose StatementEnd
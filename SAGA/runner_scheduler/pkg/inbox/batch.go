@@ -0,0 +1,128 @@
+package inbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"runner_scheduler/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// BatchSource - Source, который читает и подтверждает сообщения пачками
+type BatchSource interface {
+	Source
+	// FetchBatch ждет первое сообщение и дочитывает до max сообщений в течение maxWait
+	FetchBatch(ctx context.Context, max int, maxWait time.Duration) ([]Message, error)
+	CommitBatch(ctx context.Context, msgs []Message) error
+}
+
+// ProcessBatch обрабатывает пачку сообщений в одной транзакции: идентификаторы записываются
+// в inbox одним запросом, обработчики новых сообщений выполняются в той же транзакции, а
+// сообщения подтверждаются один раз после ее коммита.
+//
+// Если в пачке есть сообщения без идентификатора или обработчика или транзакция завершилась
+// ошибкой, сообщения обрабатываются по одному через Process, чтобы одно сообщение не
+// задерживало остальные. Возвращает ошибку в тех же случаях, что и Process
+func (c *Consumer) ProcessBatch(ctx context.Context, msgs []Message) error {
+	log := logger.FromContext(ctx).With(zap.Int("batch_size", len(msgs)))
+
+	if rs, ok := c.receiveBatch(msgs); ok {
+		processed, err := c.handleBatch(ctx, rs)
+		if err == nil {
+			log.Info("batch processed",
+				zap.Int("processed", processed),
+				zap.Int("duplicates", len(rs)-processed),
+			)
+			c.commitBatch(ctx, log, msgs)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Warn("failed to process batch, processing messages one by one", zap.Error(err))
+	}
+
+	for _, msg := range msgs {
+		if err := c.Process(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// receiveBatch разбирает заголовки пачки. Возвращает false, если пачку нельзя обработать
+// целиком
+func (c *Consumer) receiveBatch(msgs []Message) ([]received, bool) {
+	rs := make([]received, len(msgs))
+	for i, msg := range msgs {
+		rs[i] = c.receive(msg)
+		if _, ok := c.handlers[rs[i].eventType]; !ok || rs[i].id == "" {
+			return nil, false
+		}
+	}
+	return rs, true
+}
+
+// handleBatch записывает пачку в inbox и обрабатывает новые сообщения в одной транзакции.
+// Сообщения группируются по типу события, порядок внутри группы сохраняется
+func (c *Consumer) handleBatch(ctx context.Context, rs []received) (processed int, err error) {
+	err = c.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		processed = 0
+
+		inserted, err := c.store.markProcessedBatch(txCtx, rs)
+		if err != nil {
+			return fmt.Errorf("insert inbox records: %w", err)
+		}
+
+		var eventTypes []string
+		groups := make(map[string][]Message)
+		for _, r := range rs {
+			if !inserted[r.id] {
+				continue
+			}
+			// Повтор идентификатора внутри пачки обрабатывается один раз
+			delete(inserted, r.id)
+
+			if _, ok := groups[r.eventType]; !ok {
+				eventTypes = append(eventTypes, r.eventType)
+			}
+			groups[r.eventType] = append(groups[r.eventType], r.Message)
+			processed++
+		}
+
+		for _, eventType := range eventTypes {
+			if handler, ok := c.batches[eventType]; ok {
+				if err := handler(txCtx, groups[eventType]); err != nil {
+					return err
+				}
+				continue
+			}
+
+			for _, msg := range groups[eventType] {
+				if err := c.handlers[eventType](txCtx, msg); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	return processed, err
+}
+
+// commitBatch подтверждает пачку одним вызовом, если Source это умеет. Ошибка только
+// логируется: сообщения придут повторно и будут пропущены дедупликацией
+func (c *Consumer) commitBatch(ctx context.Context, log *zap.Logger, msgs []Message) {
+	source, ok := c.source.(BatchSource)
+	if !ok {
+		for _, msg := range msgs {
+			c.commit(ctx, log, msg)
+		}
+		return
+	}
+
+	if err := source.CommitBatch(ctx, msgs); err != nil {
+		log.Error("failed to commit batch", zap.Error(err))
+	}
+}
@@ -0,0 +1,142 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeBatchSource struct {
+	fakeSource
+	batches [][]Message
+}
+
+func (s *fakeBatchSource) FetchBatch(ctx context.Context, max int, maxWait time.Duration) ([]Message, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (s *fakeBatchSource) CommitBatch(ctx context.Context, msgs []Message) error {
+	s.batches = append(s.batches, msgs)
+	return nil
+}
+
+// countingTransactor считает транзакции
+type countingTransactor struct {
+	fakeTransactor
+	count *int
+}
+
+func (t countingTransactor) WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error {
+	*t.count++
+	return t.fakeTransactor.WithinTransaction(ctx, tFunc)
+}
+
+func newBatchConsumer(store *fakeStorage, source *fakeBatchSource, transactions *int) *Consumer {
+	c := newTestConsumer(store, nil)
+	c.source = source
+	c.transactor = countingTransactor{fakeTransactor: fakeTransactor{store: store}, count: transactions}
+	return c
+}
+
+func TestProcessBatchUsesOneTransactionAndCommit(t *testing.T) {
+	store := newFakeStorage()
+	store.processed["1"] = true
+	source := &fakeBatchSource{}
+	var transactions int
+	c := newBatchConsumer(store, source, &transactions)
+
+	var created [][]Message
+	var deleted []string
+	c.Handle("created", func(ctx context.Context, msg Message) error {
+		t.Fatalf("per-message handler must not be used when batch handler is registered")
+		return nil
+	})
+	c.HandleBatch("created", func(ctx context.Context, msgs []Message) error {
+		created = append(created, msgs)
+		return nil
+	})
+	c.Handle("deleted", JSON(func(ctx context.Context, msg Message, p payload) error {
+		deleted = append(deleted, p.Name)
+		return nil
+	}))
+
+	msgs := []Message{
+		message("1", "", `{"name":"a"}`),
+		message("2", "", `{"name":"b"}`),
+		message("3", "deleted", `{"name":"c"}`),
+		message("2", "", `{"name":"b"}`),
+		message("4", "", `{"name":"d"}`),
+	}
+	if err := c.ProcessBatch(context.Background(), msgs); err != nil {
+		t.Fatalf("ProcessBatch returned error: %v", err)
+	}
+
+	if transactions != 1 {
+		t.Fatalf("expected one transaction, got %d", transactions)
+	}
+	if len(created) != 1 || len(created[0]) != 2 ||
+		string(created[0][0].Headers["outbox_uuid"]) != "2" || string(created[0][1].Headers["outbox_uuid"]) != "4" {
+		t.Fatalf("expected new created messages 2 and 4 in one call, got %v", created)
+	}
+	if len(deleted) != 1 || deleted[0] != "c" {
+		t.Fatalf("expected one deleted message, got %v", deleted)
+	}
+	if len(source.batches) != 1 || len(source.batches[0]) != len(msgs) || len(source.committed) != 0 {
+		t.Fatalf("expected the whole batch committed once, got %d batches and %d single commits",
+			len(source.batches), len(source.committed))
+	}
+}
+
+func TestProcessBatchFallsBackToSingleMessagesOnError(t *testing.T) {
+	store := newFakeStorage()
+	source := &fakeBatchSource{}
+	var transactions int
+	c := newBatchConsumer(store, source, &transactions)
+
+	errBroken := Permanent(errors.New("broken payload"))
+	c.Handle("created", JSON(func(ctx context.Context, msg Message, p payload) error {
+		if p.Name == "broken" {
+			return errBroken
+		}
+		return nil
+	}))
+
+	msgs := []Message{
+		message("1", "", `{"name":"a"}`),
+		message("2", "", `{"name":"broken"}`),
+		message("3", "", `{"name":"c"}`),
+	}
+	if err := c.ProcessBatch(context.Background(), msgs); err != nil {
+		t.Fatalf("ProcessBatch returned error: %v", err)
+	}
+
+	if !store.processed["1"] || !store.processed["3"] || store.processed["2"] {
+		t.Fatalf("expected messages 1 and 3 processed after fallback, got %v", store.processed)
+	}
+	if !errors.Is(store.poison["2"], errBroken) {
+		t.Fatalf("expected broken message stored as poison, got %v", store.poison)
+	}
+	if len(source.batches) != 0 || len(source.committed) != len(msgs) {
+		t.Fatalf("expected messages committed one by one, got %d batches and %d single commits",
+			len(source.batches), len(source.committed))
+	}
+}
+
+func TestProcessBatchWithoutIDFallsBackToSingleMessages(t *testing.T) {
+	store := newFakeStorage()
+	source := &fakeBatchSource{}
+	var transactions int
+	c := newBatchConsumer(store, source, &transactions)
+	c.Handle("created", func(ctx context.Context, msg Message) error { return nil })
+
+	msgs := []Message{message("1", "", `{}`), message("", "", `{}`)}
+	if err := c.ProcessBatch(context.Background(), msgs); err != nil {
+		t.Fatalf("ProcessBatch returned error: %v", err)
+	}
+
+	if !store.processed["1"] || len(source.committed) != 2 {
+		t.Fatalf("expected messages processed one by one, got processed=%v commits=%d", store.processed, len(source.committed))
+	}
+}
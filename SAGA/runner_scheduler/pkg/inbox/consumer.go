@@ -0,0 +1,297 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"runner_scheduler/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+var errUnknownEventType = errors.New("no handler registered for event type")
+
+// Source - источник сообщений, например Kafka consumer group
+type Source interface {
+	Fetch(ctx context.Context) (Message, error)
+	Commit(ctx context.Context, msg Message) error
+}
+
+type Transactor interface {
+	WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error
+}
+
+type RetryPolicy struct {
+	// MaxAttempts - количество попыток обработки, после которого сообщение считается poison
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+type Config struct {
+	// IDHeader - заголовок с идентификатором сообщения, по которому дедуплицируется доставка
+	IDHeader string
+	// EventTypeHeader - заголовок с типом события, по которому выбирается обработчик
+	EventTypeHeader string
+	// DefaultEventType - тип сообщений без заголовка EventTypeHeader
+	DefaultEventType string
+	// Retry - повторы на месте. Используются, только если consumer создан без Publisher
+	Retry RetryPolicy
+	// RetryTopics - топики отложенных повторов по порядку попыток. Используются с Publisher:
+	// после n-й неудачной попытки сообщение публикуется в RetryTopics[n-1]
+	RetryTopics []RetryTopic
+	// DeadLetterTopic - топик для сообщений, которые не удалось обработать. Обязателен с Publisher
+	DeadLetterTopic string
+	// Workers - количество параллельных обработчиков Run. Сообщения одной партиции с одним
+	// ключом всегда попадают к одному обработчику и обрабатываются по порядку. По умолчанию 1
+	Workers int
+	// QueueSize - размер очереди каждого обработчика. Пока очередь заполнена, Run не читает
+	// новые сообщения из Source. По умолчанию 1
+	QueueSize int
+	// DrainTimeout - сколько Run после отмены ctx ждет завершения начатых обработок.
+	// По умолчанию 5 секунд
+	DrainTimeout time.Duration
+	// BatchSize - максимальный размер пачки, которую Run читает и обрабатывает в одной
+	// транзакции. Пачки используются, если BatchSize больше 1 и Source реализует BatchSource
+	BatchSize int
+	// BatchWait - сколько после первого сообщения пачки ждать остальные
+	BatchWait time.Duration
+}
+
+type storage interface {
+	markProcessed(ctx context.Context, msg received) (bool, error)
+	markProcessedBatch(ctx context.Context, msgs []received) (map[string]bool, error)
+	markPoison(ctx context.Context, msg received, cause error) error
+}
+
+// received - сообщение с разобранными заголовками и номером попытки обработки
+type received struct {
+	Message
+	id        string
+	eventType string
+	attempts  int32
+}
+
+// Consumer читает сообщения из Source и обрабатывает каждое ровно один раз: обработчик
+// выполняется в одной транзакции с записью идентификатора сообщения в inbox, а повторная
+// доставка уже записанного сообщения пропускается.
+//
+// Если Publisher не задан, ошибки обработчика повторяются на месте с экспоненциальной задержкой.
+// Сообщения без обработчика, с ошибкой Permanent или исчерпавшие попытки сохраняются в inbox со
+// статусом poison. С Publisher сообщение с ошибкой публикуется в следующий топик повторов, а
+// оттуда, когда повторы исчерпаны, в DLQ, и партиция не ждет повтора. В обоих случаях после
+// этого смещение подтверждается, чтобы одно сообщение не останавливало партицию
+type Consumer struct {
+	store      storage
+	transactor Transactor
+	source     Source
+	publisher  Publisher
+	handlers   map[string]Handler
+	batches    map[string]BatchHandler
+	cfg        Config
+}
+
+// NewConsumer создает consumer. Если publisher равен nil, повторы выполняются на месте, а
+// poison сообщения сохраняются в inbox
+func NewConsumer(store *Store, transactor Transactor, source Source, publisher Publisher, cfg Config) *Consumer {
+	return &Consumer{
+		store:      store,
+		transactor: transactor,
+		source:     source,
+		publisher:  publisher,
+		handlers:   make(map[string]Handler),
+		batches:    make(map[string]BatchHandler),
+		cfg:        cfg,
+	}
+}
+
+// Handle регистрирует обработчик событий eventType. Вызывается до Run
+func (c *Consumer) Handle(eventType string, handler Handler) {
+	c.handlers[eventType] = handler
+}
+
+// HandleBatch регистрирует обработчик пачек событий eventType. Без него события из пачки
+// обрабатываются по одному обработчиком Handle, который для eventType обязателен в любом
+// случае. Вызывается до Run
+func (c *Consumer) HandleBatch(eventType string, handler BatchHandler) {
+	c.batches[eventType] = handler
+}
+
+// Process обрабатывает одно сообщение и подтверждает его. Возвращает ошибку, только если
+// ctx отменен до завершения обработки или, с TransactionalSource, не удалась публикация в
+// топик повторов или DLQ: тогда сообщение не подтверждается и придет повторно
+func (c *Consumer) Process(ctx context.Context, msg Message) error {
+	r := c.receive(msg)
+
+	log := logger.FromContext(ctx).With(
+		zap.String("message_id", r.id),
+		zap.String("event_type", r.eventType),
+		zap.String("topic", msg.Topic),
+	)
+
+	if c.publisher != nil && !waitRetryAt(ctx, msg) {
+		return ctx.Err()
+	}
+
+	if r.id == "" && c.publisher == nil {
+		// Без идентификатора сообщение нельзя ни дедуплицировать, ни сохранить как poison
+		log.Error("message without id header, skipping", zap.String("header", c.cfg.IDHeader))
+		c.commit(ctx, log, msg)
+		return nil
+	}
+
+	var err error
+	handler, ok := c.handlers[r.eventType]
+	switch {
+	case r.id == "":
+		err = c.deadLetter(ctx, log, r, fmt.Errorf("message without %s header", c.cfg.IDHeader))
+	case !ok:
+		err = c.fail(ctx, log, r, errUnknownEventType)
+	case c.publisher != nil:
+		err = c.processOnce(ctx, log, r, handler)
+	default:
+		err = c.processWithRetries(ctx, log, r, handler)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.commit(ctx, log, msg)
+	return nil
+}
+
+// receive разбирает заголовки сообщения
+func (c *Consumer) receive(msg Message) received {
+	r := received{
+		Message:   msg,
+		id:        string(msg.Headers[c.cfg.IDHeader]),
+		eventType: c.cfg.DefaultEventType,
+		attempts:  previousAttempts(msg) + 1,
+	}
+	if eventType, ok := msg.Headers[c.cfg.EventTypeHeader]; ok {
+		r.eventType = string(eventType)
+	}
+	return r
+}
+
+// processOnce выполняет одну попытку обработки, а при ошибке передает сообщение в топик
+// повторов или DLQ
+func (c *Consumer) processOnce(ctx context.Context, log *zap.Logger, r received, handler Handler) error {
+	duplicate, err := c.handle(ctx, r, handler)
+	if err == nil {
+		logProcessed(log, duplicate)
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return c.forward(ctx, log, r, err)
+}
+
+// processWithRetries повторяет обработку на месте до RetryPolicy.MaxAttempts попыток
+func (c *Consumer) processWithRetries(ctx context.Context, log *zap.Logger, r received, handler Handler) error {
+	for attempt := 1; ; attempt++ {
+		r.attempts = int32(attempt)
+
+		duplicate, err := c.handle(ctx, r, handler)
+		if err == nil {
+			logProcessed(log, duplicate)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if IsPermanent(err) || attempt >= c.cfg.Retry.MaxAttempts {
+			return c.poison(ctx, log, r, err)
+		}
+
+		delay := c.backoff(attempt)
+		log.Warn("failed to process message, retrying",
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", delay),
+			zap.Error(err),
+		)
+		if !sleep(ctx, delay) {
+			return ctx.Err()
+		}
+	}
+}
+
+// fail отправляет необрабатываемое сообщение в DLQ или, без Publisher, сохраняет как poison
+func (c *Consumer) fail(ctx context.Context, log *zap.Logger, r received, cause error) error {
+	if c.publisher != nil {
+		return c.deadLetter(ctx, log, r, cause)
+	}
+	return c.poison(ctx, log, r, cause)
+}
+
+func logProcessed(log *zap.Logger, duplicate bool) {
+	if duplicate {
+		log.Warn("message already processed, skipping")
+	} else {
+		log.Info("message processed")
+	}
+}
+
+func (c *Consumer) handle(ctx context.Context, r received, handler Handler) (duplicate bool, err error) {
+	err = c.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		inserted, err := c.store.markProcessed(txCtx, r)
+		if err != nil {
+			return fmt.Errorf("insert inbox record: %w", err)
+		}
+		if !inserted {
+			duplicate = true
+			return nil
+		}
+		return handler(txCtx, r.Message)
+	})
+	return duplicate, err
+}
+
+// poison сохраняет сообщение со статусом poison. Ошибки базы повторяются до успеха или
+// отмены ctx: подтвердить сообщение, не сохранив его, значит потерять его
+func (c *Consumer) poison(ctx context.Context, log *zap.Logger, r received, cause error) error {
+	log.Error("poison message", zap.Int32("attempts", r.attempts), zap.Error(cause))
+
+	for attempt := 1; ; attempt++ {
+		err := c.store.markPoison(ctx, r, cause)
+		if err == nil {
+			return nil
+		}
+
+		delay := c.backoff(attempt)
+		log.Error("failed to save poison message, retrying", zap.Duration("retry_in", delay), zap.Error(err))
+		if !sleep(ctx, delay) {
+			return ctx.Err()
+		}
+	}
+}
+
+// commit подтверждает сообщение. Ошибка только логируется: сообщение придет повторно
+// и будет пропущено дедупликацией
+func (c *Consumer) commit(ctx context.Context, log *zap.Logger, msg Message) {
+	if err := c.source.Commit(ctx, msg); err != nil {
+		log.Error("failed to commit message", zap.Error(err))
+	}
+}
+
+func (c *Consumer) backoff(attempt int) time.Duration {
+	delay := c.cfg.Retry.BaseDelay << min(attempt-1, 30)
+	if delay <= 0 || delay > c.cfg.Retry.MaxDelay {
+		return c.cfg.Retry.MaxDelay
+	}
+	return delay
+}
+
+// sleep ждет d и возвращает false, если ctx отменили раньше
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
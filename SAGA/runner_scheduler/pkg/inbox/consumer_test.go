@@ -0,0 +1,354 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeStorage struct {
+	processed map[string]bool
+	poison    map[string]error
+}
+
+func newFakeStorage() *fakeStorage {
+	return &fakeStorage{processed: map[string]bool{}, poison: map[string]error{}}
+}
+
+func (s *fakeStorage) markProcessed(ctx context.Context, msg received) (bool, error) {
+	if s.processed[msg.id] {
+		return false, nil
+	}
+	s.processed[msg.id] = true
+	return true, nil
+}
+
+func (s *fakeStorage) markProcessedBatch(ctx context.Context, msgs []received) (map[string]bool, error) {
+	inserted := make(map[string]bool)
+	for _, msg := range msgs {
+		if ok, _ := s.markProcessed(ctx, msg); ok {
+			inserted[msg.id] = true
+		}
+	}
+	return inserted, nil
+}
+
+func (s *fakeStorage) markPoison(ctx context.Context, msg received, cause error) error {
+	s.poison[msg.id] = cause
+	return nil
+}
+
+// fakeTransactor откатывает записи inbox, если функция вернула ошибку
+type fakeTransactor struct {
+	store *fakeStorage
+}
+
+func (t fakeTransactor) WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error {
+	before := make(map[string]bool, len(t.store.processed))
+	for id := range t.store.processed {
+		before[id] = true
+	}
+	err := tFunc(ctx)
+	if err != nil {
+		t.store.processed = before
+	}
+	return err
+}
+
+type fakeSource struct {
+	committed []Message
+}
+
+func (s *fakeSource) Fetch(ctx context.Context) (Message, error) {
+	<-ctx.Done()
+	return Message{}, ctx.Err()
+}
+
+func (s *fakeSource) Commit(ctx context.Context, msg Message) error {
+	s.committed = append(s.committed, msg)
+	return nil
+}
+
+type payload struct {
+	Name string `json:"name"`
+}
+
+func newTestConsumer(store *fakeStorage, source *fakeSource) *Consumer {
+	return &Consumer{
+		store:      store,
+		transactor: fakeTransactor{store: store},
+		source:     source,
+		handlers:   make(map[string]Handler),
+		batches:    make(map[string]BatchHandler),
+		cfg: Config{
+			IDHeader:         "outbox_uuid",
+			EventTypeHeader:  "event_type",
+			DefaultEventType: "created",
+			Retry:            RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+		},
+	}
+}
+
+func message(id, eventType, value string) Message {
+	headers := map[string][]byte{"outbox_uuid": []byte(id)}
+	if eventType != "" {
+		headers["event_type"] = []byte(eventType)
+	}
+	return Message{Topic: "events", Value: []byte(value), Headers: headers}
+}
+
+func TestProcessRoutesByEventTypeAndSkipsDuplicates(t *testing.T) {
+	store := newFakeStorage()
+	source := &fakeSource{}
+	c := newTestConsumer(store, source)
+
+	var created, deleted []string
+	c.Handle("created", JSON(func(ctx context.Context, msg Message, p payload) error {
+		created = append(created, p.Name)
+		return nil
+	}))
+	c.Handle("deleted", JSON(func(ctx context.Context, msg Message, p payload) error {
+		deleted = append(deleted, p.Name)
+		return nil
+	}))
+
+	ctx := context.Background()
+	for _, msg := range []Message{
+		message("1", "", `{"name":"a"}`),
+		message("2", "deleted", `{"name":"b"}`),
+		message("1", "", `{"name":"a"}`),
+	} {
+		if err := c.Process(ctx, msg); err != nil {
+			t.Fatalf("Process returned error: %v", err)
+		}
+	}
+
+	if len(created) != 1 || created[0] != "a" {
+		t.Fatalf("expected one created event without header, got %v", created)
+	}
+	if len(deleted) != 1 || deleted[0] != "b" {
+		t.Fatalf("expected one deleted event, got %v", deleted)
+	}
+	if len(source.committed) != 3 {
+		t.Fatalf("expected all messages committed, got %d", len(source.committed))
+	}
+}
+
+func TestProcessRetriesHandlerErrors(t *testing.T) {
+	store := newFakeStorage()
+	c := newTestConsumer(store, &fakeSource{})
+
+	calls := 0
+	c.Handle("created", func(ctx context.Context, msg Message) error {
+		calls++
+		if calls < 3 {
+			return errors.New("database unavailable")
+		}
+		return nil
+	})
+
+	if err := c.Process(context.Background(), message("1", "created", `{}`)); err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if calls != 3 || !store.processed["1"] || len(store.poison) != 0 {
+		t.Fatalf("expected success on third attempt, got calls=%d processed=%v poison=%v", calls, store.processed, store.poison)
+	}
+}
+
+func TestProcessStoresPoisonMessages(t *testing.T) {
+	store := newFakeStorage()
+	source := &fakeSource{}
+	c := newTestConsumer(store, source)
+
+	calls := 0
+	c.Handle("created", JSON(func(ctx context.Context, msg Message, p payload) error {
+		calls++
+		return nil
+	}))
+	c.Handle("failing", func(ctx context.Context, msg Message) error {
+		return errors.New("always fails")
+	})
+
+	ctx := context.Background()
+	for _, msg := range []Message{
+		message("bad-json", "created", `not json`),
+		message("unknown", "renamed", `{}`),
+		message("exhausted", "failing", `{}`),
+	} {
+		if err := c.Process(ctx, msg); err != nil {
+			t.Fatalf("Process returned error: %v", err)
+		}
+	}
+
+	if calls != 0 {
+		t.Fatalf("handler must not be called for undecodable payload")
+	}
+	for _, id := range []string{"bad-json", "unknown", "exhausted"} {
+		if store.poison[id] == nil {
+			t.Fatalf("expected %s stored as poison, got %v", id, store.poison)
+		}
+		if store.processed[id] {
+			t.Fatalf("poison message %s must not be marked processed", id)
+		}
+	}
+	if !errors.Is(store.poison["unknown"], errUnknownEventType) {
+		t.Fatalf("expected unknown event type error, got %v", store.poison["unknown"])
+	}
+	if len(source.committed) != 3 {
+		t.Fatalf("expected poison messages committed, got %d", len(source.committed))
+	}
+}
+
+func TestProcessDoesNotCommitOnCancel(t *testing.T) {
+	store := newFakeStorage()
+	source := &fakeSource{}
+	c := newTestConsumer(store, source)
+	c.cfg.Retry = RetryPolicy{MaxAttempts: 10, BaseDelay: time.Hour, MaxDelay: time.Hour}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.Handle("created", func(ctx context.Context, msg Message) error {
+		cancel()
+		return errors.New("interrupted")
+	})
+
+	if err := c.Process(ctx, message("1", "created", `{}`)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled, got %v", err)
+	}
+	if len(source.committed) != 0 {
+		t.Fatalf("message must not be committed after cancel")
+	}
+}
+
+type fakePublisher struct {
+	published []Message
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, msg Message) error {
+	p.published = append(p.published, msg)
+	return nil
+}
+
+func newTopicConsumer(store *fakeStorage, source *fakeSource, publisher *fakePublisher) *Consumer {
+	c := newTestConsumer(store, source)
+	c.publisher = publisher
+	c.cfg.RetryTopics = []RetryTopic{
+		{Topic: "events.retry.1", Delay: time.Second},
+		{Topic: "events.retry.2", Delay: time.Minute},
+	}
+	c.cfg.DeadLetterTopic = "events.dlq"
+	return c
+}
+
+func TestProcessSendsFailedMessageToRetryTopics(t *testing.T) {
+	store := newFakeStorage()
+	source := &fakeSource{}
+	publisher := &fakePublisher{}
+	c := newTopicConsumer(store, source, publisher)
+
+	calls := 0
+	c.Handle("created", func(ctx context.Context, msg Message) error {
+		calls++
+		return errors.New("database unavailable")
+	})
+
+	msg := message("1", "created", `{}`)
+	msg.Key = "camera-7"
+	if err := c.Process(context.Background(), msg); err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected a single attempt without in-place retries, got %d", calls)
+	}
+	if len(publisher.published) != 1 {
+		t.Fatalf("expected message published to retry topic, got %d", len(publisher.published))
+	}
+
+	retry := publisher.published[0]
+	if retry.Topic != "events.retry.1" || retry.Key != "camera-7" || string(retry.Value) != `{}` {
+		t.Fatalf("unexpected retry message: %+v", retry)
+	}
+	if string(retry.Headers[HeaderAttempts]) != "1" || string(retry.Headers[HeaderOriginalTopic]) != "events" {
+		t.Fatalf("unexpected retry headers: %v", retry.Headers)
+	}
+	if string(retry.Headers[HeaderError]) != "database unavailable" || len(retry.Headers[HeaderRetryAt]) == 0 {
+		t.Fatalf("expected error and retry_at headers, got %v", retry.Headers)
+	}
+	if len(source.committed) != 1 || len(store.poison) != 0 {
+		t.Fatalf("expected message committed without poison record")
+	}
+}
+
+func TestProcessSendsExhaustedMessageToDeadLetterTopic(t *testing.T) {
+	publisher := &fakePublisher{}
+	c := newTopicConsumer(newFakeStorage(), &fakeSource{}, publisher)
+	c.Handle("created", func(ctx context.Context, msg Message) error {
+		return errors.New("still failing")
+	})
+
+	// Последний топик повторов: сообщение уже дважды не удалось обработать
+	msg := message("1", "created", `{}`)
+	msg.Topic = "events.retry.2"
+	msg.Headers[HeaderAttempts] = []byte("2")
+	msg.Headers[HeaderOriginalTopic] = []byte("events")
+	msg.Headers[HeaderRetryAt] = []byte(time.Now().Add(-time.Second).UTC().Format(time.RFC3339Nano))
+
+	if err := c.Process(context.Background(), msg); err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if len(publisher.published) != 1 {
+		t.Fatalf("expected message published to dead letter topic, got %d", len(publisher.published))
+	}
+
+	dead := publisher.published[0]
+	if dead.Topic != "events.dlq" || string(dead.Headers[HeaderAttempts]) != "3" {
+		t.Fatalf("unexpected dead letter message: topic=%s headers=%v", dead.Topic, dead.Headers)
+	}
+	if string(dead.Headers[HeaderOriginalTopic]) != "events" || len(dead.Headers[HeaderFailedAt]) == 0 {
+		t.Fatalf("expected original topic and failed_at headers, got %v", dead.Headers)
+	}
+	if _, ok := dead.Headers[HeaderRetryAt]; ok {
+		t.Fatalf("dead letter message must not carry retry_at header")
+	}
+}
+
+func TestProcessSendsPermanentErrorsToDeadLetterTopic(t *testing.T) {
+	publisher := &fakePublisher{}
+	c := newTopicConsumer(newFakeStorage(), &fakeSource{}, publisher)
+	c.Handle("created", JSON(func(ctx context.Context, msg Message, p payload) error {
+		return nil
+	}))
+
+	ctx := context.Background()
+	for _, msg := range []Message{
+		message("bad-json", "created", `not json`),
+		message("unknown", "renamed", `{}`),
+		{Topic: "events", Value: []byte(`{}`), Headers: map[string][]byte{}},
+	} {
+		if err := c.Process(ctx, msg); err != nil {
+			t.Fatalf("Process returned error: %v", err)
+		}
+	}
+
+	if len(publisher.published) != 3 {
+		t.Fatalf("expected 3 dead letter messages, got %d", len(publisher.published))
+	}
+	for _, msg := range publisher.published {
+		if msg.Topic != "events.dlq" || string(msg.Headers[HeaderAttempts]) != "1" {
+			t.Fatalf("expected dead letter after first attempt, got topic=%s headers=%v", msg.Topic, msg.Headers)
+		}
+	}
+}
+
+func TestStripHeaders(t *testing.T) {
+	headers := StripHeaders(map[string][]byte{
+		"outbox_uuid":       []byte("1"),
+		HeaderAttempts:      []byte("3"),
+		HeaderError:         []byte("boom"),
+		HeaderOriginalTopic: []byte("events"),
+		HeaderFailedAt:      []byte("2025-12-01T10:00:00Z"),
+	})
+	if len(headers) != 1 || string(headers["outbox_uuid"]) != "1" {
+		t.Fatalf("expected only message headers left, got %v", headers)
+	}
+}
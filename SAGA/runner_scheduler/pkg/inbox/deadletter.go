@@ -0,0 +1,152 @@
+package inbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Заголовки, которые consumer добавляет к сообщениям в топиках повторов и DLQ
+const (
+	// HeaderAttempts - количество уже выполненных попыток обработки
+	HeaderAttempts = "inbox_attempts"
+	// HeaderError - ошибка последней попытки
+	HeaderError = "inbox_error"
+	// HeaderOriginalTopic - топик, из которого сообщение было прочитано впервые
+	HeaderOriginalTopic = "inbox_original_topic"
+	// HeaderRetryAt - момент (RFC 3339), раньше которого сообщение из топика повторов не обрабатывается
+	HeaderRetryAt = "inbox_retry_at"
+	// HeaderFailedAt - момент (RFC 3339), когда сообщение попало в DLQ
+	HeaderFailedAt = "inbox_failed_at"
+)
+
+// Publisher публикует сообщения в топики повторов и DLQ
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// RetryTopic - топик отложенных повторов. Сообщение из него обрабатывается не раньше
+// чем через Delay после публикации
+type RetryTopic struct {
+	Topic string
+	Delay time.Duration
+}
+
+// forward отправляет сообщение, обработка которого завершилась ошибкой, в следующий топик
+// повторов или, если повторы исчерпаны или ошибка постоянная, в DLQ
+func (c *Consumer) forward(ctx context.Context, log *zap.Logger, r received, cause error) error {
+	if IsPermanent(cause) || int(r.attempts) > len(c.cfg.RetryTopics) {
+		return c.deadLetter(ctx, log, r, cause)
+	}
+
+	retry := c.cfg.RetryTopics[r.attempts-1]
+	msg := failedMessage(r, cause, retry.Topic)
+	msg.Headers[HeaderRetryAt] = []byte(time.Now().Add(retry.Delay).UTC().Format(time.RFC3339Nano))
+
+	log.Warn("failed to process message, sending to retry topic",
+		zap.Int32("attempts", r.attempts),
+		zap.String("retry_topic", retry.Topic),
+		zap.Duration("retry_in", retry.Delay),
+		zap.Error(cause),
+	)
+	return c.publish(ctx, log, msg)
+}
+
+// deadLetter отправляет сообщение в DLQ с исходным payload и заголовками ошибки
+func (c *Consumer) deadLetter(ctx context.Context, log *zap.Logger, r received, cause error) error {
+	msg := failedMessage(r, cause, c.cfg.DeadLetterTopic)
+	delete(msg.Headers, HeaderRetryAt)
+	msg.Headers[HeaderFailedAt] = []byte(time.Now().UTC().Format(time.RFC3339Nano))
+
+	log.Error("message sent to dead letter topic",
+		zap.Int32("attempts", r.attempts),
+		zap.String("dead_letter_topic", c.cfg.DeadLetterTopic),
+		zap.Error(cause),
+	)
+	return c.publish(ctx, log, msg)
+}
+
+// publish повторяет публикацию до успеха или отмены ctx: подтвердить исходное сообщение,
+// не опубликовав его копию, значит потерять его. С TransactionalSource ошибка возвращается
+// сразу: публикация в транзакции после ошибки не повторяется, транзакция отменяется целиком
+func (c *Consumer) publish(ctx context.Context, log *zap.Logger, msg Message) error {
+	_, transactional := c.source.(TransactionalSource)
+	for attempt := 1; ; attempt++ {
+		err := c.publisher.Publish(ctx, msg)
+		if err == nil {
+			return nil
+		}
+		if transactional {
+			return fmt.Errorf("publish to %s: %w", msg.Topic, err)
+		}
+
+		delay := c.backoff(attempt)
+		log.Error("failed to publish message, retrying",
+			zap.String("target_topic", msg.Topic),
+			zap.Duration("retry_in", delay),
+			zap.Error(err),
+		)
+		if !sleep(ctx, delay) {
+			return ctx.Err()
+		}
+	}
+}
+
+// waitRetryAt ждет момента повтора из заголовка HeaderRetryAt. Возвращает false, если ctx
+// отменили раньше
+func waitRetryAt(ctx context.Context, msg Message) bool {
+	retryAt, err := time.Parse(time.RFC3339Nano, string(msg.Headers[HeaderRetryAt]))
+	if err != nil {
+		return true
+	}
+	if delay := time.Until(retryAt); delay > 0 {
+		return sleep(ctx, delay)
+	}
+	return true
+}
+
+// failedMessage копирует сообщение для публикации в topic с заголовками попытки и ошибки
+func failedMessage(r received, cause error, topic string) Message {
+	headers := make(map[string][]byte, len(r.Headers)+4)
+	for name, value := range r.Headers {
+		headers[name] = value
+	}
+	if _, ok := headers[HeaderOriginalTopic]; !ok {
+		headers[HeaderOriginalTopic] = []byte(r.Topic)
+	}
+	headers[HeaderAttempts] = []byte(strconv.Itoa(int(r.attempts)))
+	headers[HeaderError] = []byte(cause.Error())
+
+	return Message{
+		Topic:   topic,
+		Key:     r.Key,
+		Value:   r.Value,
+		Headers: headers,
+	}
+}
+
+// previousAttempts возвращает количество попыток из заголовка HeaderAttempts
+func previousAttempts(msg Message) int32 {
+	attempts, err := strconv.ParseInt(string(msg.Headers[HeaderAttempts]), 10, 32)
+	if err != nil || attempts < 0 {
+		return 0
+	}
+	return int32(attempts)
+}
+
+// StripHeaders возвращает копию заголовков без служебных заголовков повторов и DLQ. Нужна
+// при возврате сообщения из DLQ в исходный топик: счетчик попыток начинается заново
+func StripHeaders(headers map[string][]byte) map[string][]byte {
+	stripped := make(map[string][]byte, len(headers))
+	for name, value := range headers {
+		switch name {
+		case HeaderAttempts, HeaderError, HeaderOriginalTopic, HeaderRetryAt, HeaderFailedAt:
+			continue
+		}
+		stripped[name] = value
+	}
+	return stripped
+}
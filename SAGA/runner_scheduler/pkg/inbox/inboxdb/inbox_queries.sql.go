@@ -0,0 +1,165 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: inbox_queries.sql
+
+package inboxdb
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const markInboxMessagePoison = `-- name: MarkInboxMessagePoison :exec
+INSERT INTO inbox (message_id, event_type, topic, status, attempts, last_error, headers, payload)
+VALUES ($1, $2, $3, 'poison', $4, $5, $6, $7)
+ON CONFLICT (message_id) DO NOTHING
+`
+
+type MarkInboxMessagePoisonParams struct {
+	MessageID string  `json:"message_id"`
+	EventType string  `json:"event_type"`
+	Topic     string  `json:"topic"`
+	Attempts  int32   `json:"attempts"`
+	LastError *string `json:"last_error"`
+	Headers   []byte  `json:"headers"`
+	Payload   []byte  `json:"payload"`
+}
+
+func (q *Queries) MarkInboxMessagePoison(ctx context.Context, arg MarkInboxMessagePoisonParams) error {
+	_, err := q.db.Exec(ctx, markInboxMessagePoison,
+		arg.MessageID,
+		arg.EventType,
+		arg.Topic,
+		arg.Attempts,
+		arg.LastError,
+		arg.Headers,
+		arg.Payload,
+	)
+	return err
+}
+
+const markInboxMessageProcessed = `-- name: MarkInboxMessageProcessed :execrows
+INSERT INTO inbox (message_id, event_type, topic, status, attempts)
+VALUES ($1, $2, $3, 'processed', $4)
+ON CONFLICT (message_id) DO NOTHING
+`
+
+type MarkInboxMessageProcessedParams struct {
+	MessageID string `json:"message_id"`
+	EventType string `json:"event_type"`
+	Topic     string `json:"topic"`
+	Attempts  int32  `json:"attempts"`
+}
+
+// Повторная доставка уже записанного сообщения не вставляет строку
+func (q *Queries) MarkInboxMessageProcessed(ctx context.Context, arg MarkInboxMessageProcessedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markInboxMessageProcessed,
+		arg.MessageID,
+		arg.EventType,
+		arg.Topic,
+		arg.Attempts,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const markInboxMessagesProcessed = `-- name: MarkInboxMessagesProcessed :many
+INSERT INTO inbox (message_id, event_type, topic, status, attempts)
+SELECT
+    unnest($1::text[]),
+    unnest($2::text[]),
+    unnest($3::text[]),
+    'processed',
+    unnest($4::integer[])
+ON CONFLICT (message_id) DO NOTHING
+RETURNING message_id
+`
+
+type MarkInboxMessagesProcessedParams struct {
+	MessageIds []string `json:"message_ids"`
+	EventTypes []string `json:"event_types"`
+	Topics     []string `json:"topics"`
+	Attempts   []int32  `json:"attempts"`
+}
+
+// Записывает пачку сообщений одним запросом: массивы параметров одной длины, i-е элементы -
+// одно сообщение. Возвращает идентификаторы сообщений, которых еще не было в inbox
+func (q *Queries) MarkInboxMessagesProcessed(ctx context.Context, arg MarkInboxMessagesProcessedParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, markInboxMessagesProcessed,
+		arg.MessageIds,
+		arg.EventTypes,
+		arg.Topics,
+		arg.Attempts,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var message_id string
+		if err := rows.Scan(&message_id); err != nil {
+			return nil, err
+		}
+		items = append(items, message_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeProcessedInboxMessages = `-- name: PurgeProcessedInboxMessages :many
+DELETE FROM inbox
+WHERE message_id IN (
+    SELECT message_id FROM inbox
+    WHERE status = 'processed'
+      AND created_at < NOW() - $1::integer * INTERVAL '1 second'
+    ORDER BY created_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING message_id, event_type, topic, created_at
+`
+
+type PurgeProcessedInboxMessagesParams struct {
+	OlderThanSeconds int32 `json:"older_than_seconds"`
+	BatchLimit       int32 `json:"batch_limit"`
+}
+
+type PurgeProcessedInboxMessagesRow struct {
+	MessageID string           `json:"message_id"`
+	EventType string           `json:"event_type"`
+	Topic     string           `json:"topic"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+// Poison записи не удаляются
+func (q *Queries) PurgeProcessedInboxMessages(ctx context.Context, arg PurgeProcessedInboxMessagesParams) ([]PurgeProcessedInboxMessagesRow, error) {
+	rows, err := q.db.Query(ctx, purgeProcessedInboxMessages, arg.OlderThanSeconds, arg.BatchLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []PurgeProcessedInboxMessagesRow{}
+	for rows.Next() {
+		var i PurgeProcessedInboxMessagesRow
+		if err := rows.Scan(
+			&i.MessageID,
+			&i.EventType,
+			&i.Topic,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
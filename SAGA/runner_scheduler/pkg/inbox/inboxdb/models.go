@@ -0,0 +1,147 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package inboxdb
+
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Placement of camera workers on runner instances
+type CameraAssignment struct {
+	// ID of the camera whose worker is placed on the runner
+	CameraID int32 `json:"camera_id"`
+	// UUID of the scenario the worker was started for
+	ScenarioUuid pgtype.UUID `json:"scenario_uuid"`
+	// Runner instance hosting the camera worker (may point to a deregistered node until the camera is re-placed)
+	NodeID string `json:"node_id"`
+	// RTSP stream URL of the camera, used to restart the worker on another runner
+	Url string `json:"url"`
+	// Monotonic token incremented on every placement of the camera; workers started with an older token are revoked
+	FencingToken int64 `json:"fencing_token"`
+	// Timestamp when the camera was placed
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	// Timestamp when the assignment was last updated
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+// Generic inbox for exactly-once processing of consumed Kafka messages
+type Inbox struct {
+	// Identifier of the message from the outbox_uuid header (serves as primary key for idempotency)
+	MessageID string `json:"message_id"`
+	// Type of the event
+	EventType string `json:"event_type"`
+	// Kafka topic the message was consumed from
+	Topic string `json:"topic"`
+	// Processing status: processed, poison
+	Status string `json:"status"`
+	// Number of processing attempts
+	Attempts int32 `json:"attempts"`
+	// Error that made the message poison
+	LastError *string `json:"last_error"`
+	// Kafka message headers of a poison message
+	Headers []byte `json:"headers"`
+	// Payload of a poison message
+	Payload []byte `json:"payload"`
+	// Timestamp when the message was processed
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+// Inbox pattern table for idempotent message processing in SAGA (start scenario events)
+type InboxStartScenario struct {
+	// Unique identifier from the outbox message (serves as primary key for idempotency)
+	OutboxUuid pgtype.UUID `json:"outbox_uuid"`
+	// ID of the camera associated with the scenario
+	CameraID int32 `json:"camera_id"`
+	// UUID of the scenario being started
+	ScenarioUuid pgtype.UUID `json:"scenario_uuid"`
+	// URL associated with the scenario
+	Url string `json:"url"`
+	// Processing status: received, in_process, processed, failed
+	Status string `json:"status"`
+	// Timestamp when the message was first received
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	// Timestamp when the message status was last updated
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+	// Number of dispatch attempts made by the scheduler
+	Attempts int32 `json:"attempts"`
+	// Timestamp before which the message must not be dispatched again (retry backoff)
+	NextAttemptAt pgtype.Timestamp `json:"next_attempt_at"`
+	// Error of the last failed dispatch attempt
+	LastError *string `json:"last_error"`
+}
+
+// Inbox pattern table for idempotent message processing in SAGA (stop scenario events)
+type InboxStopScenario struct {
+	// Unique identifier from the outbox message (serves as primary key for idempotency)
+	OutboxUuid pgtype.UUID `json:"outbox_uuid"`
+	// ID of the camera associated with the scenario
+	CameraID int32 `json:"camera_id"`
+	// UUID of the scenario being stopped
+	ScenarioUuid pgtype.UUID `json:"scenario_uuid"`
+	// Processing status: received, in_process, processed, failed
+	Status string `json:"status"`
+	// Timestamp when the message was first received
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	// Timestamp when the message status was last updated
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+	// Number of dispatch attempts made by the scheduler
+	Attempts int32 `json:"attempts"`
+	// Timestamp before which the message must not be dispatched again (retry backoff)
+	NextAttemptAt pgtype.Timestamp `json:"next_attempt_at"`
+	// Error of the last failed dispatch attempt
+	LastError *string `json:"last_error"`
+}
+
+// Generic outbox for reliable publishing of aggregate events to Kafka
+type Outbox struct {
+	// Unique identifier of the event, sent in the outbox_uuid header for deduplication
+	ID pgtype.UUID `json:"id"`
+	// Type of the aggregate the event belongs to (scenario, camera, ...)
+	AggregateType string `json:"aggregate_type"`
+	// Identifier of the aggregate; used as the Kafka message key, events of one aggregate are published in order
+	AggregateID string `json:"aggregate_id"`
+	// Type of the event
+	EventType string `json:"event_type"`
+	// Kafka topic the event is published to
+	Topic string `json:"topic"`
+	// Additional Kafka message headers
+	Headers []byte `json:"headers"`
+	// Message payload
+	Payload []byte `json:"payload"`
+	// State of the event (pending, sent, failed)
+	State string `json:"state"`
+	// Number of publish attempts
+	Attempts int32 `json:"attempts"`
+	// Error of the last failed publish attempt
+	LastError *string `json:"last_error"`
+	// Timestamp before which the event must not be claimed (lease of the relay or retry backoff)
+	NextAttemptAt pgtype.Timestamp `json:"next_attempt_at"`
+	// Insertion order of events, defines publish order within an aggregate
+	Seq int64 `json:"seq"`
+	// Timestamp when the event was created
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	// Timestamp when the event was last updated
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+// Registry of runner instances that host camera workers
+type RunnerNode struct {
+	// Unique identifier of the runner instance
+	NodeID string `json:"node_id"`
+	// gRPC address (host:port) of the runner reachable from the scheduler
+	Address string `json:"address"`
+	// Maximum number of workers the runner can host
+	Capacity int32 `json:"capacity"`
+	// Number of workers reported by the runner in the last heartbeat
+	WorkerCount int32 `json:"worker_count"`
+	// Timestamp of the last heartbeat received from the runner
+	LastHeartbeatAt pgtype.Timestamp `json:"last_heartbeat_at"`
+	// Liveness of the runner: alive, or dead after missing heartbeats (cameras are moved to other runners)
+	Status string `json:"status"`
+	// Timestamp when the runner was first registered
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	// Timestamp when the runner record was last updated
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
@@ -0,0 +1,22 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package inboxdb
+
+import (
+	"context"
+)
+
+type Querier interface {
+	MarkInboxMessagePoison(ctx context.Context, arg MarkInboxMessagePoisonParams) error
+	// Повторная доставка уже записанного сообщения не вставляет строку
+	MarkInboxMessageProcessed(ctx context.Context, arg MarkInboxMessageProcessedParams) (int64, error)
+	// Записывает пачку сообщений одним запросом: массивы параметров одной длины, i-е элементы -
+	// одно сообщение. Возвращает идентификаторы сообщений, которых еще не было в inbox
+	MarkInboxMessagesProcessed(ctx context.Context, arg MarkInboxMessagesProcessedParams) ([]string, error)
+	// Poison записи не удаляются
+	PurgeProcessedInboxMessages(ctx context.Context, arg PurgeProcessedInboxMessagesParams) ([]PurgeProcessedInboxMessagesRow, error)
+}
+
+var _ Querier = (*Queries)(nil)
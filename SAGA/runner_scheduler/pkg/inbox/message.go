@@ -0,0 +1,64 @@
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Message - сообщение из Kafka
+type Message struct {
+	Topic   string
+	Key     string
+	Value   []byte
+	Headers map[string][]byte
+	// Partition - партиция источника. Вместе с Key определяет порядок обработки в Run
+	Partition int
+	// Raw - исходное сообщение источника, нужно Source для подтверждения
+	Raw any
+}
+
+// Handler обрабатывает сообщение своего типа. Вызывается в транзакции вместе с записью
+// в inbox, поэтому все изменения, сделанные через ctx, коммитятся атомарно с дедупликацией
+type Handler func(ctx context.Context, msg Message) error
+
+// BatchHandler обрабатывает новые сообщения одного типа из пачки в той же транзакции, что и
+// запись пачки в inbox. Ошибка откатывает всю пачку, и ее сообщения обрабатываются по одному
+// обработчиком Handler
+type BatchHandler func(ctx context.Context, msgs []Message) error
+
+// permanentError - ошибка, повтор которой не поможет: сообщение сразу считается poison
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent помечает ошибку обработчика как неисправимую: сообщение не повторяется
+// и сразу сохраняется как poison
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent сообщает, помечена ли ошибка через Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// JSON оборачивает типизированный обработчик: payload сообщения разбирается в T, ошибка
+// разбора считается неисправимой
+func JSON[T any](handler func(ctx context.Context, msg Message, payload T) error) Handler {
+	return func(ctx context.Context, msg Message) error {
+		var payload T
+		if err := json.Unmarshal(msg.Value, &payload); err != nil {
+			return Permanent(fmt.Errorf("unmarshal payload: %w", err))
+		}
+		return handler(ctx, msg, payload)
+	}
+}
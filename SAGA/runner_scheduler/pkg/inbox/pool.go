@@ -0,0 +1,181 @@
+package inbox
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"runner_scheduler/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// fetchRetryDelay - пауза после ошибки чтения из источника
+	fetchRetryDelay     = time.Second
+	defaultDrainTimeout = 5 * time.Second
+)
+
+// Run обрабатывает сообщения до отмены ctx.
+//
+// Сообщения распределяются между Config.Workers обработчиками по партиции и ключу, поэтому
+// порядок сообщений одного ключа в партиции сохраняется. Если Config.BatchSize больше 1 и
+// Source реализует BatchSource, сообщения читаются пачками, и часть пачки, попавшая к
+// обработчику, обрабатывается через ProcessBatch. У каждого обработчика своя очередь на
+// Config.QueueSize сообщений или частей пачек: пока очередь нужного обработчика заполнена,
+// чтение из Source приостанавливается. Подтверждения приходят в Source не по порядку, Source
+// сам не должен сдвигать смещение партиции дальше неподтвержденного сообщения.
+//
+// После отмены ctx чтение прекращается, сообщения из очередей не начинаются и придут повторно,
+// а начатые обработки завершаются, но не дольше Config.DrainTimeout.
+//
+// TransactionalSource обрабатывается последовательно, пачками в транзакциях источника
+func (c *Consumer) Run(ctx context.Context) error {
+	if source, ok := c.source.(TransactionalSource); ok {
+		return c.runTransactional(ctx, source)
+	}
+
+	log := logger.FromContext(ctx)
+
+	// Начатая обработка не прерывается отменой ctx, иначе ее транзакция откатится на середине
+	procCtx, cancelProc := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelProc()
+
+	queues := make([]chan []Message, max(c.cfg.Workers, 1))
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan []Message, max(c.cfg.QueueSize, 1))
+
+		wg.Add(1)
+		go func(queue <-chan []Message) {
+			defer wg.Done()
+			c.work(ctx, procCtx, queue)
+		}(queues[i])
+	}
+
+	c.dispatch(ctx, queues)
+
+	for _, queue := range queues {
+		close(queue)
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(drained)
+	}()
+
+	drainTimeout := c.cfg.DrainTimeout
+	if drainTimeout <= 0 {
+		drainTimeout = defaultDrainTimeout
+	}
+
+	select {
+	case <-drained:
+	case <-time.After(drainTimeout):
+		log.Warn("in-flight messages were not processed before drain timeout, interrupting",
+			zap.Duration("drain_timeout", drainTimeout),
+		)
+		cancelProc()
+		<-drained
+	}
+
+	return nil
+}
+
+// dispatch читает сообщения из Source и раскладывает их по очередям обработчиков до отмены ctx
+func (c *Consumer) dispatch(ctx context.Context, queues []chan []Message) {
+	log := logger.FromContext(ctx)
+
+	for {
+		msgs, err := c.fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Error("failed to fetch message", zap.Error(err))
+			if !sleep(ctx, fetchRetryDelay) {
+				return
+			}
+			continue
+		}
+
+		// Пачка делится между очередями с сохранением порядка, поэтому сообщения одного ключа
+		// попадают в одну часть в исходном порядке
+		parts := make(map[int][]Message)
+		var order []int
+		for _, msg := range msgs {
+			i := queueIndex(msg, len(queues))
+			if _, ok := parts[i]; !ok {
+				order = append(order, i)
+			}
+			parts[i] = append(parts[i], msg)
+		}
+
+		for _, i := range order {
+			select {
+			case queues[i] <- parts[i]:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// fetch читает пачку, если она включена и Source ее поддерживает, иначе одно сообщение
+func (c *Consumer) fetch(ctx context.Context) ([]Message, error) {
+	if source, ok := c.source.(BatchSource); ok && c.cfg.BatchSize > 1 {
+		return source.FetchBatch(ctx, c.cfg.BatchSize, c.cfg.BatchWait)
+	}
+
+	msg, err := c.source.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []Message{msg}, nil
+}
+
+// work обрабатывает сообщения очереди по порядку. Ожидание повтора прерывается отменой ctx,
+// а сама обработка выполняется в procCtx
+func (c *Consumer) work(ctx, procCtx context.Context, queue <-chan []Message) {
+	for msgs := range queue {
+		if ctx.Err() != nil || !c.waitRetries(ctx, msgs) {
+			continue
+		}
+
+		// Ошибка означает, что обработку прервали по DrainTimeout: сообщения не подтверждены
+		// и придут повторно
+		if len(msgs) == 1 {
+			_ = c.Process(procCtx, msgs[0])
+		} else {
+			_ = c.ProcessBatch(procCtx, msgs)
+		}
+	}
+}
+
+// waitRetries ждет момента повтора каждого сообщения. Возвращает false, если ctx отменили раньше
+func (c *Consumer) waitRetries(ctx context.Context, msgs []Message) bool {
+	if c.publisher == nil {
+		return true
+	}
+	for _, msg := range msgs {
+		if !waitRetryAt(ctx, msg) {
+			return false
+		}
+	}
+	return true
+}
+
+// queueIndex выбирает очередь по топику, партиции и ключу. Сообщения без ключа распределяются
+// по партиции целиком
+func queueIndex(msg Message, queues int) int {
+	h := fnv.New32a()
+	h.Write([]byte(msg.Topic))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(msg.Partition)))
+	h.Write([]byte{0})
+	h.Write([]byte(msg.Key))
+	return int(h.Sum32() % uint32(queues))
+}
@@ -0,0 +1,196 @@
+package inbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedTransactor выполняет транзакции по одной: fakeStorage не рассчитан на параллельный доступ
+type lockedTransactor struct {
+	mu *sync.Mutex
+	fakeTransactor
+}
+
+func (t lockedTransactor) WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.fakeTransactor.WithinTransaction(ctx, tFunc)
+}
+
+type channelSource struct {
+	messages chan Message
+
+	mu        sync.Mutex
+	committed []Message
+}
+
+func (s *channelSource) Fetch(ctx context.Context) (Message, error) {
+	select {
+	case msg := <-s.messages:
+		return msg, nil
+	case <-ctx.Done():
+		return Message{}, ctx.Err()
+	}
+}
+
+func (s *channelSource) Commit(ctx context.Context, msg Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.committed = append(s.committed, msg)
+	return nil
+}
+
+func (s *channelSource) committedCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.committed)
+}
+
+func newPoolConsumer(source *channelSource, workers int) *Consumer {
+	store := newFakeStorage()
+	c := newTestConsumer(store, nil)
+	c.source = source
+	c.transactor = lockedTransactor{mu: &sync.Mutex{}, fakeTransactor: fakeTransactor{store: store}}
+	c.cfg.Workers = workers
+	c.cfg.QueueSize = 2
+	c.cfg.DrainTimeout = time.Second
+	return c
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition was not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRunPreservesOrderPerKey(t *testing.T) {
+	const keys, perKey = 4, 25
+
+	source := &channelSource{messages: make(chan Message)}
+	c := newPoolConsumer(source, 3)
+
+	var mu sync.Mutex
+	got := make(map[string][]string)
+	c.Handle("created", JSON(func(ctx context.Context, msg Message, p payload) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got[msg.Key] = append(got[msg.Key], p.Name)
+		return nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Run(ctx)
+	}()
+
+	for i := 0; i < perKey; i++ {
+		for k := 0; k < keys; k++ {
+			msg := message(fmt.Sprintf("%d-%d", k, i), "", fmt.Sprintf(`{"name":"%d"}`, i))
+			msg.Key = fmt.Sprintf("key-%d", k)
+			msg.Partition = k % 2
+			source.messages <- msg
+		}
+	}
+
+	waitFor(t, func() bool { return source.committedCount() == keys*perKey })
+	cancel()
+	<-done
+
+	for k := 0; k < keys; k++ {
+		names := got[fmt.Sprintf("key-%d", k)]
+		if len(names) != perKey {
+			t.Fatalf("key-%d: expected %d messages, got %d", k, perKey, len(names))
+		}
+		for i, name := range names {
+			if name != fmt.Sprint(i) {
+				t.Fatalf("key-%d: messages processed out of order: %v", k, names)
+			}
+		}
+	}
+}
+
+func TestRunDrainsInFlightMessagesOnShutdown(t *testing.T) {
+	source := &channelSource{messages: make(chan Message)}
+	c := newPoolConsumer(source, 1)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var handlerErr error
+	c.Handle("created", func(ctx context.Context, msg Message) error {
+		close(started)
+		<-release
+		handlerErr = ctx.Err()
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Run(ctx)
+	}()
+
+	source.messages <- message("1", "", `{}`)
+	<-started
+	cancel()
+
+	select {
+	case <-done:
+		t.Fatalf("Run returned before the in-flight message was processed")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	<-done
+
+	if handlerErr != nil {
+		t.Fatalf("in-flight handler context was canceled: %v", handlerErr)
+	}
+	if source.committedCount() != 1 {
+		t.Fatalf("expected in-flight message to be committed, got %d commits", source.committedCount())
+	}
+}
+
+func TestRunInterruptsInFlightMessagesAfterDrainTimeout(t *testing.T) {
+	source := &channelSource{messages: make(chan Message)}
+	c := newPoolConsumer(source, 1)
+	c.cfg.DrainTimeout = 10 * time.Millisecond
+
+	started := make(chan struct{})
+	c.Handle("created", func(ctx context.Context, msg Message) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Run(ctx)
+	}()
+
+	source.messages <- message("1", "", `{}`)
+	<-started
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Run did not return after drain timeout")
+	}
+
+	if source.committedCount() != 0 {
+		t.Fatalf("interrupted message must not be committed")
+	}
+}
@@ -0,0 +1,136 @@
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"runner_scheduler/pkg/inbox/inboxdb"
+)
+
+// Статусы записи inbox
+const (
+	StatusProcessed = "processed"
+	StatusPoison    = "poison"
+)
+
+// DBTX - соединение, в котором выполняются запросы: пул или транзакция
+type DBTX = inboxdb.DBTX
+
+// Store работает с таблицей inbox. conn возвращает транзакцию из ctx, если она есть.
+// Запросы генерируются sqlc из queries/inbox_queries.sql
+type Store struct {
+	conn func(ctx context.Context) DBTX
+}
+
+func NewStore(conn func(ctx context.Context) DBTX) *Store {
+	return &Store{conn: conn}
+}
+
+func (s *Store) queries(ctx context.Context) *inboxdb.Queries {
+	return inboxdb.New(s.conn(ctx))
+}
+
+// markProcessed записывает сообщение как обработанное. Возвращает false, если сообщение
+// уже есть в inbox (повторная доставка)
+func (s *Store) markProcessed(ctx context.Context, msg received) (bool, error) {
+	inserted, err := s.queries(ctx).MarkInboxMessageProcessed(ctx, inboxdb.MarkInboxMessageProcessedParams{
+		MessageID: msg.id,
+		EventType: msg.eventType,
+		Topic:     msg.Topic,
+		Attempts:  msg.attempts,
+	})
+	if err != nil {
+		return false, err
+	}
+	return inserted == 1, nil
+}
+
+// markProcessedBatch записывает пачку сообщений одним запросом и возвращает идентификаторы
+// тех, которых еще не было в inbox
+func (s *Store) markProcessedBatch(ctx context.Context, msgs []received) (map[string]bool, error) {
+	arg := inboxdb.MarkInboxMessagesProcessedParams{
+		MessageIds: make([]string, 0, len(msgs)),
+		EventTypes: make([]string, 0, len(msgs)),
+		Topics:     make([]string, 0, len(msgs)),
+		Attempts:   make([]int32, 0, len(msgs)),
+	}
+	seen := make(map[string]bool, len(msgs))
+	for _, msg := range msgs {
+		if seen[msg.id] {
+			continue
+		}
+		seen[msg.id] = true
+		arg.MessageIds = append(arg.MessageIds, msg.id)
+		arg.EventTypes = append(arg.EventTypes, msg.eventType)
+		arg.Topics = append(arg.Topics, msg.Topic)
+		arg.Attempts = append(arg.Attempts, msg.attempts)
+	}
+
+	ids, err := s.queries(ctx).MarkInboxMessagesProcessed(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	inserted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		inserted[id] = true
+	}
+	return inserted, nil
+}
+
+// markPoison сохраняет сообщение, которое не удалось обработать, вместе с содержимым для разбора
+func (s *Store) markPoison(ctx context.Context, msg received, cause error) error {
+	headers := make(map[string]string, len(msg.Headers))
+	for name, value := range msg.Headers {
+		headers[name] = string(value)
+	}
+	headersJSON, err := json.Marshal(headers)
+	if err != nil {
+		return fmt.Errorf("marshal headers: %w", err)
+	}
+
+	lastError := cause.Error()
+	return s.queries(ctx).MarkInboxMessagePoison(ctx, inboxdb.MarkInboxMessagePoisonParams{
+		MessageID: msg.id,
+		EventType: msg.eventType,
+		Topic:     msg.Topic,
+		Attempts:  msg.attempts,
+		LastError: &lastError,
+		Headers:   headersJSON,
+		Payload:   msg.Value,
+	})
+}
+
+// Record - запись inbox об обработанном сообщении
+type Record struct {
+	MessageID string    `json:"message_id"`
+	EventType string    `json:"event_type"`
+	Topic     string    `json:"topic"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PurgeProcessed удаляет до batchSize записей об обработанных сообщениях старше olderThan.
+// После удаления повторная доставка того же сообщения будет обработана заново. Poison записи
+// не удаляются
+func (s *Store) PurgeProcessed(ctx context.Context, olderThan time.Duration, batchSize int32) ([]Record, error) {
+	rows, err := s.queries(ctx).PurgeProcessedInboxMessages(ctx, inboxdb.PurgeProcessedInboxMessagesParams{
+		OlderThanSeconds: int32(olderThan / time.Second),
+		BatchLimit:       batchSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("purge processed inbox: %w", err)
+	}
+
+	records := make([]Record, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, Record{
+			MessageID: row.MessageID,
+			EventType: row.EventType,
+			Topic:     row.Topic,
+			CreatedAt: row.CreatedAt.Time,
+		})
+	}
+	return records, nil
+}
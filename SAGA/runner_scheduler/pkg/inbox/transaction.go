@@ -0,0 +1,99 @@
+package inbox
+
+import (
+	"context"
+	"time"
+
+	"runner_scheduler/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// TransactionalSource - BatchSource, который подтверждает прочитанные сообщения в транзакции
+// вместе с публикациями Publisher, например Kafka consumer group в exactly-once режиме. Commit и
+// CommitBatch для него ничего не делают: смещения подтверждаются в End
+type TransactionalSource interface {
+	BatchSource
+	// Begin начинает транзакцию для прочитанных сообщений
+	Begin() error
+	// End коммитит транзакцию вместе со смещениями всех прочитанных сообщений или, если commit
+	// равен false, отменяет ее, и сообщения приходят повторно. committed равен false, если
+	// транзакцию пришлось отменить, например из-за ребалансировки группы
+	End(ctx context.Context, commit bool) (committed bool, err error)
+}
+
+// runTransactional обрабатывает сообщения TransactionalSource до отмены ctx. Пачка
+// обрабатывается целиком в одной транзакции источника, поэтому параллельные обработчики
+// Config.Workers не используются. Ошибка Process отменяет транзакцию: публикации в топики
+// повторов и DLQ не видны read_committed consumer'ам, а сообщения пачки приходят повторно и
+// пропускаются дедупликацией inbox, если уже обработаны.
+//
+// После отмены ctx начатая пачка обрабатывается, но не дольше Config.DrainTimeout
+func (c *Consumer) runTransactional(ctx context.Context, source TransactionalSource) error {
+	log := logger.FromContext(ctx)
+
+	drainTimeout := c.cfg.DrainTimeout
+	if drainTimeout <= 0 {
+		drainTimeout = defaultDrainTimeout
+	}
+
+	procCtx, cancelProc := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelProc()
+	stop := context.AfterFunc(ctx, func() {
+		time.AfterFunc(drainTimeout, cancelProc)
+	})
+	defer stop()
+
+	for {
+		msgs, err := c.fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			log.Error("failed to fetch message", zap.Error(err))
+			if !sleep(ctx, fetchRetryDelay) {
+				return nil
+			}
+			continue
+		}
+
+		// Повтор ожидается до начала транзакции, чтобы она не истекла по таймауту брокера.
+		// Неподтвержденные сообщения придут повторно
+		if !c.waitRetries(ctx, msgs) {
+			return nil
+		}
+
+		if err := source.Begin(); err != nil {
+			log.Error("failed to begin transaction", zap.Error(err))
+			c.end(procCtx, log, source, err)
+			if !sleep(ctx, fetchRetryDelay) {
+				return nil
+			}
+			continue
+		}
+
+		if len(msgs) == 1 {
+			err = c.Process(procCtx, msgs[0])
+		} else {
+			err = c.ProcessBatch(procCtx, msgs)
+		}
+		if !c.end(procCtx, log, source, err) && ctx.Err() == nil {
+			sleep(ctx, fetchRetryDelay)
+		}
+	}
+}
+
+// end коммитит транзакцию, если обработка завершилась без ошибки, иначе отменяет ее.
+// Возвращает true, если транзакция закоммичена
+func (c *Consumer) end(ctx context.Context, log *zap.Logger, source TransactionalSource, cause error) bool {
+	committed, err := source.End(ctx, cause == nil)
+	switch {
+	case err != nil:
+		log.Error("failed to end transaction", zap.NamedError("cause", cause), zap.Error(err))
+	case cause != nil:
+		log.Warn("transaction aborted, messages will be redelivered", zap.Error(cause))
+	case !committed:
+		log.Warn("transaction aborted after group rebalance, messages will be redelivered")
+	}
+	return err == nil && committed
+}
@@ -0,0 +1,132 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTransactionalSource выдает пачки из канала и записывает, чем закончились транзакции
+type fakeTransactionalSource struct {
+	channelSource
+	batches chan []Message
+
+	ends    []bool
+	inTxn   bool
+	endedMu sync.Mutex
+}
+
+func (s *fakeTransactionalSource) FetchBatch(ctx context.Context, max int, maxWait time.Duration) ([]Message, error) {
+	select {
+	case msgs := <-s.batches:
+		return msgs, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *fakeTransactionalSource) CommitBatch(ctx context.Context, msgs []Message) error {
+	return nil
+}
+
+func (s *fakeTransactionalSource) Begin() error {
+	if s.inTxn {
+		return errors.New("already in transaction")
+	}
+	s.inTxn = true
+	return nil
+}
+
+func (s *fakeTransactionalSource) End(ctx context.Context, commit bool) (bool, error) {
+	s.endedMu.Lock()
+	defer s.endedMu.Unlock()
+	s.inTxn = false
+	s.ends = append(s.ends, commit)
+	return commit, nil
+}
+
+func (s *fakeTransactionalSource) ended() []bool {
+	s.endedMu.Lock()
+	defer s.endedMu.Unlock()
+	return append([]bool(nil), s.ends...)
+}
+
+// failingPublisher считает публикации и возвращает ошибку на каждую
+type failingPublisher struct {
+	calls int
+}
+
+func (p *failingPublisher) Publish(ctx context.Context, msg Message) error {
+	p.calls++
+	return errors.New("transaction fenced")
+}
+
+func newTransactionalConsumer(source *fakeTransactionalSource, publisher Publisher) *Consumer {
+	c := newPoolConsumer(&source.channelSource, 4)
+	c.source = source
+	c.publisher = publisher
+	c.cfg.BatchSize = 10
+	c.cfg.RetryTopics = []RetryTopic{{Topic: "events.retry.1", Delay: time.Second}}
+	c.cfg.DeadLetterTopic = "events.dlq"
+	return c
+}
+
+func runUntil(t *testing.T, c *Consumer, cond func() bool) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Run(ctx)
+	}()
+
+	waitFor(t, cond)
+	cancel()
+	<-done
+}
+
+func TestRunTransactionalCommitsBatchInTransaction(t *testing.T) {
+	source := &fakeTransactionalSource{batches: make(chan []Message, 1)}
+	publisher := &fakePublisher{}
+	c := newTransactionalConsumer(source, publisher)
+
+	c.Handle("created", func(ctx context.Context, msg Message) error {
+		if string(msg.Value) == "fail" {
+			return errors.New("database unavailable")
+		}
+		return nil
+	})
+
+	source.batches <- []Message{message("1", "", `{}`), message("2", "", "fail")}
+	runUntil(t, c, func() bool { return len(source.ended()) == 1 })
+
+	if ends := source.ended(); !ends[0] {
+		t.Fatalf("expected transaction committed, got %v", ends)
+	}
+	if len(publisher.published) != 1 || publisher.published[0].Topic != "events.retry.1" {
+		t.Fatalf("expected failed message published to retry topic in transaction, got %+v", publisher.published)
+	}
+}
+
+func TestRunTransactionalAbortsWhenPublishFails(t *testing.T) {
+	source := &fakeTransactionalSource{batches: make(chan []Message, 1)}
+	publisher := &failingPublisher{}
+	c := newTransactionalConsumer(source, publisher)
+
+	c.Handle("created", func(ctx context.Context, msg Message) error {
+		return errors.New("database unavailable")
+	})
+
+	source.batches <- []Message{message("1", "", `{}`)}
+	runUntil(t, c, func() bool { return len(source.ended()) == 1 })
+
+	if ends := source.ended(); ends[0] {
+		t.Fatalf("expected transaction aborted, got %v", ends)
+	}
+	if publisher.calls != 1 {
+		t.Fatalf("publish must not be retried inside a transaction, got %d calls", publisher.calls)
+	}
+}
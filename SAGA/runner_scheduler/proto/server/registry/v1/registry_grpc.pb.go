@@ -0,0 +1,169 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: registry/v1/registry.proto
+
+package registrypb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	RunnerRegistryService_Heartbeat_FullMethodName  = "/registry.v1.RunnerRegistryService/Heartbeat"
+	RunnerRegistryService_Deregister_FullMethodName = "/registry.v1.RunnerRegistryService/Deregister"
+)
+
+// RunnerRegistryServiceClient is the client API for RunnerRegistryService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Реестр runner'ов в runner_scheduler. Runner'ы регистрируются и подтверждают
+// жизнеспособность периодическими heartbeat'ами
+type RunnerRegistryServiceClient interface {
+	// Регистрирует runner или обновляет его состояние (upsert по node_id)
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
+	// Удаляет runner из реестра при штатной остановке
+	Deregister(ctx context.Context, in *DeregisterRequest, opts ...grpc.CallOption) (*DeregisterResponse, error)
+}
+
+type runnerRegistryServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRunnerRegistryServiceClient(cc grpc.ClientConnInterface) RunnerRegistryServiceClient {
+	return &runnerRegistryServiceClient{cc}
+}
+
+func (c *runnerRegistryServiceClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HeartbeatResponse)
+	err := c.cc.Invoke(ctx, RunnerRegistryService_Heartbeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *runnerRegistryServiceClient) Deregister(ctx context.Context, in *DeregisterRequest, opts ...grpc.CallOption) (*DeregisterResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeregisterResponse)
+	err := c.cc.Invoke(ctx, RunnerRegistryService_Deregister_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RunnerRegistryServiceServer is the server API for RunnerRegistryService service.
+// All implementations must embed UnimplementedRunnerRegistryServiceServer
+// for forward compatibility.
+//
+// Реестр runner'ов в runner_scheduler. Runner'ы регистрируются и подтверждают
+// жизнеспособность периодическими heartbeat'ами
+type RunnerRegistryServiceServer interface {
+	// Регистрирует runner или обновляет его состояние (upsert по node_id)
+	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
+	// Удаляет runner из реестра при штатной остановке
+	Deregister(context.Context, *DeregisterRequest) (*DeregisterResponse, error)
+	mustEmbedUnimplementedRunnerRegistryServiceServer()
+}
+
+// UnimplementedRunnerRegistryServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRunnerRegistryServiceServer struct{}
+
+func (UnimplementedRunnerRegistryServiceServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedRunnerRegistryServiceServer) Deregister(context.Context, *DeregisterRequest) (*DeregisterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Deregister not implemented")
+}
+func (UnimplementedRunnerRegistryServiceServer) mustEmbedUnimplementedRunnerRegistryServiceServer() {}
+func (UnimplementedRunnerRegistryServiceServer) testEmbeddedByValue()                               {}
+
+// UnsafeRunnerRegistryServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RunnerRegistryServiceServer will
+// result in compilation errors.
+type UnsafeRunnerRegistryServiceServer interface {
+	mustEmbedUnimplementedRunnerRegistryServiceServer()
+}
+
+func RegisterRunnerRegistryServiceServer(s grpc.ServiceRegistrar, srv RunnerRegistryServiceServer) {
+	// If the following call pancis, it indicates UnimplementedRunnerRegistryServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RunnerRegistryService_ServiceDesc, srv)
+}
+
+func _RunnerRegistryService_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RunnerRegistryServiceServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RunnerRegistryService_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RunnerRegistryServiceServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RunnerRegistryService_Deregister_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeregisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RunnerRegistryServiceServer).Deregister(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RunnerRegistryService_Deregister_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RunnerRegistryServiceServer).Deregister(ctx, req.(*DeregisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RunnerRegistryService_ServiceDesc is the grpc.ServiceDesc for RunnerRegistryService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RunnerRegistryService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "registry.v1.RunnerRegistryService",
+	HandlerType: (*RunnerRegistryServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Heartbeat",
+			Handler:    _RunnerRegistryService_Heartbeat_Handler,
+		},
+		{
+			MethodName: "Deregister",
+			Handler:    _RunnerRegistryService_Deregister_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "registry/v1/registry.proto",
+}
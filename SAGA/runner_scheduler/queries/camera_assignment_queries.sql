@@ -0,0 +1,41 @@
+-- name: UpsertCameraAssignment :one
+-- Каждое новое размещение камеры увеличивает fencing_token, поэтому воркер, запущенный
+-- по предыдущему размещению, можно отличить и отозвать
+INSERT INTO camera_assignment (
+    camera_id,
+    scenario_uuid,
+    node_id,
+    url
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (camera_id) DO UPDATE
+SET scenario_uuid = EXCLUDED.scenario_uuid,
+    node_id = EXCLUDED.node_id,
+    url = EXCLUDED.url,
+    fencing_token = camera_assignment.fencing_token + 1,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetCameraAssignmentForUpdate :one
+SELECT * FROM camera_assignment
+WHERE camera_id = $1
+FOR UPDATE;
+
+-- name: DeleteCameraAssignment :exec
+DELETE FROM camera_assignment
+WHERE camera_id = $1;
+
+-- name: ListOrphanedCameraAssignments :many
+-- Возвращает размещения на dead или снятых с учета runner'ах, которые нужно перенести
+SELECT a.* FROM camera_assignment a
+LEFT JOIN runner_node n ON n.node_id = a.node_id
+WHERE n.node_id IS NULL
+   OR n.status = 'dead'
+ORDER BY a.camera_id
+LIMIT sqlc.arg(batch_limit)
+FOR UPDATE OF a SKIP LOCKED;
+
+-- name: ListCameraAssignmentsByCameraIDs :many
+SELECT * FROM camera_assignment
+WHERE camera_id = ANY(sqlc.arg(camera_ids)::integer[]);
@@ -0,0 +1,36 @@
+-- name: MarkInboxMessageProcessed :execrows
+-- Повторная доставка уже записанного сообщения не вставляет строку
+INSERT INTO inbox (message_id, event_type, topic, status, attempts)
+VALUES ($1, $2, $3, 'processed', $4)
+ON CONFLICT (message_id) DO NOTHING;
+
+-- name: MarkInboxMessagesProcessed :many
+-- Записывает пачку сообщений одним запросом: массивы параметров одной длины, i-е элементы -
+-- одно сообщение. Возвращает идентификаторы сообщений, которых еще не было в inbox
+INSERT INTO inbox (message_id, event_type, topic, status, attempts)
+SELECT
+    unnest(sqlc.arg(message_ids)::text[]),
+    unnest(sqlc.arg(event_types)::text[]),
+    unnest(sqlc.arg(topics)::text[]),
+    'processed',
+    unnest(sqlc.arg(attempts)::integer[])
+ON CONFLICT (message_id) DO NOTHING
+RETURNING message_id;
+
+-- name: MarkInboxMessagePoison :exec
+INSERT INTO inbox (message_id, event_type, topic, status, attempts, last_error, headers, payload)
+VALUES ($1, $2, $3, 'poison', $4, $5, $6, $7)
+ON CONFLICT (message_id) DO NOTHING;
+
+-- name: PurgeProcessedInboxMessages :many
+-- Poison записи не удаляются
+DELETE FROM inbox
+WHERE message_id IN (
+    SELECT message_id FROM inbox
+    WHERE status = 'processed'
+      AND created_at < NOW() - sqlc.arg(older_than_seconds)::integer * INTERVAL '1 second'
+    ORDER BY created_at
+    LIMIT sqlc.arg(batch_limit)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING message_id, event_type, topic, created_at;
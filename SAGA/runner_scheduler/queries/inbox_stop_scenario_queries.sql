@@ -0,0 +1,75 @@
+-- name: CreateInboxStopScenario :one
+INSERT INTO inbox_stop_scenario (
+    outbox_uuid,
+    camera_id,
+    scenario_uuid
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: BulkCreateInboxStopScenarios :execrows
+-- Сохраняет пачку событий одним запросом: массивы параметров одной длины, i-е элементы -
+-- одно событие. Уже сохраненные события пропускаются
+INSERT INTO inbox_stop_scenario (
+    outbox_uuid,
+    camera_id,
+    scenario_uuid
+)
+SELECT
+    unnest(sqlc.arg(outbox_uuids)::uuid[]),
+    unnest(sqlc.arg(camera_ids)::integer[]),
+    unnest(sqlc.arg(scenario_uuids)::uuid[])
+ON CONFLICT (outbox_uuid) DO NOTHING;
+
+-- name: ClaimInboxStopScenarios :many
+-- Захватывает пачку сообщений для обработки scheduler'ом: received, у которых наступило время
+-- следующей попытки, и in_process, зависшие дольше lease (scheduler упал посреди обработки)
+UPDATE inbox_stop_scenario
+SET status = 'in_process',
+    attempts = attempts + 1,
+    updated_at = NOW()
+WHERE outbox_uuid IN (
+    SELECT outbox_uuid FROM inbox_stop_scenario
+    WHERE (status = 'received' AND next_attempt_at <= NOW())
+       OR (status = 'in_process' AND updated_at < NOW() - sqlc.arg(lease_seconds)::integer * INTERVAL '1 second')
+    ORDER BY created_at
+    LIMIT sqlc.arg(batch_limit)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkInboxStopScenarioProcessed :exec
+UPDATE inbox_stop_scenario
+SET status = 'processed',
+    last_error = NULL,
+    updated_at = NOW()
+WHERE outbox_uuid = $1;
+
+-- name: RescheduleInboxStopScenario :exec
+UPDATE inbox_stop_scenario
+SET status = 'received',
+    last_error = sqlc.arg(last_error),
+    next_attempt_at = NOW() + sqlc.arg(delay_ms)::integer * INTERVAL '1 millisecond',
+    updated_at = NOW()
+WHERE outbox_uuid = sqlc.arg(outbox_uuid);
+
+-- name: MarkInboxStopScenarioFailed :exec
+UPDATE inbox_stop_scenario
+SET status = 'failed',
+    last_error = sqlc.arg(last_error),
+    updated_at = NOW()
+WHERE outbox_uuid = sqlc.arg(outbox_uuid);
+
+-- name: PurgeProcessedInboxStopScenarios :many
+-- Удаляет до batch_size processed записей старше older_than_seconds и возвращает их для архива.
+-- После удаления повторная доставка того же события будет обработана заново
+DELETE FROM inbox_stop_scenario
+WHERE outbox_uuid IN (
+    SELECT outbox_uuid FROM inbox_stop_scenario
+    WHERE status = 'processed'
+      AND updated_at < NOW() - sqlc.arg(older_than_seconds)::integer * INTERVAL '1 second'
+    ORDER BY updated_at
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
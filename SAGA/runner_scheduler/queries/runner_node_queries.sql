@@ -0,0 +1,53 @@
+-- name: UpsertRunnerNode :one
+INSERT INTO runner_node (
+    node_id,
+    address,
+    capacity,
+    worker_count,
+    last_heartbeat_at
+) VALUES (
+    $1, $2, $3, $4, NOW()
+)
+ON CONFLICT (node_id) DO UPDATE
+SET address = EXCLUDED.address,
+    capacity = EXCLUDED.capacity,
+    worker_count = EXCLUDED.worker_count,
+    last_heartbeat_at = NOW(),
+    status = 'alive',
+    updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteRunnerNode :exec
+DELETE FROM runner_node
+WHERE node_id = $1;
+
+-- name: GetRunnerNode :one
+SELECT * FROM runner_node
+WHERE node_id = $1;
+
+-- name: ListAliveRunnerNodes :many
+-- Возвращает живые runner'ы, приславшие heartbeat не позже heartbeat_timeout_seconds назад,
+-- вместе с количеством размещенных на них камер
+SELECT
+    n.node_id,
+    n.address,
+    n.capacity,
+    n.worker_count,
+    n.last_heartbeat_at,
+    COUNT(a.camera_id)::integer AS assigned_count
+FROM runner_node n
+LEFT JOIN camera_assignment a ON a.node_id = n.node_id
+WHERE n.status = 'alive'
+  AND n.last_heartbeat_at >= NOW() - sqlc.arg(heartbeat_timeout_seconds)::integer * INTERVAL '1 second'
+GROUP BY n.node_id
+ORDER BY n.node_id;
+
+-- name: MarkDeadRunnerNodes :many
+-- Помечает dead runner'ы, не приславшие heartbeat дольше heartbeat_timeout_seconds.
+-- Heartbeat от такого runner'а возвращает его в статус alive
+UPDATE runner_node
+SET status = 'dead',
+    updated_at = NOW()
+WHERE status = 'alive'
+  AND last_heartbeat_at < NOW() - sqlc.arg(heartbeat_timeout_seconds)::integer * INTERVAL '1 second'
+RETURNING node_id;
@@ -4,9 +4,12 @@ CREATE TABLE IF NOT EXISTS inbox_start_scenario (
     camera_id INTEGER NOT NULL,
     scenario_uuid UUID NOT NULL,
     url TEXT NOT NULL DEFAULT '',
-    status TEXT NOT NULL DEFAULT 'received' CHECK (status IN ('received', 'in_process', 'processed')),
+    status TEXT NOT NULL DEFAULT 'received' CHECK (status IN ('received', 'in_process', 'processed', 'failed')),
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP
+    updated_at TIMESTAMP,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT
 );
 
 COMMENT ON TABLE inbox_start_scenario IS 'Inbox pattern table for idempotent message processing in SAGA (start scenario events)';
@@ -14,7 +17,154 @@ COMMENT ON COLUMN inbox_start_scenario.outbox_uuid IS 'Unique identifier from th
 COMMENT ON COLUMN inbox_start_scenario.camera_id IS 'ID of the camera associated with the scenario';
 COMMENT ON COLUMN inbox_start_scenario.scenario_uuid IS 'UUID of the scenario being started';
 COMMENT ON COLUMN inbox_start_scenario.url IS 'URL associated with the scenario';
-COMMENT ON COLUMN inbox_start_scenario.status IS 'Processing status: received, in_process, processed';
+COMMENT ON COLUMN inbox_start_scenario.status IS 'Processing status: received, in_process, processed, failed';
 COMMENT ON COLUMN inbox_start_scenario.created_at IS 'Timestamp when the message was first received';
 COMMENT ON COLUMN inbox_start_scenario.updated_at IS 'Timestamp when the message status was last updated';
+COMMENT ON COLUMN inbox_start_scenario.attempts IS 'Number of dispatch attempts made by the scheduler';
+COMMENT ON COLUMN inbox_start_scenario.next_attempt_at IS 'Timestamp before which the message must not be dispatched again (retry backoff)';
+COMMENT ON COLUMN inbox_start_scenario.last_error IS 'Error of the last failed dispatch attempt';
+
+CREATE INDEX IF NOT EXISTS inbox_start_scenario_status_next_attempt_idx ON inbox_start_scenario (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS inbox_start_scenario_processed_updated_at_idx ON inbox_start_scenario (updated_at)
+    WHERE status = 'processed';
+
+
+-- Inbox Stop Scenario table for idempotent message processing
+CREATE TABLE IF NOT EXISTS inbox_stop_scenario (
+    outbox_uuid UUID NOT NULL PRIMARY KEY,
+    camera_id INTEGER NOT NULL,
+    scenario_uuid UUID NOT NULL,
+    status TEXT NOT NULL DEFAULT 'received' CHECK (status IN ('received', 'in_process', 'processed', 'failed')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT
+);
+
+COMMENT ON TABLE inbox_stop_scenario IS 'Inbox pattern table for idempotent message processing in SAGA (stop scenario events)';
+COMMENT ON COLUMN inbox_stop_scenario.outbox_uuid IS 'Unique identifier from the outbox message (serves as primary key for idempotency)';
+COMMENT ON COLUMN inbox_stop_scenario.camera_id IS 'ID of the camera associated with the scenario';
+COMMENT ON COLUMN inbox_stop_scenario.scenario_uuid IS 'UUID of the scenario being stopped';
+COMMENT ON COLUMN inbox_stop_scenario.status IS 'Processing status: received, in_process, processed, failed';
+COMMENT ON COLUMN inbox_stop_scenario.created_at IS 'Timestamp when the message was first received';
+COMMENT ON COLUMN inbox_stop_scenario.updated_at IS 'Timestamp when the message status was last updated';
+COMMENT ON COLUMN inbox_stop_scenario.attempts IS 'Number of dispatch attempts made by the scheduler';
+COMMENT ON COLUMN inbox_stop_scenario.next_attempt_at IS 'Timestamp before which the message must not be dispatched again (retry backoff)';
+COMMENT ON COLUMN inbox_stop_scenario.last_error IS 'Error of the last failed dispatch attempt';
+
+CREATE INDEX IF NOT EXISTS inbox_stop_scenario_status_next_attempt_idx ON inbox_stop_scenario (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS inbox_stop_scenario_processed_updated_at_idx ON inbox_stop_scenario (updated_at)
+    WHERE status = 'processed';
+
+-- Generic outbox table for publishing aggregate events (scenario results and others) to Kafka
+CREATE TABLE IF NOT EXISTS outbox (
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    aggregate_type TEXT NOT NULL,
+    aggregate_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    topic TEXT NOT NULL,
+    headers JSONB NOT NULL DEFAULT '{}',
+    payload BYTEA NOT NULL,
+    state TEXT NOT NULL DEFAULT 'pending' CHECK (state IN ('pending', 'sent', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGSERIAL NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+COMMENT ON TABLE outbox IS 'Generic outbox for reliable publishing of aggregate events to Kafka';
+COMMENT ON COLUMN outbox.id IS 'Unique identifier of the event, sent in the outbox_uuid header for deduplication';
+COMMENT ON COLUMN outbox.aggregate_type IS 'Type of the aggregate the event belongs to (scenario, camera, ...)';
+COMMENT ON COLUMN outbox.aggregate_id IS 'Identifier of the aggregate; used as the Kafka message key, events of one aggregate are published in order';
+COMMENT ON COLUMN outbox.event_type IS 'Type of the event';
+COMMENT ON COLUMN outbox.topic IS 'Kafka topic the event is published to';
+COMMENT ON COLUMN outbox.headers IS 'Additional Kafka message headers';
+COMMENT ON COLUMN outbox.payload IS 'Message payload';
+COMMENT ON COLUMN outbox.state IS 'State of the event (pending, sent, failed)';
+COMMENT ON COLUMN outbox.attempts IS 'Number of publish attempts';
+COMMENT ON COLUMN outbox.last_error IS 'Error of the last failed publish attempt';
+COMMENT ON COLUMN outbox.next_attempt_at IS 'Timestamp before which the event must not be claimed (lease of the relay or retry backoff)';
+COMMENT ON COLUMN outbox.seq IS 'Insertion order of events, defines publish order within an aggregate';
+COMMENT ON COLUMN outbox.created_at IS 'Timestamp when the event was created';
+COMMENT ON COLUMN outbox.updated_at IS 'Timestamp when the event was last updated';
+
+CREATE INDEX IF NOT EXISTS outbox_unsent_aggregate_seq_idx ON outbox (aggregate_type, aggregate_id, seq)
+    WHERE state <> 'sent';
+CREATE INDEX IF NOT EXISTS outbox_pending_seq_idx ON outbox (seq)
+    WHERE state = 'pending';
+CREATE INDEX IF NOT EXISTS outbox_sent_updated_at_idx ON outbox (updated_at)
+    WHERE state = 'sent';
+
+-- Generic inbox table: deduplication and poison messages of the consumer
+CREATE TABLE IF NOT EXISTS inbox (
+    message_id TEXT NOT NULL PRIMARY KEY,
+    event_type TEXT NOT NULL,
+    topic TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('processed', 'poison')),
+    attempts INTEGER NOT NULL DEFAULT 1,
+    last_error TEXT,
+    headers JSONB,
+    payload BYTEA,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE inbox IS 'Generic inbox for exactly-once processing of consumed Kafka messages';
+COMMENT ON COLUMN inbox.message_id IS 'Identifier of the message from the outbox_uuid header (serves as primary key for idempotency)';
+COMMENT ON COLUMN inbox.event_type IS 'Type of the event';
+COMMENT ON COLUMN inbox.topic IS 'Kafka topic the message was consumed from';
+COMMENT ON COLUMN inbox.status IS 'Processing status: processed, poison';
+COMMENT ON COLUMN inbox.attempts IS 'Number of processing attempts';
+COMMENT ON COLUMN inbox.last_error IS 'Error that made the message poison';
+COMMENT ON COLUMN inbox.headers IS 'Kafka message headers of a poison message';
+COMMENT ON COLUMN inbox.payload IS 'Payload of a poison message';
+COMMENT ON COLUMN inbox.created_at IS 'Timestamp when the message was processed';
+
+CREATE INDEX IF NOT EXISTS inbox_processed_created_at_idx ON inbox (created_at)
+    WHERE status = 'processed';
+
+-- Runner Node table: registry of runner instances reporting heartbeats
+CREATE TABLE IF NOT EXISTS runner_node (
+    node_id TEXT NOT NULL PRIMARY KEY,
+    address TEXT NOT NULL,
+    capacity INTEGER NOT NULL CHECK (capacity >= 0),
+    worker_count INTEGER NOT NULL DEFAULT 0,
+    last_heartbeat_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    status TEXT NOT NULL DEFAULT 'alive' CHECK (status IN ('alive', 'dead')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+COMMENT ON TABLE runner_node IS 'Registry of runner instances that host camera workers';
+COMMENT ON COLUMN runner_node.node_id IS 'Unique identifier of the runner instance';
+COMMENT ON COLUMN runner_node.address IS 'gRPC address (host:port) of the runner reachable from the scheduler';
+COMMENT ON COLUMN runner_node.capacity IS 'Maximum number of workers the runner can host';
+COMMENT ON COLUMN runner_node.worker_count IS 'Number of workers reported by the runner in the last heartbeat';
+COMMENT ON COLUMN runner_node.last_heartbeat_at IS 'Timestamp of the last heartbeat received from the runner';
+COMMENT ON COLUMN runner_node.status IS 'Liveness of the runner: alive, or dead after missing heartbeats (cameras are moved to other runners)';
+COMMENT ON COLUMN runner_node.created_at IS 'Timestamp when the runner was first registered';
+COMMENT ON COLUMN runner_node.updated_at IS 'Timestamp when the runner record was last updated';
+
+-- Camera Assignment table: placement of camera workers on runner instances
+CREATE TABLE IF NOT EXISTS camera_assignment (
+    camera_id INTEGER NOT NULL PRIMARY KEY,
+    scenario_uuid UUID NOT NULL,
+    node_id TEXT NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    fencing_token BIGINT NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS camera_assignment_node_id_idx ON camera_assignment (node_id);
 
+COMMENT ON TABLE camera_assignment IS 'Placement of camera workers on runner instances';
+COMMENT ON COLUMN camera_assignment.camera_id IS 'ID of the camera whose worker is placed on the runner';
+COMMENT ON COLUMN camera_assignment.scenario_uuid IS 'UUID of the scenario the worker was started for';
+COMMENT ON COLUMN camera_assignment.node_id IS 'Runner instance hosting the camera worker (may point to a deregistered node until the camera is re-placed)';
+COMMENT ON COLUMN camera_assignment.url IS 'RTSP stream URL of the camera, used to restart the worker on another runner';
+COMMENT ON COLUMN camera_assignment.fencing_token IS 'Monotonic token incremented on every placement of the camera; workers started with an older token are revoked';
+COMMENT ON COLUMN camera_assignment.created_at IS 'Timestamp when the camera was placed';
+COMMENT ON COLUMN camera_assignment.updated_at IS 'Timestamp when the assignment was last updated';
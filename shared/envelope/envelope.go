@@ -0,0 +1,187 @@
+package envelope
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	eventspb "shared/proto/events/v1"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Заголовок, которым помечаются сообщения в конверте. Сообщения без него - события
+// в старом формате, payload которых - JSON без конверта
+const (
+	ContentTypeHeader = "content_type"
+	ContentType       = "application/x-protobuf; type=events.v1.Envelope"
+)
+
+var (
+	ErrUnknownType        = errors.New("unknown event type")
+	ErrUnsupportedVersion = errors.New("unsupported event version")
+)
+
+// Version - версия схемы payload
+type Version struct {
+	Major uint32
+	Minor uint32
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("v%d.%d", v.Major, v.Minor)
+}
+
+// Event - событие, распакованное из конверта
+type Event struct {
+	ID            string
+	Type          string
+	Version       Version
+	OccurredAt    time.Time
+	CorrelationID string
+	Payload       proto.Message
+}
+
+type schema struct {
+	version    Version
+	newPayload func() proto.Message
+}
+
+// Registry хранит схемы payload по типам событий. Заполняется при старте сервиса и
+// после этого только читается
+type Registry struct {
+	schemas map[string]schema
+}
+
+func NewRegistry() *Registry {
+	return &Registry{schemas: make(map[string]schema)}
+}
+
+// Register регистрирует схему события eventType. newPayload создает пустое сообщение схемы
+func (r *Registry) Register(eventType string, version Version, newPayload func() proto.Message) {
+	r.schemas[eventType] = schema{version: version, newPayload: newPayload}
+}
+
+// Encode упаковывает событие в конверт. Версия берется из зарегистрированной схемы
+func (r *Registry) Encode(event Event) ([]byte, error) {
+	s, err := r.lookup(event.Type)
+	if err != nil {
+		return nil, err
+	}
+	if event.Payload == nil || event.Payload.ProtoReflect().Descriptor() != s.newPayload().ProtoReflect().Descriptor() {
+		return nil, fmt.Errorf("payload of event %s must be %s", event.Type, s.newPayload().ProtoReflect().Descriptor().FullName())
+	}
+
+	payload, err := proto.Marshal(event.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload: %w", err)
+	}
+
+	data, err := proto.Marshal(&eventspb.Envelope{
+		Id:            event.ID,
+		Type:          event.Type,
+		Version:       &eventspb.Version{Major: s.version.Major, Minor: s.version.Minor},
+		OccurredAt:    timestamppb.New(event.OccurredAt),
+		CorrelationId: event.CorrelationID,
+		Payload:       payload,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal envelope: %w", err)
+	}
+	return data, nil
+}
+
+// Decode распаковывает конверт. Событие с мажорной версией, отличной от зарегистрированной,
+// отклоняется с ErrUnsupportedVersion: его схема несовместима с кодом потребителя. Новые
+// минорные версии принимаются, неизвестные поля payload игнорируются
+func (r *Registry) Decode(data []byte) (Event, error) {
+	var env eventspb.Envelope
+	if err := proto.Unmarshal(data, &env); err != nil {
+		return Event{}, fmt.Errorf("unmarshal envelope: %w", err)
+	}
+
+	s, err := r.lookup(env.GetType())
+	if err != nil {
+		return Event{}, err
+	}
+
+	version := Version{Major: env.GetVersion().GetMajor(), Minor: env.GetVersion().GetMinor()}
+	if env.GetVersion() == nil || version.Major != s.version.Major {
+		return Event{}, fmt.Errorf("%w: %s %s, supported v%d.x",
+			ErrUnsupportedVersion, env.GetType(), version, s.version.Major)
+	}
+
+	payload := s.newPayload()
+	if err := proto.Unmarshal(env.GetPayload(), payload); err != nil {
+		return Event{}, fmt.Errorf("unmarshal %s payload: %w", env.GetType(), err)
+	}
+
+	return Event{
+		ID:            env.GetId(),
+		Type:          env.GetType(),
+		Version:       version,
+		OccurredAt:    env.GetOccurredAt().AsTime(),
+		CorrelationID: env.GetCorrelationId(),
+		Payload:       payload,
+	}, nil
+}
+
+// DecodeLegacy разбирает событие старого формата: JSON payload без конверта, идентификатор
+// и тип которого переданы в заголовках. Имена полей JSON совпадают с именами полей схемы
+func (r *Registry) DecodeLegacy(id, eventType string, data []byte) (Event, error) {
+	s, err := r.lookup(eventType)
+	if err != nil {
+		return Event{}, err
+	}
+
+	payload := s.newPayload()
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, payload); err != nil {
+		return Event{}, fmt.Errorf("unmarshal legacy %s payload: %w", eventType, err)
+	}
+
+	return Event{
+		ID:      id,
+		Type:    eventType,
+		Version: s.version,
+		Payload: payload,
+	}, nil
+}
+
+// PayloadFromJSON разбирает JSON payload события eventType по его схеме. Поля, которых нет
+// в схеме, считаются ошибкой: так переименование поля в коде обнаруживается при публикации
+func (r *Registry) PayloadFromJSON(eventType string, data []byte) (proto.Message, error) {
+	s, err := r.lookup(eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := s.newPayload()
+	if err := protojson.Unmarshal(data, payload); err != nil {
+		return nil, fmt.Errorf("%s payload does not match schema: %w", eventType, err)
+	}
+	return payload, nil
+}
+
+func (r *Registry) lookup(eventType string) (schema, error) {
+	s, ok := r.schemas[eventType]
+	if !ok {
+		return schema{}, fmt.Errorf("%w: %q", ErrUnknownType, eventType)
+	}
+	return s, nil
+}
+
+// DecodeMessage распаковывает сообщение Kafka. Сообщение без заголовка ContentTypeHeader
+// разбирается как событие старого формата с идентификатором и типом из заголовков idHeader и typeHeader
+func (r *Registry) DecodeMessage(headers map[string][]byte, value []byte, idHeader, typeHeader string) (Event, error) {
+	if IsEnvelope(headers) {
+		return r.Decode(value)
+	}
+	return r.DecodeLegacy(string(headers[idHeader]), string(headers[typeHeader]), value)
+}
+
+// IsEnvelope сообщает, упаковано ли сообщение с заголовками headers в конверт
+func IsEnvelope(headers map[string][]byte) bool {
+	return string(headers[ContentTypeHeader]) == ContentType
+}
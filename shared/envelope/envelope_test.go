@@ -0,0 +1,106 @@
+package envelope
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	eventspb "shared/proto/events/v1"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func newTestRegistry() *Registry {
+	r := NewRegistry()
+	r.Register("init_scenario", Version{Major: 1, Minor: 2}, func() proto.Message { return &eventspb.InitScenario{} })
+	r.Register("stop_scenario", Version{Major: 1}, func() proto.Message { return &eventspb.StopScenario{} })
+	return r
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	r := newTestRegistry()
+	occurredAt := time.Date(2025, 12, 1, 10, 0, 0, 0, time.UTC)
+
+	data, err := r.Encode(Event{
+		ID:            "0b7e6a8c-5f0a-4f3e-9d61-0c1f1c2a3b4d",
+		Type:          "init_scenario",
+		OccurredAt:    occurredAt,
+		CorrelationID: "scenario-1",
+		Payload:       &eventspb.InitScenario{ScenarioUuid: "scenario-1", CameraId: 7, Url: "rtsp://camera/7"},
+	})
+	if err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+
+	event, err := r.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode returned error: %v", err)
+	}
+	if event.Version != (Version{Major: 1, Minor: 2}) || !event.OccurredAt.Equal(occurredAt) || event.CorrelationID != "scenario-1" {
+		t.Fatalf("unexpected envelope fields: %+v", event)
+	}
+	payload, ok := event.Payload.(*eventspb.InitScenario)
+	if !ok || payload.GetCameraId() != 7 || payload.GetUrl() != "rtsp://camera/7" {
+		t.Fatalf("unexpected payload: %v", event.Payload)
+	}
+}
+
+func TestEncodeRejectsWrongPayload(t *testing.T) {
+	r := newTestRegistry()
+
+	_, err := r.Encode(Event{Type: "init_scenario", Payload: &eventspb.StopScenario{}})
+	if err == nil {
+		t.Fatalf("expected error for payload of another schema")
+	}
+	if _, err := r.Encode(Event{Type: "renamed", Payload: &eventspb.StopScenario{}}); !errors.Is(err, ErrUnknownType) {
+		t.Fatalf("expected ErrUnknownType, got %v", err)
+	}
+}
+
+func TestDecodeChecksMajorVersion(t *testing.T) {
+	r := newTestRegistry()
+
+	for _, tc := range []struct {
+		name    string
+		version *eventspb.Version
+		wantErr bool
+	}{
+		{name: "newer minor", version: &eventspb.Version{Major: 1, Minor: 5}},
+		{name: "newer major", version: &eventspb.Version{Major: 2}, wantErr: true},
+		{name: "missing version", version: nil, wantErr: true},
+	} {
+		data, err := proto.Marshal(&eventspb.Envelope{Type: "stop_scenario", Version: tc.version})
+		if err != nil {
+			t.Fatalf("%s: marshal envelope: %v", tc.name, err)
+		}
+
+		_, err = r.Decode(data)
+		if tc.wantErr != errors.Is(err, ErrUnsupportedVersion) {
+			t.Fatalf("%s: unexpected error %v", tc.name, err)
+		}
+	}
+}
+
+func TestDecodeLegacyJSON(t *testing.T) {
+	r := newTestRegistry()
+
+	event, err := r.DecodeLegacy("id-1", "stop_scenario", []byte(`{"scenario_uuid":"scenario-1","camera_id":7,"extra":true}`))
+	if err != nil {
+		t.Fatalf("DecodeLegacy returned error: %v", err)
+	}
+	payload := event.Payload.(*eventspb.StopScenario)
+	if event.ID != "id-1" || payload.GetScenarioUuid() != "scenario-1" || payload.GetCameraId() != 7 {
+		t.Fatalf("unexpected legacy event: %+v", event)
+	}
+}
+
+func TestPayloadFromJSONRejectsUnknownFields(t *testing.T) {
+	r := newTestRegistry()
+
+	if _, err := r.PayloadFromJSON("init_scenario", []byte(`{"scenario_uuid":"s","camera_id":1,"url":"u"}`)); err != nil {
+		t.Fatalf("PayloadFromJSON returned error: %v", err)
+	}
+	if _, err := r.PayloadFromJSON("init_scenario", []byte(`{"scenario_id":"s","camera_id":1}`)); err == nil {
+		t.Fatalf("expected error for renamed field")
+	}
+}
@@ -0,0 +1,152 @@
+// Package kafkasecurity собирает SASL и TLS настройки подключения к Kafka, общие для всех сервисов
+package kafkasecurity
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// Механизмы SASL
+const (
+	SASLPlain       = "PLAIN"
+	SASLScramSHA256 = "SCRAM-SHA-256"
+	SASLScramSHA512 = "SCRAM-SHA-512"
+)
+
+// DialTimeout - таймаут подключения к брокеру, включая TLS и SASL рукопожатия
+const DialTimeout = 10 * time.Second
+
+type SASLConfig struct {
+	// Mechanism - PLAIN, SCRAM-SHA-256 или SCRAM-SHA-512
+	Mechanism string
+	Username  string
+	Password  string
+}
+
+type TLSConfig struct {
+	Enabled bool
+	// CAFile - сертификат CA брокеров в PEM. Пустой - системные корневые сертификаты
+	CAFile string
+	// CertFile и KeyFile - клиентский сертификат и ключ в PEM для mTLS
+	CertFile string
+	KeyFile  string
+	// InsecureSkipVerify отключает проверку сертификата брокера. Только для разработки
+	InsecureSkipVerify bool
+}
+
+func (c SASLConfig) Validate() error {
+	switch c.Mechanism {
+	case "":
+		return nil
+	case SASLPlain, SASLScramSHA256, SASLScramSHA512:
+	default:
+		return fmt.Errorf("unsupported sasl mechanism %q", c.Mechanism)
+	}
+
+	if c.Username == "" {
+		return fmt.Errorf("sasl username cannot be empty")
+	}
+	return nil
+}
+
+// mechanism возвращает механизм SASL для kafka-go. Пустой Mechanism - nil, без аутентификации
+func (c SASLConfig) mechanism() (sasl.Mechanism, error) {
+	switch c.Mechanism {
+	case "":
+		return nil, nil
+	case SASLPlain:
+		return plain.Mechanism{Username: c.Username, Password: c.Password}, nil
+	case SASLScramSHA256:
+		return scram.Mechanism(scram.SHA256, c.Username, c.Password)
+	case SASLScramSHA512:
+		return scram.Mechanism(scram.SHA512, c.Username, c.Password)
+	default:
+		return nil, fmt.Errorf("unsupported sasl mechanism %q", c.Mechanism)
+	}
+}
+
+func (c TLSConfig) Validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return fmt.Errorf("tls cert file and key file must be set together")
+	}
+	return nil
+}
+
+// Config возвращает TLS конфигурацию клиента, nil если TLS выключен
+func (c TLSConfig) Config() (*tls.Config, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CAFile != "" {
+		ca, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read tls ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("tls ca file %s contains no certificates", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load tls client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// Dialer создает dialer для reader'а и прямых подключений к брокерам
+func Dialer(saslCfg SASLConfig, tlsCfg TLSConfig) (*kafka.Dialer, error) {
+	mechanism, err := saslCfg.mechanism()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := tlsCfg.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafka.Dialer{
+		Timeout:       DialTimeout,
+		DualStack:     true,
+		SASLMechanism: mechanism,
+		TLS:           tlsConfig,
+	}, nil
+}
+
+// Transport создает транспорт для writer'а
+func Transport(saslCfg SASLConfig, tlsCfg TLSConfig) (*kafka.Transport, error) {
+	mechanism, err := saslCfg.mechanism()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := tlsCfg.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafka.Transport{
+		DialTimeout: DialTimeout,
+		SASL:        mechanism,
+		TLS:         tlsConfig,
+	}, nil
+}
@@ -0,0 +1,88 @@
+package kafkasecurity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/segmentio/kafka-go/sasl/plain"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		sasl    SASLConfig
+		tls     TLSConfig
+		wantErr bool
+	}{
+		{name: "plaintext"},
+		{name: "scram", sasl: SASLConfig{Mechanism: SASLScramSHA512, Username: "user", Password: "secret"}},
+		{name: "unknown mechanism", sasl: SASLConfig{Mechanism: "GSSAPI", Username: "user"}, wantErr: true},
+		{name: "sasl without username", sasl: SASLConfig{Mechanism: SASLPlain, Password: "secret"}, wantErr: true},
+		{name: "cert without key", tls: TLSConfig{Enabled: true, CertFile: "client.pem"}, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.sasl.Validate()
+			if err == nil {
+				err = tc.tls.Validate()
+			}
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestDialerUsesSASLAndTLS(t *testing.T) {
+	dialer, err := Dialer(
+		SASLConfig{Mechanism: SASLPlain, Username: "user", Password: "secret"},
+		TLSConfig{Enabled: true, InsecureSkipVerify: true},
+	)
+	if err != nil {
+		t.Fatalf("Dialer() returned error: %v", err)
+	}
+
+	mechanism, ok := dialer.SASLMechanism.(plain.Mechanism)
+	if !ok || mechanism.Username != "user" || mechanism.Password != "secret" {
+		t.Fatalf("unexpected sasl mechanism: %#v", dialer.SASLMechanism)
+	}
+	if dialer.TLS == nil || !dialer.TLS.InsecureSkipVerify {
+		t.Fatalf("expected tls config with InsecureSkipVerify, got %#v", dialer.TLS)
+	}
+
+	for _, mechanism := range []string{SASLScramSHA256, SASLScramSHA512} {
+		transport, err := Transport(SASLConfig{Mechanism: mechanism, Username: "user", Password: "secret"}, TLSConfig{})
+		if err != nil {
+			t.Fatalf("%s: Transport() returned error: %v", mechanism, err)
+		}
+		if transport.SASL == nil || transport.SASL.Name() != mechanism {
+			t.Fatalf("%s: unexpected sasl mechanism %#v", mechanism, transport.SASL)
+		}
+	}
+}
+
+func TestDialerWithoutSecurityIsPlaintext(t *testing.T) {
+	dialer, err := Dialer(SASLConfig{}, TLSConfig{})
+	if err != nil {
+		t.Fatalf("Dialer() returned error: %v", err)
+	}
+	if dialer.SASLMechanism != nil || dialer.TLS != nil {
+		t.Fatalf("expected plaintext dialer, got %#v", dialer)
+	}
+}
+
+func TestTLSConfigRejectsInvalidCAFile(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("write ca file: %v", err)
+	}
+
+	if _, err := (TLSConfig{Enabled: true, CAFile: caFile}).Config(); err == nil {
+		t.Fatalf("expected error for ca file without certificates")
+	}
+	if _, err := (TLSConfig{Enabled: true, CAFile: caFile + ".missing"}).Config(); err == nil {
+		t.Fatalf("expected error for missing ca file")
+	}
+}
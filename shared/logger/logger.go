@@ -0,0 +1,29 @@
+// Package logger хранит логгер запроса в контексте. Общий для всех сервисов, чтобы пакеты
+// модуля shared писали в тот же логгер, что и вызывающий сервис
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type ctxKey struct{}
+
+// WithContext возвращает контекст, в который вложен логгер
+func WithContext(ctx context.Context, lg *zap.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, lg)
+}
+
+// FromContext извлекает логгер из контекста.
+// Если его нет — возвращает "noop" логгер.
+func FromContext(ctx context.Context) *zap.Logger {
+	v := ctx.Value(ctxKey{})
+	if v == nil {
+		return zap.NewNop()
+	}
+	if lg, ok := v.(*zap.Logger); ok {
+		return lg
+	}
+	return zap.NewNop()
+}
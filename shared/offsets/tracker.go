@@ -0,0 +1,166 @@
+// Package offsets определяет, до какого смещения можно подтвердить партиции Kafka, когда
+// сообщения обрабатываются не по порядку
+package offsets
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Partition - партиция топика
+type Partition struct {
+	Topic     string
+	Partition int
+}
+
+// Offset - смещение сообщения в партиции
+type Offset struct {
+	Topic     string
+	Partition int
+	Offset    int64
+}
+
+// partitionOffsets - смещения партиции, выданные consumer'ом и еще не подтвержденные
+type partitionOffsets struct {
+	// fetched - смещения в порядке выдачи, начиная с самого раннего неподтвержденного
+	fetched []int64
+	done    map[int64]struct{}
+}
+
+// Tracker подтверждает только самое старшее смещение, до которого обработаны все выданные
+// сообщения партиции: смещение в Kafka означает, что все сообщения до него обработаны, и
+// подтверждение дальше необработанного сообщения его потеряет.
+//
+// Подтверждение двухфазное: Pending считает смещения для коммита, не меняя состояние, а
+// Processed применяет их после успешного коммита в брокере. Если коммит не удался, те же
+// сообщения можно подтвердить повторно
+type Tracker struct {
+	mu         sync.Mutex
+	partitions map[Partition]*partitionOffsets
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{partitions: make(map[Partition]*partitionOffsets)}
+}
+
+// Fetched запоминает выданное сообщение. Смещение не больше уже выданного означает повторную
+// выдачу партиции после ребалансировки: прежние неподтвержденные смещения сбрасываются
+func (t *Tracker) Fetched(topic string, partition int, offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := Partition{Topic: topic, Partition: partition}
+	p, ok := t.partitions[key]
+	if !ok || (len(p.fetched) > 0 && offset <= p.fetched[len(p.fetched)-1]) {
+		p = &partitionOffsets{done: make(map[int64]struct{})}
+		t.partitions[key] = p
+	}
+	p.fetched = append(p.fetched, offset)
+}
+
+// Pending возвращает смещения, до которых можно подтвердить партиции, если отметить offsets
+// обработанными. Партиции, подтверждение которых не продвигается, в результат не попадают
+func (t *Tracker) Pending(offsets ...Offset) (map[Partition]int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pending := make(map[Partition]map[int64]struct{})
+	for _, o := range offsets {
+		key := Partition{Topic: o.Topic, Partition: o.Partition}
+		p, found := t.partitions[key]
+		if !found || !p.contains(o.Offset) {
+			return nil, fmt.Errorf("offset %d of %s[%d] was not fetched or is already committed", o.Offset, o.Topic, o.Partition)
+		}
+		if pending[key] == nil {
+			pending[key] = make(map[int64]struct{})
+		}
+		pending[key][o.Offset] = struct{}{}
+	}
+
+	commits := make(map[Partition]int64)
+	for key, offsetsPending := range pending {
+		p := t.partitions[key]
+		for _, offset := range p.fetched {
+			_, isDone := p.done[offset]
+			_, isPending := offsetsPending[offset]
+			if !isDone && !isPending {
+				break
+			}
+			commits[key] = offset
+		}
+	}
+	return commits, nil
+}
+
+// Processed отмечает сообщения обработанными. Вызывается после коммита смещений, посчитанных
+// Pending. Смещения, забытые после повторной выдачи партиции, пропускаются
+func (t *Tracker) Processed(offsets ...Offset) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, o := range offsets {
+		p, found := t.partitions[Partition{Topic: o.Topic, Partition: o.Partition}]
+		if !found || !p.contains(o.Offset) {
+			continue
+		}
+		p.done[o.Offset] = struct{}{}
+
+		for len(p.fetched) > 0 {
+			head := p.fetched[0]
+			if _, isDone := p.done[head]; !isDone {
+				break
+			}
+			delete(p.done, head)
+			p.fetched = p.fetched[1:]
+		}
+	}
+}
+
+// Committed забывает смещения партиции ниже offset, подтвержденного в consumer group: сообщения
+// до него уже обработаны, возможно, другим экземпляром. Так снимаются смещения, оставшиеся от
+// прежнего владения партицией: если после ребалансировки партиция вернулась дальше них, Fetched
+// их не сбросит, и Pending навсегда остановился бы на первом из них
+func (t *Tracker) Committed(topic string, partition int, offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := Partition{Topic: topic, Partition: partition}
+	p, ok := t.partitions[key]
+	if !ok {
+		return
+	}
+
+	for len(p.fetched) > 0 && p.fetched[0] < offset {
+		delete(p.done, p.fetched[0])
+		p.fetched = p.fetched[1:]
+	}
+	if len(p.fetched) == 0 {
+		delete(t.partitions, key)
+	}
+}
+
+// Partitions возвращает партиции с неподтвержденными смещениями
+func (t *Tracker) Partitions() []Partition {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	partitions := make([]Partition, 0, len(t.partitions))
+	for key, p := range t.partitions {
+		if len(p.fetched) > 0 {
+			partitions = append(partitions, key)
+		}
+	}
+	return partitions
+}
+
+func (p *partitionOffsets) contains(offset int64) bool {
+	if len(p.fetched) == 0 || offset < p.fetched[0] || offset > p.fetched[len(p.fetched)-1] {
+		return false
+	}
+	for _, fetched := range p.fetched {
+		if fetched == offset {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package offsets
+
+import "testing"
+
+// commit подтверждает сообщения так же, как consumer после успешного коммита в брокере
+func commit(t *testing.T, tracker *Tracker, offsets ...Offset) map[Partition]int64 {
+	t.Helper()
+
+	commits, err := tracker.Pending(offsets...)
+	if err != nil {
+		t.Fatalf("Pending(%v) returned error: %v", offsets, err)
+	}
+	tracker.Processed(offsets...)
+	return commits
+}
+
+func TestTrackerCommitsContiguousOffsets(t *testing.T) {
+	tracker := NewTracker()
+	for _, offset := range []int64{10, 11, 13, 14} {
+		tracker.Fetched("events", 0, offset)
+	}
+	events := Partition{Topic: "events", Partition: 0}
+
+	// 11 и 13 обработаны раньше 10: подтверждать нечего
+	for _, offset := range []int64{11, 13} {
+		if commits := commit(t, tracker, Offset{Topic: "events", Offset: offset}); len(commits) != 0 {
+			t.Fatalf("offset %d: expected no commit before 10 is processed, got %v", offset, commits)
+		}
+	}
+
+	if commits := commit(t, tracker, Offset{Topic: "events", Offset: 10}); commits[events] != 13 {
+		t.Fatalf("expected commit up to 13, got %v", commits)
+	}
+	if commits := commit(t, tracker, Offset{Topic: "events", Offset: 14}); commits[events] != 14 {
+		t.Fatalf("expected commit up to 14, got %v", commits)
+	}
+}
+
+func TestTrackerSeparatesPartitions(t *testing.T) {
+	tracker := NewTracker()
+	tracker.Fetched("events", 0, 5)
+	tracker.Fetched("events", 1, 5)
+	tracker.Fetched("events", 0, 6)
+
+	if commits := commit(t, tracker, Offset{Topic: "events", Partition: 0, Offset: 6}); len(commits) != 0 {
+		t.Fatalf("partition 0 must wait for offset 5, got %v", commits)
+	}
+	commits := commit(t, tracker, Offset{Topic: "events", Partition: 1, Offset: 5})
+	if len(commits) != 1 || commits[Partition{Topic: "events", Partition: 1}] != 5 {
+		t.Fatalf("expected partition 1 committed up to 5, got %v", commits)
+	}
+}
+
+func TestTrackerRejectsUnknownOffsets(t *testing.T) {
+	tracker := NewTracker()
+	tracker.Fetched("events", 0, 5)
+
+	if _, err := tracker.Pending(Offset{Topic: "events", Offset: 4}); err == nil {
+		t.Fatalf("expected error for offset that was not fetched")
+	}
+	commit(t, tracker, Offset{Topic: "events", Offset: 5})
+	if _, err := tracker.Pending(Offset{Topic: "events", Offset: 5}); err == nil {
+		t.Fatalf("expected error for offset that is already committed")
+	}
+}
+
+func TestTrackerResetsOnRedelivery(t *testing.T) {
+	tracker := NewTracker()
+	tracker.Fetched("events", 0, 5)
+	tracker.Fetched("events", 0, 6)
+
+	// После ребалансировки партиция снова выдается с последнего подтвержденного смещения
+	tracker.Fetched("events", 0, 5)
+
+	if commits := commit(t, tracker, Offset{Topic: "events", Offset: 5}); commits[Partition{Topic: "events"}] != 5 {
+		t.Fatalf("expected commit up to 5 after redelivery, got %v", commits)
+	}
+	if _, err := tracker.Pending(Offset{Topic: "events", Offset: 6}); err == nil {
+		t.Fatalf("offset fetched before redelivery must be forgotten")
+	}
+}
+
+func TestTrackerPendingDoesNotChangeState(t *testing.T) {
+	tracker := NewTracker()
+	for _, offset := range []int64{10, 11, 12} {
+		tracker.Fetched("events", 0, offset)
+	}
+	commit(t, tracker, Offset{Topic: "events", Offset: 11})
+	events := Partition{Topic: "events", Partition: 0}
+
+	// Коммит в брокере не удался: Processed не вызывается, и те же сообщения подтверждаются повторно
+	msg := Offset{Topic: "events", Offset: 10}
+	commits, err := tracker.Pending(msg)
+	if err != nil || commits[events] != 11 {
+		t.Fatalf("expected pending commit up to 11, got %v err=%v", commits, err)
+	}
+	if commits := commit(t, tracker, msg); commits[events] != 11 {
+		t.Fatalf("expected commit up to 11 on retry, got %v", commits)
+	}
+
+	if _, err := tracker.Pending(msg); err == nil {
+		t.Fatalf("expected error for offset that is already committed")
+	}
+}
+
+func TestTrackerCommittedDropsOffsetsFromPreviousOwnership(t *testing.T) {
+	tracker := NewTracker()
+	events := Partition{Topic: "events", Partition: 0}
+	tracker.Fetched("events", 0, 5)
+	tracker.Fetched("events", 0, 6)
+
+	// Коммит 5 и 6 не удался, партицию забрали, другой экземпляр подтвердил ее до 20, и она
+	// вернулась со смещения 20
+	tracker.Fetched("events", 0, 20)
+	tracker.Committed("events", 0, 20)
+
+	if commits := commit(t, tracker, Offset{Topic: "events", Offset: 20}); commits[events] != 20 {
+		t.Fatalf("expected commit up to 20 after stale offsets are dropped, got %v", commits)
+	}
+	if _, err := tracker.Pending(Offset{Topic: "events", Offset: 5}); err == nil {
+		t.Fatalf("offset below the group commit must be forgotten")
+	}
+	if partitions := tracker.Partitions(); len(partitions) != 0 {
+		t.Fatalf("expected no partitions with pending offsets, got %v", partitions)
+	}
+}
+
+func TestTrackerCommittedKeepsInFlightOffsets(t *testing.T) {
+	tracker := NewTracker()
+	events := Partition{Topic: "events", Partition: 0}
+	for _, offset := range []int64{10, 11, 12} {
+		tracker.Fetched("events", 0, offset)
+	}
+
+	// Подтвержденное в группе смещение не дальше выданных: ничего не забывается
+	tracker.Committed("events", 0, 10)
+
+	if commits := commit(t, tracker, Offset{Topic: "events", Offset: 11}); len(commits) != 0 {
+		t.Fatalf("offset 11 must wait for 10, got %v", commits)
+	}
+	if commits := commit(t, tracker, Offset{Topic: "events", Offset: 10}); commits[events] != 11 {
+		t.Fatalf("expected commit up to 11, got %v", commits)
+	}
+	if partitions := tracker.Partitions(); len(partitions) != 1 || partitions[0] != events {
+		t.Fatalf("expected events[0] with pending offsets, got %v", partitions)
+	}
+}
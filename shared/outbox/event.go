@@ -0,0 +1,83 @@
+package outbox
+
+import (
+	"errors"
+	"time"
+)
+
+// Состояния записи outbox
+const (
+	StatePending = "pending"
+	StateSent    = "sent"
+	StateFailed  = "failed"
+)
+
+// Заголовки, которые relay выставляет каждому сообщению. Получатель дедуплицирует события по HeaderID
+const (
+	HeaderID            = "outbox_uuid"
+	HeaderEventType     = "event_type"
+	HeaderAggregateType = "aggregate_type"
+	HeaderAggregateID   = "aggregate_id"
+)
+
+// Event - событие агрегата для публикации в Kafka. События одного агрегата
+// (AggregateType, AggregateID) публикуются в порядке записи, AggregateID служит ключом сообщения
+type Event struct {
+	// ID - идентификатор события. Если пустой, генерируется базой при записи
+	ID            string
+	AggregateType string
+	AggregateID   string
+	EventType     string
+	Topic         string
+	Headers       map[string]string
+	Payload       []byte
+
+	State     string
+	Attempts  int32
+	LastError *string
+	CreatedAt time.Time
+}
+
+func (e Event) Validate() error {
+	switch {
+	case e.AggregateType == "":
+		return errors.New("aggregate type cannot be empty")
+	case e.AggregateID == "":
+		return errors.New("aggregate id cannot be empty")
+	case e.EventType == "":
+		return errors.New("event type cannot be empty")
+	case e.Topic == "":
+		return errors.New("topic cannot be empty")
+	case e.Payload == nil:
+		return errors.New("payload cannot be nil")
+	}
+	return nil
+}
+
+// Message - сообщение Kafka, собранное из события
+type Message struct {
+	Topic   string
+	Key     string
+	Value   []byte
+	Headers map[string][]byte
+}
+
+// Message собирает сообщение: пользовательские заголовки дополняются служебными, служебные
+// заголовки перезаписывают пользовательские с тем же именем
+func (e Event) Message() Message {
+	headers := make(map[string][]byte, len(e.Headers)+4)
+	for name, value := range e.Headers {
+		headers[name] = []byte(value)
+	}
+	headers[HeaderID] = []byte(e.ID)
+	headers[HeaderEventType] = []byte(e.EventType)
+	headers[HeaderAggregateType] = []byte(e.AggregateType)
+	headers[HeaderAggregateID] = []byte(e.AggregateID)
+
+	return Message{
+		Topic:   e.Topic,
+		Key:     e.AggregateID,
+		Value:   e.Payload,
+		Headers: headers,
+	}
+}
@@ -0,0 +1,254 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: outbox_queries.sql
+
+package outboxdb
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimOutboxEvents = `-- name: ClaimOutboxEvents :many
+UPDATE outbox
+SET attempts = attempts + 1,
+    next_attempt_at = NOW() + $1::integer * INTERVAL '1 millisecond',
+    updated_at = NOW()
+WHERE id IN (
+    SELECT o.id FROM outbox o
+    WHERE o.state = 'pending'
+      AND o.next_attempt_at <= NOW()
+      AND NOT EXISTS (
+          SELECT 1 FROM outbox prev
+          WHERE prev.aggregate_type = o.aggregate_type
+            AND prev.aggregate_id = o.aggregate_id
+            AND prev.seq < o.seq
+            AND prev.state <> 'sent'
+      )
+    ORDER BY o.seq
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING seq, id::text AS id, aggregate_type, aggregate_id, event_type, topic, headers, payload,
+    state, attempts, last_error, created_at
+`
+
+type ClaimOutboxEventsParams struct {
+	LeaseMs    int32 `json:"lease_ms"`
+	BatchLimit int32 `json:"batch_limit"`
+}
+
+type ClaimOutboxEventsRow struct {
+	Seq           int64            `json:"seq"`
+	ID            string           `json:"id"`
+	AggregateType string           `json:"aggregate_type"`
+	AggregateID   string           `json:"aggregate_id"`
+	EventType     string           `json:"event_type"`
+	Topic         string           `json:"topic"`
+	Headers       []byte           `json:"headers"`
+	Payload       []byte           `json:"payload"`
+	State         string           `json:"state"`
+	Attempts      int32            `json:"attempts"`
+	LastError     *string          `json:"last_error"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+}
+
+// Захватываются только самые ранние неотправленные события каждого агрегата: следующее событие
+// агрегата не уйдет, пока предыдущее не отправлено, что сохраняет порядок по ключу.
+// next_attempt_at сдвигается на lease, чтобы другой relay не взял те же события
+func (q *Queries) ClaimOutboxEvents(ctx context.Context, arg ClaimOutboxEventsParams) ([]ClaimOutboxEventsRow, error) {
+	rows, err := q.db.Query(ctx, claimOutboxEvents, arg.LeaseMs, arg.BatchLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ClaimOutboxEventsRow{}
+	for rows.Next() {
+		var i ClaimOutboxEventsRow
+		if err := rows.Scan(
+			&i.Seq,
+			&i.ID,
+			&i.AggregateType,
+			&i.AggregateID,
+			&i.EventType,
+			&i.Topic,
+			&i.Headers,
+			&i.Payload,
+			&i.State,
+			&i.Attempts,
+			&i.LastError,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markOutboxEventsSent = `-- name: MarkOutboxEventsSent :exec
+UPDATE outbox
+SET state = 'sent',
+    last_error = NULL,
+    updated_at = NOW()
+WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) MarkOutboxEventsSent(ctx context.Context, ids []pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, markOutboxEventsSent, ids)
+	return err
+}
+
+const publishOutboxEvent = `-- name: PublishOutboxEvent :exec
+INSERT INTO outbox (id, aggregate_type, aggregate_id, event_type, topic, headers, payload)
+VALUES (
+    COALESCE(NULLIF($1::text, '')::uuid, gen_random_uuid()),
+    $2, $3, $4, $5,
+    $6, $7
+)
+`
+
+type PublishOutboxEventParams struct {
+	ID            string `json:"id"`
+	AggregateType string `json:"aggregate_type"`
+	AggregateID   string `json:"aggregate_id"`
+	EventType     string `json:"event_type"`
+	Topic         string `json:"topic"`
+	Headers       []byte `json:"headers"`
+	Payload       []byte `json:"payload"`
+}
+
+// Пустой id генерируется базой
+func (q *Queries) PublishOutboxEvent(ctx context.Context, arg PublishOutboxEventParams) error {
+	_, err := q.db.Exec(ctx, publishOutboxEvent,
+		arg.ID,
+		arg.AggregateType,
+		arg.AggregateID,
+		arg.EventType,
+		arg.Topic,
+		arg.Headers,
+		arg.Payload,
+	)
+	return err
+}
+
+const purgeSentOutboxEvents = `-- name: PurgeSentOutboxEvents :many
+DELETE FROM outbox
+WHERE id IN (
+    SELECT id FROM outbox
+    WHERE state = 'sent'
+      AND updated_at < NOW() - $1::integer * INTERVAL '1 second'
+    ORDER BY updated_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING seq, id::text AS id, aggregate_type, aggregate_id, event_type, topic, headers, payload,
+    state, attempts, last_error, created_at
+`
+
+type PurgeSentOutboxEventsParams struct {
+	OlderThanSeconds int32 `json:"older_than_seconds"`
+	BatchLimit       int32 `json:"batch_limit"`
+}
+
+type PurgeSentOutboxEventsRow struct {
+	Seq           int64            `json:"seq"`
+	ID            string           `json:"id"`
+	AggregateType string           `json:"aggregate_type"`
+	AggregateID   string           `json:"aggregate_id"`
+	EventType     string           `json:"event_type"`
+	Topic         string           `json:"topic"`
+	Headers       []byte           `json:"headers"`
+	Payload       []byte           `json:"payload"`
+	State         string           `json:"state"`
+	Attempts      int32            `json:"attempts"`
+	LastError     *string          `json:"last_error"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+}
+
+func (q *Queries) PurgeSentOutboxEvents(ctx context.Context, arg PurgeSentOutboxEventsParams) ([]PurgeSentOutboxEventsRow, error) {
+	rows, err := q.db.Query(ctx, purgeSentOutboxEvents, arg.OlderThanSeconds, arg.BatchLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []PurgeSentOutboxEventsRow{}
+	for rows.Next() {
+		var i PurgeSentOutboxEventsRow
+		if err := rows.Scan(
+			&i.Seq,
+			&i.ID,
+			&i.AggregateType,
+			&i.AggregateID,
+			&i.EventType,
+			&i.Topic,
+			&i.Headers,
+			&i.Payload,
+			&i.State,
+			&i.Attempts,
+			&i.LastError,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rescheduleOutboxEvents = `-- name: RescheduleOutboxEvents :many
+UPDATE outbox
+SET state = CASE WHEN attempts >= $1::integer THEN 'failed' ELSE 'pending' END,
+    last_error = $2::text,
+    next_attempt_at = NOW() + LEAST(
+        $3::bigint * power(2, GREATEST(attempts - 1, 0))::bigint,
+        $4::bigint
+    ) * INTERVAL '1 millisecond',
+    updated_at = NOW()
+WHERE id = ANY($5::uuid[])
+RETURNING state
+`
+
+type RescheduleOutboxEventsParams struct {
+	MaxAttempts int32         `json:"max_attempts"`
+	LastError   string        `json:"last_error"`
+	BaseDelayMs int64         `json:"base_delay_ms"`
+	MaxDelayMs  int64         `json:"max_delay_ms"`
+	Ids         []pgtype.UUID `json:"ids"`
+}
+
+// Задержка следующей попытки base_delay_ms * 2^(attempts-1), но не больше max_delay_ms.
+// События, исчерпавшие max_attempts попыток, переводятся в failed и блокируют свой агрегат
+func (q *Queries) RescheduleOutboxEvents(ctx context.Context, arg RescheduleOutboxEventsParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, rescheduleOutboxEvents,
+		arg.MaxAttempts,
+		arg.LastError,
+		arg.BaseDelayMs,
+		arg.MaxDelayMs,
+		arg.Ids,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var state string
+		if err := rows.Scan(&state); err != nil {
+			return nil, err
+		}
+		items = append(items, state)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
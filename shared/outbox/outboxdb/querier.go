@@ -0,0 +1,27 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package outboxdb
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Querier interface {
+	// Захватываются только самые ранние неотправленные события каждого агрегата: следующее событие
+	// агрегата не уйдет, пока предыдущее не отправлено, что сохраняет порядок по ключу.
+	// next_attempt_at сдвигается на lease, чтобы другой relay не взял те же события
+	ClaimOutboxEvents(ctx context.Context, arg ClaimOutboxEventsParams) ([]ClaimOutboxEventsRow, error)
+	MarkOutboxEventsSent(ctx context.Context, ids []pgtype.UUID) error
+	// Пустой id генерируется базой
+	PublishOutboxEvent(ctx context.Context, arg PublishOutboxEventParams) error
+	PurgeSentOutboxEvents(ctx context.Context, arg PurgeSentOutboxEventsParams) ([]PurgeSentOutboxEventsRow, error)
+	// Задержка следующей попытки base_delay_ms * 2^(attempts-1), но не больше max_delay_ms.
+	// События, исчерпавшие max_attempts попыток, переводятся в failed и блокируют свой агрегат
+	RescheduleOutboxEvents(ctx context.Context, arg RescheduleOutboxEventsParams) ([]string, error)
+}
+
+var _ Querier = (*Queries)(nil)
@@ -0,0 +1,180 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"shared/logger"
+
+	"go.uber.org/zap"
+)
+
+// Sender отправляет сообщения в Kafka
+type Sender interface {
+	Send(ctx context.Context, msgs []Message) error
+}
+
+// PartialError - ошибка Sender, по которой известно, какие сообщения батча не доставлены.
+// Если ошибка отправки ее не реализует, недоставленным считается весь батч
+type PartialError interface {
+	error
+	Failed(i int) bool
+}
+
+type Transactor interface {
+	WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error
+}
+
+// Hook вызывается после публикации событий своего типа в той же транзакции, в которой
+// события помечаются отправленными. Ошибка хука откатывает транзакцию, и события будут
+// опубликованы повторно после истечения lease
+type Hook func(ctx context.Context, events []Event) error
+
+type RetryPolicy struct {
+	// MaxAttempts - количество попыток публикации, после которого событие переводится в failed
+	MaxAttempts int32
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+type Config struct {
+	BatchSize int32
+	// Lease - на сколько захваченные события скрываются от других relay. Должен быть больше
+	// времени отправки батча, иначе события могут уйти дважды
+	Lease time.Duration
+	Retry RetryPolicy
+}
+
+type storage interface {
+	claim(ctx context.Context, limit int32, lease time.Duration) ([]Event, error)
+	markSent(ctx context.Context, ids []string) error
+	reschedule(ctx context.Context, ids []string, lastError string, retry RetryPolicy) (int, error)
+}
+
+// Relay публикует события из outbox в Kafka
+type Relay struct {
+	store      storage
+	transactor Transactor
+	sender     Sender
+	hooks      map[string]Hook
+	cfg        Config
+}
+
+func NewRelay(store *Store, transactor Transactor, sender Sender, cfg Config) *Relay {
+	return &Relay{
+		store:      store,
+		transactor: transactor,
+		sender:     sender,
+		hooks:      make(map[string]Hook),
+		cfg:        cfg,
+	}
+}
+
+// Handle регистрирует хук после публикации событий eventType. Вызывается до запуска relay
+func (r *Relay) Handle(eventType string, hook Hook) {
+	r.hooks[eventType] = hook
+}
+
+// ProcessBatch публикует до cfg.BatchSize событий и возвращает количество доставленных.
+// Доставленные события помечаются sent вместе с вызовом хуков, недоставленные откладываются
+// с экспоненциальной задержкой
+func (r *Relay) ProcessBatch(ctx context.Context) (int, error) {
+	log := logger.FromContext(ctx)
+
+	events, err := r.store.claim(ctx, r.cfg.BatchSize, r.cfg.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("claim outbox events: %w", err)
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	msgs := make([]Message, len(events))
+	for i, event := range events {
+		msgs[i] = event.Message()
+	}
+
+	sendErr := r.sender.Send(ctx, msgs)
+	delivered, undelivered := splitSendResult(events, sendErr)
+
+	if len(delivered) > 0 {
+		if err := r.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+			return r.markSent(txCtx, delivered)
+		}); err != nil {
+			return 0, fmt.Errorf("mark outbox events sent: %w", err)
+		}
+	}
+
+	if len(undelivered) > 0 {
+		failed, err := r.store.reschedule(ctx, eventIDs(undelivered), sendErr.Error(), r.cfg.Retry)
+		if err != nil {
+			return len(delivered), fmt.Errorf("reschedule outbox events: %w", err)
+		}
+		log.Error("failed to publish outbox events",
+			zap.Int("undelivered_count", len(undelivered)),
+			zap.Int("failed_count", failed),
+			zap.Error(sendErr),
+		)
+		return len(delivered), fmt.Errorf("send outbox events: %w", sendErr)
+	}
+
+	log.Info("outbox batch published", zap.Int("processed_count", len(delivered)))
+	return len(delivered), nil
+}
+
+// markSent помечает события отправленными и вызывает хуки, группируя события по типу
+// с сохранением порядка публикации
+func (r *Relay) markSent(ctx context.Context, events []Event) error {
+	if err := r.store.markSent(ctx, eventIDs(events)); err != nil {
+		return err
+	}
+
+	var order []string
+	byType := make(map[string][]Event)
+	for _, event := range events {
+		if _, ok := r.hooks[event.EventType]; !ok {
+			continue
+		}
+		if _, ok := byType[event.EventType]; !ok {
+			order = append(order, event.EventType)
+		}
+		byType[event.EventType] = append(byType[event.EventType], event)
+	}
+
+	for _, eventType := range order {
+		if err := r.hooks[eventType](ctx, byType[eventType]); err != nil {
+			return fmt.Errorf("%s hook: %w", eventType, err)
+		}
+	}
+	return nil
+}
+
+func splitSendResult(events []Event, sendErr error) (delivered, undelivered []Event) {
+	if sendErr == nil {
+		return events, nil
+	}
+
+	var partial PartialError
+	if !errors.As(sendErr, &partial) {
+		return nil, events
+	}
+
+	for i, event := range events {
+		if partial.Failed(i) {
+			undelivered = append(undelivered, event)
+		} else {
+			delivered = append(delivered, event)
+		}
+	}
+	return delivered, undelivered
+}
+
+func eventIDs(events []Event) []string {
+	ids := make([]string, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	return ids
+}
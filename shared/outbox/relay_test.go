@@ -0,0 +1,139 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeStorage struct {
+	pending     []Event
+	sent        []string
+	rescheduled []string
+}
+
+func (s *fakeStorage) claim(ctx context.Context, limit int32, lease time.Duration) ([]Event, error) {
+	return s.pending, nil
+}
+
+func (s *fakeStorage) markSent(ctx context.Context, ids []string) error {
+	s.sent = append(s.sent, ids...)
+	return nil
+}
+
+func (s *fakeStorage) reschedule(ctx context.Context, ids []string, lastError string, retry RetryPolicy) (int, error) {
+	s.rescheduled = append(s.rescheduled, ids...)
+	return 0, nil
+}
+
+type fakeTransactor struct{}
+
+func (fakeTransactor) WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error {
+	return tFunc(ctx)
+}
+
+type fakeSender struct {
+	err  error
+	sent []Message
+}
+
+func (s *fakeSender) Send(ctx context.Context, msgs []Message) error {
+	s.sent = append(s.sent, msgs...)
+	return s.err
+}
+
+type partialError struct {
+	failed map[int]bool
+}
+
+func (e partialError) Error() string     { return "partial failure" }
+func (e partialError) Failed(i int) bool { return e.failed[i] }
+
+func newTestRelay(store storage, sender Sender) *Relay {
+	return &Relay{
+		store:      store,
+		transactor: fakeTransactor{},
+		sender:     sender,
+		hooks:      make(map[string]Hook),
+		cfg:        Config{BatchSize: 10, Lease: time.Minute},
+	}
+}
+
+func testEvents() []Event {
+	return []Event{
+		{ID: "1", AggregateType: "scenario", AggregateID: "a", EventType: "started", Topic: "t", Payload: []byte("{}")},
+		{ID: "2", AggregateType: "scenario", AggregateID: "b", EventType: "failed", Topic: "t", Payload: []byte("{}")},
+		{ID: "3", AggregateType: "scenario", AggregateID: "c", EventType: "started", Topic: "t", Payload: []byte("{}")},
+	}
+}
+
+func TestProcessBatchMarksSentAndRunsHooksByEventType(t *testing.T) {
+	store := &fakeStorage{pending: testEvents()}
+	sender := &fakeSender{}
+	relay := newTestRelay(store, sender)
+
+	var started []string
+	relay.Handle("started", func(ctx context.Context, events []Event) error {
+		for _, event := range events {
+			started = append(started, event.ID)
+		}
+		return nil
+	})
+
+	processed, err := relay.ProcessBatch(context.Background())
+	if err != nil {
+		t.Fatalf("ProcessBatch returned error: %v", err)
+	}
+	if processed != 3 || len(store.sent) != 3 {
+		t.Fatalf("expected 3 events marked sent, got processed=%d sent=%v", processed, store.sent)
+	}
+	if len(started) != 2 || started[0] != "1" || started[1] != "3" {
+		t.Fatalf("expected started hook for events 1 and 3, got %v", started)
+	}
+
+	msg := sender.sent[1]
+	if msg.Key != "b" || string(msg.Headers[HeaderID]) != "2" || string(msg.Headers[HeaderEventType]) != "failed" {
+		t.Fatalf("unexpected message for event 2: %+v", msg)
+	}
+}
+
+func TestProcessBatchReschedulesOnlyUndeliveredEvents(t *testing.T) {
+	store := &fakeStorage{pending: testEvents()}
+	sender := &fakeSender{err: partialError{failed: map[int]bool{1: true}}}
+	relay := newTestRelay(store, sender)
+
+	processed, err := relay.ProcessBatch(context.Background())
+	if err == nil {
+		t.Fatalf("expected send error")
+	}
+	if processed != 2 {
+		t.Fatalf("expected 2 delivered events, got %d", processed)
+	}
+	if len(store.rescheduled) != 1 || store.rescheduled[0] != "2" {
+		t.Fatalf("expected only event 2 rescheduled, got %v", store.rescheduled)
+	}
+}
+
+func TestProcessBatchReschedulesWholeBatchOnUnknownError(t *testing.T) {
+	store := &fakeStorage{pending: testEvents()}
+	relay := newTestRelay(store, &fakeSender{err: errors.New("broker unavailable")})
+
+	if _, err := relay.ProcessBatch(context.Background()); err == nil {
+		t.Fatalf("expected send error")
+	}
+	if len(store.sent) != 0 || len(store.rescheduled) != 3 {
+		t.Fatalf("expected whole batch rescheduled, got sent=%v rescheduled=%v", store.sent, store.rescheduled)
+	}
+}
+
+func TestProcessBatchDoesNotMarkSentWhenHookFails(t *testing.T) {
+	store := &fakeStorage{pending: testEvents()}
+	relay := newTestRelay(store, &fakeSender{})
+	hookErr := errors.New("hook failed")
+	relay.Handle("failed", func(ctx context.Context, events []Event) error { return hookErr })
+
+	if _, err := relay.ProcessBatch(context.Background()); !errors.Is(err, hookErr) {
+		t.Fatalf("expected hook error, got %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"shared/outbox/outboxdb"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DBTX - соединение, в котором выполняются запросы: пул или транзакция
+type DBTX = outboxdb.DBTX
+
+// Store работает с таблицей outbox. conn возвращает транзакцию из ctx, если она есть, поэтому
+// события, записанные внутри Repository.WithinTransaction, коммитятся вместе с изменениями агрегата.
+// Запросы генерируются sqlc из queries/outbox_queries.sql модуля
+type Store struct {
+	conn func(ctx context.Context) DBTX
+}
+
+func NewStore(conn func(ctx context.Context) DBTX) *Store {
+	return &Store{conn: conn}
+}
+
+func (s *Store) queries(ctx context.Context) *outboxdb.Queries {
+	return outboxdb.New(s.conn(ctx))
+}
+
+// Publish записывает событие в outbox. Вызывается с контекстом транзакции, в которой меняется агрегат
+func (s *Store) Publish(ctx context.Context, event Event) error {
+	if err := event.Validate(); err != nil {
+		return fmt.Errorf("invalid outbox event: %w", err)
+	}
+
+	headers := event.Headers
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	headersJSON, err := json.Marshal(headers)
+	if err != nil {
+		return fmt.Errorf("marshal outbox headers: %w", err)
+	}
+
+	if err := s.queries(ctx).PublishOutboxEvent(ctx, outboxdb.PublishOutboxEventParams{
+		ID:            event.ID,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		EventType:     event.EventType,
+		Topic:         event.Topic,
+		Headers:       headersJSON,
+		Payload:       event.Payload,
+	}); err != nil {
+		return fmt.Errorf("insert outbox event: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) claim(ctx context.Context, limit int32, lease time.Duration) ([]Event, error) {
+	rows, err := s.queries(ctx).ClaimOutboxEvents(ctx, outboxdb.ClaimOutboxEventsParams{
+		LeaseMs:    int32(lease.Milliseconds()),
+		BatchLimit: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING не сохраняет порядок подзапроса
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Seq < rows[j].Seq })
+	events := make([]Event, 0, len(rows))
+	for _, row := range rows {
+		event, err := eventFromRow(row)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (s *Store) markSent(ctx context.Context, ids []string) error {
+	uuids, err := toUUIDs(ids)
+	if err != nil {
+		return err
+	}
+	return s.queries(ctx).MarkOutboxEventsSent(ctx, uuids)
+}
+
+func (s *Store) reschedule(ctx context.Context, ids []string, lastError string, retry RetryPolicy) (failed int, err error) {
+	uuids, err := toUUIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	states, err := s.queries(ctx).RescheduleOutboxEvents(ctx, outboxdb.RescheduleOutboxEventsParams{
+		MaxAttempts: retry.MaxAttempts,
+		LastError:   lastError,
+		BaseDelayMs: retry.BaseDelay.Milliseconds(),
+		MaxDelayMs:  retry.MaxDelay.Milliseconds(),
+		Ids:         uuids,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, state := range states {
+		if state == StateFailed {
+			failed++
+		}
+	}
+	return failed, nil
+}
+
+// PurgeSent удаляет до batchSize отправленных событий старше olderThan и возвращает их для архива
+func (s *Store) PurgeSent(ctx context.Context, olderThan time.Duration, batchSize int32) ([]Event, error) {
+	rows, err := s.queries(ctx).PurgeSentOutboxEvents(ctx, outboxdb.PurgeSentOutboxEventsParams{
+		OlderThanSeconds: int32(olderThan / time.Second),
+		BatchLimit:       batchSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("purge sent outbox events: %w", err)
+	}
+
+	events := make([]Event, 0, len(rows))
+	for _, row := range rows {
+		event, err := eventFromRow(outboxdb.ClaimOutboxEventsRow(row))
+		if err != nil {
+			return nil, fmt.Errorf("purge sent outbox events: %w", err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func eventFromRow(row outboxdb.ClaimOutboxEventsRow) (Event, error) {
+	event := Event{
+		ID:            row.ID,
+		AggregateType: row.AggregateType,
+		AggregateID:   row.AggregateID,
+		EventType:     row.EventType,
+		Topic:         row.Topic,
+		Payload:       row.Payload,
+		State:         row.State,
+		Attempts:      row.Attempts,
+		LastError:     row.LastError,
+		CreatedAt:     row.CreatedAt.Time,
+	}
+	if err := json.Unmarshal(row.Headers, &event.Headers); err != nil {
+		return Event{}, fmt.Errorf("unmarshal outbox headers: %w", err)
+	}
+	return event, nil
+}
+
+func toUUIDs(ids []string) ([]pgtype.UUID, error) {
+	uuids := make([]pgtype.UUID, len(ids))
+	for i, id := range ids {
+		if err := uuids[i].Scan(id); err != nil {
+			return nil, fmt.Errorf("parse outbox event id %q: %w", id, err)
+		}
+	}
+	return uuids, nil
+}
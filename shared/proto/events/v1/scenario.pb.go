@@ -0,0 +1,274 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: events/v1/scenario.proto
+
+package eventspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Payload события init_scenario: запуск сценария на камере
+type InitScenario struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScenarioUuid  string                 `protobuf:"bytes,1,opt,name=scenario_uuid,json=scenarioUuid,proto3" json:"scenario_uuid,omitempty"`
+	CameraId      int32                  `protobuf:"varint,2,opt,name=camera_id,json=cameraId,proto3" json:"camera_id,omitempty"`
+	Url           string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"` // Адрес RTSP потока камеры
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InitScenario) Reset() {
+	*x = InitScenario{}
+	mi := &file_events_v1_scenario_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InitScenario) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitScenario) ProtoMessage() {}
+
+func (x *InitScenario) ProtoReflect() protoreflect.Message {
+	mi := &file_events_v1_scenario_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitScenario.ProtoReflect.Descriptor instead.
+func (*InitScenario) Descriptor() ([]byte, []int) {
+	return file_events_v1_scenario_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *InitScenario) GetScenarioUuid() string {
+	if x != nil {
+		return x.ScenarioUuid
+	}
+	return ""
+}
+
+func (x *InitScenario) GetCameraId() int32 {
+	if x != nil {
+		return x.CameraId
+	}
+	return 0
+}
+
+func (x *InitScenario) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+// Payload событий stop_scenario и compensate_scenario: остановка сценария на камере
+type StopScenario struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScenarioUuid  string                 `protobuf:"bytes,1,opt,name=scenario_uuid,json=scenarioUuid,proto3" json:"scenario_uuid,omitempty"`
+	CameraId      int32                  `protobuf:"varint,2,opt,name=camera_id,json=cameraId,proto3" json:"camera_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"` // Причина компенсации, пустая для stop_scenario
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopScenario) Reset() {
+	*x = StopScenario{}
+	mi := &file_events_v1_scenario_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopScenario) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopScenario) ProtoMessage() {}
+
+func (x *StopScenario) ProtoReflect() protoreflect.Message {
+	mi := &file_events_v1_scenario_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopScenario.ProtoReflect.Descriptor instead.
+func (*StopScenario) Descriptor() ([]byte, []int) {
+	return file_events_v1_scenario_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StopScenario) GetScenarioUuid() string {
+	if x != nil {
+		return x.ScenarioUuid
+	}
+	return ""
+}
+
+func (x *StopScenario) GetCameraId() int32 {
+	if x != nil {
+		return x.CameraId
+	}
+	return 0
+}
+
+func (x *StopScenario) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// Payload событий scenario_started, scenario_start_failed и scenario_stopped с результатом
+// запуска или остановки сценария
+type ScenarioResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScenarioUuid  string                 `protobuf:"bytes,1,opt,name=scenario_uuid,json=scenarioUuid,proto3" json:"scenario_uuid,omitempty"`
+	CameraId      int32                  `protobuf:"varint,2,opt,name=camera_id,json=cameraId,proto3" json:"camera_id,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"` // Причина ошибки для scenario_start_failed и scenario_stopped
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScenarioResult) Reset() {
+	*x = ScenarioResult{}
+	mi := &file_events_v1_scenario_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScenarioResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScenarioResult) ProtoMessage() {}
+
+func (x *ScenarioResult) ProtoReflect() protoreflect.Message {
+	mi := &file_events_v1_scenario_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScenarioResult.ProtoReflect.Descriptor instead.
+func (*ScenarioResult) Descriptor() ([]byte, []int) {
+	return file_events_v1_scenario_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ScenarioResult) GetScenarioUuid() string {
+	if x != nil {
+		return x.ScenarioUuid
+	}
+	return ""
+}
+
+func (x *ScenarioResult) GetCameraId() int32 {
+	if x != nil {
+		return x.CameraId
+	}
+	return 0
+}
+
+func (x *ScenarioResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_events_v1_scenario_proto protoreflect.FileDescriptor
+
+const file_events_v1_scenario_proto_rawDesc = "" +
+	"\n" +
+	"\x18events/v1/scenario.proto\x12\tevents.v1\"b\n" +
+	"\fInitScenario\x12#\n" +
+	"\rscenario_uuid\x18\x01 \x01(\tR\fscenarioUuid\x12\x1b\n" +
+	"\tcamera_id\x18\x02 \x01(\x05R\bcameraId\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\"h\n" +
+	"\fStopScenario\x12#\n" +
+	"\rscenario_uuid\x18\x01 \x01(\tR\fscenarioUuid\x12\x1b\n" +
+	"\tcamera_id\x18\x02 \x01(\x05R\bcameraId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"h\n" +
+	"\x0eScenarioResult\x12#\n" +
+	"\rscenario_uuid\x18\x01 \x01(\tR\fscenarioUuid\x12\x1b\n" +
+	"\tcamera_id\x18\x02 \x01(\x05R\bcameraId\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05errorB!Z\x1fshared/proto/events/v1;eventspbb\x06proto3"
+
+var (
+	file_events_v1_scenario_proto_rawDescOnce sync.Once
+	file_events_v1_scenario_proto_rawDescData []byte
+)
+
+func file_events_v1_scenario_proto_rawDescGZIP() []byte {
+	file_events_v1_scenario_proto_rawDescOnce.Do(func() {
+		file_events_v1_scenario_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_events_v1_scenario_proto_rawDesc), len(file_events_v1_scenario_proto_rawDesc)))
+	})
+	return file_events_v1_scenario_proto_rawDescData
+}
+
+var file_events_v1_scenario_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_events_v1_scenario_proto_goTypes = []any{
+	(*InitScenario)(nil),   // 0: events.v1.InitScenario
+	(*StopScenario)(nil),   // 1: events.v1.StopScenario
+	(*ScenarioResult)(nil), // 2: events.v1.ScenarioResult
+}
+var file_events_v1_scenario_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_events_v1_scenario_proto_init() }
+func file_events_v1_scenario_proto_init() {
+	if File_events_v1_scenario_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_events_v1_scenario_proto_rawDesc), len(file_events_v1_scenario_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_events_v1_scenario_proto_goTypes,
+		DependencyIndexes: file_events_v1_scenario_proto_depIdxs,
+		MessageInfos:      file_events_v1_scenario_proto_msgTypes,
+	}.Build()
+	File_events_v1_scenario_proto = out.File
+	file_events_v1_scenario_proto_goTypes = nil
+	file_events_v1_scenario_proto_depIdxs = nil
+}
@@ -0,0 +1,68 @@
+-- name: PublishOutboxEvent :exec
+-- Пустой id генерируется базой
+INSERT INTO outbox (id, aggregate_type, aggregate_id, event_type, topic, headers, payload)
+VALUES (
+    COALESCE(NULLIF(sqlc.arg(id)::text, '')::uuid, gen_random_uuid()),
+    sqlc.arg(aggregate_type), sqlc.arg(aggregate_id), sqlc.arg(event_type), sqlc.arg(topic),
+    sqlc.arg(headers), sqlc.arg(payload)
+);
+
+-- name: ClaimOutboxEvents :many
+-- Захватываются только самые ранние неотправленные события каждого агрегата: следующее событие
+-- агрегата не уйдет, пока предыдущее не отправлено, что сохраняет порядок по ключу.
+-- next_attempt_at сдвигается на lease, чтобы другой relay не взял те же события
+UPDATE outbox
+SET attempts = attempts + 1,
+    next_attempt_at = NOW() + sqlc.arg(lease_ms)::integer * INTERVAL '1 millisecond',
+    updated_at = NOW()
+WHERE id IN (
+    SELECT o.id FROM outbox o
+    WHERE o.state = 'pending'
+      AND o.next_attempt_at <= NOW()
+      AND NOT EXISTS (
+          SELECT 1 FROM outbox prev
+          WHERE prev.aggregate_type = o.aggregate_type
+            AND prev.aggregate_id = o.aggregate_id
+            AND prev.seq < o.seq
+            AND prev.state <> 'sent'
+      )
+    ORDER BY o.seq
+    LIMIT sqlc.arg(batch_limit)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING seq, id::text AS id, aggregate_type, aggregate_id, event_type, topic, headers, payload,
+    state, attempts, last_error, created_at;
+
+-- name: MarkOutboxEventsSent :exec
+UPDATE outbox
+SET state = 'sent',
+    last_error = NULL,
+    updated_at = NOW()
+WHERE id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- name: RescheduleOutboxEvents :many
+-- Задержка следующей попытки base_delay_ms * 2^(attempts-1), но не больше max_delay_ms.
+-- События, исчерпавшие max_attempts попыток, переводятся в failed и блокируют свой агрегат
+UPDATE outbox
+SET state = CASE WHEN attempts >= sqlc.arg(max_attempts)::integer THEN 'failed' ELSE 'pending' END,
+    last_error = sqlc.arg(last_error)::text,
+    next_attempt_at = NOW() + LEAST(
+        sqlc.arg(base_delay_ms)::bigint * power(2, GREATEST(attempts - 1, 0))::bigint,
+        sqlc.arg(max_delay_ms)::bigint
+    ) * INTERVAL '1 millisecond',
+    updated_at = NOW()
+WHERE id = ANY(sqlc.arg(ids)::uuid[])
+RETURNING state;
+
+-- name: PurgeSentOutboxEvents :many
+DELETE FROM outbox
+WHERE id IN (
+    SELECT id FROM outbox
+    WHERE state = 'sent'
+      AND updated_at < NOW() - sqlc.arg(older_than_seconds)::integer * INTERVAL '1 second'
+    ORDER BY updated_at
+    LIMIT sqlc.arg(batch_limit)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING seq, id::text AS id, aggregate_type, aggregate_id, event_type, topic, headers, payload,
+    state, attempts, last_error, created_at;